@@ -0,0 +1,32 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"os"
+
+	"gorabc/pkg/logic/services"
+)
+
+// auditCommand verifies the hash chain of the audit log of an organization,
+// the platform log without -org
+func auditCommand(args []string) {
+	if len(args) == 0 || args[0] != "verify" {
+		usage()
+	}
+
+	flags := flag.NewFlagSet("audit verify", flag.ExitOnError)
+	org := flags.String("org", "", "organization id, the platform log when empty")
+	flags.Parse(args[1:])
+
+	connect()
+	verification, err := services.AuditService.Verify(context.Background(), *org, platformUser())
+	if err != nil {
+		fail("%s", err.Message)
+	}
+
+	printJSON(verification)
+	if !verification.Valid {
+		os.Exit(1)
+	}
+}
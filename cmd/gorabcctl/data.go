@@ -0,0 +1,66 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"io/ioutil"
+	"os"
+
+	"gorabc/pkg/logic/services"
+	"gorabc/pkg/models"
+)
+
+// exportCommand writes the users, departments or roles of an organization
+func exportCommand(args []string) {
+	flags := flag.NewFlagSet("export", flag.ExitOnError)
+	org := flags.String("org", "", "organization id")
+	kind := flags.String("kind", "", "users, departments or roles")
+	format := flags.String("format", models.FormatJSON, "json or csv")
+	output := flags.String("o", "", "output file, stdout when empty")
+	flags.Parse(args)
+
+	if *org == "" || *kind == "" {
+		usage()
+	}
+
+	connect()
+	data, err := services.ImportService.Export(context.Background(), *kind, *format, systemUser(*org))
+	if err != nil {
+		fail("%s", err.Message)
+	}
+
+	if *output == "" {
+		os.Stdout.Write(data)
+		return
+	}
+	if err := ioutil.WriteFile(*output, data, 0644); err != nil {
+		fail("%s", err)
+	}
+}
+
+// importCommand upserts users, departments or roles of an organization
+func importCommand(args []string) {
+	flags := flag.NewFlagSet("import", flag.ExitOnError)
+	org := flags.String("org", "", "organization id")
+	kind := flags.String("kind", "", "users, departments or roles")
+	file := flags.String("f", "", "input file, - for stdin")
+	format := flags.String("format", models.FormatJSON, "json or csv")
+	dryRun := flags.Bool("dry-run", false, "validate without writing")
+	flags.Parse(args)
+
+	if *org == "" || *kind == "" || *file == "" {
+		usage()
+	}
+
+	data := readFile(*file)
+
+	connect()
+	report, err := services.ImportService.Import(context.Background(), *kind, data, *format, *dryRun, systemUser(*org))
+	if err != nil {
+		fail("%s", err.Message)
+	}
+	printJSON(report)
+	if report.Failed > 0 {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,134 @@
+// Command gorabcctl operates gorabc directly against its database
+//
+// Usage:
+//
+//	gorabcctl seed
+//	gorabcctl migrate
+//	gorabcctl superuser create -email EMAIL -first-name NAME -last-name NAME [-password PASSWORD]
+//	gorabcctl org create -name NAME -email EMAIL -first-name NAME -last-name NAME [-website URL] [-preset KEY] [-password PASSWORD]
+//	gorabcctl org list
+//	gorabcctl user reset-password -email EMAIL [-password PASSWORD]
+//	gorabcctl check -email EMAIL -permission PERMISSION
+//	gorabcctl export -org ORG -kind users|departments|roles [-format json|csv] [-o FILE]
+//	gorabcctl import -org ORG -kind users|departments|roles -f FILE [-format json|csv] [-dry-run]
+//	gorabcctl plan  -org ORG -f manifest.yaml [-prune]
+//	gorabcctl apply -org ORG -f manifest.yaml [-prune]
+//	gorabcctl audit verify [-org ORG]
+//
+// Passwords that are not given as flag are read from the first line of stdin.
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"gorabc/pkg/models"
+	"gorabc/pkg/settings/db/mongodb"
+)
+
+// command of the CLI
+type command struct {
+	usage string
+	run   func(args []string)
+}
+
+var commands map[string]command
+
+func init() {
+	commands = map[string]command{
+		"seed":      {"seed", seedCommand},
+		"migrate":   {"migrate", migrateCommand},
+		"superuser": {"superuser create -email EMAIL -first-name NAME -last-name NAME [-password PASSWORD]", superuserCommand},
+		"org":       {"org create|list ...", orgCommand},
+		"user":      {"user reset-password -email EMAIL [-password PASSWORD]", userCommand},
+		"check":     {"check -email EMAIL -permission PERMISSION", checkCommand},
+		"export":    {"export -org ORG -kind KIND [-format json|csv] [-o FILE]", exportCommand},
+		"import":    {"import -org ORG -kind KIND -f FILE [-format json|csv] [-dry-run]", importCommand},
+		"plan":      {"plan -org ORG -f manifest.yaml [-prune]", manifestCommand("plan")},
+		"apply":     {"apply -org ORG -f manifest.yaml [-prune]", manifestCommand("apply")},
+		"audit":     {"audit verify [-org ORG]", auditCommand},
+	}
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+	}
+
+	cmd, ok := commands[os.Args[1]]
+	if !ok {
+		usage()
+	}
+	cmd.run(os.Args[2:])
+}
+
+// usage prints the commands and exits
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: gorabcctl COMMAND [flags]")
+	for _, name := range []string{"seed", "migrate", "superuser", "org", "user", "check", "export", "import", "plan", "apply", "audit"} {
+		fmt.Fprintln(os.Stderr, "  "+commands[name].usage)
+	}
+	os.Exit(2)
+}
+
+// fail prints the error and exits
+func fail(format string, args ...interface{}) {
+	fmt.Fprintf(os.Stderr, format+"\n", args...)
+	os.Exit(1)
+}
+
+// connect to the database of the service
+func connect() {
+	mongodb.InitMongoClient()
+}
+
+// systemUser acts on an organization as the organization admin
+func systemUser(org string) *models.AuthUser {
+	return &models.AuthUser{ID: models.ActorSystem, Organization: org, IsOrgAdmin: true}
+}
+
+// platformUser acts across organizations as a superuser
+func platformUser() *models.AuthUser {
+	return &models.AuthUser{ID: models.ActorSystem, IsSuperuser: true}
+}
+
+// readPassword from the flag or the first line of stdin
+func readPassword(password string) string {
+	if password != "" {
+		return password
+	}
+	fmt.Fprint(os.Stderr, "Password: ")
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && line == "" {
+		fail("A password is required")
+	}
+	return strings.TrimSpace(line)
+}
+
+// readFile or stdin for -
+func readFile(file string) []byte {
+	var data []byte
+	var err error
+	if file == "-" {
+		data, err = ioutil.ReadAll(os.Stdin)
+	} else {
+		data, err = ioutil.ReadFile(file)
+	}
+	if err != nil {
+		fail("%s", err)
+	}
+	return data
+}
+
+// printJSON to stdout
+func printJSON(value interface{}) {
+	data, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		fail("%s", err)
+	}
+	fmt.Println(string(data))
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"gorabc/pkg/logic/helpers"
+	"gorabc/pkg/logic/services"
+	"gorabc/pkg/models"
+)
+
+// manifestCommand plans or applies a manifest file
+func manifestCommand(name string) func(args []string) {
+	return func(args []string) {
+		flags := flag.NewFlagSet(name, flag.ExitOnError)
+		org := flags.String("org", "", "organization id")
+		file := flags.String("f", "", "manifest file, - for stdin")
+		prune := flags.Bool("prune", false, "delete departments and roles missing from the manifest")
+		flags.Parse(args)
+
+		if *org == "" || *file == "" {
+			usage()
+		}
+
+		manifest, restErr := helpers.ParseManifest(readFile(*file))
+		if restErr != nil {
+			fail("%s", restErr.Message)
+		}
+
+		connect()
+
+		var plan *models.ManifestPlan
+		if name == "apply" {
+			plan, restErr = services.ManifestService.Apply(context.Background(), *manifest, *prune, systemUser(*org))
+		} else {
+			plan, restErr = services.ManifestService.Plan(context.Background(), *manifest, *prune, systemUser(*org))
+		}
+		if plan != nil {
+			printPlan(plan)
+		}
+		if restErr != nil {
+			fail("%s", restErr.Message)
+		}
+		if len(plan.Errors) > 0 {
+			os.Exit(1)
+		}
+		if name == "apply" && !plan.IsEmpty() {
+			fmt.Println("Manifest applied.")
+		}
+	}
+}
+
+// printPlan in a terraform like listing
+func printPlan(plan *models.ManifestPlan) {
+	symbols := map[string]string{
+		models.ManifestCreate: "+",
+		models.ManifestUpdate: "~",
+		models.ManifestDelete: "-",
+	}
+	for i := 0; i < len(plan.Changes); i++ {
+		change := plan.Changes[i]
+		line := fmt.Sprintf("%s %s %s", symbols[change.Action], change.Kind, change.Key)
+		if len(change.Details) > 0 {
+			line += " (" + strings.Join(change.Details, ", ") + ")"
+		}
+		fmt.Println(line)
+	}
+	for i := 0; i < len(plan.Unmanaged); i++ {
+		fmt.Printf("? %s is not in the manifest, use -prune to delete it\n", plan.Unmanaged[i])
+	}
+	for i := 0; i < len(plan.Errors); i++ {
+		fmt.Printf("! %s\n", plan.Errors[i])
+	}
+	if plan.IsEmpty() && len(plan.Errors) == 0 {
+		fmt.Println("No changes, the organization matches the manifest.")
+	}
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+
+	"gorabc/pkg/logic/services"
+	"gorabc/pkg/models"
+)
+
+// superuserCommand creates a platform superuser without the HTTP endpoint
+func superuserCommand(args []string) {
+	if len(args) < 1 || args[0] != "create" {
+		usage()
+	}
+
+	flags := flag.NewFlagSet("superuser create", flag.ExitOnError)
+	email := flags.String("email", "", "email")
+	firstname := flags.String("first-name", "", "first name")
+	lastname := flags.String("last-name", "", "last name")
+	password := flags.String("password", "", "password, read from stdin when empty")
+	flags.Parse(args[1:])
+
+	if *email == "" || *firstname == "" || *lastname == "" {
+		usage()
+	}
+
+	request := models.CreateUserRequest{
+		Email:     *email,
+		Firstname: *firstname,
+		Lastname:  *lastname,
+		Password:  readPassword(*password),
+	}
+	if err := request.Validate(); err != nil {
+		fail("%s", err.Message)
+	}
+
+	connect()
+	superuser, err := services.AuthService.RegisterSuperuser(context.Background(), request)
+	if err != nil {
+		fail("%s", err.Message)
+	}
+	fmt.Printf("Superuser %s created with id %s\n", superuser.Email, superuser.ID)
+}
+
+// orgCommand creates or lists organizations
+func orgCommand(args []string) {
+	if len(args) < 1 {
+		usage()
+	}
+
+	switch args[0] {
+	case "list":
+		flag.NewFlagSet("org list", flag.ExitOnError).Parse(args[1:])
+
+		connect()
+		organizations, err := services.OrganizationService.FindAll(context.Background(), platformUser())
+		if err != nil {
+			fail("%s", err.Message)
+		}
+		for i := 0; i < len(organizations); i++ {
+			fmt.Printf("%s\t%s\t%s\n", organizations[i].ID, organizations[i].Name, organizations[i].Status)
+		}
+	case "create":
+		flags := flag.NewFlagSet("org create", flag.ExitOnError)
+		request := models.RegistrationRequest{}
+		flags.StringVar(&request.OrgName, "name", "", "organization name")
+		flags.StringVar(&request.Website, "website", "", "organization website")
+		flags.StringVar(&request.Preset, "preset", "", "preset of departments and roles")
+		flags.StringVar(&request.Email, "email", "", "email of the organization admin")
+		flags.StringVar(&request.Firstname, "first-name", "", "first name of the organization admin")
+		flags.StringVar(&request.Lastname, "last-name", "", "last name of the organization admin")
+		password := flags.String("password", "", "password of the organization admin, read from stdin when empty")
+		flags.Parse(args[1:])
+
+		request.Password = readPassword(*password)
+		if err := request.Validate(); err != nil {
+			fail("%s", err.Message)
+		}
+
+		connect()
+		organization, err := services.AuthService.CreateOrg(context.Background(), request)
+		if err != nil {
+			fail("%s", err.Message)
+		}
+		fmt.Printf("Organization %s created with id %s\n", organization.Name, organization.ID)
+	default:
+		usage()
+	}
+}
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"context"
+	"flag"
+
+	"gorabc/pkg/settings/seed"
+)
+
+// seedCommand adds the missing permissions of the catalogue, it can be run again after upgrades
+func seedCommand(args []string) {
+	flag.NewFlagSet("seed", flag.ExitOnError).Parse(args)
+
+	connect()
+	seed.AddPermissions()
+}
+
+// migrateCommand runs the data migrations
+func migrateCommand(args []string) {
+	flag.NewFlagSet("migrate", flag.ExitOnError).Parse(args)
+
+	connect()
+	// Rename legacy permission names
+	if err := seed.MigratePermissionNames(); err != nil {
+		fail("Permission migration failed: %s", err.Message)
+	}
+	// Mirror memberships into relation tuples
+	if err := seed.MirrorRelationTuples(context.Background()); err != nil {
+		fail("Relation tuple migration failed: %s", err.Message)
+	}
+}
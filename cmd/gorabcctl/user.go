@@ -0,0 +1,74 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"gorabc/pkg/logic/helpers"
+	"gorabc/pkg/logic/services"
+	"gorabc/pkg/models"
+	"gorabc/pkg/repository/dao"
+)
+
+// userCommand resets the password of a user
+func userCommand(args []string) {
+	if len(args) < 1 || args[0] != "reset-password" {
+		usage()
+	}
+
+	flags := flag.NewFlagSet("user reset-password", flag.ExitOnError)
+	email := flags.String("email", "", "email of the user")
+	password := flags.String("password", "", "new password, read from stdin when empty")
+	flags.Parse(args[1:])
+
+	if *email == "" {
+		usage()
+	}
+	request := models.UpdatePasswordRequest{Password: readPassword(*password)}
+	if err := request.Validate(); err != nil {
+		fail("%s", err.Message)
+	}
+
+	connect()
+	user := getUser(*email)
+	if _, err := services.UserService.UpdatePassword(context.Background(), user.ID, request, systemUser(user.Organization)); err != nil {
+		fail("%s", err.Message)
+	}
+	fmt.Printf("Password of %s reset\n", user.Email)
+}
+
+// checkCommand explains whether a user is granted a permission
+func checkCommand(args []string) {
+	flags := flag.NewFlagSet("check", flag.ExitOnError)
+	email := flags.String("email", "", "email of the user")
+	permission := flags.String("permission", "", "permission name, e.g. org:user:read")
+	flags.Parse(args)
+
+	if *email == "" || *permission == "" {
+		usage()
+	}
+
+	connect()
+	user := getUser(*email)
+	user, err := services.UserService.GetByID(context.Background(), user.ID, systemUser(user.Organization))
+	if err != nil {
+		fail("%s", err.Message)
+	}
+
+	decision := helpers.ExplainPermission(*permission, helpers.GetAuthUser(*user))
+	printJSON(decision)
+	if !decision.Allowed {
+		os.Exit(1)
+	}
+}
+
+// getUser by email or exit
+func getUser(email string) *models.User {
+	user, err := dao.UserDao.GetByEmail(context.Background(), email)
+	if err != nil {
+		fail("User not found: %s", email)
+	}
+	return user
+}
@@ -0,0 +1,195 @@
+package handlers
+
+import (
+	"net/http"
+
+	"gorabc/pkg/logic/services"
+	"gorabc/pkg/models"
+	"gorabc/pkg/utils/resterr"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AccessRequestHandlerInterface type
+type AccessRequestHandlerInterface interface {
+	Create(ctx *gin.Context)
+	FindAll(ctx *gin.Context)
+	GetByID(ctx *gin.Context)
+	Approve(ctx *gin.Context)
+	Deny(ctx *gin.Context)
+	Cancel(ctx *gin.Context)
+}
+
+// accessRequestHandler struct
+type accessRequestHandler struct{}
+
+// AccessRequestHandler variable
+var (
+	AccessRequestHandler AccessRequestHandlerInterface = &accessRequestHandler{}
+)
+
+// Create Handler
+func (ctrl *accessRequestHandler) Create(ctx *gin.Context) {
+	// Get JWT --> authUser from request.Header
+	authUser, err := authenticate(ctx)
+	if err != nil {
+		respondError(ctx, err)
+		return
+	}
+
+	var request models.AccessRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		restErr := resterr.NewBadRequestError("Invalid JSON body")
+		respondError(ctx, restErr)
+		return
+	}
+
+	accessRequest, err := services.AccessRequestService.Create(ctx.Request.Context(), request, authUser)
+	if err != nil {
+		respondError(ctx, err)
+		return
+	}
+
+	response := gin.H{
+		"object":  accessRequest,
+		"message": "Access request successfully created",
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+// FindAll Handler
+func (ctrl *accessRequestHandler) FindAll(ctx *gin.Context) {
+	// Get JWT --> authUser from request.Header
+	authUser, err := authenticate(ctx)
+	if err != nil {
+		respondError(ctx, err)
+		return
+	}
+
+	var filter models.AccessRequestFilter
+	if err := ctx.ShouldBindQuery(&filter); err != nil {
+		restErr := resterr.NewBadRequestError("Invalid query parameters")
+		respondError(ctx, restErr)
+		return
+	}
+
+	requests, err := services.AccessRequestService.FindAll(ctx.Request.Context(), filter, authUser)
+	if err != nil {
+		respondError(ctx, err)
+		return
+	}
+
+	response := gin.H{
+		"list":    requests,
+		"message": "List of access requests",
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+// GetByID Handler
+func (ctrl *accessRequestHandler) GetByID(ctx *gin.Context) {
+	// Get JWT --> authUser from request.Header
+	authUser, err := authenticate(ctx)
+	if err != nil {
+		respondError(ctx, err)
+		return
+	}
+
+	request, err := services.AccessRequestService.GetByID(ctx.Request.Context(), ctx.Param("id"), authUser)
+	if err != nil {
+		respondError(ctx, err)
+		return
+	}
+
+	response := gin.H{
+		"object":  request,
+		"message": "Access request details",
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+// Approve Handler
+func (ctrl *accessRequestHandler) Approve(ctx *gin.Context) {
+	// Get JWT --> authUser from request.Header
+	authUser, err := authenticate(ctx)
+	if err != nil {
+		respondError(ctx, err)
+		return
+	}
+
+	var decision models.AccessRequestDecision
+	if err := ctx.ShouldBindJSON(&decision); err != nil {
+		restErr := resterr.NewBadRequestError("Invalid JSON body")
+		respondError(ctx, restErr)
+		return
+	}
+
+	request, err := services.AccessRequestService.Approve(ctx.Request.Context(), ctx.Param("id"), decision, authUser)
+	if err != nil {
+		respondError(ctx, err)
+		return
+	}
+
+	response := gin.H{
+		"object":  request,
+		"message": "Access request successfully approved",
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+// Deny Handler
+func (ctrl *accessRequestHandler) Deny(ctx *gin.Context) {
+	// Get JWT --> authUser from request.Header
+	authUser, err := authenticate(ctx)
+	if err != nil {
+		respondError(ctx, err)
+		return
+	}
+
+	var decision models.AccessRequestDecision
+	if err := ctx.ShouldBindJSON(&decision); err != nil {
+		restErr := resterr.NewBadRequestError("Invalid JSON body")
+		respondError(ctx, restErr)
+		return
+	}
+
+	request, err := services.AccessRequestService.Deny(ctx.Request.Context(), ctx.Param("id"), decision, authUser)
+	if err != nil {
+		respondError(ctx, err)
+		return
+	}
+
+	response := gin.H{
+		"object":  request,
+		"message": "Access request successfully denied",
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+// Cancel Handler
+func (ctrl *accessRequestHandler) Cancel(ctx *gin.Context) {
+	// Get JWT --> authUser from request.Header
+	authUser, err := authenticate(ctx)
+	if err != nil {
+		respondError(ctx, err)
+		return
+	}
+
+	request, err := services.AccessRequestService.Cancel(ctx.Request.Context(), ctx.Param("id"), authUser)
+	if err != nil {
+		respondError(ctx, err)
+		return
+	}
+
+	response := gin.H{
+		"object":  request,
+		"message": "Access request successfully cancelled",
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
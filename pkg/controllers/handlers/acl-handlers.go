@@ -0,0 +1,142 @@
+package handlers
+
+import (
+	"net/http"
+
+	"gorabc/pkg/logic/services"
+	"gorabc/pkg/models"
+	"gorabc/pkg/utils/resterr"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ACLHandlerInterface type
+type ACLHandlerInterface interface {
+	Grant(ctx *gin.Context)
+	FindAll(ctx *gin.Context)
+	Revoke(ctx *gin.Context)
+	Check(ctx *gin.Context)
+}
+
+// aclHandler struct
+type aclHandler struct{}
+
+// ACLHandler variable
+var (
+	ACLHandler ACLHandlerInterface = &aclHandler{}
+)
+
+// Grant Handler
+func (ctrl *aclHandler) Grant(ctx *gin.Context) {
+	// Get JWT --> authUser from request.Header
+	authUser, err := authenticate(ctx)
+	if err != nil {
+		respondError(ctx, err)
+		return
+	}
+
+	var request models.ObjectGrant
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		restErr := resterr.NewBadRequestError("Invalid JSON body")
+		respondError(ctx, restErr)
+		return
+	}
+
+	grant, err := services.ACLService.Grant(ctx.Request.Context(), request, authUser)
+	if err != nil {
+		respondError(ctx, err)
+		return
+	}
+
+	response := gin.H{
+		"object":  grant,
+		"message": "Object grant successfully created",
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+// FindAll Handler
+func (ctrl *aclHandler) FindAll(ctx *gin.Context) {
+	// Get JWT --> authUser from request.Header
+	authUser, err := authenticate(ctx)
+	if err != nil {
+		respondError(ctx, err)
+		return
+	}
+
+	var filter models.ObjectGrantFilter
+	if err := ctx.ShouldBindQuery(&filter); err != nil {
+		restErr := resterr.NewBadRequestError("Invalid query parameters")
+		respondError(ctx, restErr)
+		return
+	}
+
+	grants, err := services.ACLService.FindAll(ctx.Request.Context(), filter, authUser)
+	if err != nil {
+		respondError(ctx, err)
+		return
+	}
+
+	response := gin.H{
+		"list":    grants,
+		"message": "List of object grants",
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+// Revoke Handler
+func (ctrl *aclHandler) Revoke(ctx *gin.Context) {
+	// Get JWT --> authUser from request.Header
+	authUser, err := authenticate(ctx)
+	if err != nil {
+		respondError(ctx, err)
+		return
+	}
+
+	// Verify ID
+	id := ctx.Param("id")
+
+	if err := services.ACLService.Revoke(ctx.Request.Context(), id, authUser); err != nil {
+		respondError(ctx, err)
+		return
+	}
+
+	response := gin.H{
+		"object":  map[string]string{"Status": "Revoked"},
+		"message": "Object grant successfully revoked",
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+// Check Handler
+func (ctrl *aclHandler) Check(ctx *gin.Context) {
+	// Get JWT --> authUser from request.Header
+	authUser, err := authenticate(ctx)
+	if err != nil {
+		respondError(ctx, err)
+		return
+	}
+
+	var request models.AccessCheckRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		restErr := resterr.NewBadRequestError("Invalid JSON body")
+		respondError(ctx, restErr)
+		return
+	}
+
+	decision, err := services.ACLService.Check(ctx.Request.Context(), request, authUser)
+	if err != nil {
+		respondError(ctx, err)
+		return
+	}
+
+	response := gin.H{
+		"object":  decision,
+		"message": "Access check decision",
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
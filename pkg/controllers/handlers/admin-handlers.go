@@ -0,0 +1,311 @@
+package handlers
+
+import (
+	"net/http"
+
+	"gorabc/pkg/logic/services"
+	"gorabc/pkg/models"
+	"gorabc/pkg/utils/resterr"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AdminHandlerInterface type
+type AdminHandlerInterface interface {
+	FindTenants(ctx *gin.Context)
+	GetTenant(ctx *gin.Context)
+	SuspendTenant(ctx *gin.Context)
+	ReactivateTenant(ctx *gin.Context)
+	SetOrgAdmin(ctx *gin.Context)
+	Impersonate(ctx *gin.Context)
+	FindPermissions(ctx *gin.Context)
+	CreatePermission(ctx *gin.Context)
+	DeletePermission(ctx *gin.Context)
+	Search(ctx *gin.Context)
+}
+
+// adminHandler struct
+type adminHandler struct{}
+
+// AdminHandler variable
+var (
+	AdminHandler AdminHandlerInterface = &adminHandler{}
+)
+
+// FindTenants Handler
+func (ctrl *adminHandler) FindTenants(ctx *gin.Context) {
+	// Get JWT --> authUser from request.Header
+	authUser, err := authenticate(ctx)
+	if err != nil {
+		respondError(ctx, err)
+		return
+	}
+
+	var filter models.TenantFilter
+	if err := ctx.ShouldBindQuery(&filter); err != nil {
+		restErr := resterr.NewBadRequestError("Invalid query parameters")
+		respondError(ctx, restErr)
+		return
+	}
+
+	tenants, err := services.AdminService.FindTenants(ctx.Request.Context(), filter, authUser)
+	if err != nil {
+		respondError(ctx, err)
+		return
+	}
+
+	response := gin.H{
+		"list":    tenants,
+		"message": "List of tenants",
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+// GetTenant Handler
+func (ctrl *adminHandler) GetTenant(ctx *gin.Context) {
+	// Get JWT --> authUser from request.Header
+	authUser, err := authenticate(ctx)
+	if err != nil {
+		respondError(ctx, err)
+		return
+	}
+
+	tenant, err := services.AdminService.GetTenant(ctx.Request.Context(), ctx.Param("id"), authUser)
+	if err != nil {
+		respondError(ctx, err)
+		return
+	}
+
+	response := gin.H{
+		"object":  tenant,
+		"message": "Tenant object",
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+// SuspendTenant Handler
+func (ctrl *adminHandler) SuspendTenant(ctx *gin.Context) {
+	// Get JWT --> authUser from request.Header
+	authUser, err := authenticate(ctx)
+	if err != nil {
+		respondError(ctx, err)
+		return
+	}
+
+	var request models.TenantStatusRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		restErr := resterr.NewBadRequestError("Invalid JSON body")
+		respondError(ctx, restErr)
+		return
+	}
+
+	organization, err := services.AdminService.SuspendTenant(ctx.Request.Context(), ctx.Param("id"), request, authUser)
+	if err != nil {
+		respondError(ctx, err)
+		return
+	}
+
+	response := gin.H{
+		"object":  organization,
+		"message": "Organization suspended",
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+// ReactivateTenant Handler
+func (ctrl *adminHandler) ReactivateTenant(ctx *gin.Context) {
+	// Get JWT --> authUser from request.Header
+	authUser, err := authenticate(ctx)
+	if err != nil {
+		respondError(ctx, err)
+		return
+	}
+
+	var request models.TenantStatusRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		restErr := resterr.NewBadRequestError("Invalid JSON body")
+		respondError(ctx, restErr)
+		return
+	}
+
+	organization, err := services.AdminService.ReactivateTenant(ctx.Request.Context(), ctx.Param("id"), request, authUser)
+	if err != nil {
+		respondError(ctx, err)
+		return
+	}
+
+	response := gin.H{
+		"object":  organization,
+		"message": "Organization reactivated",
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+// SetOrgAdmin Handler
+func (ctrl *adminHandler) SetOrgAdmin(ctx *gin.Context) {
+	// Get JWT --> authUser from request.Header
+	authUser, err := authenticate(ctx)
+	if err != nil {
+		respondError(ctx, err)
+		return
+	}
+
+	var request models.OrgAdminRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		restErr := resterr.NewBadRequestError("Invalid JSON body")
+		respondError(ctx, restErr)
+		return
+	}
+
+	user, err := services.AdminService.SetOrgAdmin(ctx.Request.Context(), ctx.Param("id"), ctx.Param("user_id"), request, authUser)
+	if err != nil {
+		respondError(ctx, err)
+		return
+	}
+
+	response := gin.H{
+		"object":  user.Marshal(),
+		"message": "User successfully updated",
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+// Impersonate Handler
+func (ctrl *adminHandler) Impersonate(ctx *gin.Context) {
+	// Get JWT --> authUser from request.Header
+	authUser, err := authenticate(ctx)
+	if err != nil {
+		respondError(ctx, err)
+		return
+	}
+
+	var request models.ImpersonationRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		restErr := resterr.NewBadRequestError("Invalid JSON body")
+		respondError(ctx, restErr)
+		return
+	}
+
+	impersonation, err := services.AdminService.Impersonate(ctx.Request.Context(), ctx.Param("id"), ctx.Param("user_id"), request, authUser)
+	if err != nil {
+		respondError(ctx, err)
+		return
+	}
+
+	response := gin.H{
+		"object":  impersonation,
+		"message": "Impersonation token issued",
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+// FindPermissions Handler
+func (ctrl *adminHandler) FindPermissions(ctx *gin.Context) {
+	// Get JWT --> authUser from request.Header
+	authUser, err := authenticate(ctx)
+	if err != nil {
+		respondError(ctx, err)
+		return
+	}
+
+	permissions, err := services.AdminService.FindPermissions(ctx.Request.Context(), authUser)
+	if err != nil {
+		respondError(ctx, err)
+		return
+	}
+
+	response := gin.H{
+		"list":    permissions,
+		"message": "List of catalog permissions",
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+// CreatePermission Handler
+func (ctrl *adminHandler) CreatePermission(ctx *gin.Context) {
+	// Get JWT --> authUser from request.Header
+	authUser, err := authenticate(ctx)
+	if err != nil {
+		respondError(ctx, err)
+		return
+	}
+
+	var request models.CatalogPermissionRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		restErr := resterr.NewBadRequestError("Invalid JSON body")
+		respondError(ctx, restErr)
+		return
+	}
+
+	permission, err := services.AdminService.CreatePermission(ctx.Request.Context(), request, authUser)
+	if err != nil {
+		respondError(ctx, err)
+		return
+	}
+
+	response := gin.H{
+		"object":  permission,
+		"message": "Permission added to the catalog",
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+// DeletePermission Handler
+func (ctrl *adminHandler) DeletePermission(ctx *gin.Context) {
+	// Get JWT --> authUser from request.Header
+	authUser, err := authenticate(ctx)
+	if err != nil {
+		respondError(ctx, err)
+		return
+	}
+
+	if err := services.AdminService.DeletePermission(ctx.Request.Context(), ctx.Param("name"), authUser); err != nil {
+		respondError(ctx, err)
+		return
+	}
+
+	response := gin.H{
+		"object":  map[string]string{"Name": ctx.Param("name")},
+		"message": "Permission removed from the catalog",
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+// Search Handler
+func (ctrl *adminHandler) Search(ctx *gin.Context) {
+	// Get JWT --> authUser from request.Header
+	authUser, err := authenticate(ctx)
+	if err != nil {
+		respondError(ctx, err)
+		return
+	}
+
+	var request models.AdminSearchRequest
+	if err := ctx.ShouldBindQuery(&request); err != nil {
+		restErr := resterr.NewBadRequestError("Invalid query parameters")
+		respondError(ctx, restErr)
+		return
+	}
+
+	result, err := services.AdminService.Search(ctx.Request.Context(), request, authUser)
+	if err != nil {
+		respondError(ctx, err)
+		return
+	}
+
+	response := gin.H{
+		"object":  result,
+		"message": "Search results",
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,109 @@
+package handlers
+
+import (
+	"net/http"
+
+	"gorabc/pkg/logic/services"
+	"gorabc/pkg/models"
+	"gorabc/pkg/utils/resterr"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AuditHandlerInterface type
+type AuditHandlerInterface interface {
+	Find(ctx *gin.Context)
+	Export(ctx *gin.Context)
+	Verify(ctx *gin.Context)
+}
+
+// auditHandler struct
+type auditHandler struct{}
+
+// AuditHandler variable
+var (
+	AuditHandler AuditHandlerInterface = &auditHandler{}
+)
+
+// Find Handler
+func (ctrl *auditHandler) Find(ctx *gin.Context) {
+	// Get JWT --> authUser from request.Header
+	authUser, err := authenticate(ctx)
+	if err != nil {
+		respondError(ctx, err)
+		return
+	}
+
+	var filter models.AuditFilter
+	if err := ctx.ShouldBindQuery(&filter); err != nil {
+		restErr := resterr.NewBadRequestError("Invalid query parameters")
+		respondError(ctx, restErr)
+		return
+	}
+
+	events, err := services.AuditService.Find(ctx.Request.Context(), filter, authUser)
+	if err != nil {
+		respondError(ctx, err)
+		return
+	}
+
+	response := gin.H{
+		"list":    events,
+		"message": "List of audit events",
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+// Export Handler
+func (ctrl *auditHandler) Export(ctx *gin.Context) {
+	// Get JWT --> authUser from request.Header
+	authUser, err := authenticate(ctx)
+	if err != nil {
+		respondError(ctx, err)
+		return
+	}
+
+	var filter models.AuditFilter
+	if err := ctx.ShouldBindQuery(&filter); err != nil {
+		restErr := resterr.NewBadRequestError("Invalid query parameters")
+		respondError(ctx, restErr)
+		return
+	}
+
+	data, err := services.AuditService.Export(ctx.Request.Context(), filter, authUser)
+	if err != nil {
+		respondError(ctx, err)
+		return
+	}
+
+	ctx.Header("Content-Disposition", "attachment; filename=audit.jsonl")
+	ctx.Data(http.StatusOK, "application/x-ndjson", data)
+}
+
+// Verify Handler
+func (ctrl *auditHandler) Verify(ctx *gin.Context) {
+	// Get JWT --> authUser from request.Header
+	authUser, err := authenticate(ctx)
+	if err != nil {
+		respondError(ctx, err)
+		return
+	}
+
+	verification, err := services.AuditService.Verify(ctx.Request.Context(), ctx.Query("organization"), authUser)
+	if err != nil {
+		respondError(ctx, err)
+		return
+	}
+
+	message := "Audit log is intact"
+	if !verification.Valid {
+		message = "Audit log was tampered with"
+	}
+	response := gin.H{
+		"object":  verification,
+		"message": message,
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
@@ -3,7 +3,12 @@ package handlers
 import (
 	"net/http"
 
+	"gorabc/pkg/logic/helpers"
 	"gorabc/pkg/logic/services"
+	"gorabc/pkg/middlewares/clientip"
+	"gorabc/pkg/middlewares/jwt"
+	"gorabc/pkg/middlewares/logging"
+	"gorabc/pkg/middlewares/requestid"
 	"gorabc/pkg/models"
 	"gorabc/pkg/utils/resterr"
 
@@ -31,18 +36,24 @@ func (ctrl *authHandler) Login(ctx *gin.Context) {
 	var request models.LoginRequest
 	if err := ctx.ShouldBindJSON(&request); err != nil {
 		reqErr := resterr.NewBadRequestError("Invalid JSON body")
-		ctx.JSON(reqErr.Status, reqErr)
+		respondError(ctx, reqErr)
 		return
 	}
 
-	user, err := services.AuthService.Login(request)
+	request.IP = clientip.Get(ctx)
+	request.RequestID = requestid.Get(ctx)
+
+	user, err := services.AuthService.Login(ctx.Request.Context(), request)
 	if err != nil {
-		ctx.JSON(err.Status, err)
+		respondError(ctx, err)
 		return
 	}
 
+	authUser := helpers.GetAuthUser(*user)
+
 	response := gin.H{
-		"object":  user.AuthMarshal(),
+		"object":  authUser,
+		"token":   jwt.GenerateToken(&authUser),
 		"message": "User logged in successfully!",
 	}
 
@@ -55,13 +66,16 @@ func (ctrl *authHandler) RegisterOrg(ctx *gin.Context) {
 	var request models.RegistrationRequest
 	if err := ctx.ShouldBindJSON(&request); err != nil {
 		reqErr := resterr.NewBadRequestError("Invalid JSON body")
-		ctx.JSON(reqErr.Status, reqErr)
+		respondError(ctx, reqErr)
 		return
 	}
 
-	organization, err := services.AuthService.RegisterOrg(request)
+	request.IP = clientip.Get(ctx)
+	request.RequestID = requestid.Get(ctx)
+
+	organization, err := services.AuthService.RegisterOrg(ctx.Request.Context(), request)
 	if err != nil {
-		ctx.JSON(err.Status, err)
+		respondError(ctx, err)
 		return
 	}
 
@@ -76,16 +90,16 @@ func (ctrl *authHandler) RegisterOrg(ctx *gin.Context) {
 // Register Handler
 func (ctrl *authHandler) RegisterSuperuser(ctx *gin.Context) {
 
-	var request models.User
+	var request models.CreateUserRequest
 	if err := ctx.ShouldBindJSON(&request); err != nil {
 		reqErr := resterr.NewBadRequestError("Invalid JSON body")
-		ctx.JSON(reqErr.Status, reqErr)
+		respondError(ctx, reqErr)
 		return
 	}
 
-	superuser, err := services.AuthService.RegisterSuperuser(request)
+	superuser, err := services.AuthService.BootstrapSuperuser(ctx.Request.Context(), request, ctx.GetHeader("X-Bootstrap-Token"))
 	if err != nil {
-		ctx.JSON(err.Status, err)
+		respondError(ctx, err)
 		return
 	}
 
@@ -96,3 +110,44 @@ func (ctrl *authHandler) RegisterSuperuser(ctx *gin.Context) {
 
 	ctx.JSON(http.StatusCreated, response)
 }
+
+// authenticate the request with its JWT, the user carries the client IP and
+// the request id for the audit log, and its logs carry the user
+func authenticate(ctx *gin.Context) (*models.AuthUser, *resterr.RestErr) {
+	authUser, err := jwt.DecodeToken(ctx.GetHeader("Authorization"))
+	if err != nil {
+		return nil, err
+	}
+	authUser.IP = clientip.Get(ctx)
+	authUser.RequestID = requestid.Get(ctx)
+	logging.SetUser(ctx, authUser.ID, authUser.Organization)
+	if authUser.Act != nil {
+		logging.SetImpersonator(ctx, authUser.Act.ID)
+	}
+
+	// Read-only impersonation tokens only read
+	if authUser.ReadOnly && !isReadRequest(ctx) {
+		return nil, resterr.NewForbiddenError("Read-only token")
+	}
+	return authUser, nil
+}
+
+// readRoutes are the POST routes that only evaluate a decision
+var readRoutes = map[string]bool{
+	"/api/acl/check":              true,
+	"/api/relations/check":        true,
+	"/api/relations/expand":       true,
+	"/api/relations/list-objects": true,
+	"/api/authorize":              true,
+}
+
+// isReadRequest of a request that changes nothing
+func isReadRequest(ctx *gin.Context) bool {
+	switch ctx.Request.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return true
+	case http.MethodPost:
+		return readRoutes[ctx.FullPath()]
+	}
+	return false
+}
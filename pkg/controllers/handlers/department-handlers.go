@@ -4,7 +4,6 @@ import (
 	"net/http"
 
 	"gorabc/pkg/logic/services"
-	"gorabc/pkg/middlewares/jwt"
 	"gorabc/pkg/models"
 	"gorabc/pkg/utils/resterr"
 
@@ -31,22 +30,21 @@ var (
 // Create Handler
 func (ctrl *departmentHandler) Create(ctx *gin.Context) {
 	// Get JWT from request.Header
-	authHeader := ctx.GetHeader("Authorization")
-	authUser, err := jwt.DecodeToken(authHeader)
+	authUser, err := authenticate(ctx)
 	if err != nil {
-		ctx.JSON(err.Status, err)
+		respondError(ctx, err)
 		return
 	}
 	var request models.Department
 	if err := ctx.ShouldBindJSON(&request); err != nil {
 		restErr := resterr.NewBadRequestError("Invalid JSON body")
-		ctx.JSON(restErr.Status, restErr)
+		respondError(ctx, restErr)
 		return
 	}
 
-	department, err := services.DepartmentService.Create(request, authUser)
+	department, err := services.DepartmentService.Create(ctx.Request.Context(), request, authUser)
 	if err != nil {
-		ctx.JSON(err.Status, err)
+		respondError(ctx, err)
 		return
 	}
 
@@ -61,15 +59,15 @@ func (ctrl *departmentHandler) Create(ctx *gin.Context) {
 // FindAll Handler
 func (ctrl *departmentHandler) FindAll(ctx *gin.Context) {
 	// Get JWT --> authUser from request.Header
-	authUser, err := jwt.DecodeToken(ctx.GetHeader("Authorization"))
+	authUser, err := authenticate(ctx)
 	if err != nil {
-		ctx.JSON(err.Status, err)
+		respondError(ctx, err)
 		return
 	}
 
-	departments, err := services.DepartmentService.FindAll(authUser)
+	departments, err := services.DepartmentService.FindAll(ctx.Request.Context(), authUser)
 	if err != nil {
-		ctx.JSON(err.Status, err)
+		respondError(ctx, err)
 		return
 	}
 
@@ -84,18 +82,18 @@ func (ctrl *departmentHandler) FindAll(ctx *gin.Context) {
 // GetByID department
 func (ctrl *departmentHandler) GetByID(ctx *gin.Context) {
 	// Get JWT --> authUser from request.Header
-	authUser, err := jwt.DecodeToken(ctx.GetHeader("Authorization"))
+	authUser, err := authenticate(ctx)
 	if err != nil {
-		ctx.JSON(err.Status, err)
+		respondError(ctx, err)
 		return
 	}
 
 	// Get id from request.Param
 	id := ctx.Param("id")
 
-	department, err := services.DepartmentService.GetByID(id, authUser)
+	department, err := services.DepartmentService.GetByID(ctx.Request.Context(), id, authUser)
 	if err != nil {
-		ctx.JSON(err.Status, err)
+		respondError(ctx, err)
 		return
 	}
 
@@ -110,9 +108,9 @@ func (ctrl *departmentHandler) GetByID(ctx *gin.Context) {
 // Update department
 func (ctrl *departmentHandler) Update(ctx *gin.Context) {
 	// Get JWT --> authUser from request.Header
-	authUser, err := jwt.DecodeToken(ctx.GetHeader("Authorization"))
+	authUser, err := authenticate(ctx)
 	if err != nil {
-		ctx.JSON(err.Status, err)
+		respondError(ctx, err)
 		return
 	}
 
@@ -120,18 +118,16 @@ func (ctrl *departmentHandler) Update(ctx *gin.Context) {
 	id := ctx.Param("id")
 
 	// Verify body
-	var request models.Department
+	var request models.UpdateDepartmentRequest
 	if err := ctx.ShouldBindJSON(&request); err != nil {
 		reqErr := resterr.NewBadRequestError("Invalid JSON body")
-		ctx.JSON(reqErr.Status, reqErr)
+		respondError(ctx, reqErr)
 		return
 	}
 
-	request.ID = id
-
-	department, updateErr := services.DepartmentService.Update(request, authUser)
+	department, updateErr := services.DepartmentService.Update(ctx.Request.Context(), id, request, authUser)
 	if updateErr != nil {
-		ctx.JSON(updateErr.Status, updateErr)
+		respondError(ctx, updateErr)
 		return
 	}
 
@@ -146,17 +142,17 @@ func (ctrl *departmentHandler) Update(ctx *gin.Context) {
 // Delete Handler
 func (ctrl *departmentHandler) Delete(ctx *gin.Context) {
 	// Get JWT --> authUser from request.Header
-	authUser, err := jwt.DecodeToken(ctx.GetHeader("Authorization"))
+	authUser, err := authenticate(ctx)
 	if err != nil {
-		ctx.JSON(err.Status, err)
+		respondError(ctx, err)
 		return
 	}
 
 	// Verify ID
 	id := ctx.Param("id")
 
-	if err := services.DepartmentService.Delete(id, authUser); err != nil {
-		ctx.JSON(err.Status, err)
+	if err := services.DepartmentService.Delete(ctx.Request.Context(), id, authUser); err != nil {
+		respondError(ctx, err)
 		return
 	}
 
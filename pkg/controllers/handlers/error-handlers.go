@@ -0,0 +1,20 @@
+package handlers
+
+import (
+	"strconv"
+
+	"gorabc/pkg/middlewares/requestid"
+	"gorabc/pkg/utils/resterr"
+
+	"github.com/gin-gonic/gin"
+)
+
+// respondError renders the error as problem details, every handler answers
+// its errors through it
+func respondError(ctx *gin.Context, err *resterr.RestErr) {
+	if err.RetryAfter > 0 {
+		ctx.Header("Retry-After", strconv.Itoa(err.RetryAfter))
+	}
+	ctx.Header("Content-Type", resterr.ProblemContentType)
+	ctx.JSON(err.Status, err.Problem(ctx.Request.URL.Path, requestid.Get(ctx)))
+}
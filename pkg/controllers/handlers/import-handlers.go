@@ -0,0 +1,99 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"gorabc/pkg/logic/services"
+	"gorabc/pkg/models"
+	"gorabc/pkg/utils/resterr"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ImportHandlerInterface type
+type ImportHandlerInterface interface {
+	Import(ctx *gin.Context)
+	Export(ctx *gin.Context)
+}
+
+// importHandler struct
+type importHandler struct{}
+
+// ImportHandler variable
+var (
+	ImportHandler ImportHandlerInterface = &importHandler{}
+)
+
+// Import Handler
+func (ctrl *importHandler) Import(ctx *gin.Context) {
+	// Get JWT --> authUser from request.Header
+	authUser, err := authenticate(ctx)
+	if err != nil {
+		respondError(ctx, err)
+		return
+	}
+
+	data, readErr := ctx.GetRawData()
+	if readErr != nil {
+		restErr := resterr.NewBadRequestError("Invalid request body")
+		respondError(ctx, restErr)
+		return
+	}
+
+	dryRun := ctx.Query("dry_run") == "true"
+
+	report, err := services.ImportService.Import(ctx.Request.Context(), ctx.Param("kind"), data, fileFormat(ctx), dryRun, authUser)
+	if err != nil {
+		respondError(ctx, err)
+		return
+	}
+
+	message := "Import completed"
+	if dryRun {
+		message = "Import validated, nothing was written"
+	}
+
+	response := gin.H{
+		"object":  report,
+		"message": message,
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+// Export Handler
+func (ctrl *importHandler) Export(ctx *gin.Context) {
+	// Get JWT --> authUser from request.Header
+	authUser, err := authenticate(ctx)
+	if err != nil {
+		respondError(ctx, err)
+		return
+	}
+
+	format := ctx.DefaultQuery("format", models.FormatJSON)
+
+	data, err := services.ImportService.Export(ctx.Request.Context(), ctx.Param("kind"), format, authUser)
+	if err != nil {
+		respondError(ctx, err)
+		return
+	}
+
+	contentType := "application/json"
+	if format == models.FormatCSV {
+		contentType = "text/csv"
+	}
+	ctx.Header("Content-Disposition", "attachment; filename="+ctx.Param("kind")+"."+format)
+	ctx.Data(http.StatusOK, contentType, data)
+}
+
+// fileFormat of the request, the format query parameter takes precedence over the Content-Type
+func fileFormat(ctx *gin.Context) string {
+	if format := ctx.Query("format"); format != "" {
+		return format
+	}
+	if strings.Contains(ctx.ContentType(), "csv") {
+		return models.FormatCSV
+	}
+	return models.FormatJSON
+}
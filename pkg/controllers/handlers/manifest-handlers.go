@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"net/http"
+
+	"gorabc/pkg/logic/helpers"
+	"gorabc/pkg/logic/services"
+	"gorabc/pkg/utils/resterr"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ManifestHandlerInterface type
+type ManifestHandlerInterface interface {
+	Plan(ctx *gin.Context)
+	Apply(ctx *gin.Context)
+}
+
+// manifestHandler struct
+type manifestHandler struct{}
+
+// ManifestHandler variable
+var (
+	ManifestHandler ManifestHandlerInterface = &manifestHandler{}
+)
+
+// Plan Handler
+func (ctrl *manifestHandler) Plan(ctx *gin.Context) {
+	// Get JWT --> authUser from request.Header
+	authUser, err := authenticate(ctx)
+	if err != nil {
+		respondError(ctx, err)
+		return
+	}
+
+	data, readErr := ctx.GetRawData()
+	if readErr != nil {
+		restErr := resterr.NewBadRequestError("Invalid request body")
+		respondError(ctx, restErr)
+		return
+	}
+	manifest, err := helpers.ParseManifest(data)
+	if err != nil {
+		respondError(ctx, err)
+		return
+	}
+
+	plan, err := services.ManifestService.Plan(ctx.Request.Context(), *manifest, ctx.Query("prune") == "true", authUser)
+	if err != nil {
+		respondError(ctx, err)
+		return
+	}
+
+	response := gin.H{
+		"object":  plan,
+		"message": "Manifest plan",
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+// Apply Handler
+func (ctrl *manifestHandler) Apply(ctx *gin.Context) {
+	// Get JWT --> authUser from request.Header
+	authUser, err := authenticate(ctx)
+	if err != nil {
+		respondError(ctx, err)
+		return
+	}
+
+	data, readErr := ctx.GetRawData()
+	if readErr != nil {
+		restErr := resterr.NewBadRequestError("Invalid request body")
+		respondError(ctx, restErr)
+		return
+	}
+	manifest, err := helpers.ParseManifest(data)
+	if err != nil {
+		respondError(ctx, err)
+		return
+	}
+
+	plan, err := services.ManifestService.Apply(ctx.Request.Context(), *manifest, ctx.Query("prune") == "true", authUser)
+	if err != nil {
+		// an invalid manifest lists its errors as fields
+		respondError(ctx, err)
+		return
+	}
+
+	response := gin.H{
+		"object":  plan,
+		"message": "Manifest applied",
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
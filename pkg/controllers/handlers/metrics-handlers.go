@@ -0,0 +1,14 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// metricsHandler serves the registered Prometheus metrics
+var metricsHandler = promhttp.Handler()
+
+// Metrics in the Prometheus text format
+func Metrics(ctx *gin.Context) {
+	metricsHandler.ServeHTTP(ctx.Writer, ctx.Request)
+}
@@ -4,7 +4,6 @@ import (
 	"net/http"
 
 	"gorabc/pkg/logic/services"
-	"gorabc/pkg/middlewares/jwt"
 	"gorabc/pkg/models"
 	"gorabc/pkg/utils/resterr"
 
@@ -30,15 +29,15 @@ var (
 // FindAll Handler
 func (ctrl *organizationHandler) FindAll(ctx *gin.Context) {
 	// Get JWT --> authUser from request.Header
-	authUser, err := jwt.DecodeToken(ctx.GetHeader("Authorization"))
+	authUser, err := authenticate(ctx)
 	if err != nil {
-		ctx.JSON(err.Status, err)
+		respondError(ctx, err)
 		return
 	}
 
-	org, err := services.OrganizationService.FindAll(authUser)
+	org, err := services.OrganizationService.FindAll(ctx.Request.Context(), authUser)
 	if err != nil {
-		ctx.JSON(err.Status, err)
+		respondError(ctx, err)
 		return
 	}
 
@@ -53,18 +52,18 @@ func (ctrl *organizationHandler) FindAll(ctx *gin.Context) {
 // GetByID organization
 func (ctrl *organizationHandler) GetByID(ctx *gin.Context) {
 	// Get JWT --> authUser from request.Header
-	authUser, err := jwt.DecodeToken(ctx.GetHeader("Authorization"))
+	authUser, err := authenticate(ctx)
 	if err != nil {
-		ctx.JSON(err.Status, err)
+		respondError(ctx, err)
 		return
 	}
 
 	// Get id from request.Param
 	id := ctx.Param("id")
 
-	org, err := services.OrganizationService.GetByID(id, authUser)
+	org, err := services.OrganizationService.GetByID(ctx.Request.Context(), id, authUser)
 	if err != nil {
-		ctx.JSON(err.Status, err)
+		respondError(ctx, err)
 		return
 	}
 
@@ -79,9 +78,9 @@ func (ctrl *organizationHandler) GetByID(ctx *gin.Context) {
 // Update organization
 func (ctrl *organizationHandler) Update(ctx *gin.Context) {
 	// Get JWT --> authUser from request.Header
-	authUser, err := jwt.DecodeToken(ctx.GetHeader("Authorization"))
+	authUser, err := authenticate(ctx)
 	if err != nil {
-		ctx.JSON(err.Status, err)
+		respondError(ctx, err)
 		return
 	}
 
@@ -92,15 +91,15 @@ func (ctrl *organizationHandler) Update(ctx *gin.Context) {
 	var request models.Organization
 	if err := ctx.ShouldBindJSON(&request); err != nil {
 		reqErr := resterr.NewBadRequestError("Invalid JSON body")
-		ctx.JSON(reqErr.Status, reqErr)
+		respondError(ctx, reqErr)
 		return
 	}
 
 	request.ID = id
 
-	org, updateErr := services.OrganizationService.Update(request, authUser)
+	org, updateErr := services.OrganizationService.Update(ctx.Request.Context(), request, authUser)
 	if updateErr != nil {
-		ctx.JSON(updateErr.Status, updateErr)
+		respondError(ctx, updateErr)
 		return
 	}
 
@@ -115,17 +114,17 @@ func (ctrl *organizationHandler) Update(ctx *gin.Context) {
 // Delete Handler
 func (ctrl *organizationHandler) Delete(ctx *gin.Context) {
 	// Get JWT --> authUser from request.Header
-	authUser, err := jwt.DecodeToken(ctx.GetHeader("Authorization"))
+	authUser, err := authenticate(ctx)
 	if err != nil {
-		ctx.JSON(err.Status, err)
+		respondError(ctx, err)
 		return
 	}
 
 	// Verify ID
 	id := ctx.Param("id")
 
-	if err := services.OrganizationService.Delete(id, authUser); err != nil {
-		ctx.JSON(err.Status, err)
+	if err := services.OrganizationService.Delete(ctx.Request.Context(), id, authUser); err != nil {
+		respondError(ctx, err)
 		return
 	}
 
@@ -0,0 +1,202 @@
+package handlers
+
+import (
+	"net/http"
+
+	"gorabc/pkg/logic/services"
+	"gorabc/pkg/middlewares/clientip"
+	"gorabc/pkg/models"
+	"gorabc/pkg/utils/resterr"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PolicyHandlerInterface type
+type PolicyHandlerInterface interface {
+	Create(ctx *gin.Context)
+	FindAll(ctx *gin.Context)
+	GetByID(ctx *gin.Context)
+	Update(ctx *gin.Context)
+	Delete(ctx *gin.Context)
+	Authorize(ctx *gin.Context)
+}
+
+// policyHandler struct
+type policyHandler struct{}
+
+// PolicyHandler variable
+var (
+	PolicyHandler PolicyHandlerInterface = &policyHandler{}
+)
+
+// Create Handler
+func (ctrl *policyHandler) Create(ctx *gin.Context) {
+	// Get JWT --> authUser from request.Header
+	authUser, err := authenticate(ctx)
+	if err != nil {
+		respondError(ctx, err)
+		return
+	}
+
+	var request models.Policy
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		restErr := resterr.NewBadRequestError("Invalid JSON body")
+		respondError(ctx, restErr)
+		return
+	}
+
+	policy, err := services.PolicyService.Create(ctx.Request.Context(), request, authUser)
+	if err != nil {
+		respondError(ctx, err)
+		return
+	}
+
+	response := gin.H{
+		"object":  policy,
+		"message": "Policy successfully created",
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+// FindAll Handler
+func (ctrl *policyHandler) FindAll(ctx *gin.Context) {
+	// Get JWT --> authUser from request.Header
+	authUser, err := authenticate(ctx)
+	if err != nil {
+		respondError(ctx, err)
+		return
+	}
+
+	policies, err := services.PolicyService.FindAll(ctx.Request.Context(), authUser)
+	if err != nil {
+		respondError(ctx, err)
+		return
+	}
+
+	response := gin.H{
+		"list":    policies,
+		"message": "List of all active policies",
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+// GetByID policy
+func (ctrl *policyHandler) GetByID(ctx *gin.Context) {
+	// Get JWT --> authUser from request.Header
+	authUser, err := authenticate(ctx)
+	if err != nil {
+		respondError(ctx, err)
+		return
+	}
+
+	// Get id from request.Param
+	id := ctx.Param("id")
+
+	policy, err := services.PolicyService.GetByID(ctx.Request.Context(), id, authUser)
+	if err != nil {
+		respondError(ctx, err)
+		return
+	}
+
+	response := gin.H{
+		"object":  policy,
+		"message": "Policy object",
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+// Update policy
+func (ctrl *policyHandler) Update(ctx *gin.Context) {
+	// Get JWT --> authUser from request.Header
+	authUser, err := authenticate(ctx)
+	if err != nil {
+		respondError(ctx, err)
+		return
+	}
+
+	// Get id from request.Param
+	id := ctx.Param("id")
+
+	// Verify body
+	var request models.Policy
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		reqErr := resterr.NewBadRequestError("Invalid JSON body")
+		respondError(ctx, reqErr)
+		return
+	}
+
+	request.ID = id
+
+	policy, updateErr := services.PolicyService.Update(ctx.Request.Context(), request, authUser)
+	if updateErr != nil {
+		respondError(ctx, updateErr)
+		return
+	}
+
+	response := gin.H{
+		"object":  policy,
+		"message": "Policy updated",
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+// Delete Handler
+func (ctrl *policyHandler) Delete(ctx *gin.Context) {
+	// Get JWT --> authUser from request.Header
+	authUser, err := authenticate(ctx)
+	if err != nil {
+		respondError(ctx, err)
+		return
+	}
+
+	// Verify ID
+	id := ctx.Param("id")
+
+	if err := services.PolicyService.Delete(ctx.Request.Context(), id, authUser); err != nil {
+		respondError(ctx, err)
+		return
+	}
+
+	response := gin.H{
+		"object":  map[string]string{"Status": "Deleted"},
+		"message": "Policy successfully deleted",
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+// Authorize Handler
+func (ctrl *policyHandler) Authorize(ctx *gin.Context) {
+	// Get JWT --> authUser from request.Header
+	authUser, err := authenticate(ctx)
+	if err != nil {
+		respondError(ctx, err)
+		return
+	}
+
+	var request models.AuthorizeRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		reqErr := resterr.NewBadRequestError("Invalid JSON body")
+		respondError(ctx, reqErr)
+		return
+	}
+
+	request.IP = clientip.Get(ctx)
+
+	decision, err := services.PolicyService.Authorize(ctx.Request.Context(), request, authUser)
+	if err != nil {
+		respondError(ctx, err)
+		return
+	}
+
+	response := gin.H{
+		"object":  decision,
+		"message": "Authorization decision",
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
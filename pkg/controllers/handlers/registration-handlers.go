@@ -0,0 +1,101 @@
+package handlers
+
+import (
+	"net/http"
+
+	"gorabc/pkg/logic/services"
+	"gorabc/pkg/models"
+	"gorabc/pkg/utils/resterr"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RegistrationHandlerInterface type
+type RegistrationHandlerInterface interface {
+	CreateInvite(ctx *gin.Context)
+	FindInvites(ctx *gin.Context)
+	RevokeInvite(ctx *gin.Context)
+}
+
+// registrationHandler struct
+type registrationHandler struct{}
+
+// RegistrationHandler variable
+var (
+	RegistrationHandler RegistrationHandlerInterface = &registrationHandler{}
+)
+
+// CreateInvite Handler
+func (ctrl *registrationHandler) CreateInvite(ctx *gin.Context) {
+	// Get JWT --> authUser from request.Header
+	authUser, err := authenticate(ctx)
+	if err != nil {
+		respondError(ctx, err)
+		return
+	}
+
+	var request models.RegistrationInviteRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		restErr := resterr.NewBadRequestError("Invalid JSON body")
+		respondError(ctx, restErr)
+		return
+	}
+
+	invite, err := services.RegistrationService.CreateInvite(ctx.Request.Context(), request, authUser)
+	if err != nil {
+		respondError(ctx, err)
+		return
+	}
+
+	response := gin.H{
+		"object":  invite,
+		"message": "Registration invite created, the token is only shown once",
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+// FindInvites Handler
+func (ctrl *registrationHandler) FindInvites(ctx *gin.Context) {
+	// Get JWT --> authUser from request.Header
+	authUser, err := authenticate(ctx)
+	if err != nil {
+		respondError(ctx, err)
+		return
+	}
+
+	invites, err := services.RegistrationService.FindInvites(ctx.Request.Context(), authUser)
+	if err != nil {
+		respondError(ctx, err)
+		return
+	}
+
+	response := gin.H{
+		"list":    invites,
+		"message": "List of registration invites",
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+// RevokeInvite Handler
+func (ctrl *registrationHandler) RevokeInvite(ctx *gin.Context) {
+	// Get JWT --> authUser from request.Header
+	authUser, err := authenticate(ctx)
+	if err != nil {
+		respondError(ctx, err)
+		return
+	}
+
+	if err := services.RegistrationService.RevokeInvite(ctx.Request.Context(), ctx.Param("id"), authUser); err != nil {
+		respondError(ctx, err)
+		return
+	}
+
+	response := gin.H{
+		"object":  map[string]string{"Status": models.InviteStatusRevoked},
+		"message": "Registration invite revoked",
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,263 @@
+package handlers
+
+import (
+	"net/http"
+
+	"gorabc/pkg/logic/services"
+	"gorabc/pkg/models"
+	"gorabc/pkg/utils/resterr"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RelationHandlerInterface type
+type RelationHandlerInterface interface {
+	Write(ctx *gin.Context)
+	FindTuples(ctx *gin.Context)
+	Check(ctx *gin.Context)
+	Expand(ctx *gin.Context)
+	ListObjects(ctx *gin.Context)
+	FindNamespaces(ctx *gin.Context)
+	UpdateNamespace(ctx *gin.Context)
+	DeleteNamespace(ctx *gin.Context)
+}
+
+// relationHandler struct
+type relationHandler struct{}
+
+// RelationHandler variable
+var (
+	RelationHandler RelationHandlerInterface = &relationHandler{}
+)
+
+// Write Handler
+func (ctrl *relationHandler) Write(ctx *gin.Context) {
+	// Get JWT --> authUser from request.Header
+	authUser, err := authenticate(ctx)
+	if err != nil {
+		respondError(ctx, err)
+		return
+	}
+
+	var request models.RelationWriteRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		restErr := resterr.NewBadRequestError("Invalid JSON body")
+		respondError(ctx, restErr)
+		return
+	}
+
+	result, err := services.RelationService.Write(ctx.Request.Context(), request, authUser)
+	if err != nil {
+		respondError(ctx, err)
+		return
+	}
+
+	response := gin.H{
+		"object":  result,
+		"message": "Relation tuples successfully written",
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+// FindTuples Handler
+func (ctrl *relationHandler) FindTuples(ctx *gin.Context) {
+	// Get JWT --> authUser from request.Header
+	authUser, err := authenticate(ctx)
+	if err != nil {
+		respondError(ctx, err)
+		return
+	}
+
+	var filter models.RelationTupleFilter
+	var options models.RelationReadOptions
+	if err := ctx.ShouldBindQuery(&filter); err != nil {
+		restErr := resterr.NewBadRequestError("Invalid query parameters")
+		respondError(ctx, restErr)
+		return
+	}
+	if err := ctx.ShouldBindQuery(&options); err != nil {
+		restErr := resterr.NewBadRequestError("Invalid query parameters")
+		respondError(ctx, restErr)
+		return
+	}
+
+	tuples, err := services.RelationService.FindTuples(ctx.Request.Context(), filter, options, authUser)
+	if err != nil {
+		respondError(ctx, err)
+		return
+	}
+
+	response := gin.H{
+		"list":    tuples,
+		"message": "List of relation tuples",
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+// Check Handler
+func (ctrl *relationHandler) Check(ctx *gin.Context) {
+	// Get JWT --> authUser from request.Header
+	authUser, err := authenticate(ctx)
+	if err != nil {
+		respondError(ctx, err)
+		return
+	}
+
+	var request models.RelationCheckRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		restErr := resterr.NewBadRequestError("Invalid JSON body")
+		respondError(ctx, restErr)
+		return
+	}
+
+	result, err := services.RelationService.Check(ctx.Request.Context(), request, authUser)
+	if err != nil {
+		respondError(ctx, err)
+		return
+	}
+
+	response := gin.H{
+		"object":  result,
+		"message": "Relation check decision",
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+// Expand Handler
+func (ctrl *relationHandler) Expand(ctx *gin.Context) {
+	// Get JWT --> authUser from request.Header
+	authUser, err := authenticate(ctx)
+	if err != nil {
+		respondError(ctx, err)
+		return
+	}
+
+	var request models.RelationExpandRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		restErr := resterr.NewBadRequestError("Invalid JSON body")
+		respondError(ctx, restErr)
+		return
+	}
+
+	result, err := services.RelationService.Expand(ctx.Request.Context(), request, authUser)
+	if err != nil {
+		respondError(ctx, err)
+		return
+	}
+
+	response := gin.H{
+		"object":  result,
+		"message": "Relation userset tree",
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+// ListObjects Handler
+func (ctrl *relationHandler) ListObjects(ctx *gin.Context) {
+	// Get JWT --> authUser from request.Header
+	authUser, err := authenticate(ctx)
+	if err != nil {
+		respondError(ctx, err)
+		return
+	}
+
+	var request models.ListObjectsRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		restErr := resterr.NewBadRequestError("Invalid JSON body")
+		respondError(ctx, restErr)
+		return
+	}
+
+	result, err := services.RelationService.ListObjects(ctx.Request.Context(), request, authUser)
+	if err != nil {
+		respondError(ctx, err)
+		return
+	}
+
+	response := gin.H{
+		"object":  result,
+		"message": "List of objects",
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+// FindNamespaces Handler
+func (ctrl *relationHandler) FindNamespaces(ctx *gin.Context) {
+	// Get JWT --> authUser from request.Header
+	authUser, err := authenticate(ctx)
+	if err != nil {
+		respondError(ctx, err)
+		return
+	}
+
+	namespaces, err := services.RelationService.FindNamespaces(ctx.Request.Context(), authUser)
+	if err != nil {
+		respondError(ctx, err)
+		return
+	}
+
+	response := gin.H{
+		"list":    namespaces,
+		"message": "List of namespaces",
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+// UpdateNamespace Handler
+func (ctrl *relationHandler) UpdateNamespace(ctx *gin.Context) {
+	// Get JWT --> authUser from request.Header
+	authUser, err := authenticate(ctx)
+	if err != nil {
+		respondError(ctx, err)
+		return
+	}
+
+	var namespace models.Namespace
+	if err := ctx.ShouldBindJSON(&namespace); err != nil {
+		restErr := resterr.NewBadRequestError("Invalid JSON body")
+		respondError(ctx, restErr)
+		return
+	}
+	namespace.Name = ctx.Param("name")
+
+	result, err := services.RelationService.UpdateNamespace(ctx.Request.Context(), namespace, authUser)
+	if err != nil {
+		respondError(ctx, err)
+		return
+	}
+
+	response := gin.H{
+		"object":  result,
+		"message": "Namespace successfully updated",
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+// DeleteNamespace Handler
+func (ctrl *relationHandler) DeleteNamespace(ctx *gin.Context) {
+	// Get JWT --> authUser from request.Header
+	authUser, err := authenticate(ctx)
+	if err != nil {
+		respondError(ctx, err)
+		return
+	}
+
+	if err := services.RelationService.DeleteNamespace(ctx.Request.Context(), ctx.Param("name"), authUser); err != nil {
+		respondError(ctx, err)
+		return
+	}
+
+	response := gin.H{
+		"object":  map[string]string{"Status": "Deleted"},
+		"message": "Namespace successfully deleted",
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
@@ -4,7 +4,6 @@ import (
 	"net/http"
 
 	"gorabc/pkg/logic/services"
-	"gorabc/pkg/middlewares/jwt"
 	"gorabc/pkg/models"
 	"gorabc/pkg/utils/resterr"
 
@@ -31,22 +30,21 @@ var (
 // Create Handler
 func (ctrl *roleHandler) Create(ctx *gin.Context) {
 	// Get JWT from request.Header
-	authHeader := ctx.GetHeader("Authorization")
-	authUser, err := jwt.DecodeToken(authHeader)
+	authUser, err := authenticate(ctx)
 	if err != nil {
-		ctx.JSON(err.Status, err)
+		respondError(ctx, err)
 		return
 	}
-	var request models.Role
+	var request models.CreateRoleRequest
 	if err := ctx.ShouldBindJSON(&request); err != nil {
 		restErr := resterr.NewBadRequestError("Invalid JSON body")
-		ctx.JSON(restErr.Status, restErr)
+		respondError(ctx, restErr)
 		return
 	}
 
-	role, err := services.RoleService.Create(request, authUser)
+	role, err := services.RoleService.Create(ctx.Request.Context(), request, authUser)
 	if err != nil {
-		ctx.JSON(err.Status, err)
+		respondError(ctx, err)
 		return
 	}
 
@@ -61,15 +59,15 @@ func (ctrl *roleHandler) Create(ctx *gin.Context) {
 // FindAll Handler
 func (ctrl *roleHandler) FindAll(ctx *gin.Context) {
 	// Get JWT --> authUser from request.Header
-	authUser, err := jwt.DecodeToken(ctx.GetHeader("Authorization"))
+	authUser, err := authenticate(ctx)
 	if err != nil {
-		ctx.JSON(err.Status, err)
+		respondError(ctx, err)
 		return
 	}
 
-	roles, err := services.RoleService.FindAll(authUser)
+	roles, err := services.RoleService.FindAll(ctx.Request.Context(), authUser)
 	if err != nil {
-		ctx.JSON(err.Status, err)
+		respondError(ctx, err)
 		return
 	}
 
@@ -84,18 +82,18 @@ func (ctrl *roleHandler) FindAll(ctx *gin.Context) {
 // GetByID role
 func (ctrl *roleHandler) GetByID(ctx *gin.Context) {
 	// Get JWT --> authUser from request.Header
-	authUser, err := jwt.DecodeToken(ctx.GetHeader("Authorization"))
+	authUser, err := authenticate(ctx)
 	if err != nil {
-		ctx.JSON(err.Status, err)
+		respondError(ctx, err)
 		return
 	}
 
 	// Get id from request.Param
 	id := ctx.Param("id")
 
-	role, err := services.RoleService.GetByID(id, authUser)
+	role, err := services.RoleService.GetByID(ctx.Request.Context(), id, authUser)
 	if err != nil {
-		ctx.JSON(err.Status, err)
+		respondError(ctx, err)
 		return
 	}
 
@@ -110,9 +108,9 @@ func (ctrl *roleHandler) GetByID(ctx *gin.Context) {
 // Update role
 func (ctrl *roleHandler) Update(ctx *gin.Context) {
 	// Get JWT --> authUser from request.Header
-	authUser, err := jwt.DecodeToken(ctx.GetHeader("Authorization"))
+	authUser, err := authenticate(ctx)
 	if err != nil {
-		ctx.JSON(err.Status, err)
+		respondError(ctx, err)
 		return
 	}
 
@@ -120,18 +118,16 @@ func (ctrl *roleHandler) Update(ctx *gin.Context) {
 	id := ctx.Param("id")
 
 	// Verify body
-	var request models.Role
+	var request models.UpdateRoleRequest
 	if err := ctx.ShouldBindJSON(&request); err != nil {
 		reqErr := resterr.NewBadRequestError("Invalid JSON body")
-		ctx.JSON(reqErr.Status, reqErr)
+		respondError(ctx, reqErr)
 		return
 	}
 
-	request.ID = id
-
-	role, updateErr := services.RoleService.Update(request, authUser)
+	role, updateErr := services.RoleService.Update(ctx.Request.Context(), id, request, authUser)
 	if updateErr != nil {
-		ctx.JSON(updateErr.Status, updateErr)
+		respondError(ctx, updateErr)
 		return
 	}
 
@@ -146,17 +142,17 @@ func (ctrl *roleHandler) Update(ctx *gin.Context) {
 // Delete Handler
 func (ctrl *roleHandler) Delete(ctx *gin.Context) {
 	// Get JWT --> authUser from request.Header
-	authUser, err := jwt.DecodeToken(ctx.GetHeader("Authorization"))
+	authUser, err := authenticate(ctx)
 	if err != nil {
-		ctx.JSON(err.Status, err)
+		respondError(ctx, err)
 		return
 	}
 
 	// Verify ID
 	id := ctx.Param("id")
 
-	if err := services.RoleService.Delete(id, authUser); err != nil {
-		ctx.JSON(err.Status, err)
+	if err := services.RoleService.Delete(ctx.Request.Context(), id, authUser); err != nil {
+		respondError(ctx, err)
 		return
 	}
 
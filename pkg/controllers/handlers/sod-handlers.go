@@ -0,0 +1,192 @@
+package handlers
+
+import (
+	"net/http"
+
+	"gorabc/pkg/logic/services"
+	"gorabc/pkg/models"
+	"gorabc/pkg/utils/resterr"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SoDHandlerInterface type
+type SoDHandlerInterface interface {
+	Create(ctx *gin.Context)
+	FindAll(ctx *gin.Context)
+	GetByID(ctx *gin.Context)
+	Update(ctx *gin.Context)
+	Delete(ctx *gin.Context)
+	Violations(ctx *gin.Context)
+}
+
+// sodHandler struct
+type sodHandler struct{}
+
+// SoDHandler variable
+var (
+	SoDHandler SoDHandlerInterface = &sodHandler{}
+)
+
+// Create Handler
+func (ctrl *sodHandler) Create(ctx *gin.Context) {
+	// Get JWT --> authUser from request.Header
+	authUser, err := authenticate(ctx)
+	if err != nil {
+		respondError(ctx, err)
+		return
+	}
+
+	var request models.SoDConstraint
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		restErr := resterr.NewBadRequestError("Invalid JSON body")
+		respondError(ctx, restErr)
+		return
+	}
+
+	constraint, err := services.SoDService.Create(ctx.Request.Context(), request, authUser)
+	if err != nil {
+		respondError(ctx, err)
+		return
+	}
+
+	response := gin.H{
+		"object":  constraint,
+		"message": "Separation of duties constraint successfully created",
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+// FindAll Handler
+func (ctrl *sodHandler) FindAll(ctx *gin.Context) {
+	// Get JWT --> authUser from request.Header
+	authUser, err := authenticate(ctx)
+	if err != nil {
+		respondError(ctx, err)
+		return
+	}
+
+	constraints, err := services.SoDService.FindAll(ctx.Request.Context(), authUser)
+	if err != nil {
+		respondError(ctx, err)
+		return
+	}
+
+	response := gin.H{
+		"list":    constraints,
+		"message": "List of separation of duties constraints",
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+// GetByID Handler
+func (ctrl *sodHandler) GetByID(ctx *gin.Context) {
+	// Get JWT --> authUser from request.Header
+	authUser, err := authenticate(ctx)
+	if err != nil {
+		respondError(ctx, err)
+		return
+	}
+
+	// Get id from request.Param
+	id := ctx.Param("id")
+
+	constraint, err := services.SoDService.GetByID(ctx.Request.Context(), id, authUser)
+	if err != nil {
+		respondError(ctx, err)
+		return
+	}
+
+	response := gin.H{
+		"object":  constraint,
+		"message": "Separation of duties constraint object",
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+// Update Handler
+func (ctrl *sodHandler) Update(ctx *gin.Context) {
+	// Get JWT --> authUser from request.Header
+	authUser, err := authenticate(ctx)
+	if err != nil {
+		respondError(ctx, err)
+		return
+	}
+
+	// Get id from request.Param
+	id := ctx.Param("id")
+
+	// Verify body
+	var request models.SoDConstraint
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		reqErr := resterr.NewBadRequestError("Invalid JSON body")
+		respondError(ctx, reqErr)
+		return
+	}
+
+	request.ID = id
+
+	constraint, updateErr := services.SoDService.Update(ctx.Request.Context(), request, authUser)
+	if updateErr != nil {
+		respondError(ctx, updateErr)
+		return
+	}
+
+	response := gin.H{
+		"object":  constraint,
+		"message": "Separation of duties constraint updated",
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+// Delete Handler
+func (ctrl *sodHandler) Delete(ctx *gin.Context) {
+	// Get JWT --> authUser from request.Header
+	authUser, err := authenticate(ctx)
+	if err != nil {
+		respondError(ctx, err)
+		return
+	}
+
+	// Verify ID
+	id := ctx.Param("id")
+
+	if err := services.SoDService.Delete(ctx.Request.Context(), id, authUser); err != nil {
+		respondError(ctx, err)
+		return
+	}
+
+	response := gin.H{
+		"object":  map[string]string{"Status": "Deleted"},
+		"message": "Separation of duties constraint successfully deleted",
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+// Violations Handler
+func (ctrl *sodHandler) Violations(ctx *gin.Context) {
+	// Get JWT --> authUser from request.Header
+	authUser, err := authenticate(ctx)
+	if err != nil {
+		respondError(ctx, err)
+		return
+	}
+
+	violations, err := services.SoDService.Violations(ctx.Request.Context(), authUser)
+	if err != nil {
+		respondError(ctx, err)
+		return
+	}
+
+	response := gin.H{
+		"list":    violations,
+		"message": "Separation of duties violations",
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"net/http"
+
+	"gorabc/pkg/logic/services"
+	"gorabc/pkg/models"
+	"gorabc/pkg/utils/resterr"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TemplateHandlerInterface type
+type TemplateHandlerInterface interface {
+	FindAll(ctx *gin.Context)
+	Instantiate(ctx *gin.Context)
+}
+
+// templateHandler struct
+type templateHandler struct{}
+
+// TemplateHandler variable
+var (
+	TemplateHandler TemplateHandlerInterface = &templateHandler{}
+)
+
+// FindAll Handler
+func (ctrl *templateHandler) FindAll(ctx *gin.Context) {
+	// Get JWT --> authUser from request.Header
+	authUser, err := authenticate(ctx)
+	if err != nil {
+		respondError(ctx, err)
+		return
+	}
+
+	catalogue, err := services.TemplateService.FindAll(ctx.Request.Context(), authUser)
+	if err != nil {
+		respondError(ctx, err)
+		return
+	}
+
+	response := gin.H{
+		"object":  catalogue,
+		"message": "Role templates and presets",
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+// Instantiate Handler
+func (ctrl *templateHandler) Instantiate(ctx *gin.Context) {
+	// Get JWT --> authUser from request.Header
+	authUser, err := authenticate(ctx)
+	if err != nil {
+		respondError(ctx, err)
+		return
+	}
+
+	var request models.InstantiateTemplateRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		restErr := resterr.NewBadRequestError("Invalid JSON body")
+		respondError(ctx, restErr)
+		return
+	}
+
+	role, err := services.TemplateService.Instantiate(ctx.Request.Context(), ctx.Param("key"), request, authUser)
+	if err != nil {
+		respondError(ctx, err)
+		return
+	}
+
+	response := gin.H{
+		"object":  role,
+		"message": "Role successfully created from template",
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
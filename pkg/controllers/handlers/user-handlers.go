@@ -4,7 +4,6 @@ import (
 	"net/http"
 
 	"gorabc/pkg/logic/services"
-	"gorabc/pkg/middlewares/jwt"
 	"gorabc/pkg/models"
 	"gorabc/pkg/utils/resterr"
 
@@ -18,7 +17,10 @@ type UserHandlerInterface interface {
 	GetByID(ctx *gin.Context)
 	Update(ctx *gin.Context)
 	UpdatePassword(ctx *gin.Context)
+	SetOrgAdmin(ctx *gin.Context)
 	Delete(ctx *gin.Context)
+	GetEffectivePermissions(ctx *gin.Context)
+	GetGrantEvents(ctx *gin.Context)
 }
 
 // userHandler struct
@@ -32,22 +34,21 @@ var (
 // Create Handler
 func (ctrl *userHandler) Create(ctx *gin.Context) {
 	// Get JWT from request.Header
-	authHeader := ctx.GetHeader("Authorization")
-	authUser, err := jwt.DecodeToken(authHeader)
+	authUser, err := authenticate(ctx)
 	if err != nil {
-		ctx.JSON(err.Status, err)
+		respondError(ctx, err)
 		return
 	}
-	var request models.User
+	var request models.CreateUserRequest
 	if err := ctx.ShouldBindJSON(&request); err != nil {
 		restErr := resterr.NewBadRequestError("Invalid JSON body")
-		ctx.JSON(restErr.Status, restErr)
+		respondError(ctx, restErr)
 		return
 	}
 
-	user, err := services.UserService.Create(request, authUser)
+	user, err := services.UserService.Create(ctx.Request.Context(), request, authUser)
 	if err != nil {
-		ctx.JSON(err.Status, err)
+		respondError(ctx, err)
 		return
 	}
 
@@ -62,16 +63,15 @@ func (ctrl *userHandler) Create(ctx *gin.Context) {
 // FindAll Handler
 func (ctrl *userHandler) FindAll(ctx *gin.Context) {
 	// Get JWT from request.Header
-	authHeader := ctx.GetHeader("Authorization")
-	authUser, err := jwt.DecodeToken(authHeader)
+	authUser, err := authenticate(ctx)
 	if err != nil {
-		ctx.JSON(err.Status, err)
+		respondError(ctx, err)
 		return
 	}
 
-	users, err := services.UserService.FindAll(authUser)
+	users, err := services.UserService.FindAll(ctx.Request.Context(), authUser)
 	if err != nil {
-		ctx.JSON(err.Status, err)
+		respondError(ctx, err)
 		return
 	}
 
@@ -86,19 +86,18 @@ func (ctrl *userHandler) FindAll(ctx *gin.Context) {
 // GetByID Handler
 func (ctrl *userHandler) GetByID(ctx *gin.Context) {
 	// Get JWT from request.Header
-	authHeader := ctx.GetHeader("Authorization")
-	authUser, err := jwt.DecodeToken(authHeader)
+	authUser, err := authenticate(ctx)
 	if err != nil {
-		ctx.JSON(err.Status, err)
+		respondError(ctx, err)
 		return
 	}
 
 	// Get id from request.Param
 	id := ctx.Param("id")
 
-	user, err := services.UserService.GetByID(id, authUser)
+	user, err := services.UserService.GetByID(ctx.Request.Context(), id, authUser)
 	if err != nil {
-		ctx.JSON(err.Status, err)
+		respondError(ctx, err)
 		return
 	}
 
@@ -113,10 +112,9 @@ func (ctrl *userHandler) GetByID(ctx *gin.Context) {
 // Update Handler
 func (ctrl *userHandler) Update(ctx *gin.Context) {
 	// Get JWT from request.Header
-	authHeader := ctx.GetHeader("Authorization")
-	authUser, err := jwt.DecodeToken(authHeader)
+	authUser, err := authenticate(ctx)
 	if err != nil {
-		ctx.JSON(err.Status, err)
+		respondError(ctx, err)
 		return
 	}
 
@@ -124,18 +122,16 @@ func (ctrl *userHandler) Update(ctx *gin.Context) {
 	id := ctx.Param("id")
 
 	// Verify body
-	var user models.User
-	if err := ctx.ShouldBindJSON(&user); err != nil {
+	var request models.UpdateUserRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
 		restErr := resterr.NewBadRequestError("Invalid JSON body")
-		ctx.JSON(restErr.Status, restErr)
+		respondError(ctx, restErr)
 		return
 	}
 
-	user.ID = id
-
-	result, updateErr := services.UserService.Update(user, authUser)
+	result, updateErr := services.UserService.Update(ctx.Request.Context(), id, request, authUser)
 	if updateErr != nil {
-		ctx.JSON(updateErr.Status, updateErr)
+		respondError(ctx, updateErr)
 		return
 	}
 
@@ -150,10 +146,9 @@ func (ctrl *userHandler) Update(ctx *gin.Context) {
 // UpdatePassword Handler
 func (ctrl *userHandler) UpdatePassword(ctx *gin.Context) {
 	// Get JWT from request.Header
-	authHeader := ctx.GetHeader("Authorization")
-	authUser, err := jwt.DecodeToken(authHeader)
+	authUser, err := authenticate(ctx)
 	if err != nil {
-		ctx.JSON(err.Status, err)
+		respondError(ctx, err)
 		return
 	}
 
@@ -161,18 +156,50 @@ func (ctrl *userHandler) UpdatePassword(ctx *gin.Context) {
 	id := ctx.Param("id")
 
 	// Verify body
-	var user models.User
-	if err := ctx.ShouldBindJSON(&user); err != nil {
+	var request models.UpdatePasswordRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
 		restErr := resterr.NewBadRequestError("Invalid JSON body")
-		ctx.JSON(restErr.Status, restErr)
+		respondError(ctx, restErr)
+		return
+	}
+
+	result, updateErr := services.UserService.UpdatePassword(ctx.Request.Context(), id, request, authUser)
+	if updateErr != nil {
+		respondError(ctx, updateErr)
+		return
+	}
+
+	response := gin.H{
+		"object":  result.Marshal(),
+		"message": "User successfully updated",
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+// SetOrgAdmin Handler
+func (ctrl *userHandler) SetOrgAdmin(ctx *gin.Context) {
+	// Get JWT from request.Header
+	authUser, err := authenticate(ctx)
+	if err != nil {
+		respondError(ctx, err)
 		return
 	}
 
-	user.ID = id
+	// Verify ID
+	id := ctx.Param("id")
+
+	// Verify body
+	var request models.OrgAdminRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		restErr := resterr.NewBadRequestError("Invalid JSON body")
+		respondError(ctx, restErr)
+		return
+	}
 
-	result, updateErr := services.UserService.UpdatePassword(user, authUser)
+	result, updateErr := services.UserService.SetOrgAdmin(ctx.Request.Context(), id, request, authUser)
 	if updateErr != nil {
-		ctx.JSON(updateErr.Status, updateErr)
+		respondError(ctx, updateErr)
 		return
 	}
 
@@ -187,17 +214,16 @@ func (ctrl *userHandler) UpdatePassword(ctx *gin.Context) {
 // Delete Handler
 func (ctrl *userHandler) Delete(ctx *gin.Context) {
 	// Get JWT from request.Header
-	authHeader := ctx.GetHeader("Authorization")
-	authUser, err := jwt.DecodeToken(authHeader)
+	authUser, err := authenticate(ctx)
 	if err != nil {
-		ctx.JSON(err.Status, err)
+		respondError(ctx, err)
 		return
 	}
 	// Verify ID
 	id := ctx.Param("id")
 
-	if err := services.UserService.Delete(id, authUser); err != nil {
-		ctx.JSON(err.Status, err)
+	if err := services.UserService.Delete(ctx.Request.Context(), id, authUser); err != nil {
+		respondError(ctx, err)
 		return
 	}
 
@@ -208,3 +234,55 @@ func (ctrl *userHandler) Delete(ctx *gin.Context) {
 
 	ctx.JSON(http.StatusOK, response)
 }
+
+// GetEffectivePermissions Handler
+func (ctrl *userHandler) GetEffectivePermissions(ctx *gin.Context) {
+	// Get JWT from request.Header
+	authUser, err := authenticate(ctx)
+	if err != nil {
+		respondError(ctx, err)
+		return
+	}
+
+	// Get id from request.Param
+	id := ctx.Param("id")
+
+	permissions, err := services.UserService.GetEffectivePermissions(ctx.Request.Context(), id, authUser)
+	if err != nil {
+		respondError(ctx, err)
+		return
+	}
+
+	response := gin.H{
+		"list":    permissions,
+		"message": "Effective permissions of the user",
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+// GetGrantEvents Handler
+func (ctrl *userHandler) GetGrantEvents(ctx *gin.Context) {
+	// Get JWT from request.Header
+	authUser, err := authenticate(ctx)
+	if err != nil {
+		respondError(ctx, err)
+		return
+	}
+
+	// Get id from request.Param
+	id := ctx.Param("id")
+
+	events, err := services.UserService.GetGrantEvents(ctx.Request.Context(), id, authUser)
+	if err != nil {
+		respondError(ctx, err)
+		return
+	}
+
+	response := gin.H{
+		"list":    events,
+		"message": "Grant events of the user",
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
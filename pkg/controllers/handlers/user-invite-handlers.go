@@ -0,0 +1,164 @@
+package handlers
+
+import (
+	"net/http"
+
+	"gorabc/pkg/logic/services"
+	"gorabc/pkg/middlewares/clientip"
+	"gorabc/pkg/middlewares/requestid"
+	"gorabc/pkg/models"
+	"gorabc/pkg/utils/resterr"
+
+	"github.com/gin-gonic/gin"
+)
+
+// UserInviteHandlerInterface type
+type UserInviteHandlerInterface interface {
+	Invite(ctx *gin.Context)
+	FindInvites(ctx *gin.Context)
+	ResendInvite(ctx *gin.Context)
+	RevokeInvite(ctx *gin.Context)
+	AcceptInvite(ctx *gin.Context)
+}
+
+// userInviteHandler struct
+type userInviteHandler struct{}
+
+// UserInviteHandler variable
+var (
+	UserInviteHandler UserInviteHandlerInterface = &userInviteHandler{}
+)
+
+// Invite Handler
+func (ctrl *userInviteHandler) Invite(ctx *gin.Context) {
+	// Get JWT --> authUser from request.Header
+	authUser, err := authenticate(ctx)
+	if err != nil {
+		respondError(ctx, err)
+		return
+	}
+
+	var request models.InviteUserRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		restErr := resterr.NewBadRequestError("Invalid JSON body")
+		respondError(ctx, restErr)
+		return
+	}
+
+	invite, err := services.UserService.Invite(ctx.Request.Context(), request, authUser)
+	if err != nil {
+		respondError(ctx, err)
+		return
+	}
+
+	response := gin.H{
+		"object":  invite,
+		"message": "User invited",
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+// FindInvites Handler
+func (ctrl *userInviteHandler) FindInvites(ctx *gin.Context) {
+	// Get JWT --> authUser from request.Header
+	authUser, err := authenticate(ctx)
+	if err != nil {
+		respondError(ctx, err)
+		return
+	}
+
+	invites, err := services.UserService.FindInvites(ctx.Request.Context(), authUser)
+	if err != nil {
+		respondError(ctx, err)
+		return
+	}
+
+	response := gin.H{
+		"list":    invites,
+		"message": "List of pending user invites",
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+// ResendInvite Handler
+func (ctrl *userInviteHandler) ResendInvite(ctx *gin.Context) {
+	// Get JWT --> authUser from request.Header
+	authUser, err := authenticate(ctx)
+	if err != nil {
+		respondError(ctx, err)
+		return
+	}
+
+	// The body is optional, the default expiry applies without one
+	var request models.ResendInviteRequest
+	if ctx.Request.ContentLength > 0 {
+		if err := ctx.ShouldBindJSON(&request); err != nil {
+			restErr := resterr.NewBadRequestError("Invalid JSON body")
+			respondError(ctx, restErr)
+			return
+		}
+	}
+
+	invite, err := services.UserService.ResendInvite(ctx.Request.Context(), ctx.Param("id"), request, authUser)
+	if err != nil {
+		respondError(ctx, err)
+		return
+	}
+
+	response := gin.H{
+		"object":  invite,
+		"message": "User invite sent again, the previous link no longer works",
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+// RevokeInvite Handler
+func (ctrl *userInviteHandler) RevokeInvite(ctx *gin.Context) {
+	// Get JWT --> authUser from request.Header
+	authUser, err := authenticate(ctx)
+	if err != nil {
+		respondError(ctx, err)
+		return
+	}
+
+	if err := services.UserService.RevokeInvite(ctx.Request.Context(), ctx.Param("id"), authUser); err != nil {
+		respondError(ctx, err)
+		return
+	}
+
+	response := gin.H{
+		"object":  map[string]string{"Status": models.InviteStatusRevoked},
+		"message": "User invite revoked and pending user removed",
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+// AcceptInvite Handler, the signed token replaces the JWT
+func (ctrl *userInviteHandler) AcceptInvite(ctx *gin.Context) {
+	var request models.AcceptInviteRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		restErr := resterr.NewBadRequestError("Invalid JSON body")
+		respondError(ctx, restErr)
+		return
+	}
+
+	request.IP = clientip.Get(ctx)
+	request.RequestID = requestid.Get(ctx)
+
+	user, err := services.UserService.AcceptInvite(ctx.Request.Context(), request)
+	if err != nil {
+		respondError(ctx, err)
+		return
+	}
+
+	response := gin.H{
+		"object":  user.Marshal(),
+		"message": "Invite accepted, the user can now login",
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,21 @@
+package routes
+
+import (
+	"gorabc/pkg/controllers/handlers"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AccessRequest Routes function
+func AccessRequest(r *gin.Engine) {
+	h := handlers.AccessRequestHandler
+
+	router := r.Group("/api/access-requests")
+
+	router.POST("", h.Create)
+	router.GET("", h.FindAll)
+	router.GET(":id", h.GetByID)
+	router.POST(":id/approve", h.Approve)
+	router.POST(":id/deny", h.Deny)
+	router.POST(":id/cancel", h.Cancel)
+}
@@ -0,0 +1,19 @@
+package routes
+
+import (
+	"gorabc/pkg/controllers/handlers"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ACL Routes function
+func ACL(r *gin.Engine) {
+	h := handlers.ACLHandler
+
+	router := r.Group("/api/acl")
+
+	router.POST("", h.Grant)
+	router.GET("", h.FindAll)
+	router.DELETE(":id", h.Revoke)
+	router.POST("check", h.Check)
+}
@@ -0,0 +1,23 @@
+package routes
+
+import (
+	"gorabc/pkg/controllers/handlers"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Admin Routes function
+func Admin(r *gin.Engine) {
+	h := handlers.AdminHandler
+	router := r.Group("api/admin")
+	router.GET("tenants", h.FindTenants)
+	router.GET("tenants/:id", h.GetTenant)
+	router.POST("tenants/:id/suspend", h.SuspendTenant)
+	router.POST("tenants/:id/reactivate", h.ReactivateTenant)
+	router.PUT("tenants/:id/users/:user_id/org-admin", h.SetOrgAdmin)
+	router.POST("tenants/:id/users/:user_id/impersonate", h.Impersonate)
+	router.GET("permissions", h.FindPermissions)
+	router.POST("permissions", h.CreatePermission)
+	router.DELETE("permissions/:name", h.DeletePermission)
+	router.GET("search", h.Search)
+}
@@ -0,0 +1,18 @@
+package routes
+
+import (
+	"gorabc/pkg/controllers/handlers"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Audit Routes function
+func Audit(r *gin.Engine) {
+	h := handlers.AuditHandler
+
+	router := r.Group("api/audit")
+
+	router.GET("", h.Find)
+	router.GET("export", h.Export)
+	router.GET("verify", h.Verify)
+}
@@ -15,4 +15,9 @@ func Auth(r *gin.Engine) {
 	router.POST("login", h.Login)
 	router.POST("register/org", h.RegisterOrg)
 	router.POST("register/superuser", h.RegisterSuperuser)
+
+	invites := handlers.RegistrationHandler
+	router.POST("register/invites", invites.CreateInvite)
+	router.GET("register/invites", invites.FindInvites)
+	router.DELETE("register/invites/:id", invites.RevokeInvite)
 }
@@ -0,0 +1,17 @@
+package routes
+
+import (
+	"gorabc/pkg/controllers/handlers"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Import Routes function
+func Import(r *gin.Engine) {
+	h := handlers.ImportHandler
+
+	router := r.Group("/api")
+
+	router.POST("import/:kind", h.Import)
+	router.GET("export/:kind", h.Export)
+}
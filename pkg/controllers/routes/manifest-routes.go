@@ -0,0 +1,17 @@
+package routes
+
+import (
+	"gorabc/pkg/controllers/handlers"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Manifest Routes function
+func Manifest(r *gin.Engine) {
+	h := handlers.ManifestHandler
+
+	router := r.Group("/api/manifest")
+
+	router.POST("plan", h.Plan)
+	router.POST("apply", h.Apply)
+}
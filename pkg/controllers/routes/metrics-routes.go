@@ -0,0 +1,14 @@
+package routes
+
+import (
+	"gorabc/pkg/controllers/handlers"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Metrics Routes function
+func Metrics(r *gin.Engine) {
+	router := r.Group("/")
+
+	router.GET("metrics", handlers.Metrics)
+}
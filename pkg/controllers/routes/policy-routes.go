@@ -0,0 +1,22 @@
+package routes
+
+import (
+	"gorabc/pkg/controllers/handlers"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Policy Routes function
+func Policy(r *gin.Engine) {
+	h := handlers.PolicyHandler
+
+	router := r.Group("/api/policy")
+
+	router.POST("", h.Create)
+	router.GET("", h.FindAll)
+	router.GET(":id", h.GetByID)
+	router.PUT(":id", h.Update)
+	router.DELETE(":id", h.Delete)
+
+	r.POST("/api/authorize", h.Authorize)
+}
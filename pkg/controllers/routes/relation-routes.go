@@ -0,0 +1,23 @@
+package routes
+
+import (
+	"gorabc/pkg/controllers/handlers"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Relation Routes function
+func Relation(r *gin.Engine) {
+	h := handlers.RelationHandler
+
+	router := r.Group("/api/relations")
+
+	router.POST("tuples", h.Write)
+	router.GET("tuples", h.FindTuples)
+	router.POST("check", h.Check)
+	router.POST("expand", h.Expand)
+	router.POST("list-objects", h.ListObjects)
+	router.GET("namespaces", h.FindNamespaces)
+	router.PUT("namespaces/:name", h.UpdateNamespace)
+	router.DELETE("namespaces/:name", h.DeleteNamespace)
+}
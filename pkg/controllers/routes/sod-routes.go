@@ -0,0 +1,21 @@
+package routes
+
+import (
+	"gorabc/pkg/controllers/handlers"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SoD Routes function
+func SoD(r *gin.Engine) {
+	h := handlers.SoDHandler
+
+	router := r.Group("/api/sod")
+
+	router.POST("", h.Create)
+	router.GET("", h.FindAll)
+	router.GET("violations", h.Violations)
+	router.GET(":id", h.GetByID)
+	router.PUT(":id", h.Update)
+	router.DELETE(":id", h.Delete)
+}
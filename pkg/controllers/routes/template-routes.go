@@ -0,0 +1,17 @@
+package routes
+
+import (
+	"gorabc/pkg/controllers/handlers"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Template Routes function
+func Template(r *gin.Engine) {
+	h := handlers.TemplateHandler
+
+	router := r.Group("/api/role-templates")
+
+	router.GET("", h.FindAll)
+	router.POST(":key/instantiate", h.Instantiate)
+}
@@ -0,0 +1,22 @@
+package routes
+
+import (
+	"gorabc/pkg/controllers/handlers"
+
+	"github.com/gin-gonic/gin"
+)
+
+// UserInvites Routes function
+func UserInvites(r *gin.Engine) {
+	h := handlers.UserInviteHandler
+
+	users := r.Group("api/users")
+	users.POST("invite", h.Invite)
+	users.POST("invite/accept", h.AcceptInvite)
+
+	// Kept apart from api/users so the paths do not clash with :id
+	router := r.Group("api/user-invites")
+	router.GET("", h.FindInvites)
+	router.POST(":id/resend", h.ResendInvite)
+	router.DELETE(":id", h.RevokeInvite)
+}
@@ -17,5 +17,8 @@ func Users(r *gin.Engine) {
 	router.GET(":id", h.GetByID)
 	router.PUT(":id", h.Update)
 	router.PUT(":id/password", h.UpdatePassword)
+	router.PUT(":id/org-admin", h.SetOrgAdmin)
+	router.GET(":id/permissions", h.GetEffectivePermissions)
+	router.GET(":id/grant-events", h.GetGrantEvents)
 	router.DELETE(":id", h.Delete)
 }
@@ -0,0 +1,97 @@
+package helpers
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strings"
+
+	"gorabc/pkg/models"
+	"gorabc/pkg/utils/encrypt"
+)
+
+// secretFields are never written to the audit log
+var secretFields = []string{"password", "token", "secret", "nonce"}
+
+// AuditChanges between two states of an object, the fields of its JSON
+// representation that differ. A nil before is a creation, a nil after a deletion.
+func AuditChanges(before interface{}, after interface{}) []models.AuditChange {
+	beforeFields := auditFields(before)
+	afterFields := auditFields(after)
+
+	names := []string{}
+	for name := range beforeFields {
+		names = append(names, name)
+	}
+	for name := range afterFields {
+		if _, ok := beforeFields[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	changes := []models.AuditChange{}
+	for _, name := range names {
+		beforeValue, afterValue := beforeFields[name], afterFields[name]
+		if reflect.DeepEqual(beforeValue, afterValue) {
+			continue
+		}
+
+		change := models.AuditChange{Field: name}
+		if isSecretField(name) {
+			// Record that the secret changed, not its value
+			if beforeValue != nil {
+				change.Before = models.AuditRedacted
+			}
+			if afterValue != nil {
+				change.After = models.AuditRedacted
+			}
+		} else {
+			change.Before = auditValue(beforeValue)
+			change.After = auditValue(afterValue)
+		}
+		changes = append(changes, change)
+	}
+	return changes
+}
+
+// AuditHash of an event, it covers every field but the hash itself, the
+// previous hash included, so each event seals the chain before it
+func AuditHash(event models.AuditEvent) string {
+	event.Hash = ""
+	data, _ := json.Marshal(event)
+	return encrypt.HashToken(string(data))
+}
+
+// auditFields of the JSON object of a value
+func auditFields(value interface{}) map[string]interface{} {
+	fields := map[string]interface{}{}
+	if value == nil || reflect.ValueOf(value).Kind() == reflect.Ptr && reflect.ValueOf(value).IsNil() {
+		return fields
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fields
+	}
+	json.Unmarshal(data, &fields)
+	return fields
+}
+
+// auditValue JSON encoded, empty for a missing field
+func auditValue(value interface{}) string {
+	if value == nil {
+		return ""
+	}
+	data, _ := json.Marshal(value)
+	return string(data)
+}
+
+func isSecretField(name string) bool {
+	name = strings.ToLower(name)
+	for _, secret := range secretFields {
+		if strings.Contains(name, secret) {
+			return true
+		}
+	}
+	return false
+}
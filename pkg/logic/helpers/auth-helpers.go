@@ -1,13 +1,137 @@
 package helpers
 
-import "gorabc/pkg/models"
+import (
+	"strings"
+
+	"gorabc/pkg/models"
+	"gorabc/pkg/utils/datetime"
+	"gorabc/pkg/utils/metrics"
+)
 
 // IsGranted middlewares verifies the permission of the user
+// Only organization wide grants are considered, department scoped grants
+// are verified through GetScope. An explicit organization wide deny always
+// takes precedence, also for organization admins.
 func IsGranted(permission string, user models.AuthUser) bool {
-	for i := 0; i < len(user.Permissions); i++ {
-		if permission == user.Permissions[i].Name {
+	granted := isGranted(permission, user)
+	metrics.ObservePermission(permission, granted)
+	return granted
+}
+
+// isGranted checks the permission without recording the check
+func isGranted(permission string, user models.AuthUser) bool {
+	if IsDenied(permission, user) {
+		return false
+	}
+	if user.IsOrgAdmin {
+		return true
+	}
+
+	grants := user.GrantResolver().Match(permission)
+	for i := 0; i < len(grants); i++ {
+		if grants[i].Scope == "" {
 			return true
 		}
 	}
 	return false
 }
+
+// GetAuthUser builds the authenticated user of a user with its effective
+// permissions, grants outside of their validity are left out
+func GetAuthUser(user models.User) models.AuthUser {
+	now := datetime.GetDateTimeString()
+	au := models.AuthUser{
+		ID:           user.ID,
+		Organization: user.Organization,
+		IsSuperuser:  user.IsSuperuser,
+		IsOrgAdmin:   user.IsOrgAdmin,
+		Permissions:  ActivePermissions(user.Permissions, now),
+		Denies:       ActivePermissions(user.Denies, now),
+		Attributes:   user.Attributes,
+	}
+	au.Resolve()
+	return au
+}
+
+// ReadOnlyAuthUser restricts the user to the read actions of its grants,
+// wildcard grants are narrowed to their reads and an organization admin
+// reads everything without being an admin anymore. Denies are kept.
+func ReadOnlyAuthUser(user models.AuthUser) models.AuthUser {
+	grants := user.Permissions
+	if user.IsOrgAdmin {
+		grants = []models.Permission{{Name: "*"}}
+	}
+
+	readOnly := []models.Permission{}
+	for i := 0; i < len(grants); i++ {
+		grant := grants[i]
+		switch {
+		case strings.HasSuffix(grant.Name, ":read"):
+		case grant.Name == "*" || strings.HasSuffix(grant.Name, ":*"):
+			grant.Name = grant.Name + ":read"
+		default:
+			continue
+		}
+		readOnly = append(readOnly, grant)
+	}
+
+	user.IsOrgAdmin = false
+	user.Permissions = readOnly
+	user.ReadOnly = true
+	user.Resolve()
+	return user
+}
+
+// ActivePermissions filters the grants valid at the given datetime string
+func ActivePermissions(list []models.Permission, now string) []models.Permission {
+	result := []models.Permission{}
+	for i := 0; i < len(list); i++ {
+		if list[i].IsActive(now) {
+			result = append(result, list[i])
+		}
+	}
+	return result
+}
+
+// IsDenied verifies whether the permission is explicitly denied organization wide
+func IsDenied(permission string, user models.AuthUser) bool {
+	return getDeny(permission, user) != nil
+}
+
+// getDeny returns the organization wide deny blocking the permission
+func getDeny(permission string, user models.AuthUser) *models.Permission {
+	denies := user.DenyResolver().Match(permission)
+	for i := 0; i < len(denies); i++ {
+		if denies[i].Scope == "" {
+			return &denies[i]
+		}
+	}
+	return nil
+}
+
+// ExplainPermission reports which grants allow the permission and which
+// deny blocks it
+func ExplainPermission(permission string, user models.AuthUser) models.PermissionDecision {
+	decision := models.PermissionDecision{
+		Permission: permission,
+		GrantedBy:  user.GrantResolver().Match(permission),
+	}
+
+	if user.IsOrgAdmin {
+		decision.GrantedBy = append(decision.GrantedBy, models.Permission{Name: permission, Source: "org_admin"})
+	}
+
+	if deny := getDeny(permission, user); deny != nil {
+		decision.DeniedBy = deny
+		return decision
+	}
+
+	// scoped denies only block their department subtree
+	denies := user.DenyResolver().Match(permission)
+	if len(denies) > 0 {
+		decision.DeniedBy = &denies[0]
+	}
+
+	decision.Allowed = isGranted(permission, user) || !getScope(permission, user).IsEmpty()
+	return decision
+}
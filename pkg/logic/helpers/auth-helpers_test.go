@@ -0,0 +1,33 @@
+package helpers
+
+import (
+	"testing"
+
+	"gorabc/pkg/models"
+)
+
+func TestReadOnlyAuthUser(t *testing.T) {
+	tests := []struct {
+		name       string
+		user       models.AuthUser
+		permission string
+		want       bool
+	}{
+		{"read grant", models.AuthUser{Permissions: []models.Permission{{Name: "org:user:read"}}}, "org:user:read", true},
+		{"write grant", models.AuthUser{Permissions: []models.Permission{{Name: "org:user:update"}}}, "org:user:update", false},
+		{"wildcard reads", models.AuthUser{Permissions: []models.Permission{{Name: "org:*"}}}, "org:role:read", true},
+		{"wildcard writes", models.AuthUser{Permissions: []models.Permission{{Name: "org:*"}}}, "org:role:delete", false},
+		{"wildcard of another domain", models.AuthUser{Permissions: []models.Permission{{Name: "org:*"}}}, "inventory:product:read", false},
+		{"org admin reads", models.AuthUser{IsOrgAdmin: true}, "inventory:product:read", true},
+		{"org admin writes", models.AuthUser{IsOrgAdmin: true}, "org:user:update", false},
+		{"deny kept", models.AuthUser{IsOrgAdmin: true, Denies: []models.Permission{{Name: "org:audit:read"}}}, "org:audit:read", false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := IsGranted(test.permission, ReadOnlyAuthUser(test.user)); got != test.want {
+				t.Errorf("expected %s granted %v, got %v", test.permission, test.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,195 @@
+package helpers
+
+import (
+	"context"
+
+	"gorabc/pkg/models"
+	"gorabc/pkg/repository/dao"
+	"gorabc/pkg/utils/metrics"
+	"gorabc/pkg/utils/resterr"
+)
+
+// DepartmentScope lists where a permission has been granted to a user
+// Denied subtrees take precedence over the granted ones.
+type DepartmentScope struct {
+	OrgWide     bool
+	Departments []string
+	Denied      []string
+}
+
+// GetScope collects the department subtrees in which the permission is granted
+func GetScope(permission string, user models.AuthUser) DepartmentScope {
+	scope := getScope(permission, user)
+	metrics.ObservePermission(permission, !scope.IsEmpty())
+	return scope
+}
+
+// getScope resolves the scope without recording the check
+func getScope(permission string, user models.AuthUser) DepartmentScope {
+	scope := DepartmentScope{}
+
+	// explicit denies
+	denies := user.DenyResolver().Match(permission)
+	for i := 0; i < len(denies); i++ {
+		if denies[i].Scope == "" {
+			return DepartmentScope{}
+		}
+		scope.Denied = append(scope.Denied, denies[i].Scope)
+	}
+
+	if user.IsOrgAdmin {
+		scope.OrgWide = true
+		return scope
+	}
+
+	grants := user.GrantResolver().Match(permission)
+	for i := 0; i < len(grants); i++ {
+		if grants[i].Scope == "" {
+			scope.OrgWide = true
+			continue
+		}
+		scope.Departments = append(scope.Departments, grants[i].Scope)
+	}
+	return scope
+}
+
+// IsEmpty reports whether the permission has not been granted anywhere
+func (scope DepartmentScope) IsEmpty() bool {
+	return !scope.OrgWide && len(scope.Departments) == 0
+}
+
+// Unrestricted reports whether the permission applies to the whole organization
+func (scope DepartmentScope) Unrestricted() bool {
+	return scope.OrgWide && len(scope.Denied) == 0
+}
+
+// Allows verifies that the department lies within one of the granted subtrees
+func (scope DepartmentScope) Allows(dept models.Department) bool {
+	for i := 0; i < len(scope.Denied); i++ {
+		if dept.InSubtree(scope.Denied[i]) {
+			return false
+		}
+	}
+	if scope.OrgWide {
+		return true
+	}
+	for i := 0; i < len(scope.Departments); i++ {
+		if dept.InSubtree(scope.Departments[i]) {
+			return true
+		}
+	}
+	return false
+}
+
+// AllowsAll verifies that every department id lies within the granted subtrees,
+// departments missing from the index are never allowed
+func (scope DepartmentScope) AllowsAll(deptIDs []string, index map[string]models.Department) bool {
+	if scope.Unrestricted() {
+		return true
+	}
+	if len(deptIDs) == 0 {
+		return scope.OrgWide
+	}
+	for i := 0; i < len(deptIDs); i++ {
+		dept, ok := index[deptIDs[i]]
+		if !ok || !scope.Allows(dept) {
+			return false
+		}
+	}
+	return true
+}
+
+// GetDepartmentIndex maps the active departments of an organization by id
+func GetDepartmentIndex(ctx context.Context, org string) (map[string]models.Department, *resterr.RestErr) {
+	deptList, err := dao.DepartmentDao.FindAll(ctx, org)
+	if err != nil {
+		return nil, err
+	}
+
+	index := make(map[string]models.Department, len(deptList))
+	for i := 0; i < len(deptList); i++ {
+		index[deptList[i].ID] = deptList[i]
+	}
+	return index, nil
+}
+
+// UserDepartmentIDs returns the department ids of a user
+func UserDepartmentIDs(user models.User) []string {
+	deptIDs := []string{}
+	for i := 0; i < len(user.Departments); i++ {
+		deptIDs = append(deptIDs, user.Departments[i].DepartmentID)
+	}
+	return deptIDs
+}
+
+// VerifyUserScope checks that the permission covers every department of the user
+func VerifyUserScope(ctx context.Context, permission string, user models.User, au models.AuthUser) *resterr.RestErr {
+	scope := GetScope(permission, au)
+	if scope.Unrestricted() {
+		return nil
+	}
+	if scope.IsEmpty() {
+		return resterr.NewForbiddenError("Permission not granted")
+	}
+
+	index, err := GetDepartmentIndex(ctx, au.Organization)
+	if err != nil {
+		return err
+	}
+	if !scope.AllowsAll(UserDepartmentIDs(user), index) {
+		return resterr.NewForbiddenError("User is outside of your department scope")
+	}
+	return nil
+}
+
+// VerifyDepartmentScope checks that the permission covers the department
+func VerifyDepartmentScope(ctx context.Context, permission string, deptID string, au models.AuthUser) *resterr.RestErr {
+	scope := GetScope(permission, au)
+	if scope.Unrestricted() {
+		return nil
+	}
+	if scope.IsEmpty() {
+		return resterr.NewForbiddenError("Permission not granted")
+	}
+
+	index, err := GetDepartmentIndex(ctx, au.Organization)
+	if err != nil {
+		return err
+	}
+	if !scope.AllowsAll([]string{deptID}, index) {
+		return resterr.NewForbiddenError("Department is outside of your scope")
+	}
+	return nil
+}
+
+// VerifyAssignmentScope checks that the departments, role assignments and
+// permission grants of a user stay within the department scope of the permission
+func VerifyAssignmentScope(ctx context.Context, permission string, user models.User, au models.AuthUser) *resterr.RestErr {
+	scope := GetScope(permission, au)
+	if scope.Unrestricted() {
+		return nil
+	}
+	if scope.IsEmpty() {
+		return resterr.NewForbiddenError("Permission not granted")
+	}
+
+	index, err := GetDepartmentIndex(ctx, au.Organization)
+	if err != nil {
+		return err
+	}
+
+	if !scope.AllowsAll(UserDepartmentIDs(user), index) {
+		return resterr.NewForbiddenError("User is outside of your department scope")
+	}
+	for i := 0; i < len(user.Roles); i++ {
+		if !scope.AllowsAll([]string{user.Roles[i].Scope}, index) {
+			return resterr.NewForbiddenError("Role assignment is outside of your department scope")
+		}
+	}
+	for i := 0; i < len(user.Permissions); i++ {
+		if !scope.AllowsAll([]string{user.Permissions[i].Scope}, index) {
+			return resterr.NewForbiddenError("Permission grant is outside of your department scope")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,135 @@
+package helpers
+
+import (
+	"context"
+	"reflect"
+
+	"gorabc/pkg/models"
+	"gorabc/pkg/utils/resterr"
+)
+
+// AddedPermissions lists the grants of after missing from before, grants are
+// told apart by name, scope and validity so that widening the scope or the
+// validity of a grant counts as adding it
+func AddedPermissions(before []models.Permission, after []models.Permission) []models.Permission {
+	held := map[string]bool{}
+	for i := 0; i < len(before); i++ {
+		held[grantKey(before[i])] = true
+	}
+
+	added := []models.Permission{}
+	for i := 0; i < len(after); i++ {
+		if !held[grantKey(after[i])] {
+			held[grantKey(after[i])] = true
+			added = append(added, after[i])
+		}
+	}
+	return added
+}
+
+func grantKey(grant models.Permission) string {
+	return grant.Name + "|" + grant.Scope + "|" + grant.ValidFrom + "|" + grant.ValidUntil
+}
+
+// CanGrant reports whether the user holds the permission wherever the grant
+// applies: organization wide grants need the permission organization wide,
+// scoped grants need it on a subtree holding the department. Departments
+// missing from the index, e.g. planned ones, need it organization wide.
+// Superusers grant anything, organization admins anything not denied to them.
+func CanGrant(grant models.Permission, user models.AuthUser, index map[string]models.Department) bool {
+	if user.IsSuperuser {
+		return true
+	}
+	dept, ok := index[grant.Scope]
+	if grant.Scope == "" || !ok {
+		return isGranted(grant.Name, user)
+	}
+	return getScope(grant.Name, user).Allows(dept)
+}
+
+// VerifyGrants verifies that the user holds every granted permission, users
+// cannot give away more than they have
+func VerifyGrants(grants []models.Permission, user models.AuthUser, index map[string]models.Department) *resterr.RestErr {
+	for i := 0; i < len(grants); i++ {
+		if !CanGrant(grants[i], user, index) {
+			return resterr.NewForbiddenError("Cannot grant a permission you do not hold: " + grants[i].Name)
+		}
+	}
+	return nil
+}
+
+// VerifyObjectGrants verifies that the user holds every permission granted on
+// an object, object grants are not scoped so the permissions are needed
+// organization wide
+func VerifyObjectGrants(grants []models.Permission, user models.AuthUser) *resterr.RestErr {
+	unscoped := make([]models.Permission, len(grants))
+	for i := 0; i < len(grants); i++ {
+		unscoped[i] = models.Permission{Name: grants[i].Name}
+	}
+	return VerifyGrants(unscoped, user, nil)
+}
+
+// VerifyAdminTarget verifies that only organization admins and superusers
+// change organization admins, scoped managers cannot take over an admin account
+func VerifyAdminTarget(target models.User, user models.AuthUser) *resterr.RestErr {
+	if target.IsOrgAdmin && !user.IsOrgAdmin && !user.IsSuperuser {
+		return resterr.NewForbiddenError("Only organization admins can change an organization admin")
+	}
+	return nil
+}
+
+// VerifyAttributeChange verifies that the caller may change the subject
+// attributes trusted by the attribute based policies: it needs
+// org:user-attributes:update over the departments of the user, and only
+// organization admins change their own attributes
+func VerifyAttributeChange(ctx context.Context, before models.User, after models.User, au models.AuthUser) *resterr.RestErr {
+	if len(before.Attributes) == 0 && len(after.Attributes) == 0 || reflect.DeepEqual(before.Attributes, after.Attributes) {
+		return nil
+	}
+	if au.IsSuperuser {
+		return nil
+	}
+	if after.ID == au.ID && !au.IsOrgAdmin {
+		return resterr.NewForbiddenError("Only organization admins can change their own attributes")
+	}
+	return VerifyUserScope(ctx, "org:user-attributes:update", after, au)
+}
+
+// UserGrants lists what a change of the user grants it: the added
+// permissions, including those of added roles, and the lifted denies
+func UserGrants(before models.User, after models.User) []models.Permission {
+	return append(AddedPermissions(before.Permissions, after.Permissions), AddedPermissions(after.Denies, before.Denies)...)
+}
+
+// RoleGrants lists what a change of the role grants its holders, scoped to
+// the department of the role
+func RoleGrants(before models.Role, after models.Role) []models.Permission {
+	grants := append(AddedPermissions(before.Permissions, after.Permissions), AddedPermissions(after.Denies, before.Denies)...)
+	for i := 0; i < len(grants); i++ {
+		grants[i].Scope = after.Department
+	}
+	return grants
+}
+
+// VerifyUserGrants verifies that the caller holds everything the change of
+// the user grants
+func VerifyUserGrants(ctx context.Context, before models.User, after models.User, au models.AuthUser) *resterr.RestErr {
+	return verifyGrants(ctx, UserGrants(before, after), after.Organization, au)
+}
+
+// VerifyRoleGrants verifies that the caller holds everything the change of
+// the role grants
+func VerifyRoleGrants(ctx context.Context, before models.Role, after models.Role, au models.AuthUser) *resterr.RestErr {
+	return verifyGrants(ctx, RoleGrants(before, after), after.Organization, au)
+}
+
+func verifyGrants(ctx context.Context, grants []models.Permission, org string, au models.AuthUser) *resterr.RestErr {
+	if len(grants) == 0 || au.IsSuperuser {
+		return nil
+	}
+	index, err := GetDepartmentIndex(ctx, org)
+	if err != nil {
+		return err
+	}
+	return VerifyGrants(grants, au, index)
+}
@@ -0,0 +1,289 @@
+package helpers
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"gorabc/pkg/models"
+)
+
+// departments of the tests: sales below the root, emea below sales and ops
+// next to sales
+var grantIndex = map[string]models.Department{
+	"ROOT":  {ID: "ROOT", Path: "/ROOT/"},
+	"SALES": {ID: "SALES", Parent: "ROOT", Path: "/ROOT/SALES/"},
+	"EMEA":  {ID: "EMEA", Parent: "SALES", Path: "/ROOT/SALES/EMEA/"},
+	"OPS":   {ID: "OPS", Parent: "ROOT", Path: "/ROOT/OPS/"},
+}
+
+func TestAddedPermissions(t *testing.T) {
+	before := []models.Permission{
+		{Name: "org:user:read"},
+		{Name: "org:role:read", Scope: "SALES"},
+	}
+	after := []models.Permission{
+		{Name: "org:user:read", Source: models.SourceRole + "R1"},
+		{Name: "org:role:read", Scope: "ROOT"},
+		{Name: "org:user:update"},
+		{Name: "org:user:update"},
+	}
+
+	added := AddedPermissions(before, after)
+	if len(added) != 2 {
+		t.Fatalf("expected 2 added grants, got %v", added)
+	}
+	if added[0].Name != "org:role:read" || added[0].Scope != "ROOT" {
+		t.Errorf("widening the scope should add the grant, got %v", added[0])
+	}
+	if added[1].Name != "org:user:update" {
+		t.Errorf("expected org:user:update once, got %v", added[1])
+	}
+
+	extended := AddedPermissions(
+		[]models.Permission{{Name: "org:user:read", ValidUntil: "2026-01-01T00:00:00Z"}},
+		[]models.Permission{{Name: "org:user:read"}},
+	)
+	if len(extended) != 1 {
+		t.Errorf("extending the validity should add the grant, got %v", extended)
+	}
+}
+
+func TestCanGrant(t *testing.T) {
+	tests := []struct {
+		name  string
+		grant models.Permission
+		user  models.AuthUser
+		want  bool
+	}{
+		{
+			name:  "superuser",
+			grant: models.Permission{Name: "org:user:delete"},
+			user:  models.AuthUser{IsSuperuser: true},
+			want:  true,
+		},
+		{
+			name:  "org admin",
+			grant: models.Permission{Name: "org:user:delete"},
+			user:  models.AuthUser{IsOrgAdmin: true},
+			want:  true,
+		},
+		{
+			name:  "org admin denied",
+			grant: models.Permission{Name: "org:user:delete"},
+			user:  models.AuthUser{IsOrgAdmin: true, Denies: []models.Permission{{Name: "org:user:delete"}}},
+			want:  false,
+		},
+		{
+			name:  "held permission",
+			grant: models.Permission{Name: "org:user:read"},
+			user:  models.AuthUser{Permissions: []models.Permission{{Name: "org:user:read"}}},
+			want:  true,
+		},
+		{
+			name:  "missing permission",
+			grant: models.Permission{Name: "org:user:delete"},
+			user:  models.AuthUser{Permissions: []models.Permission{{Name: "org:user:read"}}},
+			want:  false,
+		},
+		{
+			name:  "wildcard covers narrower permission",
+			grant: models.Permission{Name: "org:user:read"},
+			user:  models.AuthUser{Permissions: []models.Permission{{Name: "org:user:*"}}},
+			want:  true,
+		},
+		{
+			name:  "narrower permission does not cover wildcard",
+			grant: models.Permission{Name: "org:*"},
+			user:  models.AuthUser{Permissions: []models.Permission{{Name: "org:user:*"}}},
+			want:  false,
+		},
+		{
+			name:  "scoped holder grants within the subtree",
+			grant: models.Permission{Name: "org:user:read", Scope: "EMEA"},
+			user:  models.AuthUser{Permissions: []models.Permission{{Name: "org:user:read", Scope: "SALES"}}},
+			want:  true,
+		},
+		{
+			name:  "scoped holder cannot grant outside the subtree",
+			grant: models.Permission{Name: "org:user:read", Scope: "OPS"},
+			user:  models.AuthUser{Permissions: []models.Permission{{Name: "org:user:read", Scope: "SALES"}}},
+			want:  false,
+		},
+		{
+			name:  "scoped holder cannot grant organization wide",
+			grant: models.Permission{Name: "org:user:read"},
+			user:  models.AuthUser{Permissions: []models.Permission{{Name: "org:user:read", Scope: "SALES"}}},
+			want:  false,
+		},
+		{
+			name:  "scoped deny blocks the subtree",
+			grant: models.Permission{Name: "org:user:read", Scope: "EMEA"},
+			user: models.AuthUser{
+				Permissions: []models.Permission{{Name: "org:user:read"}},
+				Denies:      []models.Permission{{Name: "org:user:read", Scope: "SALES"}},
+			},
+			want: false,
+		},
+		{
+			name:  "unknown department needs the permission organization wide",
+			grant: models.Permission{Name: "org:user:read", Scope: "PLANNED"},
+			user:  models.AuthUser{Permissions: []models.Permission{{Name: "org:user:read", Scope: "SALES"}}},
+			want:  false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := CanGrant(test.grant, test.user, grantIndex); got != test.want {
+				t.Errorf("CanGrant(%v) = %v, want %v", test.grant, got, test.want)
+			}
+		})
+	}
+}
+
+func TestVerifyGrants(t *testing.T) {
+	user := models.AuthUser{Permissions: []models.Permission{{Name: "org:user:read"}}}
+
+	if err := VerifyGrants([]models.Permission{{Name: "org:user:read"}}, user, grantIndex); err != nil {
+		t.Errorf("held permission should be granted, got %v", err)
+	}
+
+	err := VerifyGrants([]models.Permission{{Name: "org:user:read"}, {Name: "org:user:delete"}}, user, grantIndex)
+	if err == nil {
+		t.Fatal("granting a permission the caller lacks should fail")
+	}
+	if err.Status != http.StatusForbidden {
+		t.Errorf("expected status %d, got %d", http.StatusForbidden, err.Status)
+	}
+}
+
+func TestVerifyObjectGrants(t *testing.T) {
+	aclManager := []models.Permission{{Name: "org:acl:grant"}}
+	tests := []struct {
+		name   string
+		grants []string
+		user   models.AuthUser
+		want   bool
+	}{
+		{"acl grant permission alone", []string{"org:user:read"}, models.AuthUser{Permissions: aclManager}, false},
+		{"held permission", []string{"org:user:read"}, models.AuthUser{Permissions: append(aclManager, models.Permission{Name: "org:user:read"})}, true},
+		{"one missing permission", []string{"org:user:read", "org:user:delete"}, models.AuthUser{Permissions: append(aclManager, models.Permission{Name: "org:user:read"})}, false},
+		{"covering wildcard", []string{"inventory:product:read"}, models.AuthUser{Permissions: append(aclManager, models.Permission{Name: "inventory:*"})}, true},
+		{"wildcard of a narrower holder", []string{"inventory:*"}, models.AuthUser{Permissions: append(aclManager, models.Permission{Name: "inventory:product:read"})}, false},
+		{"everything", []string{"*"}, models.AuthUser{Permissions: aclManager}, false},
+		{"scoped holder", []string{"org:user:read"}, models.AuthUser{Permissions: append(aclManager, models.Permission{Name: "org:user:read", Scope: "SALES"})}, false},
+		{"denied permission", []string{"org:user:read"}, models.AuthUser{Permissions: append(aclManager, models.Permission{Name: "org:*"}), Denies: []models.Permission{{Name: "org:user:read"}}}, false},
+		{"org admin", []string{"*"}, models.AuthUser{IsOrgAdmin: true}, true},
+		{"superuser", []string{"org:user:delete"}, models.AuthUser{IsSuperuser: true}, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			grants := []models.Permission{}
+			for _, name := range test.grants {
+				// a scope sent by the caller does not narrow an object grant
+				grants = append(grants, models.Permission{Name: name, Scope: "SALES"})
+			}
+			if err := VerifyObjectGrants(grants, test.user); (err == nil) != test.want {
+				t.Errorf("expected allowed %v, got %v", test.want, err)
+			}
+		})
+	}
+}
+
+func TestUserGrants(t *testing.T) {
+	before := models.User{
+		Permissions: []models.Permission{{Name: "org:user:read"}},
+		Denies:      []models.Permission{{Name: "org:user:delete", Source: models.SourceUser}},
+	}
+	after := models.User{
+		Permissions: []models.Permission{
+			{Name: "org:user:read"},
+			{Name: "org:role:update", Source: models.SourceRole + "R1"},
+		},
+	}
+
+	grants := UserGrants(before, after)
+	if len(grants) != 2 || grants[0].Name != "org:role:update" || grants[1].Name != "org:user:delete" {
+		t.Errorf("expected the role permission and the lifted deny, got %v", grants)
+	}
+
+	// adding a deny grants nothing
+	restricted := before
+	restricted.Denies = append(restricted.Denies, models.Permission{Name: "org:user:read"})
+	if grants := UserGrants(before, restricted); len(grants) != 0 {
+		t.Errorf("adding a deny should grant nothing, got %v", grants)
+	}
+}
+
+func TestRoleGrants(t *testing.T) {
+	before := models.Role{Department: "SALES", Permissions: []models.Permission{{Name: "org:user:read"}}}
+	after := models.Role{Department: "SALES", Permissions: []models.Permission{{Name: "org:user:read"}, {Name: "org:user:update"}}}
+
+	grants := RoleGrants(before, after)
+	if len(grants) != 1 || grants[0].Name != "org:user:update" || grants[0].Scope != "SALES" {
+		t.Fatalf("expected org:user:update scoped to the role department, got %v", grants)
+	}
+
+	manager := models.AuthUser{Permissions: []models.Permission{{Name: "org:user:*", Scope: "SALES"}}}
+	if err := VerifyGrants(grants, manager, grantIndex); err != nil {
+		t.Errorf("a manager of the department should extend its roles, got %v", err)
+	}
+	outsider := models.AuthUser{Permissions: []models.Permission{{Name: "org:user:*", Scope: "OPS"}}}
+	if err := VerifyGrants(grants, outsider, grantIndex); err == nil {
+		t.Error("a manager of another department should not extend the role")
+	}
+}
+
+func TestVerifyAdminTarget(t *testing.T) {
+	admin := models.User{IsOrgAdmin: true}
+	manager := models.AuthUser{Permissions: []models.Permission{{Name: "org:user:*"}}}
+
+	if err := VerifyAdminTarget(admin, manager); err == nil {
+		t.Error("a manager should not change an organization admin")
+	}
+	if err := VerifyAdminTarget(admin, models.AuthUser{IsOrgAdmin: true}); err != nil {
+		t.Errorf("an organization admin should change another admin, got %v", err)
+	}
+	if err := VerifyAdminTarget(admin, models.AuthUser{IsSuperuser: true}); err != nil {
+		t.Errorf("a superuser should change an organization admin, got %v", err)
+	}
+	if err := VerifyAdminTarget(models.User{}, manager); err != nil {
+		t.Errorf("a manager should change a regular user, got %v", err)
+	}
+}
+
+func TestVerifyAttributeChange(t *testing.T) {
+	ctx := context.Background()
+	before := models.User{ID: "U1", Attributes: map[string]string{"warehouse": "WH1"}}
+	after := models.User{ID: "U1", Attributes: map[string]string{"warehouse": "WH2"}}
+	updater := models.AuthUser{ID: "U2", Permissions: []models.Permission{{Name: "org:user:update"}}}
+	attributer := models.AuthUser{ID: "U2", Permissions: []models.Permission{{Name: "org:user-attributes:update"}}}
+
+	tests := []struct {
+		name   string
+		before models.User
+		after  models.User
+		user   models.AuthUser
+		want   bool
+	}{
+		{"unchanged attributes", before, before, updater, true},
+		{"no attributes", models.User{ID: "U1"}, models.User{ID: "U1", Attributes: map[string]string{}}, updater, true},
+		{"user update only", before, after, updater, false},
+		{"attribute update", before, after, attributer, true},
+		{"new user with attributes", models.User{}, after, updater, false},
+		{"own attributes", before, after, models.AuthUser{ID: "U1", Permissions: []models.Permission{{Name: "org:user-attributes:update"}}}, false},
+		{"own attributes of an organization admin", before, after, models.AuthUser{ID: "U1", IsOrgAdmin: true}, true},
+		{"superuser", before, after, models.AuthUser{IsSuperuser: true}, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := VerifyAttributeChange(ctx, test.before, test.after, test.user)
+			if (err == nil) != test.want {
+				t.Errorf("expected allowed %v, got %v", test.want, err)
+			}
+		})
+	}
+}
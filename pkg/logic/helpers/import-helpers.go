@@ -0,0 +1,198 @@
+package helpers
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strings"
+
+	"gorabc/pkg/models"
+	"gorabc/pkg/utils/resterr"
+)
+
+// Import file columns
+var (
+	userColumns       = []string{"email", "first_name", "last_name", "password", "departments", "roles"}
+	departmentColumns = []string{"name", "parent"}
+	roleColumns       = []string{"name", "department", "permissions"}
+)
+
+// ParseUserRows of a CSV or JSON file
+func ParseUserRows(data []byte, format string) ([]models.UserImportRow, *resterr.RestErr) {
+	rows := []models.UserImportRow{}
+	if format == models.FormatJSON {
+		if err := json.Unmarshal(data, &rows); err != nil {
+			return nil, resterr.NewBadRequestError("Invalid JSON file")
+		}
+		return rows, nil
+	}
+
+	records, err := readCSV(data, userColumns)
+	if err != nil {
+		return nil, err
+	}
+	for i := 0; i < len(records); i++ {
+		rows = append(rows, models.UserImportRow{
+			Email:       records[i]["email"],
+			Firstname:   records[i]["first_name"],
+			Lastname:    records[i]["last_name"],
+			Password:    records[i]["password"],
+			Departments: splitList(records[i]["departments"]),
+			Roles:       splitList(records[i]["roles"]),
+		})
+	}
+	return rows, nil
+}
+
+// ParseDepartmentRows of a CSV or JSON file
+func ParseDepartmentRows(data []byte, format string) ([]models.DepartmentImportRow, *resterr.RestErr) {
+	rows := []models.DepartmentImportRow{}
+	if format == models.FormatJSON {
+		if err := json.Unmarshal(data, &rows); err != nil {
+			return nil, resterr.NewBadRequestError("Invalid JSON file")
+		}
+		return rows, nil
+	}
+
+	records, err := readCSV(data, departmentColumns)
+	if err != nil {
+		return nil, err
+	}
+	for i := 0; i < len(records); i++ {
+		rows = append(rows, models.DepartmentImportRow{
+			Name:   records[i]["name"],
+			Parent: records[i]["parent"],
+		})
+	}
+	return rows, nil
+}
+
+// ParseRoleRows of a CSV or JSON file
+func ParseRoleRows(data []byte, format string) ([]models.RoleImportRow, *resterr.RestErr) {
+	rows := []models.RoleImportRow{}
+	if format == models.FormatJSON {
+		if err := json.Unmarshal(data, &rows); err != nil {
+			return nil, resterr.NewBadRequestError("Invalid JSON file")
+		}
+		return rows, nil
+	}
+
+	records, err := readCSV(data, roleColumns)
+	if err != nil {
+		return nil, err
+	}
+	for i := 0; i < len(records); i++ {
+		rows = append(rows, models.RoleImportRow{
+			Name:        records[i]["name"],
+			Department:  records[i]["department"],
+			Permissions: splitList(records[i]["permissions"]),
+		})
+	}
+	return rows, nil
+}
+
+// WriteUserRows as CSV
+func WriteUserRows(rows []models.UserImportRow) ([]byte, *resterr.RestErr) {
+	records := [][]string{}
+	for i := 0; i < len(rows); i++ {
+		records = append(records, []string{
+			rows[i].Email,
+			rows[i].Firstname,
+			rows[i].Lastname,
+			"",
+			strings.Join(rows[i].Departments, models.ImportListSeparator),
+			strings.Join(rows[i].Roles, models.ImportListSeparator),
+		})
+	}
+	return writeCSV(userColumns, records)
+}
+
+// WriteDepartmentRows as CSV
+func WriteDepartmentRows(rows []models.DepartmentImportRow) ([]byte, *resterr.RestErr) {
+	records := [][]string{}
+	for i := 0; i < len(rows); i++ {
+		records = append(records, []string{rows[i].Name, rows[i].Parent})
+	}
+	return writeCSV(departmentColumns, records)
+}
+
+// WriteRoleRows as CSV
+func WriteRoleRows(rows []models.RoleImportRow) ([]byte, *resterr.RestErr) {
+	records := [][]string{}
+	for i := 0; i < len(rows); i++ {
+		records = append(records, []string{
+			rows[i].Name,
+			rows[i].Department,
+			strings.Join(rows[i].Permissions, models.ImportListSeparator),
+		})
+	}
+	return writeCSV(roleColumns, records)
+}
+
+// readCSV maps every record to its header, unknown columns are rejected
+func readCSV(data []byte, columns []string) ([]map[string]string, *resterr.RestErr) {
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return []map[string]string{}, nil
+	}
+	if err != nil {
+		return nil, resterr.NewBadRequestError("Invalid CSV file: " + err.Error())
+	}
+
+	known := map[string]bool{}
+	for i := 0; i < len(columns); i++ {
+		known[columns[i]] = true
+	}
+	for i := 0; i < len(header); i++ {
+		header[i] = strings.ToLower(strings.TrimSpace(header[i]))
+		if !known[header[i]] {
+			return nil, resterr.NewBadRequestError("Unknown CSV column: " + header[i])
+		}
+	}
+
+	records := []map[string]string{}
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, resterr.NewBadRequestError("Invalid CSV file: " + err.Error())
+		}
+		values := map[string]string{}
+		for i := 0; i < len(header) && i < len(record); i++ {
+			values[header[i]] = strings.TrimSpace(record[i])
+		}
+		records = append(records, values)
+	}
+	return records, nil
+}
+
+// writeCSV with a header
+func writeCSV(columns []string, records [][]string) ([]byte, *resterr.RestErr) {
+	buffer := bytes.Buffer{}
+	writer := csv.NewWriter(&buffer)
+	if err := writer.Write(columns); err != nil {
+		return nil, resterr.NewInternalServerError(err.Error())
+	}
+	if err := writer.WriteAll(records); err != nil {
+		return nil, resterr.NewInternalServerError(err.Error())
+	}
+	return buffer.Bytes(), nil
+}
+
+// splitList of a CSV list column
+func splitList(value string) []string {
+	result := []string{}
+	values := strings.Split(value, models.ImportListSeparator)
+	for i := 0; i < len(values); i++ {
+		if v := strings.TrimSpace(values[i]); v != "" {
+			result = append(result, v)
+		}
+	}
+	return result
+}
@@ -0,0 +1,43 @@
+package helpers
+
+import (
+	"net/url"
+
+	"gorabc/pkg/middlewares/jwt"
+	"gorabc/pkg/models"
+	"gorabc/pkg/utils/datetime"
+	"gorabc/pkg/utils/mailer"
+)
+
+// inviteURL is the page where invitees choose their password, the signed
+// token is appended as token query parameter
+var inviteURL = "http://localhost:3000/accept-invite"
+
+// ConfigureInviteURL sets the page of the invite links
+func ConfigureInviteURL(value string) {
+	if value != "" {
+		inviteURL = value
+	}
+}
+
+// InviteMessage builds the email of a user invite with its signed link
+func InviteMessage(invite models.UserInvite) mailer.Message {
+	expiry := datetime.GetDateTime()
+	if expiresAt, err := datetime.ParseDateTimeString(invite.ExpiresAt); err == nil {
+		expiry = expiresAt
+	}
+	token := jwt.GenerateInviteToken(models.InviteClaims{
+		InviteID: invite.ID,
+		Nonce:    invite.Nonce,
+		Expiry:   expiry.UTC().Unix(),
+	})
+
+	link := inviteURL + "?token=" + url.QueryEscape(token)
+	return mailer.Message{
+		To:      invite.Email,
+		Subject: "You have been invited",
+		Body: "You have been invited to join your organization.\n\n" +
+			"Choose your password to activate your account:\n" + link + "\n\n" +
+			"The link expires at " + invite.ExpiresAt + ".\n",
+	}
+}
@@ -0,0 +1,17 @@
+package helpers
+
+import (
+	"gorabc/pkg/models"
+	"gorabc/pkg/utils/resterr"
+
+	"gopkg.in/yaml.v2"
+)
+
+// ParseManifest of a YAML or JSON document, unknown fields are rejected
+func ParseManifest(data []byte) (*models.Manifest, *resterr.RestErr) {
+	manifest := models.Manifest{}
+	if err := yaml.UnmarshalStrict(data, &manifest); err != nil {
+		return nil, resterr.NewBadRequestError("Invalid manifest: " + err.Error())
+	}
+	return &manifest, nil
+}
@@ -1,37 +1,81 @@
 package helpers
 
 import (
+	"context"
+
 	"gorabc/pkg/models"
 	"gorabc/pkg/repository/dao"
+	"gorabc/pkg/utils/metrics"
 	"gorabc/pkg/utils/resterr"
 )
 
+// LoadPermissionMetrics sets the permission catalogue as the names of the
+// permission check metrics
+func LoadPermissionMetrics(ctx context.Context) *resterr.RestErr {
+	permList, err := dao.PermissionDao.FindAll(ctx)
+	if err != nil {
+		return err
+	}
+	names := []string{}
+	for i := 0; i < len(permList); i++ {
+		names = append(names, permList[i].Name)
+	}
+	metrics.SetPermissions(names)
+	return nil
+}
+
 // AssignRolePermissions to the user
-func AssignRolePermissions(role models.Role) (*[]models.Permission, *resterr.RestErr) {
+func AssignRolePermissions(ctx context.Context, role models.Role) (*[]models.Permission, *resterr.RestErr) {
 	// Get permissions list from db
-	permList, err := dao.PermissionDao.FindAll()
+	permList, err := dao.PermissionDao.FindAll(ctx)
 	if err != nil {
 		return nil, err
 	}
 	// Factor out invalid permissions from role.Permissions
 	validList := ValidatePermissions(role.Permissions, permList)
 
+	// roles are scoped and time limited on assignment, not on definition
+	for i := 0; i < len(validList); i++ {
+		validList[i].Scope = ""
+		validList[i].Source = ""
+		validList[i].ValidFrom = ""
+		validList[i].ValidUntil = ""
+	}
+
 	// factor out duplicate entries
 	rolePermList := UniquePermissions(validList)
 
 	return &rolePermList, nil
 }
 
+// AssignRoleDenies to the role
+func AssignRoleDenies(ctx context.Context, role models.Role) (*[]models.Permission, *resterr.RestErr) {
+	role.Permissions = role.Denies
+	return AssignRolePermissions(ctx, role)
+}
+
 // ValidatePermissions verifies the validity of request
+// Legacy names are mapped to their structured name and wildcard grants are
+// kept when they match at least one permission of the catalogue.
 func ValidatePermissions(request []models.Permission, permList []models.Permission) []models.Permission {
 	validList := []models.Permission{}
 
 	if len(request) > 0 {
 		// Factor out invalid permissions from request
 		for i := 0; i < len(request); i++ {
+			if request[i].IsWildcard() {
+				pattern := models.NewPermissionResolver([]models.Permission{request[i]})
+				for j := 0; j < len(permList); j++ {
+					if pattern.Has(permList[j].Name) {
+						validList = append(validList, models.Permission{Name: request[i].Name, Scope: request[i].Scope, Source: request[i].Source, ValidFrom: request[i].ValidFrom, ValidUntil: request[i].ValidUntil})
+						break
+					}
+				}
+				continue
+			}
 			for j := 0; j < len(permList); j++ {
-				if request[i].Name == permList[j].Name {
-					validList = append(validList, request[i])
+				if request[i].Name == permList[j].Name || (permList[j].LegacyName != "" && request[i].Name == permList[j].LegacyName) {
+					validList = append(validList, models.Permission{Name: permList[j].Name, Scope: request[i].Scope, Source: request[i].Source, ValidFrom: request[i].ValidFrom, ValidUntil: request[i].ValidUntil})
 				}
 			}
 		}
@@ -0,0 +1,68 @@
+package helpers
+
+import (
+	"crypto/subtle"
+
+	"gorabc/pkg/models"
+	"gorabc/pkg/utils/resterr"
+)
+
+// RegistrationSettings of the public registration endpoints
+// The superuser bootstrap endpoint is disabled without a bootstrap token.
+type RegistrationSettings struct {
+	Mode           string
+	BootstrapToken string
+}
+
+// RegistrationVerifier verifies a self-registration before the organization
+// is created, e.g. a CAPTCHA or an email domain check
+type RegistrationVerifier interface {
+	Verify(request models.RegistrationRequest) *resterr.RestErr
+}
+
+var (
+	registrationSettings = RegistrationSettings{Mode: models.RegistrationOpen}
+	registrationVerifier RegistrationVerifier
+)
+
+// ConfigureRegistration sets the registration mode and the bootstrap token
+func ConfigureRegistration(settings RegistrationSettings) *resterr.RestErr {
+	switch settings.Mode {
+	case "":
+		settings.Mode = models.RegistrationOpen
+	case models.RegistrationOpen, models.RegistrationInviteOnly, models.RegistrationDisabled:
+	default:
+		return resterr.NewBadRequestError("Invalid registration mode: " + settings.Mode)
+	}
+	registrationSettings = settings
+	return nil
+}
+
+// GetRegistrationMode of organization self-registration
+func GetRegistrationMode() string {
+	return registrationSettings.Mode
+}
+
+// SetRegistrationVerifier plugs a verifier into organization self-registration
+func SetRegistrationVerifier(verifier RegistrationVerifier) {
+	registrationVerifier = verifier
+}
+
+// VerifyRegistration with the configured verifier, if any
+func VerifyRegistration(request models.RegistrationRequest) *resterr.RestErr {
+	if registrationVerifier == nil {
+		return nil
+	}
+	return registrationVerifier.Verify(request)
+}
+
+// VerifyBootstrapToken compares the token with the configured bootstrap token
+func VerifyBootstrapToken(token string) *resterr.RestErr {
+	if registrationSettings.BootstrapToken == "" {
+		return resterr.NewForbiddenError("Superuser bootstrap is disabled, use gorabcctl superuser create")
+	}
+	if subtle.ConstantTimeCompare([]byte(token), []byte(registrationSettings.BootstrapToken)) != 1 {
+		return resterr.NewForbiddenError("Invalid bootstrap token")
+	}
+	return nil
+}
@@ -0,0 +1,198 @@
+package helpers
+
+import (
+	"context"
+
+	"gorabc/pkg/models"
+	"gorabc/pkg/repository/dao"
+	"gorabc/pkg/utils/datetime"
+	"gorabc/pkg/utils/resterr"
+)
+
+// Managed relation tuple types
+const (
+	ObjectTypeOrganization = "organization"
+	ObjectTypeDepartment   = "department"
+	ObjectTypeRole         = "role"
+	ObjectTypeUser         = "user"
+)
+
+// RelationObject returns "type:id"
+func RelationObject(objectType string, id string) string {
+	return objectType + models.ObjectSeparator + id
+}
+
+// managedTuple builds a mirrored membership tuple
+func managedTuple(object string, relation string, subject string) models.RelationTuple {
+	tuple := models.RelationTuple{Object: object, Relation: relation, Subject: subject, Managed: true}
+	tuple.Validate()
+	return tuple
+}
+
+// MirrorUser replaces the mirrored organization, department and role
+// memberships of the user, platform users belong to no tenant
+func MirrorUser(ctx context.Context, user models.User) *resterr.RestErr {
+	if user.Organization == "" {
+		return nil
+	}
+	subject := RelationObject(ObjectTypeUser, user.ID)
+	org := RelationObject(ObjectTypeOrganization, user.Organization)
+
+	desired := models.RelationTuples{managedTuple(org, "member", subject)}
+	if user.IsOrgAdmin {
+		desired = append(desired, managedTuple(org, "admin", subject))
+	}
+	for i := 0; i < len(user.Departments); i++ {
+		desired = append(desired, managedTuple(RelationObject(ObjectTypeDepartment, user.Departments[i].DepartmentID), "member", subject))
+	}
+	now := datetime.GetDateTimeString()
+	for i := 0; i < len(user.Roles); i++ {
+		if user.Roles[i].IsLapsed(now) {
+			continue
+		}
+		desired = append(desired, managedTuple(RelationObject(ObjectTypeRole, user.Roles[i].RoleID), "member", subject))
+	}
+
+	filters := []models.RelationTupleFilter{{Subject: subject}}
+	return replaceManagedTuples(ctx, user.Organization, filters, desired)
+}
+
+// MirrorDepartment replaces the mirrored organization and parent of the department
+func MirrorDepartment(ctx context.Context, department models.Department) *resterr.RestErr {
+	object := RelationObject(ObjectTypeDepartment, department.ID)
+
+	desired := models.RelationTuples{
+		managedTuple(object, "organization", RelationObject(ObjectTypeOrganization, department.Organization)),
+	}
+	if department.Parent != "" {
+		desired = append(desired, managedTuple(object, "parent", RelationObject(ObjectTypeDepartment, department.Parent)))
+	}
+
+	filters := []models.RelationTupleFilter{
+		{Object: object, Relation: "organization"},
+		{Object: object, Relation: "parent"},
+	}
+	return replaceManagedTuples(ctx, department.Organization, filters, desired)
+}
+
+// MirrorRole replaces the mirrored organization and department of the role
+func MirrorRole(ctx context.Context, role models.Role) *resterr.RestErr {
+	object := RelationObject(ObjectTypeRole, role.ID)
+
+	desired := models.RelationTuples{
+		managedTuple(object, "organization", RelationObject(ObjectTypeOrganization, role.Organization)),
+		managedTuple(object, "department", RelationObject(ObjectTypeDepartment, role.Department)),
+	}
+
+	filters := []models.RelationTupleFilter{
+		{Object: object, Relation: "organization"},
+		{Object: object, Relation: "department"},
+	}
+	return replaceManagedTuples(ctx, role.Organization, filters, desired)
+}
+
+// RemoveObjectTuples deletes every tuple on the object or with the object as subject
+func RemoveObjectTuples(ctx context.Context, org string, object string) *resterr.RestErr {
+	revision, err := dao.RelationDao.Revision(ctx, org)
+	if err != nil {
+		return err
+	}
+
+	deletes := models.RelationTuples{}
+	filters := []models.RelationTupleFilter{{Object: object}, {SubjectObject: object}}
+	for i := 0; i < len(filters); i++ {
+		tuples, err := dao.RelationDao.Find(ctx, org, filters[i], revision)
+		if err != nil {
+			return err
+		}
+		deletes = append(deletes, tuples...)
+	}
+
+	if len(deletes) == 0 {
+		return nil
+	}
+	_, err = dao.RelationDao.Write(ctx, org, nil, deletes)
+	return err
+}
+
+// MirrorOrganization mirrors every membership of the organization
+func MirrorOrganization(ctx context.Context, org string) *resterr.RestErr {
+	departments, err := dao.DepartmentDao.FindAll(ctx, org)
+	if err != nil {
+		return err
+	}
+	for i := 0; i < len(departments); i++ {
+		if err := MirrorDepartment(ctx, departments[i]); err != nil {
+			return err
+		}
+	}
+
+	roles, err := dao.RoleDao.FindAll(ctx, org)
+	if err != nil {
+		return err
+	}
+	for i := 0; i < len(roles); i++ {
+		if err := MirrorRole(ctx, roles[i]); err != nil {
+			return err
+		}
+	}
+
+	users, err := dao.UserDao.FindAll(ctx, org)
+	if err != nil {
+		return err
+	}
+	for i := 0; i < len(users); i++ {
+		if err := MirrorUser(ctx, users[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// replaceManagedTuples writes the missing desired tuples and deletes the
+// managed tuples matching the filters that are no longer desired
+func replaceManagedTuples(ctx context.Context, org string, filters []models.RelationTupleFilter, desired models.RelationTuples) *resterr.RestErr {
+	revision, err := dao.RelationDao.Revision(ctx, org)
+	if err != nil {
+		return err
+	}
+
+	current := map[string]models.RelationTuple{}
+	for i := 0; i < len(filters); i++ {
+		tuples, err := dao.RelationDao.Find(ctx, org, filters[i], revision)
+		if err != nil {
+			return err
+		}
+		for j := 0; j < len(tuples); j++ {
+			if tuples[j].Managed {
+				current[tuples[j].String()] = tuples[j]
+			}
+		}
+	}
+
+	writes := models.RelationTuples{}
+	wanted := map[string]bool{}
+	for i := 0; i < len(desired); i++ {
+		key := desired[i].String()
+		if wanted[key] {
+			continue
+		}
+		wanted[key] = true
+		if _, ok := current[key]; !ok {
+			writes = append(writes, desired[i])
+		}
+	}
+
+	deletes := models.RelationTuples{}
+	for key, tuple := range current {
+		if !wanted[key] {
+			deletes = append(deletes, tuple)
+		}
+	}
+
+	if len(writes) == 0 && len(deletes) == 0 {
+		return nil
+	}
+	_, err = dao.RelationDao.Write(ctx, org, writes, deletes)
+	return err
+}
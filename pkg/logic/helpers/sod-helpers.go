@@ -0,0 +1,176 @@
+package helpers
+
+import (
+	"context"
+	"strings"
+
+	"gorabc/pkg/models"
+	"gorabc/pkg/repository/dao"
+	"gorabc/pkg/utils/datetime"
+	"gorabc/pkg/utils/resterr"
+)
+
+// EvaluateSoD returns the violation of the constraint by a holder of the
+// roles and permissions, nil when the constraint holds
+func EvaluateSoD(constraint models.SoDConstraint, roleIDs []string, permissions []models.Permission) *models.SoDViolation {
+	max := constraint.MaxAllowed
+	if max < 1 {
+		max = 1
+	}
+
+	held := map[string]bool{}
+	for i := 0; i < len(roleIDs); i++ {
+		held[roleIDs[i]] = true
+	}
+	matchedRoles := []string{}
+	for i := 0; i < len(constraint.Roles); i++ {
+		if held[constraint.Roles[i]] {
+			matchedRoles = append(matchedRoles, constraint.Roles[i])
+		}
+	}
+
+	resolver := models.NewPermissionResolver(permissions)
+	matchedPermissions := []string{}
+	for i := 0; i < len(constraint.Permissions); i++ {
+		if resolver.Has(constraint.Permissions[i]) {
+			matchedPermissions = append(matchedPermissions, constraint.Permissions[i])
+		}
+	}
+
+	if len(matchedRoles) <= max && len(matchedPermissions) <= max {
+		return nil
+	}
+
+	return &models.SoDViolation{
+		ConstraintID:   constraint.ID,
+		ConstraintName: constraint.Name,
+		Type:           constraint.Type,
+		Roles:          matchedRoles,
+		Permissions:    matchedPermissions,
+	}
+}
+
+// FindUserSoDViolations of the constraints of the given type by the user
+func FindUserSoDViolations(user models.User, constraints []models.SoDConstraint, constraintType string) []models.SoDViolation {
+	now := datetime.GetDateTimeString()
+	roleIDs := []string{}
+	for i := 0; i < len(user.Roles); i++ {
+		if !user.Roles[i].IsLapsed(now) {
+			roleIDs = append(roleIDs, user.Roles[i].RoleID)
+		}
+	}
+
+	violations := []models.SoDViolation{}
+	for i := 0; i < len(constraints); i++ {
+		if constraints[i].Type != constraintType {
+			continue
+		}
+		if violation := EvaluateSoD(constraints[i], roleIDs, user.Permissions); violation != nil {
+			violation.UserID = user.ID
+			violations = append(violations, *violation)
+		}
+	}
+	return violations
+}
+
+// VerifyStaticSoD verifies the roles and permissions of the user against the
+// static separation of duties constraints of the organization
+func VerifyStaticSoD(ctx context.Context, user models.User) *resterr.RestErr {
+	constraints, err := dao.SoDDao.FindAll(ctx, user.Organization)
+	if err != nil {
+		return err
+	}
+
+	violations := FindUserSoDViolations(user, constraints, models.SoDStatic)
+	if len(violations) > 0 {
+		return sodError(violations[0])
+	}
+	return nil
+}
+
+// VerifyRoleSoD verifies an updated role and every user holding it against
+// the static separation of duties constraints of the organization
+func VerifyRoleSoD(ctx context.Context, role models.Role) *resterr.RestErr {
+	constraints, err := dao.SoDDao.FindAll(ctx, role.Organization)
+	if err != nil {
+		return err
+	}
+	if len(constraints) == 0 {
+		return nil
+	}
+
+	// the role alone
+	for i := 0; i < len(constraints); i++ {
+		if constraints[i].Type != models.SoDStatic {
+			continue
+		}
+		if violation := EvaluateSoD(constraints[i], []string{role.ID}, role.Permissions); violation != nil {
+			return sodError(*violation)
+		}
+	}
+
+	// the holders of the role with its new permissions
+	holders, err := RoleHolders(ctx, role)
+	if err != nil {
+		return err
+	}
+	for i := 0; i < len(holders); i++ {
+		violations := FindUserSoDViolations(holders[i], constraints, models.SoDStatic)
+		if len(violations) > 0 {
+			return sodError(violations[0])
+		}
+	}
+	return nil
+}
+
+// ActivateRoles restricts the user to the roles activated in a session and
+// verifies the dynamic separation of duties constraints, all roles are
+// activated when none is selected
+func ActivateRoles(ctx context.Context, user *models.User, roleIDs []string) *resterr.RestErr {
+	if len(roleIDs) > 0 {
+		active := map[string]bool{}
+		roles := []models.UserRole{}
+		for i := 0; i < len(roleIDs); i++ {
+			found := false
+			for j := 0; j < len(user.Roles); j++ {
+				if user.Roles[j].RoleID == roleIDs[i] {
+					found = true
+					if !active[roleIDs[i]] {
+						roles = append(roles, user.Roles[j])
+					}
+				}
+			}
+			if !found {
+				return resterr.NewBadRequestError("Role not assigned: " + roleIDs[i])
+			}
+			active[roleIDs[i]] = true
+		}
+
+		// direct permissions stay, denies of inactive roles still apply
+		permissions := []models.Permission{}
+		for i := 0; i < len(user.Permissions); i++ {
+			source := user.Permissions[i].Source
+			if !strings.HasPrefix(source, models.SourceRole) || active[strings.TrimPrefix(source, models.SourceRole)] {
+				permissions = append(permissions, user.Permissions[i])
+			}
+		}
+		user.Roles = roles
+		user.Permissions = permissions
+	}
+
+	constraints, err := dao.SoDDao.FindAll(ctx, user.Organization)
+	if err != nil {
+		return err
+	}
+
+	violations := FindUserSoDViolations(*user, constraints, models.SoDDynamic)
+	if len(violations) > 0 {
+		return resterr.NewBadRequestError("Select the active roles, separation of duties violated: " + violations[0].ConstraintName)
+	}
+	return nil
+}
+
+// sodError of a violation
+func sodError(violation models.SoDViolation) *resterr.RestErr {
+	return resterr.NewBadRequestError("Separation of duties violated: " + violation.ConstraintName)
+}
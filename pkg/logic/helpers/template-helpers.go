@@ -0,0 +1,56 @@
+package helpers
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"sync"
+
+	"gorabc/pkg/models"
+)
+
+// templateFile holds the role templates and the organization presets
+const templateFile = "static/json/templates/role_templates.json"
+
+var (
+	templateOnce      sync.Once
+	templateCatalogue models.RoleTemplateCatalogue
+)
+
+// GetTemplateCatalogue reads the role templates once
+func GetTemplateCatalogue() models.RoleTemplateCatalogue {
+	templateOnce.Do(func() {
+		data, err := ioutil.ReadFile(templateFile)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+
+		if err := json.Unmarshal(data, &templateCatalogue); err != nil {
+			fmt.Println("Error while unmarshalling " + templateFile)
+		}
+	})
+	return templateCatalogue
+}
+
+// GetRoleTemplate by key
+func GetRoleTemplate(key string) (*models.RoleTemplate, bool) {
+	templates := GetTemplateCatalogue().Templates
+	for i := 0; i < len(templates); i++ {
+		if templates[i].Key == key {
+			return &templates[i], true
+		}
+	}
+	return nil, false
+}
+
+// GetPreset by key
+func GetPreset(key string) (*models.Preset, bool) {
+	presets := GetTemplateCatalogue().Presets
+	for i := 0; i < len(presets); i++ {
+		if presets[i].Key == key {
+			return &presets[i], true
+		}
+	}
+	return nil, false
+}
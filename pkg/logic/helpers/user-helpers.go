@@ -1,15 +1,17 @@
 package helpers
 
 import (
+	"context"
+
 	"gorabc/pkg/models"
 	"gorabc/pkg/repository/dao"
 	"gorabc/pkg/utils/resterr"
 )
 
 // AssignUserDepartments to the user
-func AssignUserDepartments(user models.User) (*[]models.UserDepartment, *resterr.RestErr) {
+func AssignUserDepartments(ctx context.Context, user models.User) (*[]models.UserDepartment, *resterr.RestErr) {
 	// Get departments list from db
-	deptList, err := dao.DepartmentDao.FindAll(user.Organization)
+	deptList, err := dao.DepartmentDao.FindAll(ctx, user.Organization)
 	if err != nil {
 		return nil, err
 	}
@@ -24,9 +26,9 @@ func AssignUserDepartments(user models.User) (*[]models.UserDepartment, *resterr
 }
 
 // AssignUserRoles to the user
-func AssignUserRoles(user models.User) (*[]models.UserRole, *[]models.UserDepartment, *[]models.Permission, *resterr.RestErr) {
+func AssignUserRoles(ctx context.Context, user models.User) (*[]models.UserRole, *[]models.UserDepartment, *[]models.Permission, *resterr.RestErr) {
 	// Get departments list from db
-	roleList, err := dao.RoleDao.FindAll(user.Organization)
+	roleList, err := dao.RoleDao.FindAll(ctx, user.Organization)
 	if err != nil {
 		return nil, nil, nil, err
 	}
@@ -34,6 +36,11 @@ func AssignUserRoles(user models.User) (*[]models.UserRole, *[]models.UserDepart
 	// Factor out invalid departments from user.Roles
 	validList := ValidateRoles(user, roleList)
 
+	// Verify the department scope of every assignment
+	if err := ValidateRoleScopes(ctx, validList, user.Organization); err != nil {
+		return nil, nil, nil, err
+	}
+
 	// factor out duplicate entries
 	userRoleList := UniqueRoles(validList)
 
@@ -41,7 +48,8 @@ func AssignUserRoles(user models.User) (*[]models.UserRole, *[]models.UserDepart
 	roleDeptList := AssignRoleDeptToUser(user, roleList)
 
 	// assign roles permissions to user
-	rolePermList, err := AssignRolesPermToUser(user)
+	user.Roles = userRoleList
+	rolePermList, err := AssignRolesPermToUser(ctx, user)
 	if err != nil {
 		return nil, nil, nil, err
 	}
@@ -50,15 +58,32 @@ func AssignUserRoles(user models.User) (*[]models.UserRole, *[]models.UserDepart
 }
 
 // AssignUserPermissions to the user
-func AssignUserPermissions(user models.User) (*[]models.Permission, *resterr.RestErr) {
+func AssignUserPermissions(ctx context.Context, user models.User) (*[]models.Permission, *resterr.RestErr) {
 	// Get permissions list from db
-	permList, err := dao.PermissionDao.FindAll()
+	permList, err := dao.PermissionDao.FindAll(ctx)
 	if err != nil {
 		return nil, err
 	}
 	// Factor out invalid permissions from user.Permissions
 	validList := ValidatePermissions(user.Permissions, permList)
 
+	// Verify the department scope of every grant
+	index, err := GetDepartmentIndex(ctx, user.Organization)
+	if err != nil {
+		return nil, err
+	}
+	for i := 0; i < len(validList); i++ {
+		if _, ok := index[validList[i].Scope]; validList[i].Scope != "" && !ok {
+			return nil, resterr.NewBadRequestError("Invalid permission scope: " + validList[i].Scope)
+		}
+		if err := models.ValidateValidity(validList[i].ValidFrom, validList[i].ValidUntil); err != nil {
+			return nil, err
+		}
+		if validList[i].Source == "" {
+			validList[i].Source = models.SourceUser
+		}
+	}
+
 	// factor out duplicate entries
 	userPermList := UniquePermissions(validList)
 
@@ -133,10 +158,32 @@ func ValidateRoles(user models.User, roleList []models.Role) []models.UserRole {
 	return validList
 }
 
+// ValidateRoleScopes verifies that every role scope is a department of the
+// organization and that every validity window is well formed
+func ValidateRoleScopes(ctx context.Context, roles []models.UserRole, org string) *resterr.RestErr {
+	index, err := GetDepartmentIndex(ctx, org)
+	if err != nil {
+		return err
+	}
+
+	for i := 0; i < len(roles); i++ {
+		if err := models.ValidateValidity(roles[i].ValidFrom, roles[i].ValidUntil); err != nil {
+			return err
+		}
+		if roles[i].Scope == "" {
+			continue
+		}
+		if _, ok := index[roles[i].Scope]; !ok {
+			return resterr.NewBadRequestError("Invalid role scope: " + roles[i].Scope)
+		}
+	}
+	return nil
+}
+
 // AssignRolesPermToUser sets roles permissions as user permissions
-func AssignRolesPermToUser(user models.User) (*[]models.Permission, *resterr.RestErr) {
+func AssignRolesPermToUser(ctx context.Context, user models.User) (*[]models.Permission, *resterr.RestErr) {
 	// Get all role permissions
-	rp, err := dao.RoleDao.FindAllRolePermissions(user.Organization)
+	rp, err := dao.RoleDao.FindAllRolePermissions(ctx, user.Organization)
 	if err != nil {
 		return nil, err
 	}
@@ -146,7 +193,15 @@ func AssignRolesPermToUser(user models.User) (*[]models.Permission, *resterr.Res
 	for i := 0; i < len(user.Roles); i++ {
 		for j := 0; j < len(rp); j++ {
 			if user.Roles[i].RoleID == rp[j].RoleID {
-				rolePermList = append(rolePermList, rp[j].Permissions...)
+				// stamp the assignment scope and validity on the role permissions
+				for k := 0; k < len(rp[j].Permissions); k++ {
+					permission := rp[j].Permissions[k]
+					permission.Scope = user.Roles[i].Scope
+					permission.Source = models.SourceRole + rp[j].RoleID
+					permission.ValidFrom = user.Roles[i].ValidFrom
+					permission.ValidUntil = user.Roles[i].ValidUntil
+					rolePermList = append(rolePermList, permission)
+				}
 			}
 		}
 
@@ -158,6 +213,106 @@ func AssignRolesPermToUser(user models.User) (*[]models.Permission, *resterr.Res
 	return &userPermList, nil
 }
 
+// AssignRolesDenyToUser sets roles denies as user denies
+func AssignRolesDenyToUser(ctx context.Context, user models.User) (*[]models.Permission, *resterr.RestErr) {
+	// Get all role permissions
+	rp, err := dao.RoleDao.FindAllRolePermissions(ctx, user.Organization)
+	if err != nil {
+		return nil, err
+	}
+
+	roleDenyList := []models.Permission{}
+
+	for i := 0; i < len(user.Roles); i++ {
+		for j := 0; j < len(rp); j++ {
+			if user.Roles[i].RoleID == rp[j].RoleID {
+				for k := 0; k < len(rp[j].Denies); k++ {
+					deny := rp[j].Denies[k]
+					deny.Scope = user.Roles[i].Scope
+					deny.Source = models.SourceRole + rp[j].RoleID
+					deny.ValidFrom = user.Roles[i].ValidFrom
+					deny.ValidUntil = user.Roles[i].ValidUntil
+					roleDenyList = append(roleDenyList, deny)
+				}
+			}
+		}
+	}
+
+	return &roleDenyList, nil
+}
+
+// AssignUserDenies validates the direct denies of the user and merges them
+// with the denies of the given roles
+func AssignUserDenies(ctx context.Context, user models.User, roleDenies []models.Permission) (*[]models.Permission, *resterr.RestErr) {
+	direct := []models.Permission{}
+	for i := 0; i < len(user.Denies); i++ {
+		if user.Denies[i].Source == "" || user.Denies[i].Source == models.SourceUser {
+			deny := user.Denies[i]
+			deny.Source = models.SourceUser
+			direct = append(direct, deny)
+		}
+	}
+
+	user.Permissions = direct
+	userDenyList, err := AssignUserPermissions(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+
+	denies := append(*userDenyList, roleDenies...)
+	return &denies, nil
+}
+
+// RoleHolders returns the users holding the role, the permissions and denies
+// they hold through the role are replaced by the current ones of the role
+// stamped with the scope and validity of each assignment
+func RoleHolders(ctx context.Context, role models.Role) ([]models.User, *resterr.RestErr) {
+	users, err := dao.UserDao.FindAll(ctx, role.Organization)
+	if err != nil {
+		return nil, err
+	}
+
+	holders := []models.User{}
+	for i := 0; i < len(users); i++ {
+		assignments := []models.UserRole{}
+		for j := 0; j < len(users[i].Roles); j++ {
+			if users[i].Roles[j].RoleID == role.ID {
+				assignments = append(assignments, users[i].Roles[j])
+			}
+		}
+		if len(assignments) == 0 {
+			continue
+		}
+
+		user, err := dao.UserDao.GetByID(ctx, users[i].ID, users[i].Organization)
+		if err != nil {
+			return nil, err
+		}
+		user.Permissions = restampRoleGrants(user.Permissions, role.ID, role.Permissions, assignments)
+		user.Denies = restampRoleGrants(user.Denies, role.ID, role.Denies, assignments)
+		holders = append(holders, *user)
+	}
+	return holders, nil
+}
+
+// restampRoleGrants replaces the grants obtained through the role by the
+// grants of the role stamped on each assignment
+func restampRoleGrants(list []models.Permission, roleID string, grants []models.Permission, assignments []models.UserRole) []models.Permission {
+	source := models.SourceRole + roleID
+	result := withoutSource(list, source)
+	for i := 0; i < len(assignments); i++ {
+		for j := 0; j < len(grants); j++ {
+			grant := grants[j]
+			grant.Scope = assignments[i].Scope
+			grant.Source = source
+			grant.ValidFrom = assignments[i].ValidFrom
+			grant.ValidUntil = assignments[i].ValidUntil
+			result = append(result, grant)
+		}
+	}
+	return result
+}
+
 // AssignRoleDeptToUser from roles
 func AssignRoleDeptToUser(user models.User, roleList []models.Role) []models.UserDepartment {
 	userDeptList := []models.UserDepartment{}
@@ -174,3 +329,80 @@ func AssignRoleDeptToUser(user models.User, roleList []models.Role) []models.Use
 
 	return userDeptList
 }
+
+// AddUserRole assigns one more role to the user and keeps its current roles,
+// departments, permissions and denies, an assignment of the role in the same
+// scope is extended instead of assigned twice
+func AddUserRole(ctx context.Context, user *models.User, role models.Role, userRole models.UserRole) *resterr.RestErr {
+	if err := ValidateRoleScopes(ctx, []models.UserRole{userRole}, user.Organization); err != nil {
+		return err
+	}
+
+	// extend a current assignment of the role in the same scope
+	assigned := false
+	for i := 0; i < len(user.Roles); i++ {
+		if user.Roles[i].RoleID == userRole.RoleID && user.Roles[i].Scope == userRole.Scope {
+			user.Roles[i] = extendAssignment(user.Roles[i], userRole)
+			assigned = true
+		}
+	}
+	if !assigned {
+		user.Roles = append(user.Roles, userRole)
+	}
+
+	// stamp the role grants on every assignment of the role again
+	assignment := models.User{Organization: user.Organization}
+	for i := 0; i < len(user.Roles); i++ {
+		if user.Roles[i].RoleID == userRole.RoleID {
+			assignment.Roles = append(assignment.Roles, user.Roles[i])
+		}
+	}
+	rolePermList, err := AssignRolesPermToUser(ctx, assignment)
+	if err != nil {
+		return err
+	}
+	roleDenyList, err := AssignRolesDenyToUser(ctx, assignment)
+	if err != nil {
+		return err
+	}
+	source := models.SourceRole + userRole.RoleID
+	user.Permissions = append(withoutSource(user.Permissions, source), *rolePermList...)
+	user.Denies = append(withoutSource(user.Denies, source), *roleDenyList...)
+
+	// Verify separation of duties
+	if err := VerifyStaticSoD(ctx, *user); err != nil {
+		return err
+	}
+
+	// set role department to user departments
+	for i := 0; i < len(user.Departments); i++ {
+		if user.Departments[i].DepartmentID == role.Department {
+			return nil
+		}
+	}
+	user.Departments = append(user.Departments, models.UserDepartment{DepartmentID: role.Department})
+	return nil
+}
+
+// extendAssignment covers the validity of both assignments, an empty bound
+// is open
+func extendAssignment(current models.UserRole, next models.UserRole) models.UserRole {
+	if current.ValidFrom != "" && (next.ValidFrom == "" || next.ValidFrom < current.ValidFrom) {
+		current.ValidFrom = next.ValidFrom
+	}
+	if current.ValidUntil != "" && (next.ValidUntil == "" || next.ValidUntil > current.ValidUntil) {
+		current.ValidUntil = next.ValidUntil
+	}
+	return current
+}
+
+// withoutSource filters out the grants obtained from the source
+func withoutSource(list []models.Permission, source string) []models.Permission {
+	result := []models.Permission{}
+	for i := 0; i < len(list); i++ {
+		if list[i].Source != source {
+			result = append(result, list[i])
+		}
+	}
+	return result
+}
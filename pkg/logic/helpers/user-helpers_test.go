@@ -0,0 +1,49 @@
+package helpers
+
+import (
+	"testing"
+
+	"gorabc/pkg/models"
+)
+
+func TestExtendAssignment(t *testing.T) {
+	tests := []struct {
+		name    string
+		current models.UserRole
+		next    models.UserRole
+		want    models.UserRole
+	}{
+		{
+			"later end",
+			models.UserRole{ValidFrom: "2026-01-01T08:00:00Z", ValidUntil: "2026-01-01T12:00:00Z"},
+			models.UserRole{ValidFrom: "2026-01-01T10:00:00Z", ValidUntil: "2026-01-01T14:00:00Z"},
+			models.UserRole{ValidFrom: "2026-01-01T08:00:00Z", ValidUntil: "2026-01-01T14:00:00Z"},
+		},
+		{
+			"earlier end is kept",
+			models.UserRole{ValidFrom: "2026-01-01T08:00:00Z", ValidUntil: "2026-01-01T12:00:00Z"},
+			models.UserRole{ValidFrom: "2026-01-01T09:00:00Z", ValidUntil: "2026-01-01T10:00:00Z"},
+			models.UserRole{ValidFrom: "2026-01-01T08:00:00Z", ValidUntil: "2026-01-01T12:00:00Z"},
+		},
+		{
+			"permanent assignment is kept",
+			models.UserRole{},
+			models.UserRole{ValidFrom: "2026-01-01T09:00:00Z", ValidUntil: "2026-01-01T10:00:00Z"},
+			models.UserRole{},
+		},
+		{
+			"open bounds win",
+			models.UserRole{ValidFrom: "2026-01-01T09:00:00Z", ValidUntil: "2026-01-01T10:00:00Z"},
+			models.UserRole{},
+			models.UserRole{},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := extendAssignment(test.current, test.next); got != test.want {
+				t.Errorf("expected %+v, got %+v", test.want, got)
+			}
+		})
+	}
+}
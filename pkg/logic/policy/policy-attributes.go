@@ -0,0 +1,72 @@
+package policy
+
+import (
+	"strings"
+	"time"
+
+	"gorabc/pkg/models"
+)
+
+// SubjectAttributes builds the subject attributes of the authenticated user
+// The custom user attributes are available next to the built in ones,
+// e.g. subject.warehouse.
+func SubjectAttributes(au models.AuthUser) Attributes {
+	attributes := Attributes{}
+	for key, value := range au.Attributes {
+		attributes[key] = value
+	}
+
+	permissions := []interface{}{}
+	for i := 0; i < len(au.Permissions); i++ {
+		permissions = append(permissions, au.Permissions[i].Name)
+	}
+
+	attributes["id"] = au.ID
+	attributes["organization"] = au.Organization
+	attributes["is_superuser"] = au.IsSuperuser
+	attributes["is_org_admin"] = au.IsOrgAdmin
+	attributes["permissions"] = permissions
+	return attributes
+}
+
+// EnvironmentAttributes builds the environment attributes of a request
+func EnvironmentAttributes(now time.Time, ip string) Attributes {
+	return Attributes{
+		"time":      now.Format("15:04"),
+		"date":      now.Format("2006-01-02"),
+		"weekday":   strings.ToLower(now.Weekday().String()),
+		"timestamp": float64(now.Unix()),
+		"ip":        ip,
+	}
+}
+
+// resolve looks up a dotted attribute path such as resource.owner.id
+func (request Request) resolve(path string) (interface{}, bool) {
+	segments := strings.Split(path, ".")
+	if len(segments) < 2 {
+		return nil, false
+	}
+
+	var current interface{}
+	switch segments[0] {
+	case "subject":
+		current = map[string]interface{}(request.Subject)
+	case "resource":
+		current = map[string]interface{}(request.Resource)
+	case "environment":
+		current = map[string]interface{}(request.Environment)
+	default:
+		return nil, false
+	}
+
+	for i := 1; i < len(segments); i++ {
+		values, ok := current.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		if current, ok = values[segments[i]]; !ok {
+			return nil, false
+		}
+	}
+	return current, true
+}
@@ -0,0 +1,223 @@
+package policy
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	"gorabc/pkg/models"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Condition operators
+const (
+	OperatorEquals      = "eq"
+	OperatorNotEquals   = "ne"
+	OperatorIn          = "in"
+	OperatorNotIn       = "not_in"
+	OperatorContains    = "contains"
+	OperatorGreater     = "gt"
+	OperatorGreaterOrEq = "gte"
+	OperatorLess        = "lt"
+	OperatorLessOrEq    = "lte"
+	OperatorBetween     = "between"
+	OperatorNotBetween  = "not_between"
+	OperatorCIDR        = "cidr"
+	OperatorExists      = "exists"
+)
+
+// evaluateCondition evaluates a single condition against the request
+func evaluateCondition(condition models.PolicyCondition, request Request) (bool, error) {
+	actual, found := request.resolve(condition.Attribute)
+	if condition.Operator == OperatorExists {
+		return found, nil
+	}
+
+	expected := condition.Value
+	if reference, ok := expected.(string); ok && strings.HasPrefix(reference, "${") && strings.HasSuffix(reference, "}") {
+		value, ok := request.resolve(reference[2 : len(reference)-1])
+		if !ok {
+			return false, fmt.Errorf("missing attribute %s", reference[2:len(reference)-1])
+		}
+		expected = value
+	}
+
+	// a missing attribute is an evaluation error, negations included, so that
+	// a deny policy still denies and an allow policy never allows
+	if !found {
+		return false, fmt.Errorf("missing attribute %s", condition.Attribute)
+	}
+
+	switch condition.Operator {
+	case OperatorEquals:
+		return equals(actual, expected), nil
+	case OperatorNotEquals:
+		return !equals(actual, expected), nil
+	case OperatorIn:
+		return contains(expected, actual), nil
+	case OperatorNotIn:
+		return !contains(expected, actual), nil
+	case OperatorContains:
+		return contains(actual, expected), nil
+	case OperatorGreater, OperatorGreaterOrEq, OperatorLess, OperatorLessOrEq:
+		cmp, err := compare(actual, expected)
+		if err != nil {
+			return false, err
+		}
+		switch condition.Operator {
+		case OperatorGreater:
+			return cmp > 0, nil
+		case OperatorGreaterOrEq:
+			return cmp >= 0, nil
+		case OperatorLess:
+			return cmp < 0, nil
+		default:
+			return cmp <= 0, nil
+		}
+	case OperatorBetween, OperatorNotBetween:
+		ok, err := between(actual, expected)
+		if err != nil {
+			return false, err
+		}
+		return ok == (condition.Operator == OperatorBetween), nil
+	case OperatorCIDR:
+		return inNetwork(actual, expected)
+	}
+	return false, fmt.Errorf("unknown operator %s", condition.Operator)
+}
+
+// equals compares two attribute values
+func equals(a interface{}, b interface{}) bool {
+	if x, err := toFloat(a); err == nil {
+		if y, err := toFloat(b); err == nil {
+			return x == y
+		}
+	}
+	return fmt.Sprint(a) == fmt.Sprint(b)
+}
+
+// contains reports whether the list (or string) holds the value
+func contains(list interface{}, value interface{}) bool {
+	if text, ok := list.(string); ok {
+		return strings.Contains(text, fmt.Sprint(value))
+	}
+
+	values, _ := toList(list)
+	for i := 0; i < len(values); i++ {
+		if equals(values[i], value) {
+			return true
+		}
+	}
+	return false
+}
+
+// compare orders two numbers, or two strings such as 15:04 times
+func compare(a interface{}, b interface{}) (int, error) {
+	x, errX := toFloat(a)
+	y, errY := toFloat(b)
+	if errX == nil && errY == nil {
+		switch {
+		case x < y:
+			return -1, nil
+		case x > y:
+			return 1, nil
+		}
+		return 0, nil
+	}
+
+	sa, okA := a.(string)
+	sb, okB := b.(string)
+	if !okA || !okB {
+		return 0, fmt.Errorf("cannot compare %v and %v", a, b)
+	}
+	return strings.Compare(sa, sb), nil
+}
+
+// between verifies that the value lies within [low, high], ranges with
+// low > high wrap around, e.g. 22:00 to 06:00
+func between(value interface{}, bounds interface{}) (bool, error) {
+	limits, ok := toList(bounds)
+	if !ok || len(limits) != 2 {
+		return false, fmt.Errorf("between requires a [low, high] value")
+	}
+
+	low, err := compare(value, limits[0])
+	if err != nil {
+		return false, err
+	}
+	high, err := compare(value, limits[1])
+	if err != nil {
+		return false, err
+	}
+	order, err := compare(limits[0], limits[1])
+	if err != nil {
+		return false, err
+	}
+
+	if order <= 0 {
+		return low >= 0 && high <= 0, nil
+	}
+	return low >= 0 || high <= 0, nil
+}
+
+// inNetwork verifies that the ip lies within one of the networks
+func inNetwork(value interface{}, networks interface{}) (bool, error) {
+	ip := net.ParseIP(fmt.Sprint(value))
+	if ip == nil {
+		return false, nil
+	}
+
+	cidrs, ok := toList(networks)
+	if !ok {
+		cidrs = []interface{}{networks}
+	}
+
+	for i := 0; i < len(cidrs); i++ {
+		_, network, err := net.ParseCIDR(fmt.Sprint(cidrs[i]))
+		if err != nil {
+			return false, err
+		}
+		if network.Contains(ip) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// toList converts list attribute values, including lists decoded from mongodb
+func toList(value interface{}) ([]interface{}, bool) {
+	switch values := value.(type) {
+	case []interface{}:
+		return values, true
+	case primitive.A:
+		return []interface{}(values), true
+	case []string:
+		list := make([]interface{}, len(values))
+		for i := 0; i < len(values); i++ {
+			list[i] = values[i]
+		}
+		return list, true
+	}
+	return nil, false
+}
+
+// toFloat converts numeric attribute values
+func toFloat(value interface{}) (float64, error) {
+	switch number := value.(type) {
+	case float64:
+		return number, nil
+	case float32:
+		return float64(number), nil
+	case int:
+		return float64(number), nil
+	case int32:
+		return float64(number), nil
+	case int64:
+		return float64(number), nil
+	case string:
+		return strconv.ParseFloat(number, 64)
+	}
+	return 0, fmt.Errorf("%v is not a number", value)
+}
@@ -0,0 +1,147 @@
+package policy
+
+import (
+	"testing"
+
+	"gorabc/pkg/models"
+)
+
+func TestEvaluateCondition(t *testing.T) {
+	request := Request{
+		Subject: Attributes{
+			"department":  "sales",
+			"warehouse":   "WH1",
+			"level":       "3",
+			"permissions": []interface{}{"org:user:read", "org:role:read"},
+		},
+		Resource: Attributes{
+			"warehouse": "WH1",
+			"amount":    float64(1200),
+			"tags":      []string{"urgent", "export"},
+			"owner":     map[string]interface{}{"id": "U1"},
+		},
+		Environment: Attributes{
+			"time": "23:30",
+			"ip":   "10.1.2.3",
+		},
+	}
+
+	tests := []struct {
+		name      string
+		attribute string
+		operator  string
+		value     interface{}
+		want      bool
+		wantErr   bool
+	}{
+		{"eq", "subject.department", OperatorEquals, "sales", true, false},
+		{"eq other value", "subject.department", OperatorEquals, "finance", false, false},
+		{"eq number and string", "subject.level", OperatorEquals, float64(3), true, false},
+		{"eq nested", "resource.owner.id", OperatorEquals, "U1", true, false},
+		{"eq reference", "resource.warehouse", OperatorEquals, "${subject.warehouse}", true, false},
+		{"ne", "subject.department", OperatorNotEquals, "finance", true, false},
+		{"ne same value", "subject.department", OperatorNotEquals, "sales", false, false},
+		{"in", "subject.department", OperatorIn, []interface{}{"sales", "finance"}, true, false},
+		{"in missing value", "subject.department", OperatorIn, []interface{}{"finance"}, false, false},
+		{"not_in", "subject.department", OperatorNotIn, []interface{}{"finance"}, true, false},
+		{"not_in listed value", "subject.department", OperatorNotIn, []interface{}{"sales"}, false, false},
+		{"contains list", "resource.tags", OperatorContains, "export", true, false},
+		{"contains string", "subject.department", OperatorContains, "ale", true, false},
+		{"contains subject permission", "subject.permissions", OperatorContains, "org:role:read", true, false},
+		{"gt", "resource.amount", OperatorGreater, float64(1000), true, false},
+		{"gte", "resource.amount", OperatorGreaterOrEq, float64(1200), true, false},
+		{"lt", "resource.amount", OperatorLess, float64(1200), false, false},
+		{"lte", "resource.amount", OperatorLessOrEq, float64(1200), true, false},
+		{"gt string times", "environment.time", OperatorGreater, "18:00", true, false},
+		{"gt incomparable", "resource.amount", OperatorGreater, true, false, true},
+		{"between", "resource.amount", OperatorBetween, []interface{}{float64(1000), float64(2000)}, true, false},
+		{"between wrapping", "environment.time", OperatorBetween, []interface{}{"22:00", "06:00"}, true, false},
+		{"not_between", "environment.time", OperatorNotBetween, []interface{}{"09:00", "18:00"}, true, false},
+		{"between without bounds", "resource.amount", OperatorBetween, float64(1000), false, true},
+		{"cidr", "environment.ip", OperatorCIDR, "10.0.0.0/8", true, false},
+		{"cidr list", "environment.ip", OperatorCIDR, []interface{}{"192.168.0.0/16", "10.1.2.0/24"}, true, false},
+		{"cidr outside", "environment.ip", OperatorCIDR, "192.168.0.0/16", false, false},
+		{"cidr invalid network", "environment.ip", OperatorCIDR, "10.0.0.0/99", false, true},
+		{"exists", "resource.owner.id", OperatorExists, nil, true, false},
+		{"exists missing", "subject.manager", OperatorExists, nil, false, false},
+		{"unknown operator", "subject.department", "like", "sales", false, true},
+
+		// missing attributes are an error for every operator but exists
+		{"eq missing", "subject.manager", OperatorEquals, "U1", false, true},
+		{"ne missing", "subject.manager", OperatorNotEquals, "finance", false, true},
+		{"not_in missing", "subject.manager", OperatorNotIn, []interface{}{"U1"}, false, true},
+		{"not_between missing", "resource.weight", OperatorNotBetween, []interface{}{float64(1), float64(2)}, false, true},
+		{"ne missing reference", "subject.department", OperatorNotEquals, "${subject.manager}", false, true},
+		{"unknown root", "user.department", OperatorNotEquals, "finance", false, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			condition := models.PolicyCondition{Attribute: test.attribute, Operator: test.operator, Value: test.value}
+			got, err := evaluateCondition(condition, request)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("expected error %v, got %v", test.wantErr, err)
+			}
+			if got != test.want {
+				t.Errorf("expected %v, got %v", test.want, got)
+			}
+		})
+	}
+}
+
+func TestEvaluateAllowPolicyOnMissingAttribute(t *testing.T) {
+	policies := []models.Policy{{
+		ID:         "POL1",
+		Effect:     models.PolicyEffectAllow,
+		IsActive:   true,
+		Actions:    []string{"finance:*"},
+		Conditions: []models.PolicyCondition{{Attribute: "subject.department", Operator: OperatorNotEquals, Value: "finance"}},
+	}}
+
+	if result := Evaluate(policies, Request{Action: "finance:report:read", Subject: Attributes{}}); result.Effect != "" {
+		t.Errorf("an allow policy should not apply to a subject without the attribute, got %+v", result)
+	}
+	if result := Evaluate(policies, Request{Action: "finance:report:read", Subject: Attributes{"department": "sales"}}); result.Effect != models.PolicyEffectAllow {
+		t.Errorf("expected the allow policy to apply, got %+v", result)
+	}
+}
+
+func TestEvaluateDenyPolicyOnMissingAttribute(t *testing.T) {
+	policies := []models.Policy{{
+		ID:             "POL1",
+		Effect:         models.PolicyEffectDeny,
+		IsActive:       true,
+		Actions:        []string{"order:*:update"},
+		ConditionMatch: models.PolicyMatchAny,
+		Conditions: []models.PolicyCondition{
+			{Attribute: "resource.warehouse", Operator: OperatorNotEquals, Value: "${subject.warehouse}"},
+			{Attribute: "environment.time", Operator: OperatorNotBetween, Value: []interface{}{"09:00", "18:00"}},
+		},
+	}}
+
+	tests := []struct {
+		name     string
+		subject  Attributes
+		resource Attributes
+		want     string
+	}{
+		{"own warehouse", Attributes{"warehouse": "WH1"}, Attributes{"warehouse": "WH1"}, ""},
+		{"other warehouse", Attributes{"warehouse": "WH1"}, Attributes{"warehouse": "WH2"}, models.PolicyEffectDeny},
+		{"subject without warehouse", Attributes{}, Attributes{"warehouse": "WH1"}, models.PolicyEffectDeny},
+		{"resource without warehouse", Attributes{"warehouse": "WH1"}, Attributes{}, models.PolicyEffectDeny},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			request := Request{
+				Action:      "order:sales-order:update",
+				Subject:     test.subject,
+				Resource:    test.resource,
+				Environment: Attributes{"time": "10:00"},
+			}
+			if result := Evaluate(policies, request); result.Effect != test.want {
+				t.Errorf("expected effect %q, got %+v", test.want, result)
+			}
+		})
+	}
+}
@@ -0,0 +1,114 @@
+package policy
+
+import (
+	"gorabc/pkg/models"
+)
+
+// Attributes of a subject, resource or environment
+type Attributes map[string]interface{}
+
+// Request is evaluated against the policies of an organization
+type Request struct {
+	Action      string
+	Subject     Attributes
+	Resource    Attributes
+	Environment Attributes
+}
+
+// Result of the evaluation of a set of policies
+// Effect is empty when no policy applies to the request.
+type Result struct {
+	Effect string
+	Policy string
+}
+
+// Evaluate the policies with deny-overrides: the first applicable deny policy
+// wins, otherwise the first applicable allow policy
+func Evaluate(policies []models.Policy, request Request) Result {
+	result := Result{}
+
+	for i := 0; i < len(policies); i++ {
+		if !policies[i].IsActive || !matchesAction(policies[i], request.Action) {
+			continue
+		}
+
+		applies, err := conditionsHold(policies[i], request)
+		if err != nil {
+			// fail closed: a broken deny policy still denies, a broken
+			// allow policy never allows
+			applies = policies[i].Effect == models.PolicyEffectDeny
+		}
+		if !applies {
+			continue
+		}
+
+		if policies[i].Effect == models.PolicyEffectDeny {
+			return Result{Effect: models.PolicyEffectDeny, Policy: policies[i].ID}
+		}
+		if result.Effect == "" {
+			result = Result{Effect: models.PolicyEffectAllow, Policy: policies[i].ID}
+		}
+	}
+
+	return result
+}
+
+// Decide combines the role permissions with the policies using deny-overrides,
+// an explicit deny of the user or one of its roles always wins
+func Decide(granted bool, deniedBy *models.Permission, policies []models.Policy, request Request) models.AuthorizeResponse {
+	response := models.AuthorizeResponse{Action: request.Action}
+
+	result := Evaluate(policies, request)
+	switch {
+	case deniedBy != nil:
+		response.Allowed = false
+		response.Reason = "Denied by explicit deny"
+		response.DeniedBy = deniedBy
+	case result.Effect == models.PolicyEffectDeny:
+		response.Allowed = false
+		response.Reason = "Denied by policy"
+		response.Policy = result.Policy
+	case granted:
+		response.Allowed = true
+		response.Reason = "Granted by role permissions"
+	case result.Effect == models.PolicyEffectAllow:
+		response.Allowed = true
+		response.Reason = "Allowed by policy"
+		response.Policy = result.Policy
+	default:
+		response.Allowed = false
+		response.Reason = "Permission not granted"
+	}
+	return response
+}
+
+// matchesAction verifies the action against the permission patterns of the policy
+func matchesAction(policy models.Policy, action string) bool {
+	patterns := []models.Permission{}
+	for i := 0; i < len(policy.Actions); i++ {
+		patterns = append(patterns, models.Permission{Name: policy.Actions[i]})
+	}
+	return models.NewPermissionResolver(patterns).Has(action)
+}
+
+// conditionsHold evaluates the conditions of the policy
+func conditionsHold(policy models.Policy, request Request) (bool, error) {
+	if len(policy.Conditions) == 0 {
+		return true, nil
+	}
+
+	matchAny := policy.ConditionMatch == models.PolicyMatchAny
+	for i := 0; i < len(policy.Conditions); i++ {
+		ok, err := evaluateCondition(policy.Conditions[i], request)
+		if err != nil {
+			return false, err
+		}
+		if matchAny && ok {
+			return true, nil
+		}
+		if !matchAny && !ok {
+			return false, nil
+		}
+	}
+	return !matchAny, nil
+}
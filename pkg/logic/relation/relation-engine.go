@@ -0,0 +1,275 @@
+package relation
+
+import (
+	"context"
+
+	"gorabc/pkg/models"
+	"gorabc/pkg/repository/dao"
+	"gorabc/pkg/utils/resterr"
+)
+
+// maxDepth bounds the recursion through usersets and rewrites
+const maxDepth = 25
+
+// TupleReader reads the tuples of an object relation at a revision
+type TupleReader interface {
+	Read(context.Context, string, string, string, int64) (models.RelationTuples, *resterr.RestErr)
+}
+
+// Engine answers the check, expand and list objects requests of an
+// organization on a snapshot of its tuples, within one request
+type Engine struct {
+	Organization string
+	Revision     int64
+	Namespaces   map[string]models.Namespace
+	Reader       TupleReader
+
+	ctx    context.Context
+	tuples map[string]models.RelationTuples
+}
+
+// NewEngine for the organization at a revision
+func NewEngine(ctx context.Context, org string, revision int64) (*Engine, *resterr.RestErr) {
+	namespaces, err := Namespaces(ctx, org)
+	if err != nil {
+		return nil, err
+	}
+
+	engine := Engine{
+		Organization: org,
+		Revision:     revision,
+		Namespaces:   namespaces,
+		Reader:       dao.RelationDao,
+		ctx:          ctx,
+	}
+	return &engine, nil
+}
+
+// Check whether the subject holds the relation on the object
+func (e *Engine) Check(object string, relation string, subject string) (bool, *resterr.RestErr) {
+	if _, _, ok := models.ParseSubject(subject); !ok {
+		return false, resterr.NewBadRequestError("Invalid subject: " + subject)
+	}
+	if _, err := e.getRelation(object, relation); err != nil {
+		return false, err
+	}
+	return e.check(object, relation, subject, map[string]bool{}, 0)
+}
+
+// Expand the subjects holding the relation on the object
+func (e *Engine) Expand(object string, relation string) (*models.UsersetTree, *resterr.RestErr) {
+	tree, err := e.expand(object, relation, map[string]bool{}, 0)
+	if err != nil {
+		return nil, err
+	}
+	return tree, nil
+}
+
+// ListObjects of a type on which the subject holds the relation
+func (e *Engine) ListObjects(objectType string, relation string, subject string) ([]string, *resterr.RestErr) {
+	namespace, ok := e.Namespaces[objectType]
+	if !ok {
+		return nil, resterr.NewBadRequestError("Unknown namespace: " + objectType)
+	}
+	if _, ok := namespace.GetRelation(relation); !ok {
+		return nil, resterr.NewBadRequestError("Unknown relation: " + objectType + models.RelationSeparator + relation)
+	}
+
+	candidates, err := dao.RelationDao.FindObjects(e.ctx, e.Organization, objectType, e.Revision)
+	if err != nil {
+		return nil, err
+	}
+
+	objects := []string{}
+	for i := 0; i < len(candidates); i++ {
+		allowed, err := e.Check(candidates[i], relation, subject)
+		if err != nil {
+			return nil, err
+		}
+		if allowed {
+			objects = append(objects, candidates[i])
+		}
+	}
+	return objects, nil
+}
+
+// check walks the rewrites of the relation, visited breaks the cycles
+func (e *Engine) check(object string, relation string, subject string, visited map[string]bool, depth int) (bool, *resterr.RestErr) {
+	if depth > maxDepth {
+		return false, resterr.NewBadRequestError("Relation depth exceeded")
+	}
+
+	key := object + models.RelationSeparator + relation
+	if visited[key] {
+		return false, nil
+	}
+	visited[key] = true
+	defer delete(visited, key)
+
+	// a userset subject holds its own relation
+	if subject == key {
+		return true, nil
+	}
+
+	rel, err := e.getRelation(object, relation)
+	if err != nil {
+		// relations missing from other namespaces never match
+		return false, nil
+	}
+
+	for _, rewrite := range rewrites(rel) {
+		switch rewrite.Type {
+		case models.RewriteThis:
+			tuples, err := e.read(object, relation)
+			if err != nil {
+				return false, err
+			}
+			for i := 0; i < len(tuples); i++ {
+				if tuples[i].Subject == subject {
+					return true, nil
+				}
+				subjectObject, subjectRelation, _ := models.ParseSubject(tuples[i].Subject)
+				if subjectRelation == "" {
+					continue
+				}
+				allowed, err := e.check(subjectObject, subjectRelation, subject, visited, depth+1)
+				if err != nil || allowed {
+					return allowed, err
+				}
+			}
+
+		case models.RewriteComputedUserset:
+			allowed, err := e.check(object, rewrite.Relation, subject, visited, depth+1)
+			if err != nil || allowed {
+				return allowed, err
+			}
+
+		case models.RewriteTupleToUserset:
+			tuples, err := e.read(object, rewrite.Tupleset)
+			if err != nil {
+				return false, err
+			}
+			for i := 0; i < len(tuples); i++ {
+				allowed, err := e.check(tuples[i].SubjectObject, rewrite.Relation, subject, visited, depth+1)
+				if err != nil || allowed {
+					return allowed, err
+				}
+			}
+		}
+	}
+
+	return false, nil
+}
+
+// expand builds the userset tree of the relation
+func (e *Engine) expand(object string, relation string, visited map[string]bool, depth int) (*models.UsersetTree, *resterr.RestErr) {
+	if depth > maxDepth {
+		return nil, resterr.NewBadRequestError("Relation depth exceeded")
+	}
+
+	tree := models.UsersetTree{Operation: "union", Object: object, Relation: relation}
+
+	key := object + models.RelationSeparator + relation
+	if visited[key] {
+		return &tree, nil
+	}
+	visited[key] = true
+	defer delete(visited, key)
+
+	rel, err := e.getRelation(object, relation)
+	if err != nil {
+		if depth == 0 {
+			return nil, err
+		}
+		return &tree, nil
+	}
+
+	for _, rewrite := range rewrites(rel) {
+		switch rewrite.Type {
+		case models.RewriteThis:
+			tuples, err := e.read(object, relation)
+			if err != nil {
+				return nil, err
+			}
+			leaf := models.UsersetTree{Operation: "leaf", Object: object, Relation: relation, Subjects: []string{}}
+			for i := 0; i < len(tuples); i++ {
+				leaf.Subjects = append(leaf.Subjects, tuples[i].Subject)
+				subjectObject, subjectRelation, _ := models.ParseSubject(tuples[i].Subject)
+				if subjectRelation == "" {
+					continue
+				}
+				child, err := e.expand(subjectObject, subjectRelation, visited, depth+1)
+				if err != nil {
+					return nil, err
+				}
+				leaf.Children = append(leaf.Children, *child)
+			}
+			tree.Children = append(tree.Children, leaf)
+
+		case models.RewriteComputedUserset:
+			child, err := e.expand(object, rewrite.Relation, visited, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			tree.Children = append(tree.Children, *child)
+
+		case models.RewriteTupleToUserset:
+			tuples, err := e.read(object, rewrite.Tupleset)
+			if err != nil {
+				return nil, err
+			}
+			for i := 0; i < len(tuples); i++ {
+				child, err := e.expand(tuples[i].SubjectObject, rewrite.Relation, visited, depth+1)
+				if err != nil {
+					return nil, err
+				}
+				tree.Children = append(tree.Children, *child)
+			}
+		}
+	}
+
+	return &tree, nil
+}
+
+// getRelation of the namespace of the object
+func (e *Engine) getRelation(object string, relation string) (*models.NamespaceRelation, *resterr.RestErr) {
+	objectType, _, ok := models.ParseObject(object)
+	if !ok {
+		return nil, resterr.NewBadRequestError("Invalid object: " + object)
+	}
+	namespace, ok := e.Namespaces[objectType]
+	if !ok {
+		return nil, resterr.NewBadRequestError("Unknown namespace: " + objectType)
+	}
+	rel, ok := namespace.GetRelation(relation)
+	if !ok {
+		return nil, resterr.NewBadRequestError("Unknown relation: " + objectType + models.RelationSeparator + relation)
+	}
+	return rel, nil
+}
+
+// read the tuples once per request
+func (e *Engine) read(object string, relation string) (models.RelationTuples, *resterr.RestErr) {
+	key := object + models.RelationSeparator + relation
+	if tuples, ok := e.tuples[key]; ok {
+		return tuples, nil
+	}
+
+	tuples, err := e.Reader.Read(e.ctx, e.Organization, object, relation, e.Revision)
+	if err != nil {
+		return nil, err
+	}
+	if e.tuples == nil {
+		e.tuples = map[string]models.RelationTuples{}
+	}
+	e.tuples[key] = tuples
+	return tuples, nil
+}
+
+// rewrites of the relation, the direct tuples by default
+func rewrites(relation *models.NamespaceRelation) []models.RelationRewrite {
+	if len(relation.Rewrite) == 0 {
+		return []models.RelationRewrite{{Type: models.RewriteThis}}
+	}
+	return relation.Rewrite
+}
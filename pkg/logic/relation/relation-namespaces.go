@@ -0,0 +1,79 @@
+package relation
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"sync"
+
+	"gorabc/pkg/models"
+	"gorabc/pkg/repository/dao"
+	"gorabc/pkg/utils/resterr"
+)
+
+// namespaceFile holds the namespaces of the mirrored memberships
+const namespaceFile = "static/json/namespaces/namespaces.json"
+
+var (
+	builtinOnce       sync.Once
+	builtinNamespaces models.Namespaces
+)
+
+// seedNamespaces struct
+type seedNamespaces struct {
+	Namespaces []models.Namespace `json:"namespaces"`
+}
+
+// BuiltinNamespaces are shared by every organization and cannot be changed
+func BuiltinNamespaces() models.Namespaces {
+	builtinOnce.Do(func() {
+		data, err := ioutil.ReadFile(namespaceFile)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+
+		var seed seedNamespaces
+		if err := json.Unmarshal(data, &seed); err != nil {
+			fmt.Println("Error while unmarshalling " + namespaceFile)
+			return
+		}
+		for i := 0; i < len(seed.Namespaces); i++ {
+			seed.Namespaces[i].Managed = true
+		}
+		builtinNamespaces = seed.Namespaces
+	})
+	return builtinNamespaces
+}
+
+// IsBuiltin reports whether the namespace is managed by gorabc
+func IsBuiltin(name string) bool {
+	builtins := BuiltinNamespaces()
+	for i := 0; i < len(builtins); i++ {
+		if builtins[i].Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Namespaces of the organization indexed by name
+func Namespaces(ctx context.Context, org string) (map[string]models.Namespace, *resterr.RestErr) {
+	namespaces, err := dao.RelationDao.FindNamespaces(ctx, org)
+	if err != nil {
+		return nil, err
+	}
+
+	index := map[string]models.Namespace{}
+	for i := 0; i < len(namespaces); i++ {
+		index[namespaces[i].Name] = namespaces[i]
+	}
+
+	// builtin namespaces always win
+	builtins := BuiltinNamespaces()
+	for i := 0; i < len(builtins); i++ {
+		index[builtins[i].Name] = builtins[i]
+	}
+	return index, nil
+}
@@ -0,0 +1,65 @@
+package relation
+
+import (
+	"context"
+	"encoding/base64"
+	"strconv"
+	"strings"
+
+	"gorabc/pkg/models"
+	"gorabc/pkg/repository/dao"
+	"gorabc/pkg/utils/resterr"
+)
+
+// EncodeToken returns the opaque consistency token of a revision
+func EncodeToken(org string, revision int64) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(org + "@" + strconv.FormatInt(revision, 10)))
+}
+
+// DecodeToken returns the revision of a consistency token of the organization
+func DecodeToken(org string, token string) (int64, *resterr.RestErr) {
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return 0, resterr.NewBadRequestError("Invalid consistency token")
+	}
+
+	index := strings.LastIndex(string(data), "@")
+	if index < 0 || string(data[:index]) != org {
+		return 0, resterr.NewBadRequestError("Invalid consistency token")
+	}
+
+	revision, err := strconv.ParseInt(string(data[index+1:]), 10, 64)
+	if err != nil || revision < 0 {
+		return 0, resterr.NewBadRequestError("Invalid consistency token")
+	}
+	return revision, nil
+}
+
+// SnapshotRevision resolves the revision a read is evaluated at
+func SnapshotRevision(ctx context.Context, org string, options models.RelationReadOptions) (int64, *resterr.RestErr) {
+	if err := options.Validate(); err != nil {
+		return 0, err
+	}
+
+	latest, err := dao.RelationDao.Revision(ctx, org)
+	if err != nil {
+		return 0, err
+	}
+
+	if options.Consistency == "" || options.Consistency == models.ConsistencyMinimizeLatency {
+		return latest, nil
+	}
+
+	revision, err := DecodeToken(org, options.Token)
+	if err != nil {
+		return 0, err
+	}
+	if revision > latest {
+		return 0, resterr.NewBadRequestError("Consistency token is ahead of the store")
+	}
+
+	if options.Consistency == models.ConsistencyExactSnapshot {
+		return revision, nil
+	}
+	return latest, nil
+}
@@ -0,0 +1,283 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"gorabc/pkg/logic/helpers"
+	"gorabc/pkg/models"
+	"gorabc/pkg/repository/dao"
+	"gorabc/pkg/utils/datetime"
+	"gorabc/pkg/utils/encrypt"
+	"gorabc/pkg/utils/resterr"
+	"gorabc/pkg/utils/tracing"
+)
+
+// AccessRequestServiceInterface interface
+type AccessRequestServiceInterface interface {
+	Create(context.Context, models.AccessRequest, *models.AuthUser) (*models.AccessRequest, *resterr.RestErr)
+	FindAll(context.Context, models.AccessRequestFilter, *models.AuthUser) (models.AccessRequests, *resterr.RestErr)
+	GetByID(context.Context, string, *models.AuthUser) (*models.AccessRequest, *resterr.RestErr)
+	Approve(context.Context, string, models.AccessRequestDecision, *models.AuthUser) (*models.AccessRequest, *resterr.RestErr)
+	Deny(context.Context, string, models.AccessRequestDecision, *models.AuthUser) (*models.AccessRequest, *resterr.RestErr)
+	Cancel(context.Context, string, *models.AuthUser) (*models.AccessRequest, *resterr.RestErr)
+}
+
+type accessRequestService struct{}
+
+// AccessRequestService variable
+var (
+	AccessRequestService AccessRequestServiceInterface = &accessRequestService{}
+)
+
+// Create access request for the authenticated user
+func (s *accessRequestService) Create(ctx context.Context, request models.AccessRequest, au *models.AuthUser) (*models.AccessRequest, *resterr.RestErr) {
+	ctx, span := tracing.Start(ctx, "AccessRequestService.Create")
+	defer span.End()
+
+	// Validate request
+	if err := request.Validate(); err != nil {
+		return nil, err
+	}
+
+	// Verify role
+	role, err := RoleService.ValidateAssignment(ctx, models.UserRole{RoleID: request.RoleID, Scope: request.Scope}, au)
+	if err != nil {
+		return nil, err
+	}
+
+	request.ID = "AR" + encrypt.GenerateID(19)
+	request.Organization = au.Organization
+	request.UserID = au.ID
+	request.RoleName = role.Name
+	request.Status = models.AccessRequestPending
+	request.ApproverID = ""
+	request.DecisionReason = ""
+	request.ValidFrom = ""
+	request.ValidUntil = ""
+	request.CreatedAt = datetime.GetDateTimeString()
+	request.UpdatedAt = datetime.GetDateTimeString()
+
+	newRequest, err := dao.AccessRequestDao.Create(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := recordAccessRequest(ctx, *newRequest, models.GrantActionRequested, au.ID); err != nil {
+		return nil, err
+	}
+
+	audit(ctx, au, "access_request:create", models.AuditTargetAccessRequest, newRequest.ID, nil, newRequest)
+	return newRequest, nil
+}
+
+// FindAll access requests, users without approval rights only see their own
+func (s *accessRequestService) FindAll(ctx context.Context, filter models.AccessRequestFilter, au *models.AuthUser) (models.AccessRequests, *resterr.RestErr) {
+	ctx, span := tracing.Start(ctx, "AccessRequestService.FindAll")
+	defer span.End()
+
+	if !canReadAccessRequests(au) {
+		filter.UserID = au.ID
+	}
+
+	return dao.AccessRequestDao.FindAll(ctx, au.Organization, filter)
+}
+
+// GetByID access request
+func (s *accessRequestService) GetByID(ctx context.Context, id string, au *models.AuthUser) (*models.AccessRequest, *resterr.RestErr) {
+	ctx, span := tracing.Start(ctx, "AccessRequestService.GetByID")
+	defer span.End()
+
+	request, err := dao.AccessRequestDao.GetByID(ctx, id, au.Organization)
+	if err != nil {
+		return nil, err
+	}
+
+	// Verify permission --> IsGranted
+	if request.UserID != au.ID && !canReadAccessRequests(au) {
+		return nil, resterr.NewForbiddenError("Permission not granted")
+	}
+
+	return request, nil
+}
+
+// Approve access request, the role is assigned for the requested hours
+func (s *accessRequestService) Approve(ctx context.Context, id string, decision models.AccessRequestDecision, au *models.AuthUser) (*models.AccessRequest, *resterr.RestErr) {
+	ctx, span := tracing.Start(ctx, "AccessRequestService.Approve")
+	defer span.End()
+
+	request, err := s.getPendingForApprover(ctx, id, au)
+	if err != nil {
+		return nil, err
+	}
+
+	// Verify the role is within the scope of the approver
+	role, err := RoleService.GetByID(ctx, request.RoleID, au)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := dao.UserDao.GetByID(ctx, request.UserID, au.Organization)
+	if err != nil {
+		return nil, err
+	}
+	before, userBefore := *request, *user
+
+	now := datetime.GetDateTime()
+	request.Status = models.AccessRequestApproved
+	request.ApproverID = au.ID
+	request.DecisionReason = decision.Reason
+	request.ValidFrom = datetime.FormatDateTime(now)
+	request.ValidUntil = datetime.FormatDateTime(now.Add(time.Duration(request.Hours) * time.Hour))
+	request.UpdatedAt = datetime.GetDateTimeString()
+
+	// Assign the role as a time limited assignment
+	userRole := models.UserRole{
+		RoleID:     role.ID,
+		RoleName:   role.Name,
+		Scope:      request.Scope,
+		ValidFrom:  request.ValidFrom,
+		ValidUntil: request.ValidUntil,
+	}
+	if err := helpers.AddUserRole(ctx, user, *role, userRole); err != nil {
+		return nil, err
+	}
+	user.UpdatedAt = datetime.GetDateTimeString()
+
+	// Verify the approver holds what the role grants
+	if err := helpers.VerifyUserGrants(ctx, userBefore, *user, *au); err != nil {
+		return nil, err
+	}
+
+	// Approve the request and assign the role together
+	if err := dao.AccessRequestDao.Approve(ctx, *request, *user); err != nil {
+		return nil, err
+	}
+	if err := recordAccessRequest(ctx, *request, models.GrantActionApproved, au.ID); err != nil {
+		return nil, err
+	}
+
+	// Mirror memberships into relation tuples
+	if err := helpers.MirrorUser(ctx, *user); err != nil {
+		return nil, err
+	}
+
+	if err := recordAccessRequest(ctx, *request, models.GrantActionGranted, au.ID); err != nil {
+		return nil, err
+	}
+
+	audit(ctx, au, "access_request:approve", models.AuditTargetAccessRequest, request.ID, before, request)
+	audit(ctx, au, "user:update", models.AuditTargetUser, user.ID, userBefore, user)
+	return request, nil
+}
+
+// Deny access request
+func (s *accessRequestService) Deny(ctx context.Context, id string, decision models.AccessRequestDecision, au *models.AuthUser) (*models.AccessRequest, *resterr.RestErr) {
+	ctx, span := tracing.Start(ctx, "AccessRequestService.Deny")
+	defer span.End()
+
+	request, err := s.getPendingForApprover(ctx, id, au)
+	if err != nil {
+		return nil, err
+	}
+	before := *request
+
+	request.Status = models.AccessRequestDenied
+	request.ApproverID = au.ID
+	request.DecisionReason = decision.Reason
+	request.UpdatedAt = datetime.GetDateTimeString()
+
+	if err := dao.AccessRequestDao.Update(ctx, *request, models.AccessRequestPending); err != nil {
+		return nil, err
+	}
+	if err := recordAccessRequest(ctx, *request, models.GrantActionDenied, au.ID); err != nil {
+		return nil, err
+	}
+
+	audit(ctx, au, "access_request:deny", models.AuditTargetAccessRequest, request.ID, before, request)
+	return request, nil
+}
+
+// Cancel a pending access request of the authenticated user
+func (s *accessRequestService) Cancel(ctx context.Context, id string, au *models.AuthUser) (*models.AccessRequest, *resterr.RestErr) {
+	ctx, span := tracing.Start(ctx, "AccessRequestService.Cancel")
+	defer span.End()
+
+	request, err := dao.AccessRequestDao.GetByID(ctx, id, au.Organization)
+	if err != nil {
+		return nil, err
+	}
+	if request.UserID != au.ID {
+		return nil, resterr.NewForbiddenError("Only the requester can cancel an access request")
+	}
+	if request.Status != models.AccessRequestPending {
+		return nil, resterr.NewBadRequestError("Access request is no longer pending")
+	}
+	before := *request
+
+	request.Status = models.AccessRequestCancelled
+	request.UpdatedAt = datetime.GetDateTimeString()
+
+	if err := dao.AccessRequestDao.Update(ctx, *request, models.AccessRequestPending); err != nil {
+		return nil, err
+	}
+	if err := recordAccessRequest(ctx, *request, models.GrantActionCancelled, au.ID); err != nil {
+		return nil, err
+	}
+
+	audit(ctx, au, "access_request:cancel", models.AuditTargetAccessRequest, request.ID, before, request)
+	return request, nil
+}
+
+// getPendingForApprover verifies the approver and the request status
+func (s *accessRequestService) getPendingForApprover(ctx context.Context, id string, au *models.AuthUser) (*models.AccessRequest, *resterr.RestErr) {
+	// Verify permission --> IsGranted
+	if !helpers.IsGranted("org:access-request:approve", *au) {
+		return nil, resterr.NewForbiddenError("Permission not granted")
+	}
+
+	request, err := dao.AccessRequestDao.GetByID(ctx, id, au.Organization)
+	if err != nil {
+		return nil, err
+	}
+
+	// Nobody approves their own elevation
+	if request.UserID == au.ID {
+		return nil, resterr.NewForbiddenError("Access requests cannot be decided by the requester")
+	}
+	if request.Status != models.AccessRequestPending {
+		return nil, resterr.NewBadRequestError("Access request is no longer pending")
+	}
+
+	return request, nil
+}
+
+// canReadAccessRequests of the other users of the organization
+func canReadAccessRequests(au *models.AuthUser) bool {
+	return helpers.IsGranted("org:access-request:read", *au) || helpers.IsGranted("org:access-request:approve", *au)
+}
+
+// recordAccessRequest records a step of the access request
+func recordAccessRequest(ctx context.Context, request models.AccessRequest, action string, actor string) *resterr.RestErr {
+	event := models.GrantEvent{
+		ID:           "GEV" + encrypt.GenerateID(18),
+		Organization: request.Organization,
+		UserID:       request.UserID,
+		Type:         models.GrantTypeRole,
+		Action:       action,
+		Name:         request.RoleID,
+		Scope:        request.Scope,
+		ValidFrom:    request.ValidFrom,
+		ValidUntil:   request.ValidUntil,
+		Request:      request.ID,
+		Reason:       request.DecisionReason,
+		Actor:        actor,
+		CreatedAt:    datetime.GetDateTimeString(),
+	}
+	if action == models.GrantActionRequested {
+		event.Reason = request.Justification
+	}
+
+	_, err := dao.GrantEventDao.Create(ctx, event)
+	return err
+}
@@ -0,0 +1,200 @@
+package services
+
+import (
+	"context"
+
+	"gorabc/pkg/logic/helpers"
+	"gorabc/pkg/models"
+	"gorabc/pkg/repository/dao"
+	"gorabc/pkg/utils/datetime"
+	"gorabc/pkg/utils/encrypt"
+	"gorabc/pkg/utils/resterr"
+	"gorabc/pkg/utils/tracing"
+)
+
+// ACLServiceInterface interface
+type ACLServiceInterface interface {
+	Grant(context.Context, models.ObjectGrant, *models.AuthUser) (*models.ObjectGrant, *resterr.RestErr)
+	FindAll(context.Context, models.ObjectGrantFilter, *models.AuthUser) (models.ObjectGrants, *resterr.RestErr)
+	Revoke(context.Context, string, *models.AuthUser) *resterr.RestErr
+	Check(context.Context, models.AccessCheckRequest, *models.AuthUser) (*models.AccessCheckResponse, *resterr.RestErr)
+}
+
+type aclService struct{}
+
+// ACLService variable
+var (
+	ACLService ACLServiceInterface = &aclService{}
+)
+
+// Grant permissions on a resource to a user or a role
+func (s *aclService) Grant(ctx context.Context, grant models.ObjectGrant, au *models.AuthUser) (*models.ObjectGrant, *resterr.RestErr) {
+	ctx, span := tracing.Start(ctx, "AclService.Grant")
+	defer span.End()
+
+	// Validate request
+	if err := grant.Validate(); err != nil {
+		return nil, err
+	}
+
+	// Verify permission --> IsGranted
+	if !helpers.IsGranted("org:acl:grant", *au) {
+		return nil, resterr.NewForbiddenError("Permission not granted")
+	}
+
+	// Verify the subject belongs to the organization
+	if grant.SubjectType == models.SubjectTypeUser {
+		if _, err := dao.UserDao.GetByID(ctx, grant.SubjectID, au.Organization); err != nil {
+			return nil, err
+		}
+	} else {
+		if _, err := dao.RoleDao.GetByID(ctx, grant.SubjectID, au.Organization); err != nil {
+			return nil, err
+		}
+	}
+
+	// Factor out invalid permissions
+	permList, err := dao.PermissionDao.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	validList := helpers.UniquePermissions(helpers.ValidatePermissions(grant.Permissions, permList))
+	if len(validList) == 0 {
+		return nil, resterr.NewBadRequestError("No valid permissions to grant")
+	}
+	for i := 0; i < len(validList); i++ {
+		validList[i].Scope = ""
+		validList[i].Source = ""
+	}
+
+	// Verify the caller holds what the subject is granted
+	if err := helpers.VerifyObjectGrants(validList, *au); err != nil {
+		return nil, err
+	}
+
+	grant.ID = "ACL" + encrypt.GenerateID(18)
+	grant.Organization = au.Organization
+	grant.Permissions = validList
+	grant.GrantedBy = au.ID
+	grant.CreatedAt = datetime.GetDateTimeString()
+
+	newGrant, err := dao.ACLDao.Create(ctx, grant)
+	if err != nil {
+		return nil, err
+	}
+
+	audit(ctx, au, "acl:grant", models.AuditTargetObjectGrant, newGrant.ID, nil, newGrant)
+	return newGrant, nil
+}
+
+// FindAll object grants
+func (s *aclService) FindAll(ctx context.Context, filter models.ObjectGrantFilter, au *models.AuthUser) (models.ObjectGrants, *resterr.RestErr) {
+	ctx, span := tracing.Start(ctx, "AclService.FindAll")
+	defer span.End()
+
+	// Verify permission --> IsGranted
+	if !helpers.IsGranted("org:acl:read", *au) {
+		return nil, resterr.NewForbiddenError("Permission not granted")
+	}
+
+	grants, err := dao.ACLDao.FindAll(ctx, au.Organization, filter)
+	if err != nil {
+		return nil, err
+	}
+	return grants, nil
+}
+
+// Revoke an object grant
+func (s *aclService) Revoke(ctx context.Context, id string, au *models.AuthUser) *resterr.RestErr {
+	ctx, span := tracing.Start(ctx, "AclService.Revoke")
+	defer span.End()
+
+	// Verify permission --> IsGranted
+	if !helpers.IsGranted("org:acl:revoke", *au) {
+		return resterr.NewForbiddenError("Permission not granted")
+	}
+
+	current, err := dao.ACLDao.GetByID(ctx, id, au.Organization)
+	if err != nil {
+		return err
+	}
+
+	if err := dao.ACLDao.Delete(ctx, id, au.Organization); err != nil {
+		return err
+	}
+
+	audit(ctx, au, "acl:revoke", models.AuditTargetObjectGrant, id, current, nil)
+	return nil
+}
+
+// Check combines the object grants of a user with its role permissions,
+// explicit denies always win
+func (s *aclService) Check(ctx context.Context, request models.AccessCheckRequest, au *models.AuthUser) (*models.AccessCheckResponse, *resterr.RestErr) {
+	ctx, span := tracing.Start(ctx, "AclService.Check")
+	defer span.End()
+
+	// Validate request
+	if err := request.Validate(); err != nil {
+		return nil, err
+	}
+
+	// Checking the access of another user requires to read the grants
+	if request.UserID == "" {
+		request.UserID = au.ID
+	}
+	if request.UserID != au.ID && !helpers.IsGranted("org:acl:read", *au) {
+		return nil, resterr.NewForbiddenError("Permission not granted")
+	}
+
+	user, err := dao.UserDao.GetByID(ctx, request.UserID, au.Organization)
+	if err != nil {
+		return nil, err
+	}
+
+	response := models.AccessCheckResponse{
+		UserID:       user.ID,
+		Permission:   request.Permission,
+		ResourceType: request.ResourceType,
+		ResourceID:   request.ResourceID,
+	}
+
+	// Explicit denies
+	subject := helpers.GetAuthUser(*user)
+	explained := helpers.ExplainPermission(request.Permission, subject)
+	if explained.DeniedBy != nil && explained.DeniedBy.Scope == "" {
+		response.Reason = "Denied by explicit deny"
+		response.DeniedBy = explained.DeniedBy
+		return &response, nil
+	}
+
+	// Type level role permissions
+	if helpers.IsGranted(request.Permission, subject) {
+		response.Allowed = true
+		response.Reason = "Granted by role permissions"
+		return &response, nil
+	}
+
+	// Object grants of the user and its roles valid now
+	now := datetime.GetDateTimeString()
+	roleIDs := []string{}
+	for i := 0; i < len(user.Roles); i++ {
+		if user.Roles[i].IsActive(now) {
+			roleIDs = append(roleIDs, user.Roles[i].RoleID)
+		}
+	}
+	grants, err := dao.ACLDao.FindBySubjects(ctx, au.Organization, request.ResourceType, request.ResourceID, []string{user.ID}, roleIDs)
+	if err != nil {
+		return nil, err
+	}
+	for i := 0; i < len(grants); i++ {
+		if models.NewPermissionResolver(grants[i].Permissions).Has(request.Permission) {
+			response.Allowed = true
+			response.Reason = "Granted by object grant"
+			response.Grant = grants[i].ID
+			return &response, nil
+		}
+	}
+
+	response.Reason = "Permission not granted"
+	return &response, nil
+}
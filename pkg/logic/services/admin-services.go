@@ -0,0 +1,342 @@
+package services
+
+import (
+	"context"
+
+	"gorabc/pkg/logic/helpers"
+	"gorabc/pkg/middlewares/jwt"
+	"gorabc/pkg/models"
+	"gorabc/pkg/repository/dao"
+	"gorabc/pkg/utils/datetime"
+	"gorabc/pkg/utils/logger"
+	"gorabc/pkg/utils/resterr"
+	"gorabc/pkg/utils/tracing"
+
+	"go.uber.org/zap"
+)
+
+// AdminServiceInterface interface
+type AdminServiceInterface interface {
+	FindTenants(context.Context, models.TenantFilter, *models.AuthUser) (models.Tenants, *resterr.RestErr)
+	GetTenant(context.Context, string, *models.AuthUser) (*models.Tenant, *resterr.RestErr)
+	SuspendTenant(context.Context, string, models.TenantStatusRequest, *models.AuthUser) (*models.Organization, *resterr.RestErr)
+	ReactivateTenant(context.Context, string, models.TenantStatusRequest, *models.AuthUser) (*models.Organization, *resterr.RestErr)
+	SetOrgAdmin(context.Context, string, string, models.OrgAdminRequest, *models.AuthUser) (*models.User, *resterr.RestErr)
+	Impersonate(context.Context, string, string, models.ImpersonationRequest, *models.AuthUser) (*models.Impersonation, *resterr.RestErr)
+	FindPermissions(context.Context, *models.AuthUser) (models.Permissions, *resterr.RestErr)
+	CreatePermission(context.Context, models.CatalogPermissionRequest, *models.AuthUser) (*models.Permission, *resterr.RestErr)
+	DeletePermission(context.Context, string, *models.AuthUser) *resterr.RestErr
+	Search(context.Context, models.AdminSearchRequest, *models.AuthUser) (*models.AdminSearchResult, *resterr.RestErr)
+}
+
+type adminService struct{}
+
+// AdminService variable
+// The platform administration of superusers, every call is recorded in the
+// platform audit log and calls changing a tenant in its audit log as well.
+var (
+	AdminService AdminServiceInterface = &adminService{}
+)
+
+// FindTenants lists the organizations of every status with their usage
+func (s *adminService) FindTenants(ctx context.Context, filter models.TenantFilter, au *models.AuthUser) (models.Tenants, *resterr.RestErr) {
+	ctx, span := tracing.Start(ctx, "AdminService.FindTenants")
+	defer span.End()
+
+	// Verify permission --> IsGranted
+	if !au.IsSuperuser {
+		return nil, resterr.NewForbiddenError("Permission not granted")
+	}
+
+	organizations, err := dao.OrganizationDao.Find(ctx, filter, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	tenants := models.Tenants{}
+	for i := 0; i < len(organizations); i++ {
+		usage, err := dao.OrganizationDao.Usage(ctx, organizations[i].ID)
+		if err != nil {
+			return nil, err
+		}
+		tenants = append(tenants, models.Tenant{Organization: organizations[i], Usage: *usage})
+	}
+
+	audit(ctx, au, "admin:tenant_list", models.AuditTargetOrganization, "", nil, nil)
+	return tenants, nil
+}
+
+// GetTenant with its usage
+func (s *adminService) GetTenant(ctx context.Context, id string, au *models.AuthUser) (*models.Tenant, *resterr.RestErr) {
+	ctx, span := tracing.Start(ctx, "AdminService.GetTenant")
+	defer span.End()
+
+	// Verify permission --> IsGranted
+	if !au.IsSuperuser {
+		return nil, resterr.NewForbiddenError("Permission not granted")
+	}
+
+	organization, err := dao.OrganizationDao.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	usage, err := dao.OrganizationDao.Usage(ctx, organization.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	audit(ctx, au, "admin:tenant_read", models.AuditTargetOrganization, organization.ID, nil, nil)
+	return &models.Tenant{Organization: *organization, Usage: *usage}, nil
+}
+
+// SuspendTenant blocks the logins of every user of the organization, issued
+// access tokens run until they expire
+func (s *adminService) SuspendTenant(ctx context.Context, id string, request models.TenantStatusRequest, au *models.AuthUser) (*models.Organization, *resterr.RestErr) {
+	ctx, span := tracing.Start(ctx, "AdminService.SuspendTenant")
+	defer span.End()
+
+	return s.setTenantStatus(ctx, id, models.StatusSuspended, request, au)
+}
+
+// ReactivateTenant lets the users of a suspended organization log in again
+func (s *adminService) ReactivateTenant(ctx context.Context, id string, request models.TenantStatusRequest, au *models.AuthUser) (*models.Organization, *resterr.RestErr) {
+	ctx, span := tracing.Start(ctx, "AdminService.ReactivateTenant")
+	defer span.End()
+
+	return s.setTenantStatus(ctx, id, models.StatusActive, request, au)
+}
+
+func (s *adminService) setTenantStatus(ctx context.Context, id string, status string, request models.TenantStatusRequest, au *models.AuthUser) (*models.Organization, *resterr.RestErr) {
+	// Verify permission --> IsGranted
+	if !au.IsSuperuser {
+		return nil, resterr.NewForbiddenError("Permission not granted")
+	}
+
+	if err := request.Validate(); err != nil {
+		return nil, err
+	}
+
+	current, err := dao.OrganizationDao.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	// Only suspended organizations are reactivated
+	action := "admin:tenant_suspend"
+	if status == models.StatusActive {
+		action = "admin:tenant_reactivate"
+		if current.Status != models.StatusSuspended {
+			return nil, resterr.NewConflictError("Organization is not suspended")
+		}
+	} else if current.Status == models.StatusSuspended {
+		return nil, resterr.NewConflictError("Organization is already suspended")
+	}
+	before := *current
+
+	current.Status = status
+	current.StatusReason = request.Reason
+	current.IsActive = status == models.StatusActive
+	current.UpdatedAt = datetime.GetDateTimeString()
+
+	if err := dao.OrganizationDao.Update(ctx, *current); err != nil {
+		return nil, err
+	}
+
+	adminAudit(ctx, au, current.ID, action, models.AuditTargetOrganization, current.ID, before, current)
+	return current, nil
+}
+
+// SetOrgAdmin promotes or demotes an admin of any organization
+func (s *adminService) SetOrgAdmin(ctx context.Context, org string, id string, request models.OrgAdminRequest, au *models.AuthUser) (*models.User, *resterr.RestErr) {
+	ctx, span := tracing.Start(ctx, "AdminService.SetOrgAdmin")
+	defer span.End()
+
+	// Verify permission --> IsGranted
+	if !au.IsSuperuser {
+		return nil, resterr.NewForbiddenError("Permission not granted")
+	}
+
+	if err := request.Validate(); err != nil {
+		return nil, err
+	}
+
+	if _, err := dao.OrganizationDao.GetByID(ctx, org); err != nil {
+		return nil, err
+	}
+
+	before, current, err := setOrgAdmin(ctx, id, org, *request.IsOrgAdmin)
+	if err != nil {
+		return nil, err
+	}
+	if before.IsOrgAdmin != current.IsOrgAdmin {
+		adminAudit(ctx, au, org, orgAdminAction(current.IsOrgAdmin), models.AuditTargetUser, current.ID, before, current)
+	}
+	return current, nil
+}
+
+// Impersonate mints a short-lived token acting as a user of an organization,
+// the token carries the superuser in its act claim so that every change made
+// with it is recorded with both identities
+func (s *adminService) Impersonate(ctx context.Context, org string, id string, request models.ImpersonationRequest, au *models.AuthUser) (*models.Impersonation, *resterr.RestErr) {
+	ctx, span := tracing.Start(ctx, "AdminService.Impersonate")
+	defer span.End()
+
+	// Verify permission --> IsGranted
+	if !au.IsSuperuser || au.Act != nil {
+		return nil, resterr.NewForbiddenError("Permission not granted")
+	}
+
+	if err := request.Validate(); err != nil {
+		return nil, err
+	}
+
+	if _, err := dao.OrganizationDao.GetByID(ctx, org); err != nil {
+		return nil, err
+	}
+
+	user, err := dao.UserDao.GetByID(ctx, id, org)
+	if err != nil {
+		return nil, err
+	}
+	if user.IsSuperuser {
+		return nil, resterr.NewForbiddenError("Superusers cannot be impersonated")
+	}
+
+	// Activate the session roles --> dynamic separation of duties
+	if err := helpers.ActivateRoles(ctx, user, request.ActiveRoles); err != nil {
+		return nil, err
+	}
+
+	impersonated := helpers.GetAuthUser(*user)
+	if request.ReadOnly {
+		impersonated = helpers.ReadOnlyAuthUser(impersonated)
+	}
+	impersonated.Act = &models.TokenActor{ID: au.ID}
+
+	adminAudit(ctx, au, org, "user:impersonate", models.AuditTargetUser, user.ID, nil, request)
+	return &models.Impersonation{
+		User:     impersonated,
+		Token:    *jwt.GenerateToken(&impersonated),
+		ReadOnly: request.ReadOnly,
+	}, nil
+}
+
+// FindPermissions of the global catalog
+func (s *adminService) FindPermissions(ctx context.Context, au *models.AuthUser) (models.Permissions, *resterr.RestErr) {
+	ctx, span := tracing.Start(ctx, "AdminService.FindPermissions")
+	defer span.End()
+
+	// Verify permission --> IsGranted
+	if !au.IsSuperuser {
+		return nil, resterr.NewForbiddenError("Permission not granted")
+	}
+
+	permissions, err := dao.PermissionDao.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	audit(ctx, au, "admin:permission_list", models.AuditTargetPermission, "", nil, nil)
+	return permissions, nil
+}
+
+// CreatePermission in the global catalog, it can then be granted in every organization
+func (s *adminService) CreatePermission(ctx context.Context, request models.CatalogPermissionRequest, au *models.AuthUser) (*models.Permission, *resterr.RestErr) {
+	ctx, span := tracing.Start(ctx, "AdminService.CreatePermission")
+	defer span.End()
+
+	// Verify permission --> IsGranted
+	if !au.IsSuperuser {
+		return nil, resterr.NewForbiddenError("Permission not granted")
+	}
+
+	if err := request.Validate(); err != nil {
+		return nil, err
+	}
+
+	// Verify unique name
+	if _, err := dao.PermissionDao.GetByName(ctx, request.Name); err == nil {
+		return nil, resterr.NewConflictError("Permission already exists")
+	}
+
+	permission, err := dao.PermissionDao.Create(ctx, models.Permission{Name: request.Name})
+	if err != nil {
+		return nil, err
+	}
+
+	reloadPermissionMetrics(ctx)
+
+	audit(ctx, au, "admin:permission_create", models.AuditTargetPermission, permission.Name, nil, permission)
+	return permission, nil
+}
+
+// DeletePermission from the global catalog, it cannot be granted anymore while
+// existing grants are kept
+func (s *adminService) DeletePermission(ctx context.Context, name string, au *models.AuthUser) *resterr.RestErr {
+	ctx, span := tracing.Start(ctx, "AdminService.DeletePermission")
+	defer span.End()
+
+	// Verify permission --> IsGranted
+	if !au.IsSuperuser {
+		return resterr.NewForbiddenError("Permission not granted")
+	}
+
+	current, err := dao.PermissionDao.GetByName(ctx, name)
+	if err != nil {
+		return err
+	}
+
+	if err := dao.PermissionDao.Delete(ctx, name); err != nil {
+		return err
+	}
+
+	reloadPermissionMetrics(ctx)
+
+	audit(ctx, au, "admin:permission_delete", models.AuditTargetPermission, name, current, nil)
+	return nil
+}
+
+// Search the organizations and the users of every tenant
+func (s *adminService) Search(ctx context.Context, request models.AdminSearchRequest, au *models.AuthUser) (*models.AdminSearchResult, *resterr.RestErr) {
+	ctx, span := tracing.Start(ctx, "AdminService.Search")
+	defer span.End()
+
+	// Verify permission --> IsGranted
+	if !au.IsSuperuser {
+		return nil, resterr.NewForbiddenError("Permission not granted")
+	}
+
+	if err := request.Validate(); err != nil {
+		return nil, err
+	}
+
+	organizations, err := dao.OrganizationDao.Find(ctx, models.TenantFilter{Query: request.Query}, request.Limit)
+	if err != nil {
+		return nil, err
+	}
+	users, err := dao.UserDao.Search(ctx, request.Query, request.Limit)
+	if err != nil {
+		return nil, err
+	}
+
+	audit(ctx, au, "admin:search", models.AuditTargetSearch, request.Query, nil, nil)
+	return &models.AdminSearchResult{Organizations: organizations, Users: models.Users(users).Marshal()}, nil
+}
+
+// adminAudit records a change of a tenant by a superuser in the platform
+// audit log and in the audit log of the tenant
+func adminAudit(ctx context.Context, au *models.AuthUser, org string, action string, targetType string, target string, before interface{}, after interface{}) {
+	audit(ctx, au, action, targetType, target, before, after)
+
+	tenant := *au
+	tenant.Organization = org
+	audit(ctx, &tenant, action, targetType, target, before, after)
+}
+
+// reloadPermissionMetrics after a change of the catalogue, a failure only
+// counts the checks of the new names as other
+func reloadPermissionMetrics(ctx context.Context) {
+	if err := helpers.LoadPermissionMetrics(ctx); err != nil {
+		logger.FromContext(ctx).Error("Permission metrics not reloaded", zap.String("error", err.Message))
+	}
+}
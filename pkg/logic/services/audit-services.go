@@ -0,0 +1,193 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"sync"
+
+	"gorabc/pkg/logic/helpers"
+	"gorabc/pkg/models"
+	"gorabc/pkg/repository/dao"
+	"gorabc/pkg/utils/datetime"
+	"gorabc/pkg/utils/encrypt"
+	"gorabc/pkg/utils/logger"
+	"gorabc/pkg/utils/resterr"
+	"gorabc/pkg/utils/tracing"
+
+	"go.uber.org/zap"
+)
+
+// auditRetries bounds the attempts to append when other instances write the same chain
+const auditRetries = 5
+
+// AuditServiceInterface interface
+type AuditServiceInterface interface {
+	Record(context.Context, models.AuditEvent) *resterr.RestErr
+	Find(context.Context, models.AuditFilter, *models.AuthUser) (models.AuditEvents, *resterr.RestErr)
+	Export(context.Context, models.AuditFilter, *models.AuthUser) ([]byte, *resterr.RestErr)
+	Verify(context.Context, string, *models.AuthUser) (*models.AuditVerification, *resterr.RestErr)
+}
+
+type auditService struct {
+	// appends of this instance are serialized, the unique sequence guards the others
+	mu sync.Mutex
+}
+
+// AuditService variable
+var (
+	AuditService AuditServiceInterface = &auditService{}
+)
+
+// Record an event at the end of the chain of its organization
+func (s *auditService) Record(ctx context.Context, event models.AuditEvent) *resterr.RestErr {
+	ctx, span := tracing.Start(ctx, "AuditService.Record")
+	defer span.End()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	event.ID = "AUD" + encrypt.GenerateID(17)
+	event.CreatedAt = datetime.GetDateTimeString()
+
+	for attempt := 0; attempt < auditRetries; attempt++ {
+		last, err := dao.AuditDao.Last(ctx, event.Organization)
+		if err != nil {
+			return err
+		}
+
+		event.Sequence = 1
+		event.PrevHash = ""
+		if last != nil {
+			event.Sequence = last.Sequence + 1
+			event.PrevHash = last.Hash
+		}
+		event.Hash = helpers.AuditHash(event)
+
+		err = dao.AuditDao.Append(ctx, event)
+		if err != dao.ErrAuditSequenceTaken {
+			return err
+		}
+	}
+	return resterr.NewUnavailableError("Audit log is busy")
+}
+
+// Find events of the organization matching the filter, newest first
+func (s *auditService) Find(ctx context.Context, filter models.AuditFilter, au *models.AuthUser) (models.AuditEvents, *resterr.RestErr) {
+	ctx, span := tracing.Start(ctx, "AuditService.Find")
+	defer span.End()
+
+	if err := s.authorize(&filter, au); err != nil {
+		return nil, err
+	}
+	return dao.AuditDao.Find(ctx, filter)
+}
+
+// Export events of the organization matching the filter as JSON Lines, in chain order
+func (s *auditService) Export(ctx context.Context, filter models.AuditFilter, au *models.AuthUser) ([]byte, *resterr.RestErr) {
+	ctx, span := tracing.Start(ctx, "AuditService.Export")
+	defer span.End()
+
+	if err := s.authorize(&filter, au); err != nil {
+		return nil, err
+	}
+
+	var buffer bytes.Buffer
+	encoder := json.NewEncoder(&buffer)
+	err := dao.AuditDao.Walk(ctx, filter, func(event models.AuditEvent) *resterr.RestErr {
+		if err := encoder.Encode(event); err != nil {
+			return resterr.NewInternalServerError(err.Error())
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+// Verify the hash chain of the organization from its first event
+func (s *auditService) Verify(ctx context.Context, org string, au *models.AuthUser) (*models.AuditVerification, *resterr.RestErr) {
+	ctx, span := tracing.Start(ctx, "AuditService.Verify")
+	defer span.End()
+
+	filter := models.AuditFilter{Organization: org}
+	if err := s.authorize(&filter, au); err != nil {
+		return nil, err
+	}
+
+	verification := models.AuditVerification{Organization: filter.Organization, Valid: true}
+	previous := models.AuditEvent{}
+	err := dao.AuditDao.Walk(ctx, filter, func(event models.AuditEvent) *resterr.RestErr {
+		if !verification.Valid {
+			return nil
+		}
+		verification.Events++
+
+		reason := ""
+		switch {
+		case event.Sequence != previous.Sequence+1:
+			reason = "Missing event before this sequence"
+		case event.PrevHash != previous.Hash:
+			reason = "Previous hash does not match"
+		case event.Hash != helpers.AuditHash(event):
+			reason = "Event was modified"
+		}
+		if reason != "" {
+			verification.Valid = false
+			verification.BrokenAt = event.Sequence
+			verification.Reason = reason
+		}
+		previous = event
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &verification, nil
+}
+
+// authorize the audit read and pin the filter to the organization of the user,
+// superusers read any organization, the platform events have none
+func (s *auditService) authorize(filter *models.AuditFilter, au *models.AuthUser) *resterr.RestErr {
+	if !au.IsSuperuser {
+		// Verify permission --> IsGranted
+		if !helpers.IsGranted("org:audit:read", *au) {
+			return resterr.NewForbiddenError("Permission not granted")
+		}
+		filter.Organization = au.Organization
+	}
+	return filter.Validate()
+}
+
+// audit records a change made by the user, a failure is logged and does not
+// undo the change. A nil before is a creation, a nil after a deletion.
+func audit(ctx context.Context, au *models.AuthUser, action string, targetType string, target string, before interface{}, after interface{}) {
+	impersonator := ""
+	if au.Act != nil {
+		impersonator = au.Act.ID
+	}
+	recordAudit(ctx, models.AuditEvent{
+		Organization: au.Organization,
+		Actor:        au.ID,
+		Impersonator: impersonator,
+		Action:       action,
+		TargetType:   targetType,
+		Target:       target,
+		Changes:      helpers.AuditChanges(before, after),
+		IP:           au.IP,
+		RequestID:    au.RequestID,
+	})
+}
+
+// recordAudit logs events that could not be appended
+func recordAudit(ctx context.Context, event models.AuditEvent) {
+	if err := AuditService.Record(ctx, event); err != nil {
+		logger.FromContext(ctx).Error("Audit event not recorded",
+			zap.String("actor", event.Actor),
+			zap.String("action", event.Action),
+			zap.String("target", event.Target),
+			zap.String("error", err.Message),
+		)
+	}
+}
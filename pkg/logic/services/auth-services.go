@@ -1,20 +1,26 @@
 package services
 
 import (
+	"context"
 	"strings"
 
+	"gorabc/pkg/logic/helpers"
 	"gorabc/pkg/models"
 	"gorabc/pkg/repository/dao"
 	"gorabc/pkg/utils/datetime"
 	"gorabc/pkg/utils/encrypt"
+	"gorabc/pkg/utils/metrics"
 	"gorabc/pkg/utils/resterr"
+	"gorabc/pkg/utils/tracing"
 )
 
 // AuthServiceInterface interface
 type AuthServiceInterface interface {
-	Login(models.LoginRequest) (*models.User, *resterr.RestErr)
-	RegisterOrg(models.RegistrationRequest) (*models.Organization, *resterr.RestErr)
-	RegisterSuperuser(models.User) (*models.User, *resterr.RestErr)
+	Login(context.Context, models.LoginRequest) (*models.User, *resterr.RestErr)
+	RegisterOrg(context.Context, models.RegistrationRequest) (*models.Organization, *resterr.RestErr)
+	CreateOrg(context.Context, models.RegistrationRequest) (*models.Organization, *resterr.RestErr)
+	BootstrapSuperuser(context.Context, models.CreateUserRequest, string) (*models.User, *resterr.RestErr)
+	RegisterSuperuser(context.Context, models.CreateUserRequest) (*models.User, *resterr.RestErr)
 }
 
 type authService struct{}
@@ -25,24 +31,145 @@ var (
 )
 
 // Login service
-func (s *authService) Login(request models.LoginRequest) (*models.User, *resterr.RestErr) {
+func (s *authService) Login(ctx context.Context, request models.LoginRequest) (*models.User, *resterr.RestErr) {
+	ctx, span := tracing.Start(ctx, "AuthService.Login")
+	defer span.End()
+
 	email := request.Email
 	password := encrypt.GetMd5(request.Password)
-	user, err := dao.AuthDao.Login(email, password)
+	user, err := dao.AuthDao.Login(ctx, email, password)
+	if err != nil {
+		// The organization of an unknown email is unknown, the attempt goes to the platform log
+		recordAudit(ctx, models.AuditEvent{
+			Action:     models.AuditActionLoginFailed,
+			TargetType: models.AuditTargetUser,
+			Target:     email,
+			IP:         request.IP,
+			RequestID:  request.RequestID,
+		})
+		metrics.ObserveLogin(false)
+		return nil, err
+	}
+
+	// Invited users log in once they accepted the invite
+	if user.Status == models.StatusPending {
+		metrics.ObserveLogin(false)
+		return nil, resterr.NewBadRequestError("Invitation has not been accepted")
+	}
+
+	// Suspended organizations cannot log in
+	if user.Organization != "" {
+		organization, err := dao.OrganizationDao.GetByID(ctx, user.Organization)
+		if err != nil {
+			return nil, err
+		}
+		if organization.Status == models.StatusSuspended {
+			recordAudit(ctx, models.AuditEvent{
+				Organization: user.Organization,
+				Actor:        user.ID,
+				Action:       models.AuditActionLoginFailed,
+				TargetType:   models.AuditTargetUser,
+				Target:       user.ID,
+				IP:           request.IP,
+				RequestID:    request.RequestID,
+			})
+			metrics.ObserveLogin(false)
+			return nil, resterr.NewForbiddenError("Organization is suspended")
+		}
+	}
+
+	// Get user permissions and denies
+	user, err = dao.UserDao.GetByID(ctx, user.ID, user.Organization)
 	if err != nil {
 		return nil, err
 	}
 
+	// Activate the session roles --> dynamic separation of duties
+	if err := helpers.ActivateRoles(ctx, user, request.ActiveRoles); err != nil {
+		return nil, err
+	}
+
+	recordAudit(ctx, models.AuditEvent{
+		Organization: user.Organization,
+		Actor:        user.ID,
+		Action:       models.AuditActionLogin,
+		TargetType:   models.AuditTargetUser,
+		Target:       user.ID,
+		IP:           request.IP,
+		RequestID:    request.RequestID,
+	})
+	metrics.ObserveLogin(true)
 	return user, nil
 }
 
-// RegisterOrg organization
-func (s *authService) RegisterOrg(request models.RegistrationRequest) (*models.Organization, *resterr.RestErr) {
+// RegisterOrg self-registers an organization according to the registration mode
+func (s *authService) RegisterOrg(ctx context.Context, request models.RegistrationRequest) (*models.Organization, *resterr.RestErr) {
+	ctx, span := tracing.Start(ctx, "AuthService.RegisterOrg")
+	defer span.End()
+
+	// Validate request
+	if err := request.Validate(); err != nil {
+		return nil, err
+	}
+
+	// Verify registration mode
+	mode := helpers.GetRegistrationMode()
+	if mode == models.RegistrationDisabled {
+		return nil, resterr.NewForbiddenError("Organization registration is disabled")
+	}
+	if mode == models.RegistrationInviteOnly && request.InviteToken == "" {
+		return nil, resterr.NewForbiddenError("Organization registration requires an invite")
+	}
+
+	// Verify the registration --> e.g. CAPTCHA
+	if err := helpers.VerifyRegistration(request); err != nil {
+		return nil, err
+	}
+
+	// Verify unique email before the invite is used
+	_, emailErr := dao.UserDao.GetByEmail(ctx, request.Email)
+	if emailErr == nil {
+		return nil, resterr.NewConflictError("Email already registered")
+	}
+
+	// Use the invite
+	var invite *models.RegistrationInvite
+	if mode == models.RegistrationInviteOnly {
+		var err *resterr.RestErr
+		invite, err = dao.RegistrationDao.ConsumeInvite(ctx, encrypt.HashToken(request.InviteToken), strings.ToLower(request.Email), datetime.GetDateTimeString())
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	organization, err := s.CreateOrg(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	if invite != nil {
+		if err := dao.RegistrationDao.SetInviteOrganization(ctx, invite.ID, organization.ID); err != nil {
+			return nil, err
+		}
+	}
+	return organization, nil
+}
+
+// CreateOrg creates an organization and its admin, without the registration controls
+func (s *authService) CreateOrg(ctx context.Context, request models.RegistrationRequest) (*models.Organization, *resterr.RestErr) {
+	ctx, span := tracing.Start(ctx, "AuthService.CreateOrg")
+	defer span.End()
+
 	// Validate request
 	if err := request.Validate(); err != nil {
 		return nil, err
 	}
 
+	// Verify preset
+	if _, ok := helpers.GetPreset(request.Preset); request.Preset != "" && !ok {
+		return nil, resterr.NewBadRequestError("Invalid preset: " + request.Preset)
+	}
+
 	// Set organization fields
 	org := models.Organization{}
 	org.ID = strings.TrimSpace(strings.ToUpper(request.OrgName)) + encrypt.GenerateID(10)
@@ -54,9 +181,9 @@ func (s *authService) RegisterOrg(request models.RegistrationRequest) (*models.O
 	org.UpdatedAt = datetime.GetDateTimeString()
 
 	// Verify unique email
-	_, emailErr := dao.UserDao.GetByEmail(request.Email)
+	_, emailErr := dao.UserDao.GetByEmail(ctx, request.Email)
 	if emailErr == nil {
-		return nil, resterr.NewBadRequestError("Email already registered")
+		return nil, resterr.NewConflictError("Email already registered")
 	}
 
 	// Set user fields
@@ -75,26 +202,93 @@ func (s *authService) RegisterOrg(request models.RegistrationRequest) (*models.O
 	user.UpdatedAt = datetime.GetDateTimeString()
 
 	// Create organization
-	newOrganization, err := dao.OrganizationDao.Create(org)
+	newOrganization, err := dao.OrganizationDao.Create(ctx, org)
 	if err != nil {
 		return nil, err
 	}
 
 	// Create user
-	_, err = dao.UserDao.Create(user)
+	newUser, err := dao.UserDao.Create(ctx, user)
 	if err != nil {
 		return nil, err
 	}
 
+	// Mirror memberships into relation tuples
+	if err := helpers.MirrorUser(ctx, *newUser); err != nil {
+		return nil, err
+	}
+
+	// The admin registers the organization
+	admin := &models.AuthUser{ID: newUser.ID, Organization: newOrganization.ID, IP: request.IP, RequestID: request.RequestID}
+	audit(ctx, admin, "organization:create", models.AuditTargetOrganization, newOrganization.ID, nil, newOrganization)
+	audit(ctx, admin, "user:create", models.AuditTargetUser, newUser.ID, nil, newUser)
+
+	// Bootstrap departments and roles
+	if request.Preset != "" {
+		if err := TemplateService.ApplyPreset(ctx, request.Preset, newOrganization.ID); err != nil {
+			return nil, err
+		}
+	}
+
 	return newOrganization, nil
 }
 
+// BootstrapSuperuser creates the first platform superuser with the one-time
+// bootstrap token, the endpoint refuses once a superuser exists
+func (s *authService) BootstrapSuperuser(ctx context.Context, request models.CreateUserRequest, token string) (*models.User, *resterr.RestErr) {
+	ctx, span := tracing.Start(ctx, "AuthService.BootstrapSuperuser")
+	defer span.End()
+
+	if err := helpers.VerifyBootstrapToken(token); err != nil {
+		return nil, err
+	}
+
+	exists, err := dao.UserDao.HasSuperuser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return nil, resterr.NewForbiddenError("Superuser bootstrap already completed")
+	}
+
+	if err := request.Validate(); err != nil {
+		return nil, err
+	}
+
+	// Use the token once, concurrent requests are refused
+	consumed, err := dao.RegistrationDao.ConsumeBootstrap(ctx, "superuser")
+	if err != nil {
+		return nil, err
+	}
+	if !consumed {
+		return nil, resterr.NewForbiddenError("Superuser bootstrap already completed")
+	}
+
+	return s.RegisterSuperuser(ctx, request)
+}
+
 // RegisterSuperuser func
-func (s *authService) RegisterSuperuser(user models.User) (*models.User, *resterr.RestErr) {
+// Creates a platform superuser without the bootstrap controls, for gorabcctl.
+func (s *authService) RegisterSuperuser(ctx context.Context, request models.CreateUserRequest) (*models.User, *resterr.RestErr) {
+	ctx, span := tracing.Start(ctx, "AuthService.RegisterSuperuser")
+	defer span.End()
+
+	if err := request.Validate(); err != nil {
+		return nil, err
+	}
+
+	// Superusers hold no organization memberships
+	user := models.User{
+		Firstname: request.Firstname,
+		Lastname:  request.Lastname,
+		Email:     request.Email,
+		Password:  request.Password,
+	}
+
 	// Verify unique email
-	_, emailErr := dao.UserDao.GetByEmail(user.Email)
+	_, emailErr := dao.UserDao.GetByEmail(ctx, user.Email)
 	if emailErr == nil {
-		return nil, resterr.NewBadRequestError("Email already registered")
+		return nil, resterr.NewConflictError("Email already registered")
 	}
 
 	// Set user fields
@@ -108,9 +302,11 @@ func (s *authService) RegisterSuperuser(user models.User) (*models.User, *rester
 	user.CreatedAt = datetime.GetDateTimeString()
 	user.UpdatedAt = datetime.GetDateTimeString()
 
-	superuser, err := dao.UserDao.Create(user)
+	superuser, err := dao.UserDao.Create(ctx, user)
 	if err != nil {
 		return nil, err
 	}
+
+	audit(ctx, &models.AuthUser{ID: superuser.ID}, "superuser:create", models.AuditTargetUser, superuser.ID, nil, superuser)
 	return superuser, nil
 }
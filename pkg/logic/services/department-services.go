@@ -1,21 +1,24 @@
 package services
 
 import (
+	"context"
+
 	"gorabc/pkg/logic/helpers"
 	"gorabc/pkg/models"
 	"gorabc/pkg/repository/dao"
 	"gorabc/pkg/utils/datetime"
 	"gorabc/pkg/utils/encrypt"
 	"gorabc/pkg/utils/resterr"
+	"gorabc/pkg/utils/tracing"
 )
 
 // DepartmentServiceInterface interface
 type DepartmentServiceInterface interface {
-	Create(models.Department, *models.AuthUser) (*models.Department, *resterr.RestErr)
-	FindAll(*models.AuthUser) (models.Departments, *resterr.RestErr)
-	GetByID(string, *models.AuthUser) (*models.Department, *resterr.RestErr)
-	Update(models.Department, *models.AuthUser) (*models.Department, *resterr.RestErr)
-	Delete(string, *models.AuthUser) *resterr.RestErr
+	Create(context.Context, models.Department, *models.AuthUser) (*models.Department, *resterr.RestErr)
+	FindAll(context.Context, *models.AuthUser) (models.Departments, *resterr.RestErr)
+	GetByID(context.Context, string, *models.AuthUser) (*models.Department, *resterr.RestErr)
+	Update(context.Context, string, models.UpdateDepartmentRequest, *models.AuthUser) (*models.Department, *resterr.RestErr)
+	Delete(context.Context, string, *models.AuthUser) *resterr.RestErr
 }
 
 type departmentService struct{}
@@ -26,105 +29,248 @@ var (
 )
 
 // Create department
-func (s *departmentService) Create(dept models.Department, au *models.AuthUser) (*models.Department, *resterr.RestErr) {
+func (s *departmentService) Create(ctx context.Context, dept models.Department, au *models.AuthUser) (*models.Department, *resterr.RestErr) {
+	ctx, span := tracing.Start(ctx, "DepartmentService.Create")
+	defer span.End()
+
+	var parent *models.Department
+	if dept.Parent != "" {
+		var err *resterr.RestErr
+		if parent, err = dao.DepartmentDao.GetByID(ctx, dept.Parent, au.Organization); err != nil {
+			return nil, err
+		}
+	}
+
+	dept, err := prepareDepartmentCreate(dept, parent, au)
+	if err != nil {
+		return nil, err
+	}
+
+	newDept, err := dao.DepartmentDao.Create(ctx, dept)
+	if err != nil {
+		return nil, err
+	}
+
+	// Mirror hierarchy into relation tuples
+	if err := helpers.MirrorDepartment(ctx, *newDept); err != nil {
+		return nil, err
+	}
+
+	audit(ctx, au, "department:create", models.AuditTargetDepartment, newDept.ID, nil, newDept)
+	return newDept, nil
+}
+
+// prepareDepartmentCreate builds the validated department below its parent,
+// nil for a root, and runs every check of its creation without writing it,
+// the dry run of an import runs the same checks
+func prepareDepartmentCreate(dept models.Department, parent *models.Department, au *models.AuthUser) (models.Department, *resterr.RestErr) {
 	// Validate request
+	dept.Organization = au.Organization
 	if err := dept.Validate(); err != nil {
-		return nil, err
+		return dept, err
 	}
 
 	// Verify permission --> IsGranted
-	if !au.IsOrgAdmin {
-		if !helpers.IsGranted("CanCreateDepartment", *au) {
-			return nil, resterr.NewUnauthorizedError("Permission not granted")
-		}
+	scope := helpers.GetScope("org:department:create", *au)
+	if scope.IsEmpty() {
+		return dept, resterr.NewForbiddenError("Permission not granted")
 	}
 
 	dept.ID = "DEPT" + encrypt.GenerateID(17)
-	dept.Organization = au.Organization
 	dept.Status = models.StatusActive
 	dept.IsActive = true
 	dept.CreatedAt = datetime.GetDateTimeString()
 	dept.UpdatedAt = datetime.GetDateTimeString()
 
-	newDept, err := dao.DepartmentDao.Create(dept)
-	if err != nil {
-		return nil, err
+	// Place department in the hierarchy
+	if parent != nil {
+		// scoped managers can only create departments below their subtree
+		if !scope.Allows(*parent) {
+			return dept, resterr.NewForbiddenError("Parent department is outside of your scope")
+		}
+		dept.BuildPath(parent)
+	} else {
+		if !scope.OrgWide {
+			return dept, resterr.NewForbiddenError("Parent department is required")
+		}
+		dept.BuildPath(nil)
 	}
-	return newDept, nil
+	return dept, nil
 }
 
 // FindAll department
-func (s *departmentService) FindAll(au *models.AuthUser) (models.Departments, *resterr.RestErr) {
+func (s *departmentService) FindAll(ctx context.Context, au *models.AuthUser) (models.Departments, *resterr.RestErr) {
+	ctx, span := tracing.Start(ctx, "DepartmentService.FindAll")
+	defer span.End()
+
 	// Verify permission --> IsGranted
-	if !au.IsOrgAdmin {
-		if !helpers.IsGranted("CanReadDepartment", *au) {
-			return nil, resterr.NewUnauthorizedError("Permission not granted")
-		}
+	scope := helpers.GetScope("org:department:read", *au)
+	if scope.IsEmpty() {
+		return nil, resterr.NewForbiddenError("Permission not granted")
 	}
 
-	departments, err := dao.DepartmentDao.FindAll(au.Organization)
+	departments, err := dao.DepartmentDao.FindAll(ctx, au.Organization)
 	if err != nil {
 		return nil, err
 	}
 
-	return departments, nil
+	if scope.Unrestricted() {
+		return departments, nil
+	}
+
+	// Factor out departments outside of the granted subtrees
+	result := models.Departments{}
+	for i := 0; i < len(departments); i++ {
+		if scope.Allows(departments[i]) {
+			result = append(result, departments[i])
+		}
+	}
+
+	return result, nil
 }
 
 // GetByID department
-func (s *departmentService) GetByID(id string, au *models.AuthUser) (*models.Department, *resterr.RestErr) { // Verify permission --> IsGranted
-	if !au.IsOrgAdmin {
-		if !helpers.IsGranted("CanReadDepartment", *au) {
-			return nil, resterr.NewUnauthorizedError("Permission not granted")
-		}
+func (s *departmentService) GetByID(ctx context.Context, id string, au *models.AuthUser) (*models.Department, *resterr.RestErr) {
+	ctx, span := tracing.Start(ctx, "DepartmentService.GetByID")
+	defer span.End()
+
+	// Verify permission --> IsGranted
+	scope := helpers.GetScope("org:department:read", *au)
+	if scope.IsEmpty() {
+		return nil, resterr.NewForbiddenError("Permission not granted")
 	}
 
 	// Get department
-	department, err := dao.DepartmentDao.GetByID(id, au.Organization)
+	department, err := dao.DepartmentDao.GetByID(ctx, id, au.Organization)
 	if err != nil {
 		return nil, err
 	}
 
+	if !scope.Allows(*department) {
+		return nil, resterr.NewForbiddenError("Department is outside of your scope")
+	}
+
 	return department, nil
 }
 
 // Update department
-func (s *departmentService) Update(department models.Department, au *models.AuthUser) (*models.Department, *resterr.RestErr) { // Verify permission --> IsGranted
-	if !au.IsOrgAdmin {
-		if !helpers.IsGranted("CanUpdateDepartment", *au) {
-			return nil, resterr.NewUnauthorizedError("Permission not granted")
+func (s *departmentService) Update(ctx context.Context, id string, request models.UpdateDepartmentRequest, au *models.AuthUser) (*models.Department, *resterr.RestErr) {
+	ctx, span := tracing.Start(ctx, "DepartmentService.Update")
+	defer span.End()
+
+	if err := request.Validate(); err != nil {
+		return nil, err
+	}
+
+	current, err := dao.DepartmentDao.GetByID(ctx, id, au.Organization)
+	if err != nil {
+		return nil, err
+	}
+
+	var parent *models.Department
+	if !request.ToRoot && request.Parent != "" && request.Parent != current.Parent {
+		if parent, err = dao.DepartmentDao.GetByID(ctx, request.Parent, au.Organization); err != nil {
+			return nil, err
 		}
 	}
 
-	current, err := s.GetByID(department.ID, au)
+	updated, err := prepareDepartmentUpdate(*current, request, parent, au)
 	if err != nil {
 		return nil, err
 	}
 
-	// Verify auth user's organization
-	if au.Organization != current.Organization {
-		return nil, resterr.NewUnauthorizedError("Unauthorized request")
+	// Save the department and rewrite the paths of its descendants together
+	if current.Path != "" && current.Path != updated.Path {
+		if moveErr := dao.DepartmentDao.Move(ctx, updated, current.Path); moveErr != nil {
+			return nil, moveErr
+		}
+	} else if updateErr := dao.DepartmentDao.Update(ctx, updated); updateErr != nil {
+		return nil, updateErr
 	}
 
-	if department.Name != "" {
-		current.Name = department.Name
+	// Mirror hierarchy into relation tuples
+	if err := helpers.MirrorDepartment(ctx, updated); err != nil {
+		return nil, err
 	}
 
-	current.UpdatedAt = datetime.GetDateTimeString()
+	audit(ctx, au, "department:update", models.AuditTargetDepartment, updated.ID, current, updated)
+	return &updated, nil
+}
 
-	if updateErr := dao.DepartmentDao.Update(*current); updateErr != nil {
-		return nil, updateErr
+// prepareDepartmentUpdate applies the request to the current department, the
+// new parent is given when the request moves the department below another
+// one, and runs every check of the update without writing it, the dry run of
+// an import runs the same checks
+func prepareDepartmentUpdate(current models.Department, request models.UpdateDepartmentRequest, parent *models.Department, au *models.AuthUser) (models.Department, *resterr.RestErr) {
+	// Verify permission --> IsGranted
+	scope := helpers.GetScope("org:department:update", *au)
+	if scope.IsEmpty() {
+		return current, resterr.NewForbiddenError("Permission not granted")
+	}
+	if !scope.Allows(current) {
+		return current, resterr.NewForbiddenError("Department is outside of your scope")
+	}
+
+	if request.Name != "" {
+		current.Name = request.Name
+	}
+	if err := current.Validate(); err != nil {
+		return current, err
 	}
 
+	// Move the department below a new parent
+	if request.ToRoot {
+		// only organization wide managers create root departments
+		if !scope.OrgWide {
+			return current, resterr.NewForbiddenError("Parent department is required")
+		}
+		current.BuildPath(nil)
+	} else if parent != nil {
+		if parent.InSubtree(current.ID) {
+			return current, resterr.NewBadRequestError("Department cannot be moved below its own subtree")
+		}
+		if !scope.Allows(*parent) {
+			return current, resterr.NewForbiddenError("Parent department is outside of your scope")
+		}
+		current.BuildPath(parent)
+	}
+	if current.Path == "" {
+		current.BuildPath(nil)
+	}
+
+	current.UpdatedAt = datetime.GetDateTimeString()
 	return current, nil
 }
 
 // Delete department
-func (s *departmentService) Delete(id string, au *models.AuthUser) *resterr.RestErr { // Verify permission --> IsGranted
-	if !au.IsOrgAdmin {
-		if !helpers.IsGranted("CanDeleteDepartment", *au) {
-			return resterr.NewUnauthorizedError("Permission not granted")
-		}
+func (s *departmentService) Delete(ctx context.Context, id string, au *models.AuthUser) *resterr.RestErr {
+	ctx, span := tracing.Start(ctx, "DepartmentService.Delete")
+	defer span.End()
+
+	// Verify permission --> IsGranted
+	if err := helpers.VerifyDepartmentScope(ctx, "org:department:delete", id, *au); err != nil {
+		return err
+	}
+
+	// Verify the department has no child departments
+	children, err := dao.DepartmentDao.FindChildren(ctx, id, au.Organization)
+	if err != nil {
+		return err
+	}
+	if len(children) > 0 {
+		return resterr.NewBadRequestError("Department has child departments")
+	}
+
+	current, err := dao.DepartmentDao.GetByID(ctx, id, au.Organization)
+	if err != nil {
+		return err
+	}
+
+	if err := dao.DepartmentDao.Delete(ctx, id, au.Organization); err != nil {
+		return err
 	}
+	audit(ctx, au, "department:delete", models.AuditTargetDepartment, id, current, nil)
 
-	return dao.DepartmentDao.Delete(id)
+	// Remove the relation tuples of the department
+	return helpers.RemoveObjectTuples(ctx, au.Organization, helpers.RelationObject(helpers.ObjectTypeDepartment, id))
 }
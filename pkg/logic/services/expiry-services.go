@@ -0,0 +1,167 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"gorabc/pkg/logic/helpers"
+	"gorabc/pkg/models"
+	"gorabc/pkg/repository/dao"
+	"gorabc/pkg/utils/datetime"
+	"gorabc/pkg/utils/encrypt"
+	"gorabc/pkg/utils/resterr"
+	"gorabc/pkg/utils/tracing"
+)
+
+// ExpiryServiceInterface interface
+type ExpiryServiceInterface interface {
+	Sweep(context.Context) (int, *resterr.RestErr)
+	Start(context.Context, time.Duration)
+}
+
+type expiryService struct{}
+
+// ExpiryService variable
+var (
+	ExpiryService ExpiryServiceInterface = &expiryService{}
+)
+
+// Start sweeping the lapsed grants at every interval until the context is done
+func (s *expiryService) Start(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		removed, err := s.Sweep(ctx)
+		if err != nil {
+			log.Printf("Expiry sweep failed: %s", err.Message)
+		}
+		if removed > 0 {
+			log.Printf("Expiry sweep removed %v lapsed grants", removed)
+		}
+	}
+}
+
+// Sweep removes the lapsed roles, permissions and denies of every user and
+// records each removal, a failing user is logged and retried on the next sweep
+func (s *expiryService) Sweep(ctx context.Context) (int, *resterr.RestErr) {
+	ctx, span := tracing.Start(ctx, "ExpiryService.Sweep")
+	defer span.End()
+
+	now := datetime.GetDateTimeString()
+
+	users, err := dao.UserDao.FindLapsedGrants(ctx, now)
+	if err != nil {
+		return 0, err
+	}
+
+	removed := 0
+	failed := 0
+	for i := 0; i < len(users); i++ {
+		count, err := s.sweepUser(ctx, users[i].ID, users[i].Organization, now)
+		if err != nil {
+			log.Printf("Expiry sweep of user %s failed: %s", users[i].ID, err.Message)
+			failed++
+			continue
+		}
+		removed += count
+	}
+	if failed > 0 {
+		return removed, resterr.NewInternalServerError(fmt.Sprintf("%v of %v users could not be swept", failed, len(users)))
+	}
+	return removed, nil
+}
+
+// sweepUser removes the lapsed grants of a single user
+func (s *expiryService) sweepUser(ctx context.Context, id string, org string, now string) (int, *resterr.RestErr) {
+	user, err := dao.UserDao.GetByID(ctx, id, org)
+	if err != nil {
+		return 0, err
+	}
+
+	events := []models.GrantEvent{}
+	newEvent := func(grantType string, name string, scope string, source string, validFrom string, validUntil string) models.GrantEvent {
+		return models.GrantEvent{
+			Organization: user.Organization,
+			UserID:       user.ID,
+			Type:         grantType,
+			Action:       models.GrantActionExpired,
+			Name:         name,
+			Scope:        scope,
+			Source:       source,
+			ValidFrom:    validFrom,
+			ValidUntil:   validUntil,
+			Actor:        models.ActorSystem,
+		}
+	}
+
+	roles := []models.UserRole{}
+	for i := 0; i < len(user.Roles); i++ {
+		if user.Roles[i].IsLapsed(now) {
+			role := user.Roles[i]
+			events = append(events, newEvent(models.GrantTypeRole, role.RoleID, role.Scope, "", role.ValidFrom, role.ValidUntil))
+			continue
+		}
+		roles = append(roles, user.Roles[i])
+	}
+
+	permissions := []models.Permission{}
+	for i := 0; i < len(user.Permissions); i++ {
+		if user.Permissions[i].IsLapsed(now) {
+			p := user.Permissions[i]
+			events = append(events, newEvent(models.GrantTypePermission, p.Name, p.Scope, p.Source, p.ValidFrom, p.ValidUntil))
+			continue
+		}
+		permissions = append(permissions, user.Permissions[i])
+	}
+
+	denies := []models.Permission{}
+	for i := 0; i < len(user.Denies); i++ {
+		if user.Denies[i].IsLapsed(now) {
+			p := user.Denies[i]
+			events = append(events, newEvent(models.GrantTypeDeny, p.Name, p.Scope, p.Source, p.ValidFrom, p.ValidUntil))
+			continue
+		}
+		denies = append(denies, user.Denies[i])
+	}
+
+	if len(events) == 0 {
+		return 0, nil
+	}
+	before := *user
+
+	user.Roles = roles
+	user.Permissions = permissions
+	user.Denies = denies
+	user.UpdatedAt = datetime.GetDateTimeString()
+
+	if err := dao.UserDao.Update(ctx, *user); err != nil {
+		return 0, err
+	}
+
+	// Mirror memberships into relation tuples
+	if err := helpers.MirrorUser(ctx, *user); err != nil {
+		return 0, err
+	}
+
+	system := &models.AuthUser{ID: models.ActorSystem, Organization: user.Organization}
+	audit(ctx, system, "user:grants_expire", models.AuditTargetUser, user.ID, before, user)
+
+	for i := 0; i < len(events); i++ {
+		events[i].ID = "GEV" + encrypt.GenerateID(18)
+		events[i].CreatedAt = datetime.GetDateTimeString()
+		if _, err := dao.GrantEventDao.Create(ctx, events[i]); err != nil {
+			return 0, err
+		}
+	}
+
+	return len(events), nil
+}
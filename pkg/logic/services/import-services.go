@@ -0,0 +1,743 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"strings"
+
+	"gorabc/pkg/logic/helpers"
+	"gorabc/pkg/models"
+	"gorabc/pkg/repository/dao"
+	"gorabc/pkg/utils/resterr"
+	"gorabc/pkg/utils/tracing"
+)
+
+// ImportServiceInterface interface
+type ImportServiceInterface interface {
+	Import(context.Context, string, []byte, string, bool, *models.AuthUser) (*models.ImportReport, *resterr.RestErr)
+	Export(context.Context, string, string, *models.AuthUser) ([]byte, *resterr.RestErr)
+}
+
+type importService struct{}
+
+// ImportService variable
+var (
+	ImportService ImportServiceInterface = &importService{}
+)
+
+// Import a CSV or JSON file of users, departments or roles
+// Rows are upserted by their key, rows with errors are reported and skipped.
+// A dry run validates every row and reports the planned actions without writing.
+func (s *importService) Import(ctx context.Context, kind string, data []byte, format string, dryRun bool, au *models.AuthUser) (*models.ImportReport, *resterr.RestErr) {
+	ctx, span := tracing.Start(ctx, "ImportService.Import")
+	defer span.End()
+
+	if format != models.FormatJSON && format != models.FormatCSV {
+		return nil, resterr.NewBadRequestError("Invalid import format: " + format)
+	}
+
+	switch kind {
+	case models.ImportDepartments:
+		rows, err := helpers.ParseDepartmentRows(data, format)
+		if err != nil {
+			return nil, err
+		}
+		return s.importDepartments(ctx, rows, dryRun, au)
+	case models.ImportRoles:
+		rows, err := helpers.ParseRoleRows(data, format)
+		if err != nil {
+			return nil, err
+		}
+		return s.importRoles(ctx, rows, dryRun, au)
+	case models.ImportUsers:
+		rows, err := helpers.ParseUserRows(data, format)
+		if err != nil {
+			return nil, err
+		}
+		return s.importUsers(ctx, rows, dryRun, au)
+	}
+	return nil, resterr.NewNotFoundError("Invalid import kind: " + kind)
+}
+
+// Export users, departments or roles in the import format
+// Passwords are never exported.
+func (s *importService) Export(ctx context.Context, kind string, format string, au *models.AuthUser) ([]byte, *resterr.RestErr) {
+	ctx, span := tracing.Start(ctx, "ImportService.Export")
+	defer span.End()
+
+	if format != models.FormatJSON && format != models.FormatCSV {
+		return nil, resterr.NewBadRequestError("Invalid export format: " + format)
+	}
+
+	switch kind {
+	case models.ImportDepartments:
+		rows, err := s.exportDepartments(ctx, au)
+		if err != nil {
+			return nil, err
+		}
+		if format == models.FormatCSV {
+			return helpers.WriteDepartmentRows(rows)
+		}
+		return encodeRows(rows)
+	case models.ImportRoles:
+		rows, err := s.exportRoles(ctx, au)
+		if err != nil {
+			return nil, err
+		}
+		if format == models.FormatCSV {
+			return helpers.WriteRoleRows(rows)
+		}
+		return encodeRows(rows)
+	case models.ImportUsers:
+		rows, err := s.exportUsers(ctx, au)
+		if err != nil {
+			return nil, err
+		}
+		if format == models.FormatCSV {
+			return helpers.WriteUserRows(rows)
+		}
+		return encodeRows(rows)
+	}
+	return nil, resterr.NewNotFoundError("Invalid export kind: " + kind)
+}
+
+// importDepartments upserts departments by name
+// Parents are resolved against the existing departments and the rows above.
+func (s *importService) importDepartments(ctx context.Context, rows []models.DepartmentImportRow, dryRun bool, au *models.AuthUser) (*models.ImportReport, *resterr.RestErr) {
+	if helpers.GetScope("org:department:create", *au).IsEmpty() && helpers.GetScope("org:department:update", *au).IsEmpty() {
+		return nil, resterr.NewForbiddenError("Permission not granted")
+	}
+
+	departments, err := dao.DepartmentDao.FindAll(ctx, au.Organization)
+	if err != nil {
+		return nil, err
+	}
+	names := newNameIndex()
+	byID := map[string]models.Department{}
+	for i := 0; i < len(departments); i++ {
+		names.add(departments[i].Name, departments[i].ID)
+		byID[departments[i].ID] = departments[i]
+	}
+
+	report := &models.ImportReport{Kind: models.ImportDepartments, DryRun: dryRun, Rows: []models.ImportRowResult{}}
+	seen := map[string]bool{}
+	for i := 0; i < len(rows); i++ {
+		row := rows[i]
+		result := models.ImportRowResult{Row: i + 1, Key: strings.TrimSpace(row.Name)}
+		if result.Key == "" {
+			report.Add(failRow(result, "Department name is required"))
+			continue
+		}
+
+		// Resolve the parent
+		parentID := ""
+		if row.Parent != "" {
+			id, msg := names.resolve(row.Parent, "parent department")
+			if msg != "" {
+				report.Add(failRow(result, msg))
+				continue
+			}
+			parentID = id
+		}
+
+		if seen[strings.ToLower(result.Key)] {
+			report.Add(failRow(result, "Duplicate department name in file"))
+			continue
+		}
+		seen[strings.ToLower(result.Key)] = true
+
+		id, msg := names.resolve(result.Key, "department")
+		if names.count(result.Key) > 1 {
+			report.Add(failRow(result, msg))
+			continue
+		}
+
+		// Update an existing department
+		if current, ok := byID[id]; ok {
+			result.ID = current.ID
+			if parentID == "" || parentID == current.Parent {
+				result.Action = models.ImportActionUnchanged
+				report.Add(result)
+				continue
+			}
+			result.Action = models.ImportActionUpdate
+			if parentID == current.ID {
+				report.Add(failRow(result, "Department cannot be its own parent"))
+				continue
+			}
+			update := models.UpdateDepartmentRequest{Parent: parentID}
+			if dryRun {
+				parent := byID[parentID]
+				if _, err := prepareDepartmentUpdate(current, update, &parent, au); err != nil {
+					report.Add(failRow(result, err.Message))
+					continue
+				}
+				report.Add(result)
+				continue
+			}
+			if _, err := DepartmentService.Update(ctx, current.ID, update, au); err != nil {
+				report.Add(failRow(result, err.Message))
+				continue
+			}
+			report.Add(result)
+			continue
+		}
+
+		// Plan or create a new department
+		result.Action = models.ImportActionCreate
+		if dryRun {
+			var parent *models.Department
+			if planned, ok := byID[parentID]; ok {
+				parent = &planned
+			}
+			dept, err := prepareDepartmentCreate(models.Department{Name: result.Key, Parent: parentID}, parent, au)
+			if err != nil {
+				report.Add(failRow(result, err.Message))
+				continue
+			}
+			// planned departments can be parents of the rows below
+			names.add(result.Key, dept.ID)
+			byID[dept.ID] = dept
+			report.Add(result)
+			continue
+		}
+		dept, err := DepartmentService.Create(ctx, models.Department{Name: result.Key, Parent: parentID}, au)
+		if err != nil {
+			report.Add(failRow(result, err.Message))
+			continue
+		}
+		result.ID = dept.ID
+		names.add(dept.Name, dept.ID)
+		byID[dept.ID] = *dept
+		report.Add(result)
+	}
+
+	return report, nil
+}
+
+// importRoles upserts roles by name within the organization
+func (s *importService) importRoles(ctx context.Context, rows []models.RoleImportRow, dryRun bool, au *models.AuthUser) (*models.ImportReport, *resterr.RestErr) {
+	if helpers.GetScope("org:role:create", *au).IsEmpty() && helpers.GetScope("org:role:update", *au).IsEmpty() {
+		return nil, resterr.NewForbiddenError("Permission not granted")
+	}
+
+	departments, err := dao.DepartmentDao.FindAll(ctx, au.Organization)
+	if err != nil {
+		return nil, err
+	}
+	deptNames := newNameIndex()
+	for i := 0; i < len(departments); i++ {
+		deptNames.add(departments[i].Name, departments[i].ID)
+	}
+
+	roles, err := dao.RoleDao.FindAll(ctx, au.Organization)
+	if err != nil {
+		return nil, err
+	}
+	roleNames := newNameIndex()
+	roleByID := map[string]models.Role{}
+	for i := 0; i < len(roles); i++ {
+		roleNames.add(roles[i].Name, roles[i].ID)
+		roleByID[roles[i].ID] = roles[i]
+	}
+	rolePerms, err := rolePermissionIndex(ctx, au.Organization)
+	if err != nil {
+		return nil, err
+	}
+
+	permList, err := dao.PermissionDao.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &models.ImportReport{Kind: models.ImportRoles, DryRun: dryRun, Rows: []models.ImportRowResult{}}
+	seen := map[string]bool{}
+	for i := 0; i < len(rows); i++ {
+		row := rows[i]
+		result := models.ImportRowResult{Row: i + 1, Key: strings.TrimSpace(row.Name)}
+		errors := []string{}
+		if result.Key == "" {
+			errors = append(errors, "Role name is required")
+		}
+
+		// Resolve the department
+		deptID := ""
+		if row.Department != "" {
+			id, msg := deptNames.resolve(row.Department, "department")
+			if msg != "" {
+				errors = append(errors, msg)
+			}
+			deptID = id
+		}
+
+		// Validate every permission against the catalogue
+		requested := []models.Permission{}
+		for j := 0; j < len(row.Permissions); j++ {
+			permission := models.Permission{Name: row.Permissions[j]}
+			if len(helpers.ValidatePermissions([]models.Permission{permission}, permList)) == 0 {
+				errors = append(errors, "Invalid permission: "+row.Permissions[j])
+				continue
+			}
+			requested = append(requested, permission)
+		}
+
+		if len(errors) > 0 {
+			report.Add(failRow(result, errors...))
+			continue
+		}
+
+		if seen[strings.ToLower(result.Key)] {
+			report.Add(failRow(result, "Duplicate role name in file"))
+			continue
+		}
+		seen[strings.ToLower(result.Key)] = true
+
+		roleID, msg := roleNames.resolve(result.Key, "role")
+		if roleNames.count(result.Key) > 1 {
+			report.Add(failRow(result, msg))
+			continue
+		}
+
+		// Update an existing role
+		if current, ok := roleByID[roleID]; ok {
+			result.ID = current.ID
+			if deptID != "" && deptID != current.Department {
+				report.Add(failRow(result, "Role cannot be moved to another department"))
+				continue
+			}
+			permissions := normalizedNames(helpers.ValidatePermissions(requested, permList))
+			if len(requested) == 0 || equalNames(permissions, rolePerms[current.ID]) {
+				result.Action = models.ImportActionUnchanged
+				report.Add(result)
+				continue
+			}
+			result.Action = models.ImportActionUpdate
+			update := models.UpdateRoleRequest{Permissions: requested}
+			if dryRun {
+				if err := update.Validate(); err != nil {
+					report.Add(failRow(result, err.Message))
+					continue
+				}
+				if _, _, err := prepareRoleUpdate(ctx, current.ID, update, au); err != nil {
+					report.Add(failRow(result, err.Message))
+					continue
+				}
+				report.Add(result)
+				continue
+			}
+			if _, err := RoleService.Update(ctx, current.ID, update, au); err != nil {
+				report.Add(failRow(result, err.Message))
+				continue
+			}
+			report.Add(result)
+			continue
+		}
+
+		// Plan or create a new role
+		result.Action = models.ImportActionCreate
+		if deptID == "" {
+			report.Add(failRow(result, "Department is required"))
+			continue
+		}
+		create := models.CreateRoleRequest{Name: result.Key, Department: deptID, Permissions: requested}
+		if dryRun {
+			if err := create.Validate(); err != nil {
+				report.Add(failRow(result, err.Message))
+				continue
+			}
+			if _, err := prepareRoleCreate(ctx, create.Role(), au); err != nil {
+				report.Add(failRow(result, err.Message))
+				continue
+			}
+			roleNames.add(result.Key, plannedID(result.Key))
+			report.Add(result)
+			continue
+		}
+		role, err := RoleService.Create(ctx, create, au)
+		if err != nil {
+			report.Add(failRow(result, err.Message))
+			continue
+		}
+		result.ID = role.ID
+		roleNames.add(role.Name, role.ID)
+		roleByID[role.ID] = *role
+		rolePerms[role.ID] = normalizedNames(role.Permissions)
+		report.Add(result)
+	}
+
+	return report, nil
+}
+
+// importUsers upserts users by email
+// Roles and departments are resolved as on the user API: the departments of
+// the roles replace the requested departments when roles are given.
+// Passwords are only used for new users.
+func (s *importService) importUsers(ctx context.Context, rows []models.UserImportRow, dryRun bool, au *models.AuthUser) (*models.ImportReport, *resterr.RestErr) {
+	if helpers.GetScope("org:user:create", *au).IsEmpty() && helpers.GetScope("org:user:update", *au).IsEmpty() {
+		return nil, resterr.NewForbiddenError("Permission not granted")
+	}
+
+	departments, err := dao.DepartmentDao.FindAll(ctx, au.Organization)
+	if err != nil {
+		return nil, err
+	}
+	deptNames := newNameIndex()
+	for i := 0; i < len(departments); i++ {
+		deptNames.add(departments[i].Name, departments[i].ID)
+	}
+
+	roles, err := dao.RoleDao.FindAll(ctx, au.Organization)
+	if err != nil {
+		return nil, err
+	}
+	roleNames := newNameIndex()
+	for i := 0; i < len(roles); i++ {
+		roleNames.add(roles[i].Name, roles[i].ID)
+	}
+
+	report := &models.ImportReport{Kind: models.ImportUsers, DryRun: dryRun, Rows: []models.ImportRowResult{}}
+	seen := map[string]bool{}
+	for i := 0; i < len(rows); i++ {
+		row := rows[i]
+		email := strings.TrimSpace(strings.ToLower(row.Email))
+		result := models.ImportRowResult{Row: i + 1, Key: email}
+		errors := []string{}
+		if email == "" {
+			errors = append(errors, "Invalid Email address")
+		} else if seen[email] {
+			errors = append(errors, "Duplicate email in file")
+		}
+		seen[email] = true
+
+		// Resolve roles and departments
+		request := models.User{
+			Firstname:    strings.TrimSpace(row.Firstname),
+			Lastname:     strings.TrimSpace(row.Lastname),
+			Email:        email,
+			Password:     row.Password,
+			Organization: au.Organization,
+		}
+		for j := 0; j < len(row.Roles); j++ {
+			id, msg := roleNames.resolve(row.Roles[j], "role")
+			if msg != "" {
+				errors = append(errors, msg)
+				continue
+			}
+			request.Roles = append(request.Roles, models.UserRole{RoleID: id})
+		}
+		for j := 0; j < len(row.Departments); j++ {
+			id, msg := deptNames.resolve(row.Departments[j], "department")
+			if msg != "" {
+				errors = append(errors, msg)
+				continue
+			}
+			request.Departments = append(request.Departments, models.UserDepartment{DepartmentID: id})
+		}
+
+		if len(errors) > 0 {
+			report.Add(failRow(result, errors...))
+			continue
+		}
+
+		current, lookupErr := dao.UserDao.GetByEmail(ctx, email)
+		if lookupErr == nil && current.Organization != au.Organization {
+			report.Add(failRow(result, "Email already registered"))
+			continue
+		}
+
+		// Update an existing user
+		if lookupErr == nil {
+			result.ID = current.ID
+			request.ID = current.ID
+			if userUnchanged(request, *current) {
+				result.Action = models.ImportActionUnchanged
+				report.Add(result)
+				continue
+			}
+			result.Action = models.ImportActionUpdate
+			update := models.UpdateUserRequest{
+				Firstname:   request.Firstname,
+				Lastname:    request.Lastname,
+				Email:       request.Email,
+				Departments: request.Departments,
+				Roles:       request.Roles,
+			}
+			if dryRun {
+				if err := s.verifyUpdate(ctx, current.ID, update, au); err != nil {
+					report.Add(failRow(result, err.Message))
+					continue
+				}
+				report.Add(result)
+				continue
+			}
+			if _, err := UserService.Update(ctx, current.ID, update, au); err != nil {
+				report.Add(failRow(result, err.Message))
+				continue
+			}
+			report.Add(result)
+			continue
+		}
+
+		// Plan or create a new user
+		result.Action = models.ImportActionCreate
+		create := models.CreateUserRequest{
+			Firstname:   request.Firstname,
+			Lastname:    request.Lastname,
+			Email:       request.Email,
+			Password:    request.Password,
+			Departments: request.Departments,
+			Roles:       request.Roles,
+		}
+		if dryRun {
+			if err := create.Validate(); err != nil {
+				report.Add(failRow(result, err.Message))
+				continue
+			}
+			if _, err := prepareCreate(ctx, create.User(), models.StatusActive, au); err != nil {
+				report.Add(failRow(result, err.Message))
+				continue
+			}
+			report.Add(result)
+			continue
+		}
+		user, err := UserService.Create(ctx, create, au)
+		if err != nil {
+			report.Add(failRow(result, err.Message))
+			continue
+		}
+		result.ID = user.ID
+		report.Add(result)
+	}
+
+	return report, nil
+}
+
+// verifyUpdate runs the checks of the user API on the update without writing
+func (s *importService) verifyUpdate(ctx context.Context, id string, update models.UpdateUserRequest, au *models.AuthUser) *resterr.RestErr {
+	if err := update.Validate(); err != nil {
+		return err
+	}
+	_, _, err := prepareUpdate(ctx, id, update, au)
+	return err
+}
+
+// exportDepartments with their parent names
+func (s *importService) exportDepartments(ctx context.Context, au *models.AuthUser) ([]models.DepartmentImportRow, *resterr.RestErr) {
+	departments, err := DepartmentService.FindAll(ctx, au)
+	if err != nil {
+		return nil, err
+	}
+	index, err := helpers.GetDepartmentIndex(ctx, au.Organization)
+	if err != nil {
+		return nil, err
+	}
+
+	// parents first so that the export can be imported as is
+	sort.SliceStable(departments, func(i, j int) bool {
+		return strings.Count(departments[i].Path, "/") < strings.Count(departments[j].Path, "/")
+	})
+
+	rows := []models.DepartmentImportRow{}
+	for i := 0; i < len(departments); i++ {
+		rows = append(rows, models.DepartmentImportRow{
+			Name:   departments[i].Name,
+			Parent: index[departments[i].Parent].Name,
+		})
+	}
+	return rows, nil
+}
+
+// exportRoles with their department names and permissions
+func (s *importService) exportRoles(ctx context.Context, au *models.AuthUser) ([]models.RoleImportRow, *resterr.RestErr) {
+	roles, err := RoleService.FindAll(ctx, au)
+	if err != nil {
+		return nil, err
+	}
+	index, err := helpers.GetDepartmentIndex(ctx, au.Organization)
+	if err != nil {
+		return nil, err
+	}
+	rolePerms, err := rolePermissionIndex(ctx, au.Organization)
+	if err != nil {
+		return nil, err
+	}
+
+	rows := []models.RoleImportRow{}
+	for i := 0; i < len(roles); i++ {
+		rows = append(rows, models.RoleImportRow{
+			Name:        roles[i].Name,
+			Department:  index[roles[i].Department].Name,
+			Permissions: rolePerms[roles[i].ID],
+		})
+	}
+	return rows, nil
+}
+
+// exportUsers with their department and role names
+func (s *importService) exportUsers(ctx context.Context, au *models.AuthUser) ([]models.UserImportRow, *resterr.RestErr) {
+	users, err := UserService.FindAll(ctx, au)
+	if err != nil {
+		return nil, err
+	}
+	index, err := helpers.GetDepartmentIndex(ctx, au.Organization)
+	if err != nil {
+		return nil, err
+	}
+
+	rows := []models.UserImportRow{}
+	for i := 0; i < len(users); i++ {
+		row := models.UserImportRow{
+			Email:       users[i].Email,
+			Firstname:   users[i].Firstname,
+			Lastname:    users[i].Lastname,
+			Departments: []string{},
+			Roles:       []string{},
+		}
+		for j := 0; j < len(users[i].Departments); j++ {
+			row.Departments = append(row.Departments, index[users[i].Departments[j].DepartmentID].Name)
+		}
+		for j := 0; j < len(users[i].Roles); j++ {
+			row.Roles = append(row.Roles, users[i].Roles[j].RoleName)
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// nameIndex resolves records by id or case insensitive name
+type nameIndex struct {
+	ids   map[string]bool
+	names map[string][]string
+}
+
+func newNameIndex() nameIndex {
+	return nameIndex{ids: map[string]bool{}, names: map[string][]string{}}
+}
+
+func (index nameIndex) add(name string, id string) {
+	index.ids[id] = true
+	key := strings.ToLower(strings.TrimSpace(name))
+	index.names[key] = append(index.names[key], id)
+}
+
+func (index nameIndex) count(name string) int {
+	return len(index.names[strings.ToLower(strings.TrimSpace(name))])
+}
+
+// resolve returns the id of the record or an error message
+func (index nameIndex) resolve(value string, label string) (string, string) {
+	value = strings.TrimSpace(value)
+	if index.ids[value] {
+		return value, ""
+	}
+	ids := index.names[strings.ToLower(value)]
+	switch len(ids) {
+	case 0:
+		return "", "Unknown " + label + ": " + value
+	case 1:
+		return ids[0], ""
+	}
+	return "", "Ambiguous " + label + " name: " + value
+}
+
+// plannedID stands for a record a dry run would create
+func plannedID(name string) string {
+	return "planned:" + strings.ToLower(name)
+}
+
+// failRow marks the row as failed
+func failRow(result models.ImportRowResult, errors ...string) models.ImportRowResult {
+	result.Action = models.ImportActionError
+	result.Errors = append(result.Errors, errors...)
+	return result
+}
+
+// rolePermissionIndex maps role ids to their sorted permission names
+func rolePermissionIndex(ctx context.Context, org string) (map[string][]string, *resterr.RestErr) {
+	rp, err := dao.RoleDao.FindAllRolePermissions(ctx, org)
+	if err != nil {
+		return nil, err
+	}
+	index := map[string][]string{}
+	for i := 0; i < len(rp); i++ {
+		index[rp[i].RoleID] = normalizedNames(rp[i].Permissions)
+	}
+	return index, nil
+}
+
+// normalizedNames of permissions, sorted and without duplicates
+func normalizedNames(permissions []models.Permission) []string {
+	encountered := map[string]bool{}
+	names := []string{}
+	for i := 0; i < len(permissions); i++ {
+		if !encountered[permissions[i].Name] {
+			encountered[permissions[i].Name] = true
+			names = append(names, permissions[i].Name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// equalNames compares two sorted lists
+func equalNames(a []string, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := 0; i < len(a); i++ {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// userUnchanged reports whether applying the request would leave the user as is
+func userUnchanged(request models.User, current models.User) bool {
+	if request.Firstname != "" && request.Firstname != current.Firstname {
+		return false
+	}
+	if request.Lastname != "" && request.Lastname != current.Lastname {
+		return false
+	}
+	if len(request.Roles) > 0 {
+		requested, held := []string{}, []string{}
+		for i := 0; i < len(request.Roles); i++ {
+			requested = append(requested, request.Roles[i].RoleID)
+		}
+		for i := 0; i < len(current.Roles); i++ {
+			held = append(held, current.Roles[i].RoleID)
+		}
+		return equalNames(uniqueSorted(requested), uniqueSorted(held))
+	}
+	if len(request.Departments) > 0 {
+		requested, held := []string{}, []string{}
+		for i := 0; i < len(request.Departments); i++ {
+			requested = append(requested, request.Departments[i].DepartmentID)
+		}
+		for i := 0; i < len(current.Departments); i++ {
+			held = append(held, current.Departments[i].DepartmentID)
+		}
+		return equalNames(uniqueSorted(requested), uniqueSorted(held))
+	}
+	return true
+}
+
+// uniqueSorted list of strings
+func uniqueSorted(list []string) []string {
+	permissions := []models.Permission{}
+	for i := 0; i < len(list); i++ {
+		permissions = append(permissions, models.Permission{Name: list[i]})
+	}
+	return normalizedNames(permissions)
+}
+
+// encodeRows as a JSON file
+func encodeRows(rows interface{}) ([]byte, *resterr.RestErr) {
+	data, err := json.MarshalIndent(rows, "", "  ")
+	if err != nil {
+		return nil, resterr.NewInternalServerError(err.Error())
+	}
+	return data, nil
+}
@@ -0,0 +1,538 @@
+package services
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"gorabc/pkg/logic/helpers"
+	"gorabc/pkg/models"
+	"gorabc/pkg/repository/dao"
+	"gorabc/pkg/utils/datetime"
+	"gorabc/pkg/utils/encrypt"
+	"gorabc/pkg/utils/resterr"
+	"gorabc/pkg/utils/tracing"
+)
+
+// ManifestServiceInterface interface
+type ManifestServiceInterface interface {
+	Plan(context.Context, models.Manifest, bool, *models.AuthUser) (*models.ManifestPlan, *resterr.RestErr)
+	Apply(context.Context, models.Manifest, bool, *models.AuthUser) (*models.ManifestPlan, *resterr.RestErr)
+}
+
+type manifestService struct{}
+
+// ManifestService variable
+var (
+	ManifestService ManifestServiceInterface = &manifestService{}
+)
+
+// Plan the changes converging the organization to the manifest
+// Departments and roles missing from the manifest are deleted when pruning,
+// and listed as unmanaged otherwise.
+func (s *manifestService) Plan(ctx context.Context, manifest models.Manifest, prune bool, au *models.AuthUser) (*models.ManifestPlan, *resterr.RestErr) {
+	ctx, span := tracing.Start(ctx, "ManifestService.Plan")
+	defer span.End()
+
+	// Verify permission --> IsGranted
+	if !helpers.IsGranted("org:manifest:plan", *au) {
+		return nil, resterr.NewForbiddenError("Permission not granted")
+	}
+
+	return s.plan(ctx, manifest, prune, au.Organization)
+}
+
+// Apply the manifest, every change of the plan is written in one transaction
+func (s *manifestService) Apply(ctx context.Context, manifest models.Manifest, prune bool, au *models.AuthUser) (*models.ManifestPlan, *resterr.RestErr) {
+	ctx, span := tracing.Start(ctx, "ManifestService.Apply")
+	defer span.End()
+
+	// Verify permission --> IsGranted
+	if !helpers.IsGranted("org:manifest:apply", *au) {
+		return nil, resterr.NewForbiddenError("Permission not granted")
+	}
+
+	plan, err := s.plan(ctx, manifest, prune, au.Organization)
+	if err != nil {
+		return nil, err
+	}
+	if len(plan.Errors) > 0 {
+		return plan, manifestError(plan.Errors)
+	}
+	if plan.IsEmpty() {
+		return plan, nil
+	}
+
+	// Verify the caller holds what the roles grant
+	if err := s.verifyGrants(ctx, *plan, au); err != nil {
+		return nil, err
+	}
+
+	if err := dao.ManifestDao.Apply(ctx, *plan); err != nil {
+		return nil, err
+	}
+
+	// Mirror the hierarchy and the roles into relation tuples
+	departments := append(plan.CreateDepartments, plan.UpdateDepartments...)
+	for i := 0; i < len(departments); i++ {
+		if err := helpers.MirrorDepartment(ctx, departments[i]); err != nil {
+			return nil, err
+		}
+	}
+	for i := 0; i < len(plan.CreateRoles); i++ {
+		if err := helpers.MirrorRole(ctx, plan.CreateRoles[i]); err != nil {
+			return nil, err
+		}
+	}
+	for i := 0; i < len(plan.DeleteRoles); i++ {
+		if err := helpers.RemoveObjectTuples(ctx, plan.Organization, helpers.RelationObject(helpers.ObjectTypeRole, plan.DeleteRoles[i])); err != nil {
+			return nil, err
+		}
+	}
+	for i := 0; i < len(plan.DeleteDepartments); i++ {
+		if err := helpers.RemoveObjectTuples(ctx, plan.Organization, helpers.RelationObject(helpers.ObjectTypeDepartment, plan.DeleteDepartments[i])); err != nil {
+			return nil, err
+		}
+	}
+
+	// The changes of the plan describe each applied change
+	audit(ctx, au, "manifest:apply", models.AuditTargetManifest, plan.Organization, nil, plan)
+	return plan, nil
+}
+
+// verifyGrants verifies that the caller holds what the created and updated
+// roles grant their holders
+func (s *manifestService) verifyGrants(ctx context.Context, plan models.ManifestPlan, au *models.AuthUser) *resterr.RestErr {
+	if au.IsSuperuser {
+		return nil
+	}
+	rolePermissions, err := dao.RoleDao.FindAllRolePermissions(ctx, plan.Organization)
+	if err != nil {
+		return err
+	}
+	current := map[string]models.Role{}
+	for i := 0; i < len(rolePermissions); i++ {
+		current[rolePermissions[i].RoleID] = models.Role{
+			Permissions: rolePermissions[i].Permissions,
+			Denies:      rolePermissions[i].Denies,
+		}
+	}
+	index, err := helpers.GetDepartmentIndex(ctx, plan.Organization)
+	if err != nil {
+		return err
+	}
+
+	roles := append(append(models.Roles{}, plan.CreateRoles...), plan.UpdateRoles...)
+	for i := 0; i < len(roles); i++ {
+		if err := helpers.VerifyGrants(helpers.RoleGrants(current[roles[i].ID], roles[i]), *au, index); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// plan diffs the manifest against the departments, roles and role permissions of the organization
+func (s *manifestService) plan(ctx context.Context, manifest models.Manifest, prune bool, org string) (*models.ManifestPlan, *resterr.RestErr) {
+	plan := &models.ManifestPlan{Organization: org, Prune: prune, Changes: []models.ManifestChange{}}
+	now := datetime.GetDateTimeString()
+
+	departments, err := dao.DepartmentDao.FindAll(ctx, org)
+	if err != nil {
+		return nil, err
+	}
+	roles, err := dao.RoleDao.FindAll(ctx, org)
+	if err != nil {
+		return nil, err
+	}
+	rolePermissions, err := dao.RoleDao.FindAllRolePermissions(ctx, org)
+	if err != nil {
+		return nil, err
+	}
+	users, err := dao.UserDao.FindAll(ctx, org)
+	if err != nil {
+		return nil, err
+	}
+	permList, err := dao.PermissionDao.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	existing := map[string][]models.Department{}
+	deptByID := map[string]models.Department{}
+	for i := 0; i < len(departments); i++ {
+		key := strings.ToLower(departments[i].Name)
+		existing[key] = append(existing[key], departments[i])
+		deptByID[departments[i].ID] = departments[i]
+	}
+
+	// Departments of the manifest, matched with the existing ones by name
+	managed := map[string]string{}
+	specs := []models.ManifestDepartmentSpec{}
+	for i := 0; i < len(manifest.Departments); i++ {
+		spec := manifest.Departments[i]
+		spec.Name = strings.TrimSpace(spec.Name)
+		key := strings.ToLower(spec.Name)
+		if spec.Name == "" {
+			plan.Errors = append(plan.Errors, "Department name is required")
+			continue
+		}
+		if _, ok := managed[key]; ok {
+			plan.Errors = append(plan.Errors, "Duplicate department: "+spec.Name)
+			continue
+		}
+		switch len(existing[key]) {
+		case 0:
+			managed[key] = "DEPT" + encrypt.GenerateID(17)
+		case 1:
+			managed[key] = existing[key][0].ID
+		default:
+			plan.Errors = append(plan.Errors, "Ambiguous existing department: "+spec.Name)
+			continue
+		}
+		specs = append(specs, spec)
+	}
+
+	// resolveDepartment to a department kept after the apply
+	resolveDepartment := func(name string) (string, string) {
+		key := strings.ToLower(strings.TrimSpace(name))
+		if id, ok := managed[key]; ok {
+			return id, ""
+		}
+		switch len(existing[key]) {
+		case 0:
+			return "", "Unknown department: " + name
+		case 1:
+			if prune {
+				return "", "Department is not in the manifest and would be pruned: " + name
+			}
+			return existing[key][0].ID, ""
+		}
+		return "", "Ambiguous existing department: " + name
+	}
+
+	// Final parent of every department
+	parents := map[string]string{}
+	names := map[string]string{}
+	for i := 0; i < len(departments); i++ {
+		parents[departments[i].ID] = departments[i].Parent
+		names[departments[i].ID] = departments[i].Name
+	}
+	parentName := func(id string) string {
+		if id == "" {
+			return "(none)"
+		}
+		return names[id]
+	}
+	deleted := map[string]bool{}
+	if prune {
+		for i := 0; i < len(departments); i++ {
+			if _, ok := managed[strings.ToLower(departments[i].Name)]; !ok {
+				deleted[departments[i].ID] = true
+			}
+		}
+	}
+	for i := 0; i < len(specs); i++ {
+		id := managed[strings.ToLower(specs[i].Name)]
+		names[id] = specs[i].Name
+		parents[id] = ""
+		if specs[i].Parent == "" {
+			continue
+		}
+		parentID, msg := resolveDepartment(specs[i].Parent)
+		if msg != "" {
+			plan.Errors = append(plan.Errors, specs[i].Name+": "+msg)
+			continue
+		}
+		parents[id] = parentID
+	}
+	ids := []string{}
+	for id := range parents {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		if !deleted[id] && deleted[parents[id]] {
+			plan.Errors = append(plan.Errors, names[id]+": parent department would be pruned")
+		}
+	}
+
+	// Materialized paths of the final hierarchy
+	paths := map[string]string{}
+	var buildPath func(id string, depth int) string
+	buildPath = func(id string, depth int) string {
+		if path, ok := paths[id]; ok {
+			return path
+		}
+		if depth > len(parents) {
+			return ""
+		}
+		path := "/" + id + "/"
+		if parents[id] != "" {
+			parentPath := buildPath(parents[id], depth+1)
+			if parentPath == "" {
+				return ""
+			}
+			path = parentPath + id + "/"
+		}
+		paths[id] = path
+		return path
+	}
+	for _, id := range ids {
+		if buildPath(id, 0) == "" {
+			plan.Errors = append(plan.Errors, names[id]+": department hierarchy has a cycle")
+		}
+	}
+	if len(plan.Errors) > 0 {
+		return plan, nil
+	}
+
+	// Department changes
+	for i := 0; i < len(specs); i++ {
+		id := managed[strings.ToLower(specs[i].Name)]
+		current, ok := deptByID[id]
+		if !ok {
+			plan.CreateDepartments = append(plan.CreateDepartments, models.Department{
+				ID:           id,
+				Organization: org,
+				Name:         specs[i].Name,
+				Parent:       parents[id],
+				Path:         paths[id],
+				Status:       models.StatusActive,
+				IsActive:     true,
+				CreatedAt:    now,
+				UpdatedAt:    now,
+			})
+			plan.Changes = append(plan.Changes, models.ManifestChange{
+				Kind:    models.ManifestDepartment,
+				Action:  models.ManifestCreate,
+				Key:     specs[i].Name,
+				ID:      id,
+				Details: []string{"parent: " + parentName(parents[id])},
+			})
+			continue
+		}
+		if current.Parent != parents[id] {
+			plan.Changes = append(plan.Changes, models.ManifestChange{
+				Kind:    models.ManifestDepartment,
+				Action:  models.ManifestUpdate,
+				Key:     specs[i].Name,
+				ID:      id,
+				Details: []string{"parent: " + parentName(current.Parent) + " -> " + parentName(parents[id])},
+			})
+		}
+	}
+	// moved departments and their descendants
+	for i := 0; i < len(departments); i++ {
+		id := departments[i].ID
+		if deleted[id] || (departments[i].Parent == parents[id] && departments[i].Path == paths[id]) {
+			continue
+		}
+		department := departments[i]
+		department.Parent = parents[id]
+		department.Path = paths[id]
+		department.UpdatedAt = now
+		plan.UpdateDepartments = append(plan.UpdateDepartments, department)
+	}
+
+	// Roles of the manifest, keyed by department and name
+	currentPermissions := map[string]models.RolePermissions{}
+	for i := 0; i < len(rolePermissions); i++ {
+		currentPermissions[rolePermissions[i].RoleID] = rolePermissions[i]
+	}
+	existingRoles := map[string]models.Role{}
+	for i := 0; i < len(roles); i++ {
+		key := strings.ToLower(names[roles[i].Department] + "/" + roles[i].Name)
+		existingRoles[key] = roles[i]
+	}
+	heldRoles := map[string]bool{}
+	memberDepartments := map[string]bool{}
+	for i := 0; i < len(users); i++ {
+		for j := 0; j < len(users[i].Roles); j++ {
+			heldRoles[users[i].Roles[j].RoleID] = true
+		}
+		for j := 0; j < len(users[i].Departments); j++ {
+			memberDepartments[users[i].Departments[j].DepartmentID] = true
+		}
+	}
+
+	managedRoles := map[string]bool{}
+	for i := 0; i < len(manifest.Roles); i++ {
+		spec := manifest.Roles[i]
+		spec.Name = strings.TrimSpace(spec.Name)
+		if spec.Name == "" || spec.Department == "" {
+			plan.Errors = append(plan.Errors, "Role name and department are required")
+			continue
+		}
+		deptID, msg := resolveDepartment(spec.Department)
+		if msg != "" {
+			plan.Errors = append(plan.Errors, spec.Name+": "+msg)
+			continue
+		}
+		key := strings.ToLower(names[deptID] + "/" + spec.Name)
+		if managedRoles[key] {
+			plan.Errors = append(plan.Errors, "Duplicate role: "+names[deptID]+"/"+spec.Name)
+			continue
+		}
+		managedRoles[key] = true
+
+		permissions, errors := manifestPermissions(spec.Permissions, permList)
+		denies, denyErrors := manifestPermissions(spec.Denies, permList)
+		errors = append(errors, denyErrors...)
+		if len(errors) > 0 {
+			for j := 0; j < len(errors); j++ {
+				plan.Errors = append(plan.Errors, spec.Name+": "+errors[j])
+			}
+			continue
+		}
+
+		current, ok := existingRoles[key]
+		role := models.Role{
+			ID:           "ROLE" + encrypt.GenerateID(17),
+			Organization: org,
+			Department:   deptID,
+			Name:         spec.Name,
+			Permissions:  permissions,
+			Denies:       denies,
+			Status:       models.StatusActive,
+			IsActive:     true,
+			CreatedAt:    now,
+			UpdatedAt:    now,
+		}
+		if ok {
+			role = current
+			role.Permissions = permissions
+			role.Denies = denies
+			role.UpdatedAt = now
+		}
+
+		// Verify separation of duties of the role and its holders
+		if err := helpers.VerifyRoleSoD(ctx, role); err != nil {
+			plan.Errors = append(plan.Errors, spec.Name+": "+err.Message)
+			continue
+		}
+
+		if !ok {
+			plan.CreateRoles = append(plan.CreateRoles, role)
+			plan.Changes = append(plan.Changes, models.ManifestChange{
+				Kind:    models.ManifestRole,
+				Action:  models.ManifestCreate,
+				Key:     names[deptID] + "/" + spec.Name,
+				ID:      role.ID,
+				Details: append(diffNames(nil, normalizedNames(permissions), ""), diffNames(nil, normalizedNames(denies), "deny ")...),
+			})
+			continue
+		}
+
+		details := diffNames(normalizedNames(currentPermissions[role.ID].Permissions), normalizedNames(permissions), "")
+		details = append(details, diffNames(normalizedNames(currentPermissions[role.ID].Denies), normalizedNames(denies), "deny ")...)
+		if len(details) == 0 {
+			continue
+		}
+		plan.UpdateRoles = append(plan.UpdateRoles, role)
+		plan.Changes = append(plan.Changes, models.ManifestChange{
+			Kind:    models.ManifestRole,
+			Action:  models.ManifestUpdate,
+			Key:     names[deptID] + "/" + spec.Name,
+			ID:      role.ID,
+			Details: details,
+		})
+	}
+
+	// Unmanaged roles and departments
+	for i := 0; i < len(roles); i++ {
+		key := names[roles[i].Department] + "/" + roles[i].Name
+		if managedRoles[strings.ToLower(key)] {
+			continue
+		}
+		if !prune {
+			plan.Unmanaged = append(plan.Unmanaged, models.ManifestRole+":"+key)
+			continue
+		}
+		if heldRoles[roles[i].ID] {
+			plan.Errors = append(plan.Errors, key+": role is still assigned to users")
+			continue
+		}
+		plan.DeleteRoles = append(plan.DeleteRoles, roles[i].ID)
+		plan.Changes = append(plan.Changes, models.ManifestChange{
+			Kind:   models.ManifestRole,
+			Action: models.ManifestDelete,
+			Key:    key,
+			ID:     roles[i].ID,
+		})
+	}
+	for i := 0; i < len(departments); i++ {
+		if _, ok := managed[strings.ToLower(departments[i].Name)]; ok {
+			continue
+		}
+		if !prune {
+			plan.Unmanaged = append(plan.Unmanaged, models.ManifestDepartment+":"+departments[i].Name)
+			continue
+		}
+		if memberDepartments[departments[i].ID] {
+			plan.Errors = append(plan.Errors, departments[i].Name+": department still has users")
+			continue
+		}
+		plan.DeleteDepartments = append(plan.DeleteDepartments, departments[i].ID)
+		plan.Changes = append(plan.Changes, models.ManifestChange{
+			Kind:   models.ManifestDepartment,
+			Action: models.ManifestDelete,
+			Key:    departments[i].Name,
+			ID:     departments[i].ID,
+		})
+	}
+
+	return plan, nil
+}
+
+// manifestPermissions validates permission names against the catalogue
+func manifestPermissions(names []string, permList []models.Permission) ([]models.Permission, []string) {
+	permissions := []models.Permission{}
+	errors := []string{}
+	for i := 0; i < len(names); i++ {
+		valid := helpers.ValidatePermissions([]models.Permission{{Name: names[i]}}, permList)
+		if len(valid) == 0 {
+			errors = append(errors, "Invalid permission: "+names[i])
+			continue
+		}
+		permissions = append(permissions, valid...)
+	}
+	return helpers.UniquePermissions(permissions), errors
+}
+
+// diffNames lists the added and removed names of two sorted lists
+func diffNames(current []string, desired []string, prefix string) []string {
+	details := []string{}
+	held := map[string]bool{}
+	for i := 0; i < len(current); i++ {
+		held[current[i]] = true
+	}
+	wanted := map[string]bool{}
+	for i := 0; i < len(desired); i++ {
+		wanted[desired[i]] = true
+		if !held[desired[i]] {
+			details = append(details, "+"+prefix+desired[i])
+		}
+	}
+	for i := 0; i < len(current); i++ {
+		if !wanted[current[i]] {
+			details = append(details, "-"+prefix+current[i])
+		}
+	}
+	return details
+}
+
+// manifestError lists the errors of an invalid manifest by the department or
+// role they concern, nothing was applied
+func manifestError(planErrors []string) *resterr.RestErr {
+	fields := []resterr.FieldError{}
+	for i := 0; i < len(planErrors); i++ {
+		field, message := "manifest", planErrors[i]
+		if parts := strings.SplitN(planErrors[i], ": ", 2); len(parts) == 2 {
+			field, message = parts[0], parts[1]
+		}
+		fields = append(fields, resterr.FieldError{Field: field, Message: message})
+	}
+	err := resterr.NewValidationError(fields...)
+	err.Message = "Manifest is invalid, nothing was applied"
+	return err
+}
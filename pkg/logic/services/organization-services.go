@@ -1,19 +1,22 @@
 package services
 
 import (
+	"context"
+
 	"gorabc/pkg/logic/helpers"
 	"gorabc/pkg/models"
 	"gorabc/pkg/repository/dao"
 	"gorabc/pkg/utils/datetime"
 	"gorabc/pkg/utils/resterr"
+	"gorabc/pkg/utils/tracing"
 )
 
 // OrganizationServiceInterface interface
 type OrganizationServiceInterface interface {
-	FindAll(*models.AuthUser) (models.Organizations, *resterr.RestErr)
-	GetByID(string, *models.AuthUser) (*models.Organization, *resterr.RestErr)
-	Update(models.Organization, *models.AuthUser) (*models.Organization, *resterr.RestErr)
-	Delete(string, *models.AuthUser) *resterr.RestErr
+	FindAll(context.Context, *models.AuthUser) (models.Organizations, *resterr.RestErr)
+	GetByID(context.Context, string, *models.AuthUser) (*models.Organization, *resterr.RestErr)
+	Update(context.Context, models.Organization, *models.AuthUser) (*models.Organization, *resterr.RestErr)
+	Delete(context.Context, string, *models.AuthUser) *resterr.RestErr
 }
 
 type organizationService struct{}
@@ -24,8 +27,20 @@ var (
 )
 
 // FindAll organization
-func (s *organizationService) FindAll(au *models.AuthUser) (models.Organizations, *resterr.RestErr) {
-	organizations, err := dao.OrganizationDao.FindAll()
+func (s *organizationService) FindAll(ctx context.Context, au *models.AuthUser) (models.Organizations, *resterr.RestErr) {
+	ctx, span := tracing.Start(ctx, "OrganizationService.FindAll")
+	defer span.End()
+
+	// Tenants only see their own organization
+	if !au.IsSuperuser {
+		organization, err := s.GetByID(ctx, au.Organization, au)
+		if err != nil {
+			return nil, err
+		}
+		return models.Organizations{*organization}, nil
+	}
+
+	organizations, err := dao.OrganizationDao.FindAll(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -34,9 +49,17 @@ func (s *organizationService) FindAll(au *models.AuthUser) (models.Organizations
 }
 
 // GetByID organization
-func (s *organizationService) GetByID(id string, au *models.AuthUser) (*models.Organization, *resterr.RestErr) {
+func (s *organizationService) GetByID(ctx context.Context, id string, au *models.AuthUser) (*models.Organization, *resterr.RestErr) {
+	ctx, span := tracing.Start(ctx, "OrganizationService.GetByID")
+	defer span.End()
+
+	// Verify organization --> other tenants do not exist for the caller
+	if !au.IsSuperuser && id != au.Organization {
+		return nil, resterr.NewNotFoundError("Organization not found")
+	}
+
 	// Get organization
-	organization, err := dao.OrganizationDao.GetByID(id)
+	organization, err := dao.OrganizationDao.GetByID(ctx, id)
 	if err != nil {
 		return nil, err
 	}
@@ -45,23 +68,27 @@ func (s *organizationService) GetByID(id string, au *models.AuthUser) (*models.O
 }
 
 // Update organization
-func (s *organizationService) Update(organization models.Organization, au *models.AuthUser) (*models.Organization, *resterr.RestErr) {
+func (s *organizationService) Update(ctx context.Context, organization models.Organization, au *models.AuthUser) (*models.Organization, *resterr.RestErr) {
+	ctx, span := tracing.Start(ctx, "OrganizationService.Update")
+	defer span.End()
+
 	// Verify permission --> IsGranted
-	if !au.IsSuperuser && !au.IsOrgAdmin {
-		if !helpers.IsGranted("CanUpdateOrganization", *au) {
-			return nil, resterr.NewUnauthorizedError("Permission not granted")
+	if !au.IsSuperuser {
+		if !helpers.IsGranted("org:organization:update", *au) {
+			return nil, resterr.NewForbiddenError("Permission not granted")
 		}
 	}
 
-	current, err := s.GetByID(organization.ID, au)
+	current, err := s.GetByID(ctx, organization.ID, au)
 	if err != nil {
 		return nil, err
 	}
 
 	// Verify auth user's organization
 	if au.Organization != current.ID {
-		return nil, resterr.NewUnauthorizedError("Unauthorized request")
+		return nil, resterr.NewForbiddenError("Unauthorized request")
 	}
+	before := *current
 
 	if organization.Name != "" {
 		current.Name = organization.Name
@@ -69,24 +96,39 @@ func (s *organizationService) Update(organization models.Organization, au *model
 	if organization.Website != "" {
 		current.Website = organization.Website
 	}
+	if err := current.Validate(); err != nil {
+		return nil, err
+	}
 
 	current.UpdatedAt = datetime.GetDateTimeString()
 
-	if updateErr := dao.OrganizationDao.Update(*current); updateErr != nil {
+	if updateErr := dao.OrganizationDao.Update(ctx, *current); updateErr != nil {
 		return nil, updateErr
 	}
 
+	audit(ctx, au, "organization:update", models.AuditTargetOrganization, current.ID, before, current)
 	return current, nil
 }
 
 // Delete organization
-func (s *organizationService) Delete(id string, au *models.AuthUser) *resterr.RestErr {
+func (s *organizationService) Delete(ctx context.Context, id string, au *models.AuthUser) *resterr.RestErr {
+	ctx, span := tracing.Start(ctx, "OrganizationService.Delete")
+	defer span.End()
+
 	// Verify permission --> IsGranted
-	if !au.IsOrgAdmin {
-		if !helpers.IsGranted("CanDeleteOrganization", *au) {
-			return resterr.NewUnauthorizedError("Permission not granted")
-		}
+	if !helpers.IsGranted("org:organization:delete", *au) {
+		return resterr.NewForbiddenError("Permission not granted")
+	}
+
+	current, err := s.GetByID(ctx, id, au)
+	if err != nil {
+		return err
+	}
+
+	if err := dao.OrganizationDao.Delete(ctx, id); err != nil {
+		return err
 	}
 
-	return dao.OrganizationDao.Delete(id)
+	audit(ctx, au, "organization:delete", models.AuditTargetOrganization, id, current, nil)
+	return nil
 }
@@ -0,0 +1,212 @@
+package services
+
+import (
+	"context"
+
+	"gorabc/pkg/logic/helpers"
+	"gorabc/pkg/logic/policy"
+	"gorabc/pkg/models"
+	"gorabc/pkg/repository/dao"
+	"gorabc/pkg/utils/datetime"
+	"gorabc/pkg/utils/encrypt"
+	"gorabc/pkg/utils/resterr"
+	"gorabc/pkg/utils/tracing"
+)
+
+// PolicyServiceInterface interface
+type PolicyServiceInterface interface {
+	Create(context.Context, models.Policy, *models.AuthUser) (*models.Policy, *resterr.RestErr)
+	FindAll(context.Context, *models.AuthUser) (models.Policies, *resterr.RestErr)
+	GetByID(context.Context, string, *models.AuthUser) (*models.Policy, *resterr.RestErr)
+	Update(context.Context, models.Policy, *models.AuthUser) (*models.Policy, *resterr.RestErr)
+	Delete(context.Context, string, *models.AuthUser) *resterr.RestErr
+	Authorize(context.Context, models.AuthorizeRequest, *models.AuthUser) (*models.AuthorizeResponse, *resterr.RestErr)
+}
+
+type policyService struct{}
+
+// PolicyService variable
+var (
+	PolicyService PolicyServiceInterface = &policyService{}
+)
+
+// Create policy
+func (s *policyService) Create(ctx context.Context, p models.Policy, au *models.AuthUser) (*models.Policy, *resterr.RestErr) {
+	ctx, span := tracing.Start(ctx, "PolicyService.Create")
+	defer span.End()
+
+	// Validate request
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+
+	// Verify permission --> IsGranted
+	if !helpers.IsGranted("org:policy:create", *au) {
+		return nil, resterr.NewForbiddenError("Permission not granted")
+	}
+
+	p.ID = "POL" + encrypt.GenerateID(18)
+	p.Organization = au.Organization
+	p.Status = models.StatusActive
+	p.IsActive = true
+	p.CreatedAt = datetime.GetDateTimeString()
+	p.UpdatedAt = datetime.GetDateTimeString()
+
+	newPolicy, err := dao.PolicyDao.Create(ctx, p)
+	if err != nil {
+		return nil, err
+	}
+
+	audit(ctx, au, "policy:create", models.AuditTargetPolicy, newPolicy.ID, nil, newPolicy)
+	return newPolicy, nil
+}
+
+// FindAll policy
+func (s *policyService) FindAll(ctx context.Context, au *models.AuthUser) (models.Policies, *resterr.RestErr) {
+	ctx, span := tracing.Start(ctx, "PolicyService.FindAll")
+	defer span.End()
+
+	// Verify permission --> IsGranted
+	if !helpers.IsGranted("org:policy:read", *au) {
+		return nil, resterr.NewForbiddenError("Permission not granted")
+	}
+
+	policies, err := dao.PolicyDao.FindAll(ctx, au.Organization)
+	if err != nil {
+		return nil, err
+	}
+
+	return policies, nil
+}
+
+// GetByID policy
+func (s *policyService) GetByID(ctx context.Context, id string, au *models.AuthUser) (*models.Policy, *resterr.RestErr) {
+	ctx, span := tracing.Start(ctx, "PolicyService.GetByID")
+	defer span.End()
+
+	// Verify permission --> IsGranted
+	if !helpers.IsGranted("org:policy:read", *au) {
+		return nil, resterr.NewForbiddenError("Permission not granted")
+	}
+
+	// Get policy
+	p, err := dao.PolicyDao.GetByID(ctx, id, au.Organization)
+	if err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
+
+// Update policy
+func (s *policyService) Update(ctx context.Context, p models.Policy, au *models.AuthUser) (*models.Policy, *resterr.RestErr) {
+	ctx, span := tracing.Start(ctx, "PolicyService.Update")
+	defer span.End()
+
+	// Verify permission --> IsGranted
+	if !helpers.IsGranted("org:policy:update", *au) {
+		return nil, resterr.NewForbiddenError("Permission not granted")
+	}
+
+	current, err := dao.PolicyDao.GetByID(ctx, p.ID, au.Organization)
+	if err != nil {
+		return nil, err
+	}
+	before := *current
+
+	if p.Name != "" {
+		current.Name = p.Name
+	}
+	if p.Description != "" {
+		current.Description = p.Description
+	}
+	if p.Effect != "" {
+		current.Effect = p.Effect
+	}
+	if len(p.Actions) > 0 {
+		current.Actions = p.Actions
+	}
+	if p.Conditions != nil {
+		current.Conditions = p.Conditions
+	}
+	if p.ConditionMatch != "" {
+		current.ConditionMatch = p.ConditionMatch
+	}
+
+	// Validate the updated policy
+	if err := current.Validate(); err != nil {
+		return nil, err
+	}
+
+	current.UpdatedAt = datetime.GetDateTimeString()
+
+	if updateErr := dao.PolicyDao.Update(ctx, *current); updateErr != nil {
+		return nil, updateErr
+	}
+
+	audit(ctx, au, "policy:update", models.AuditTargetPolicy, current.ID, before, current)
+	return current, nil
+}
+
+// Delete policy
+func (s *policyService) Delete(ctx context.Context, id string, au *models.AuthUser) *resterr.RestErr {
+	ctx, span := tracing.Start(ctx, "PolicyService.Delete")
+	defer span.End()
+
+	// Verify permission --> IsGranted
+	if !helpers.IsGranted("org:policy:delete", *au) {
+		return resterr.NewForbiddenError("Permission not granted")
+	}
+
+	current, err := dao.PolicyDao.GetByID(ctx, id, au.Organization)
+	if err != nil {
+		return err
+	}
+
+	if err := dao.PolicyDao.Delete(ctx, id, au.Organization); err != nil {
+		return err
+	}
+
+	audit(ctx, au, "policy:delete", models.AuditTargetPolicy, id, current, nil)
+	return nil
+}
+
+// Authorize combines the role permissions of the user with the policies of
+// the organization, a matching deny policy always wins
+func (s *policyService) Authorize(ctx context.Context, request models.AuthorizeRequest, au *models.AuthUser) (*models.AuthorizeResponse, *resterr.RestErr) {
+	ctx, span := tracing.Start(ctx, "PolicyService.Authorize")
+	defer span.End()
+
+	// Validate request
+	if err := request.Validate(); err != nil {
+		return nil, err
+	}
+
+	policies, err := dao.PolicyDao.FindAll(ctx, au.Organization)
+	if err != nil {
+		return nil, err
+	}
+
+	// Role permissions, within the department of the resource when given
+	granted := helpers.IsGranted(request.Action, *au)
+	explained := helpers.ExplainPermission(request.Action, *au)
+	deniedBy := explained.DeniedBy
+	if deptID, ok := request.Resource["department"].(string); ok && deptID != "" {
+		granted = helpers.VerifyDepartmentScope(ctx, request.Action, deptID, *au) == nil
+		if granted {
+			deniedBy = nil
+		}
+	} else if deniedBy != nil && deniedBy.Scope != "" {
+		// scoped denies only apply within their department subtree
+		deniedBy = nil
+	}
+
+	decision := policy.Decide(granted, deniedBy, policies, policy.Request{
+		Action:      request.Action,
+		Subject:     policy.SubjectAttributes(*au),
+		Resource:    policy.Attributes(request.Resource),
+		Environment: policy.EnvironmentAttributes(datetime.GetDateTime(), request.IP),
+	})
+
+	return &decision, nil
+}
@@ -0,0 +1,94 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"gorabc/pkg/models"
+	"gorabc/pkg/repository/dao"
+	"gorabc/pkg/utils/datetime"
+	"gorabc/pkg/utils/encrypt"
+	"gorabc/pkg/utils/resterr"
+	"gorabc/pkg/utils/tracing"
+)
+
+// RegistrationServiceInterface interface
+type RegistrationServiceInterface interface {
+	CreateInvite(context.Context, models.RegistrationInviteRequest, *models.AuthUser) (*models.RegistrationInviteResponse, *resterr.RestErr)
+	FindInvites(context.Context, *models.AuthUser) (models.RegistrationInvites, *resterr.RestErr)
+	RevokeInvite(context.Context, string, *models.AuthUser) *resterr.RestErr
+}
+
+type registrationService struct{}
+
+// RegistrationService variable
+var (
+	RegistrationService RegistrationServiceInterface = &registrationService{}
+)
+
+// CreateInvite to register an organization when registration is invite-only
+func (s *registrationService) CreateInvite(ctx context.Context, request models.RegistrationInviteRequest, au *models.AuthUser) (*models.RegistrationInviteResponse, *resterr.RestErr) {
+	ctx, span := tracing.Start(ctx, "RegistrationService.CreateInvite")
+	defer span.End()
+
+	// Verify permission --> IsGranted
+	if !au.IsSuperuser {
+		return nil, resterr.NewForbiddenError("Permission not granted")
+	}
+
+	if err := request.Validate(); err != nil {
+		return nil, err
+	}
+
+	token := encrypt.GenerateToken(32)
+	now := datetime.GetDateTime()
+	invite := models.RegistrationInvite{
+		ID:        "RI" + encrypt.GenerateID(18),
+		TokenHash: encrypt.HashToken(token),
+		Email:     strings.TrimSpace(strings.ToLower(request.Email)),
+		Status:    models.InviteStatusPending,
+		CreatedBy: au.ID,
+		CreatedAt: datetime.FormatDateTime(now),
+		ExpiresAt: datetime.FormatDateTime(now.Add(time.Duration(request.ExpiresInHours) * time.Hour)),
+	}
+
+	newInvite, err := dao.RegistrationDao.CreateInvite(ctx, invite)
+	if err != nil {
+		return nil, err
+	}
+
+	audit(ctx, au, "registration:invite", models.AuditTargetRegistration, newInvite.ID, nil, newInvite)
+	return &models.RegistrationInviteResponse{Invite: *newInvite, Token: token}, nil
+}
+
+// FindInvites of organization registration
+func (s *registrationService) FindInvites(ctx context.Context, au *models.AuthUser) (models.RegistrationInvites, *resterr.RestErr) {
+	ctx, span := tracing.Start(ctx, "RegistrationService.FindInvites")
+	defer span.End()
+
+	// Verify permission --> IsGranted
+	if !au.IsSuperuser {
+		return nil, resterr.NewForbiddenError("Permission not granted")
+	}
+
+	return dao.RegistrationDao.FindInvites(ctx)
+}
+
+// RevokeInvite pending registration invite
+func (s *registrationService) RevokeInvite(ctx context.Context, id string, au *models.AuthUser) *resterr.RestErr {
+	ctx, span := tracing.Start(ctx, "RegistrationService.RevokeInvite")
+	defer span.End()
+
+	// Verify permission --> IsGranted
+	if !au.IsSuperuser {
+		return resterr.NewForbiddenError("Permission not granted")
+	}
+
+	if err := dao.RegistrationDao.RevokeInvite(ctx, id); err != nil {
+		return err
+	}
+
+	audit(ctx, au, "registration:invite_revoke", models.AuditTargetRegistration, id, nil, map[string]string{"status": models.InviteStatusRevoked})
+	return nil
+}
@@ -0,0 +1,295 @@
+package services
+
+import (
+	"context"
+
+	"gorabc/pkg/logic/helpers"
+	"gorabc/pkg/logic/relation"
+	"gorabc/pkg/models"
+	"gorabc/pkg/repository/dao"
+	"gorabc/pkg/utils/datetime"
+	"gorabc/pkg/utils/resterr"
+	"gorabc/pkg/utils/tracing"
+)
+
+// RelationServiceInterface interface
+type RelationServiceInterface interface {
+	Write(context.Context, models.RelationWriteRequest, *models.AuthUser) (*models.RelationWriteResponse, *resterr.RestErr)
+	FindTuples(context.Context, models.RelationTupleFilter, models.RelationReadOptions, *models.AuthUser) (models.RelationTuples, *resterr.RestErr)
+	Check(context.Context, models.RelationCheckRequest, *models.AuthUser) (*models.RelationCheckResponse, *resterr.RestErr)
+	Expand(context.Context, models.RelationExpandRequest, *models.AuthUser) (*models.RelationExpandResponse, *resterr.RestErr)
+	ListObjects(context.Context, models.ListObjectsRequest, *models.AuthUser) (*models.ListObjectsResponse, *resterr.RestErr)
+	FindNamespaces(context.Context, *models.AuthUser) (models.Namespaces, *resterr.RestErr)
+	UpdateNamespace(context.Context, models.Namespace, *models.AuthUser) (*models.Namespace, *resterr.RestErr)
+	DeleteNamespace(context.Context, string, *models.AuthUser) *resterr.RestErr
+}
+
+type relationService struct{}
+
+// RelationService variable
+var (
+	RelationService RelationServiceInterface = &relationService{}
+)
+
+// Write relation tuples
+func (s *relationService) Write(ctx context.Context, request models.RelationWriteRequest, au *models.AuthUser) (*models.RelationWriteResponse, *resterr.RestErr) {
+	ctx, span := tracing.Start(ctx, "RelationService.Write")
+	defer span.End()
+
+	// Validate request
+	if err := request.Validate(); err != nil {
+		return nil, err
+	}
+
+	// Verify permission --> IsGranted
+	if !helpers.IsGranted("org:relation:write", *au) {
+		return nil, resterr.NewForbiddenError("Permission not granted")
+	}
+
+	namespaces, err := relation.Namespaces(ctx, au.Organization)
+	if err != nil {
+		return nil, err
+	}
+
+	// Mirrored memberships are only changed through their services
+	tuples := append(models.RelationTuples{}, request.Writes...)
+	tuples = append(tuples, request.Deletes...)
+	for i := 0; i < len(tuples); i++ {
+		namespace, ok := namespaces[tuples[i].ObjectType]
+		if !ok {
+			return nil, resterr.NewBadRequestError("Unknown namespace: " + tuples[i].ObjectType)
+		}
+		if namespace.Managed {
+			return nil, resterr.NewBadRequestError("Tuples of the " + namespace.Name + " namespace are managed by gorabc")
+		}
+		if _, ok := namespace.GetRelation(tuples[i].Relation); !ok {
+			return nil, resterr.NewBadRequestError("Unknown relation: " + tuples[i].ObjectType + models.RelationSeparator + tuples[i].Relation)
+		}
+	}
+	for i := 0; i < len(request.Writes); i++ {
+		request.Writes[i].Managed = false
+	}
+
+	revision, err := dao.RelationDao.Write(ctx, au.Organization, request.Writes, request.Deletes)
+	if err != nil {
+		return nil, err
+	}
+
+	token := relation.EncodeToken(au.Organization, revision)
+	audit(ctx, au, "relation:write", models.AuditTargetRelation, token, nil, request)
+	return &models.RelationWriteResponse{Token: token}, nil
+}
+
+// FindTuples matching the filter
+func (s *relationService) FindTuples(ctx context.Context, filter models.RelationTupleFilter, options models.RelationReadOptions, au *models.AuthUser) (models.RelationTuples, *resterr.RestErr) {
+	ctx, span := tracing.Start(ctx, "RelationService.FindTuples")
+	defer span.End()
+
+	// Verify permission --> IsGranted
+	if !helpers.IsGranted("org:relation:read", *au) {
+		return nil, resterr.NewForbiddenError("Permission not granted")
+	}
+
+	revision, err := relation.SnapshotRevision(ctx, au.Organization, options)
+	if err != nil {
+		return nil, err
+	}
+
+	return dao.RelationDao.Find(ctx, au.Organization, filter, revision)
+}
+
+// Check a relation of a subject on an object
+func (s *relationService) Check(ctx context.Context, request models.RelationCheckRequest, au *models.AuthUser) (*models.RelationCheckResponse, *resterr.RestErr) {
+	ctx, span := tracing.Start(ctx, "RelationService.Check")
+	defer span.End()
+
+	// Checking another subject requires to read the relations
+	if err := s.verifySubject(&request.Subject, au); err != nil {
+		return nil, err
+	}
+
+	revision, err := relation.SnapshotRevision(ctx, au.Organization, request.RelationReadOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	engine, err := relation.NewEngine(ctx, au.Organization, revision)
+	if err != nil {
+		return nil, err
+	}
+
+	allowed, err := engine.Check(request.Object, request.Relation, request.Subject)
+	if err != nil {
+		return nil, err
+	}
+
+	response := models.RelationCheckResponse{
+		Object:   request.Object,
+		Relation: request.Relation,
+		Subject:  request.Subject,
+		Allowed:  allowed,
+		Token:    relation.EncodeToken(au.Organization, revision),
+	}
+	return &response, nil
+}
+
+// Expand the subjects of a relation
+func (s *relationService) Expand(ctx context.Context, request models.RelationExpandRequest, au *models.AuthUser) (*models.RelationExpandResponse, *resterr.RestErr) {
+	ctx, span := tracing.Start(ctx, "RelationService.Expand")
+	defer span.End()
+
+	// Verify permission --> IsGranted
+	if !helpers.IsGranted("org:relation:read", *au) {
+		return nil, resterr.NewForbiddenError("Permission not granted")
+	}
+
+	revision, err := relation.SnapshotRevision(ctx, au.Organization, request.RelationReadOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	engine, err := relation.NewEngine(ctx, au.Organization, revision)
+	if err != nil {
+		return nil, err
+	}
+
+	tree, err := engine.Expand(request.Object, request.Relation)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.RelationExpandResponse{Tree: *tree, Token: relation.EncodeToken(au.Organization, revision)}, nil
+}
+
+// ListObjects on which a subject holds a relation
+func (s *relationService) ListObjects(ctx context.Context, request models.ListObjectsRequest, au *models.AuthUser) (*models.ListObjectsResponse, *resterr.RestErr) {
+	ctx, span := tracing.Start(ctx, "RelationService.ListObjects")
+	defer span.End()
+
+	// Listing for another subject requires to read the relations
+	if err := s.verifySubject(&request.Subject, au); err != nil {
+		return nil, err
+	}
+
+	revision, err := relation.SnapshotRevision(ctx, au.Organization, request.RelationReadOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	engine, err := relation.NewEngine(ctx, au.Organization, revision)
+	if err != nil {
+		return nil, err
+	}
+
+	objects, err := engine.ListObjects(request.ObjectType, request.Relation, request.Subject)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.ListObjectsResponse{Objects: objects, Token: relation.EncodeToken(au.Organization, revision)}, nil
+}
+
+// FindNamespaces of the organization, builtin ones included
+func (s *relationService) FindNamespaces(ctx context.Context, au *models.AuthUser) (models.Namespaces, *resterr.RestErr) {
+	ctx, span := tracing.Start(ctx, "RelationService.FindNamespaces")
+	defer span.End()
+
+	// Verify permission --> IsGranted
+	if !helpers.IsGranted("org:relation:read", *au) {
+		return nil, resterr.NewForbiddenError("Permission not granted")
+	}
+
+	namespaces, err := relation.Namespaces(ctx, au.Organization)
+	if err != nil {
+		return nil, err
+	}
+
+	result := models.Namespaces{}
+	for _, namespace := range namespaces {
+		result = append(result, namespace)
+	}
+	return result, nil
+}
+
+// UpdateNamespace creates or replaces a namespace of the organization
+func (s *relationService) UpdateNamespace(ctx context.Context, namespace models.Namespace, au *models.AuthUser) (*models.Namespace, *resterr.RestErr) {
+	ctx, span := tracing.Start(ctx, "RelationService.UpdateNamespace")
+	defer span.End()
+
+	// Validate request
+	if err := namespace.Validate(); err != nil {
+		return nil, err
+	}
+
+	// Verify permission --> IsGranted
+	if !helpers.IsGranted("org:namespace:update", *au) {
+		return nil, resterr.NewForbiddenError("Permission not granted")
+	}
+
+	if relation.IsBuiltin(namespace.Name) {
+		return nil, resterr.NewBadRequestError("Namespace " + namespace.Name + " is managed by gorabc")
+	}
+
+	namespaces, err := relation.Namespaces(ctx, au.Organization)
+	if err != nil {
+		return nil, err
+	}
+	var before interface{}
+	if current, ok := namespaces[namespace.Name]; ok {
+		before = current
+	}
+
+	namespace.Organization = au.Organization
+	namespace.Managed = false
+	namespace.UpdatedAt = datetime.GetDateTimeString()
+
+	if err := dao.RelationDao.UpsertNamespace(ctx, namespace); err != nil {
+		return nil, err
+	}
+
+	audit(ctx, au, "namespace:update", models.AuditTargetNamespace, namespace.Name, before, namespace)
+	return &namespace, nil
+}
+
+// DeleteNamespace of the organization
+func (s *relationService) DeleteNamespace(ctx context.Context, name string, au *models.AuthUser) *resterr.RestErr {
+	ctx, span := tracing.Start(ctx, "RelationService.DeleteNamespace")
+	defer span.End()
+
+	// Verify permission --> IsGranted
+	if !helpers.IsGranted("org:namespace:update", *au) {
+		return resterr.NewForbiddenError("Permission not granted")
+	}
+
+	if relation.IsBuiltin(name) {
+		return resterr.NewBadRequestError("Namespace " + name + " is managed by gorabc")
+	}
+
+	namespaces, err := relation.Namespaces(ctx, au.Organization)
+	if err != nil {
+		return err
+	}
+	var before interface{}
+	if current, ok := namespaces[name]; ok {
+		before = current
+	}
+
+	if err := dao.RelationDao.DeleteNamespace(ctx, name, au.Organization); err != nil {
+		return err
+	}
+
+	audit(ctx, au, "namespace:delete", models.AuditTargetNamespace, name, before, nil)
+	return nil
+}
+
+// verifySubject defaults the subject to the authenticated user
+func (s *relationService) verifySubject(subject *string, au *models.AuthUser) *resterr.RestErr {
+	self := helpers.RelationObject(helpers.ObjectTypeUser, au.ID)
+	if *subject == "" {
+		*subject = self
+	}
+	if *subject != self && !helpers.IsGranted("org:relation:read", *au) {
+		return resterr.NewForbiddenError("Permission not granted")
+	}
+	return nil
+}
@@ -1,21 +1,25 @@
 package services
 
 import (
+	"context"
+
 	"gorabc/pkg/logic/helpers"
 	"gorabc/pkg/models"
 	"gorabc/pkg/repository/dao"
 	"gorabc/pkg/utils/datetime"
 	"gorabc/pkg/utils/encrypt"
 	"gorabc/pkg/utils/resterr"
+	"gorabc/pkg/utils/tracing"
 )
 
 // RoleServiceInterface interface
 type RoleServiceInterface interface {
-	Create(models.Role, *models.AuthUser) (*models.Role, *resterr.RestErr)
-	FindAll(*models.AuthUser) (models.Roles, *resterr.RestErr)
-	GetByID(string, *models.AuthUser) (*models.Role, *resterr.RestErr)
-	Update(models.Role, *models.AuthUser) (*models.Role, *resterr.RestErr)
-	Delete(string, *models.AuthUser) *resterr.RestErr
+	Create(context.Context, models.CreateRoleRequest, *models.AuthUser) (*models.Role, *resterr.RestErr)
+	FindAll(context.Context, *models.AuthUser) (models.Roles, *resterr.RestErr)
+	GetByID(context.Context, string, *models.AuthUser) (*models.Role, *resterr.RestErr)
+	Update(context.Context, string, models.UpdateRoleRequest, *models.AuthUser) (*models.Role, *resterr.RestErr)
+	Delete(context.Context, string, *models.AuthUser) *resterr.RestErr
+	ValidateAssignment(context.Context, models.UserRole, *models.AuthUser) (*models.Role, *resterr.RestErr)
 }
 
 type roleService struct{}
@@ -26,23 +30,59 @@ var (
 )
 
 // Create role
-func (s *roleService) Create(role models.Role, au *models.AuthUser) (*models.Role, *resterr.RestErr) {
-	// Validate role
-	if err := role.Validate(); err != nil {
+func (s *roleService) Create(ctx context.Context, request models.CreateRoleRequest, au *models.AuthUser) (*models.Role, *resterr.RestErr) {
+	ctx, span := tracing.Start(ctx, "RoleService.Create")
+	defer span.End()
+
+	// Validate request
+	if err := request.Validate(); err != nil {
+		return nil, err
+	}
+
+	return createRole(ctx, request.Role(), au)
+}
+
+// createRole creates a validated role, role templates record their origin
+// on the role
+func createRole(ctx context.Context, role models.Role, au *models.AuthUser) (*models.Role, *resterr.RestErr) {
+	role, err := prepareRoleCreate(ctx, role, au)
+	if err != nil {
+		return nil, err
+	}
+
+	// Create new role
+	newRole, err := dao.RoleDao.Create(ctx, role)
+	if err != nil {
 		return nil, err
 	}
 
+	// Mirror role into relation tuples
+	if err := helpers.MirrorRole(ctx, *newRole); err != nil {
+		return nil, err
+	}
+
+	audit(ctx, au, "role:create", models.AuditTargetRole, newRole.ID, nil, newRole)
+	return newRole, nil
+}
+
+// prepareRoleCreate builds the validated role and runs every check of its
+// creation without writing it, the dry run of an import runs the same checks
+func prepareRoleCreate(ctx context.Context, role models.Role, au *models.AuthUser) (models.Role, *resterr.RestErr) {
 	// Verify permission --> IsGranted
-	if !au.IsOrgAdmin {
-		if !helpers.IsGranted("CanCreateRole", *au) {
-			return nil, resterr.NewUnauthorizedError("Permission not granted")
-		}
+	scope := helpers.GetScope("org:role:create", *au)
+	if scope.IsEmpty() {
+		return role, resterr.NewForbiddenError("Permission not granted")
 	}
 
 	// Get department
-	dept, err := DepartmentService.GetByID(role.Department, au)
+	dept, err := DepartmentService.GetByID(ctx, role.Department, au)
 	if err != nil {
-		return nil, err
+		return role, err
+	}
+
+	// Verify department scope
+	if !scope.Allows(*dept) {
+		return role, resterr.NewForbiddenError("Department is outside of your scope")
 	}
 
 	role.ID = "ROLE" + encrypt.GenerateID(17)
@@ -55,70 +95,138 @@ func (s *roleService) Create(role models.Role, au *models.AuthUser) (*models.Rol
 
 	// Add role permissions
 	if len(role.Permissions) > 0 {
-		rolePermList, err := helpers.AssignRolePermissions(role)
+		rolePermList, err := helpers.AssignRolePermissions(ctx, role)
 		if err != nil {
-			return nil, err
+			return role, err
 		}
 		role.Permissions = *rolePermList
 	}
 
-	// Create new role
-	newRole, err := dao.RoleDao.Create(role)
-	if err != nil {
-		return nil, err
+	// Add role denies
+	if len(role.Denies) > 0 {
+		roleDenyList, err := helpers.AssignRoleDenies(ctx, role)
+		if err != nil {
+			return role, err
+		}
+		role.Denies = *roleDenyList
 	}
 
-	return newRole, nil
+	// Verify the caller holds what the role grants
+	if err := helpers.VerifyRoleGrants(ctx, models.Role{}, role, *au); err != nil {
+		return role, err
+	}
+	return role, nil
 }
 
 // FindAll role
-func (s *roleService) FindAll(au *models.AuthUser) (models.Roles, *resterr.RestErr) {
+func (s *roleService) FindAll(ctx context.Context, au *models.AuthUser) (models.Roles, *resterr.RestErr) {
+	ctx, span := tracing.Start(ctx, "RoleService.FindAll")
+	defer span.End()
+
 	// Verify permission --> IsGranted
-	if !au.IsOrgAdmin {
-		if !helpers.IsGranted("CanReadRole", *au) {
-			return nil, resterr.NewUnauthorizedError("Permission not granted")
-		}
+	scope := helpers.GetScope("org:role:read", *au)
+	if scope.IsEmpty() {
+		return nil, resterr.NewForbiddenError("Permission not granted")
 	}
 
-	roles, err := dao.RoleDao.FindAll(au.Organization)
+	roles, err := dao.RoleDao.FindAll(ctx, au.Organization)
 	if err != nil {
 		return nil, err
 	}
 
-	return roles, nil
-}
+	if scope.Unrestricted() {
+		return roles, nil
+	}
 
-// GetByID role
-func (s *roleService) GetByID(id string, au *models.AuthUser) (*models.Role, *resterr.RestErr) {
-	// Verify permission --> IsGranted
-	if !au.IsOrgAdmin {
-		if !helpers.IsGranted("CanReadRole", *au) {
-			return nil, resterr.NewUnauthorizedError("Permission not granted")
+	// Factor out roles of departments outside of the granted subtrees
+	index, err := helpers.GetDepartmentIndex(ctx, au.Organization)
+	if err != nil {
+		return nil, err
+	}
+
+	result := models.Roles{}
+	for i := 0; i < len(roles); i++ {
+		if scope.AllowsAll([]string{roles[i].Department}, index) {
+			result = append(result, roles[i])
 		}
 	}
 
+	return result, nil
+}
+
+// GetByID role
+func (s *roleService) GetByID(ctx context.Context, id string, au *models.AuthUser) (*models.Role, *resterr.RestErr) {
+	ctx, span := tracing.Start(ctx, "RoleService.GetByID")
+	defer span.End()
+
 	// Get role
-	role, err := dao.RoleDao.GetByID(id, au.Organization)
+	role, err := dao.RoleDao.GetByID(ctx, id, au.Organization)
 	if err != nil {
 		return nil, err
 	}
 
+	// Verify permission --> IsGranted
+	if err := helpers.VerifyDepartmentScope(ctx, "org:role:read", role.Department, *au); err != nil {
+		return nil, err
+	}
+
 	return role, nil
 }
 
 // Update role
-func (s *roleService) Update(role models.Role, au *models.AuthUser) (*models.Role, *resterr.RestErr) {
-	// Verify permission --> IsGranted
-	if !au.IsOrgAdmin {
-		if !helpers.IsGranted("CanUpdateRole", *au) {
-			return nil, resterr.NewUnauthorizedError("Permission not granted")
-		}
+func (s *roleService) Update(ctx context.Context, id string, request models.UpdateRoleRequest, au *models.AuthUser) (*models.Role, *resterr.RestErr) {
+	ctx, span := tracing.Start(ctx, "RoleService.Update")
+	defer span.End()
+
+	// Validate request
+	if err := request.Validate(); err != nil {
+		return nil, err
 	}
 
-	current, err := s.GetByID(role.ID, au)
+	before, current, err := prepareRoleUpdate(ctx, id, request, au)
 	if err != nil {
 		return nil, err
 	}
+	current.UpdatedAt = datetime.GetDateTimeString()
+
+	if updateErr := dao.RoleDao.Update(ctx, *current); updateErr != nil {
+		return nil, updateErr
+	}
+
+	// Push the permissions and denies of the role to its current holders
+	if len(request.Permissions) > 0 || request.Denies != nil {
+		holders, err := helpers.RoleHolders(ctx, *current)
+		if err != nil {
+			return nil, err
+		}
+		for i := 0; i < len(holders); i++ {
+			holders[i].UpdatedAt = current.UpdatedAt
+			if updateErr := dao.UserDao.Update(ctx, holders[i]); updateErr != nil {
+				return nil, updateErr
+			}
+		}
+	}
+
+	audit(ctx, au, "role:update", models.AuditTargetRole, current.ID, before, current)
+	return current, nil
+}
+
+// prepareRoleUpdate applies the request to the current role and runs every
+// check of the update without writing it, the dry run of an import runs the
+// same checks. It returns the role before and after the update.
+func prepareRoleUpdate(ctx context.Context, id string, request models.UpdateRoleRequest, au *models.AuthUser) (models.Role, *models.Role, *resterr.RestErr) {
+	role := request.Role(id)
+
+	current, err := RoleService.GetByID(ctx, role.ID, au)
+	if err != nil {
+		return models.Role{}, nil, err
+	}
+
+	// Verify permission --> IsGranted
+	if err := helpers.VerifyDepartmentScope(ctx, "org:role:update", current.Department, *au); err != nil {
+		return models.Role{}, nil, err
+	}
+	before := *current
 
 	if role.Name != "" {
 		current.Name = role.Name
@@ -127,31 +235,74 @@ func (s *roleService) Update(role models.Role, au *models.AuthUser) (*models.Rol
 	if len(role.Permissions) > 0 {
 		// role.Permissions = append(role.Permissions, current.Permissions...)
 		// Validate permission request
-		rolePermList, err := helpers.AssignRolePermissions(role)
+		rolePermList, err := helpers.AssignRolePermissions(ctx, role)
 		if err != nil {
-			return nil, err
+			return before, nil, err
 		}
 
 		current.Permissions = *rolePermList
 	}
 
-	current.UpdatedAt = datetime.GetDateTimeString()
+	if role.Denies != nil {
+		// Validate deny request, an empty list clears the denies
+		roleDenyList, err := helpers.AssignRoleDenies(ctx, role)
+		if err != nil {
+			return before, nil, err
+		}
 
-	if updateErr := dao.RoleDao.Update(*current); updateErr != nil {
-		return nil, updateErr
+		current.Denies = *roleDenyList
 	}
 
-	return current, nil
+	// Verify the caller holds what the role grants
+	if err := helpers.VerifyRoleGrants(ctx, before, *current, *au); err != nil {
+		return before, nil, err
+	}
+
+	// Verify separation of duties of the role and its holders
+	if err := helpers.VerifyRoleSoD(ctx, *current); err != nil {
+		return before, nil, err
+	}
+	return before, current, nil
 }
 
 // Delete role
-func (s *roleService) Delete(id string, au *models.AuthUser) *resterr.RestErr {
+func (s *roleService) Delete(ctx context.Context, id string, au *models.AuthUser) *resterr.RestErr {
+	ctx, span := tracing.Start(ctx, "RoleService.Delete")
+	defer span.End()
+
+	// Get role
+	role, err := dao.RoleDao.GetByID(ctx, id, au.Organization)
+	if err != nil {
+		return err
+	}
+
 	// Verify permission --> IsGranted
-	if !au.IsOrgAdmin {
-		if !helpers.IsGranted("CanDeleteRole", *au) {
-			return resterr.NewUnauthorizedError("Permission not granted")
-		}
+	if err := helpers.VerifyDepartmentScope(ctx, "org:role:delete", role.Department, *au); err != nil {
+		return err
+	}
+
+	if err := dao.RoleDao.Delete(ctx, id, au.Organization); err != nil {
+		return err
 	}
+	audit(ctx, au, "role:delete", models.AuditTargetRole, id, role, nil)
+
+	// Remove the relation tuples of the role
+	return helpers.RemoveObjectTuples(ctx, au.Organization, helpers.RelationObject(helpers.ObjectTypeRole, id))
+}
+
+// ValidateAssignment verifies that the role of the assignment exists in the
+// organization and that its scope is a department of the organization, it
+// needs no permission so that any user can request a role
+func (s *roleService) ValidateAssignment(ctx context.Context, assignment models.UserRole, au *models.AuthUser) (*models.Role, *resterr.RestErr) {
+	ctx, span := tracing.Start(ctx, "RoleService.ValidateAssignment")
+	defer span.End()
 
-	return dao.RoleDao.Delete(id)
+	role, err := dao.RoleDao.GetByID(ctx, assignment.RoleID, au.Organization)
+	if err != nil {
+		return nil, err
+	}
+	if err := helpers.ValidateRoleScopes(ctx, []models.UserRole{assignment}, au.Organization); err != nil {
+		return nil, err
+	}
+	return role, nil
 }
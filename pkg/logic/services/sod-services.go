@@ -0,0 +1,231 @@
+package services
+
+import (
+	"context"
+
+	"gorabc/pkg/logic/helpers"
+	"gorabc/pkg/models"
+	"gorabc/pkg/repository/dao"
+	"gorabc/pkg/utils/datetime"
+	"gorabc/pkg/utils/encrypt"
+	"gorabc/pkg/utils/resterr"
+	"gorabc/pkg/utils/tracing"
+)
+
+// SoDServiceInterface interface
+type SoDServiceInterface interface {
+	Create(context.Context, models.SoDConstraint, *models.AuthUser) (*models.SoDConstraint, *resterr.RestErr)
+	FindAll(context.Context, *models.AuthUser) (models.SoDConstraints, *resterr.RestErr)
+	GetByID(context.Context, string, *models.AuthUser) (*models.SoDConstraint, *resterr.RestErr)
+	Update(context.Context, models.SoDConstraint, *models.AuthUser) (*models.SoDConstraint, *resterr.RestErr)
+	Delete(context.Context, string, *models.AuthUser) *resterr.RestErr
+	Violations(context.Context, *models.AuthUser) ([]models.SoDViolation, *resterr.RestErr)
+}
+
+type sodService struct{}
+
+// SoDService variable
+var (
+	SoDService SoDServiceInterface = &sodService{}
+)
+
+// Create separation of duties constraint
+func (s *sodService) Create(ctx context.Context, constraint models.SoDConstraint, au *models.AuthUser) (*models.SoDConstraint, *resterr.RestErr) {
+	ctx, span := tracing.Start(ctx, "SodService.Create")
+	defer span.End()
+
+	// Validate request
+	if err := constraint.Validate(); err != nil {
+		return nil, err
+	}
+
+	// Verify permission --> IsGranted
+	if !helpers.IsGranted("org:sod:create", *au) {
+		return nil, resterr.NewForbiddenError("Permission not granted")
+	}
+
+	if err := s.verifyRoles(ctx, constraint, au); err != nil {
+		return nil, err
+	}
+
+	constraint.ID = "SOD" + encrypt.GenerateID(18)
+	constraint.Organization = au.Organization
+	constraint.Status = models.StatusActive
+	constraint.IsActive = true
+	constraint.CreatedAt = datetime.GetDateTimeString()
+	constraint.UpdatedAt = datetime.GetDateTimeString()
+
+	newConstraint, err := dao.SoDDao.Create(ctx, constraint)
+	if err != nil {
+		return nil, err
+	}
+
+	audit(ctx, au, "sod:create", models.AuditTargetSoD, newConstraint.ID, nil, newConstraint)
+	return newConstraint, nil
+}
+
+// FindAll separation of duties constraints
+func (s *sodService) FindAll(ctx context.Context, au *models.AuthUser) (models.SoDConstraints, *resterr.RestErr) {
+	ctx, span := tracing.Start(ctx, "SodService.FindAll")
+	defer span.End()
+
+	// Verify permission --> IsGranted
+	if !helpers.IsGranted("org:sod:read", *au) {
+		return nil, resterr.NewForbiddenError("Permission not granted")
+	}
+
+	return dao.SoDDao.FindAll(ctx, au.Organization)
+}
+
+// GetByID separation of duties constraint
+func (s *sodService) GetByID(ctx context.Context, id string, au *models.AuthUser) (*models.SoDConstraint, *resterr.RestErr) {
+	ctx, span := tracing.Start(ctx, "SodService.GetByID")
+	defer span.End()
+
+	// Verify permission --> IsGranted
+	if !helpers.IsGranted("org:sod:read", *au) {
+		return nil, resterr.NewForbiddenError("Permission not granted")
+	}
+
+	return dao.SoDDao.GetByID(ctx, id, au.Organization)
+}
+
+// Update separation of duties constraint
+func (s *sodService) Update(ctx context.Context, constraint models.SoDConstraint, au *models.AuthUser) (*models.SoDConstraint, *resterr.RestErr) {
+	ctx, span := tracing.Start(ctx, "SodService.Update")
+	defer span.End()
+
+	// Verify permission --> IsGranted
+	if !helpers.IsGranted("org:sod:update", *au) {
+		return nil, resterr.NewForbiddenError("Permission not granted")
+	}
+
+	current, err := dao.SoDDao.GetByID(ctx, constraint.ID, au.Organization)
+	if err != nil {
+		return nil, err
+	}
+	before := *current
+
+	if constraint.Name != "" {
+		current.Name = constraint.Name
+	}
+	if constraint.Description != "" {
+		current.Description = constraint.Description
+	}
+	if constraint.Type != "" {
+		current.Type = constraint.Type
+	}
+	if constraint.Roles != nil {
+		current.Roles = constraint.Roles
+	}
+	if constraint.Permissions != nil {
+		current.Permissions = constraint.Permissions
+	}
+	if constraint.MaxAllowed != 0 {
+		current.MaxAllowed = constraint.MaxAllowed
+	}
+
+	if err := current.Validate(); err != nil {
+		return nil, err
+	}
+	if err := s.verifyRoles(ctx, *current, au); err != nil {
+		return nil, err
+	}
+
+	current.UpdatedAt = datetime.GetDateTimeString()
+
+	if err := dao.SoDDao.Update(ctx, *current); err != nil {
+		return nil, err
+	}
+
+	audit(ctx, au, "sod:update", models.AuditTargetSoD, current.ID, before, current)
+	return current, nil
+}
+
+// Delete separation of duties constraint
+func (s *sodService) Delete(ctx context.Context, id string, au *models.AuthUser) *resterr.RestErr {
+	ctx, span := tracing.Start(ctx, "SodService.Delete")
+	defer span.End()
+
+	// Verify permission --> IsGranted
+	if !helpers.IsGranted("org:sod:delete", *au) {
+		return resterr.NewForbiddenError("Permission not granted")
+	}
+
+	current, err := dao.SoDDao.GetByID(ctx, id, au.Organization)
+	if err != nil {
+		return err
+	}
+
+	if err := dao.SoDDao.Delete(ctx, id, au.Organization); err != nil {
+		return err
+	}
+
+	audit(ctx, au, "sod:delete", models.AuditTargetSoD, id, current, nil)
+	return nil
+}
+
+// Violations reports the roles and users currently violating a static
+// constraint, e.g. assignments made before the constraint existed
+func (s *sodService) Violations(ctx context.Context, au *models.AuthUser) ([]models.SoDViolation, *resterr.RestErr) {
+	ctx, span := tracing.Start(ctx, "SodService.Violations")
+	defer span.End()
+
+	// Verify permission --> IsGranted
+	if !helpers.IsGranted("org:sod:read", *au) {
+		return nil, resterr.NewForbiddenError("Permission not granted")
+	}
+
+	constraints, err := dao.SoDDao.FindAll(ctx, au.Organization)
+	if err != nil {
+		return nil, err
+	}
+
+	violations := []models.SoDViolation{}
+
+	// Roles combining conflicting permissions
+	roles, err := dao.RoleDao.FindAll(ctx, au.Organization)
+	if err != nil {
+		return nil, err
+	}
+	for i := 0; i < len(roles); i++ {
+		role, err := dao.RoleDao.GetByID(ctx, roles[i].ID, au.Organization)
+		if err != nil {
+			return nil, err
+		}
+		for j := 0; j < len(constraints); j++ {
+			if constraints[j].Type != models.SoDStatic {
+				continue
+			}
+			if violation := helpers.EvaluateSoD(constraints[j], []string{role.ID}, role.Permissions); violation != nil {
+				violation.RoleID = role.ID
+				violations = append(violations, *violation)
+			}
+		}
+	}
+
+	// Users
+	users, err := dao.UserDao.FindAll(ctx, au.Organization)
+	if err != nil {
+		return nil, err
+	}
+	for i := 0; i < len(users); i++ {
+		user, err := dao.UserDao.GetByID(ctx, users[i].ID, users[i].Organization)
+		if err != nil {
+			return nil, err
+		}
+		violations = append(violations, helpers.FindUserSoDViolations(*user, constraints, models.SoDStatic)...)
+	}
+
+	return violations, nil
+}
+
+// verifyRoles of the constraint belong to the organization
+func (s *sodService) verifyRoles(ctx context.Context, constraint models.SoDConstraint, au *models.AuthUser) *resterr.RestErr {
+	for i := 0; i < len(constraint.Roles); i++ {
+		if _, err := dao.RoleDao.GetByID(ctx, constraint.Roles[i], au.Organization); err != nil {
+			return resterr.NewBadRequestError("Invalid role: " + constraint.Roles[i])
+		}
+	}
+	return nil
+}
@@ -0,0 +1,141 @@
+package services
+
+import (
+	"context"
+
+	"gorabc/pkg/logic/helpers"
+	"gorabc/pkg/models"
+	"gorabc/pkg/repository/dao"
+	"gorabc/pkg/utils/datetime"
+	"gorabc/pkg/utils/encrypt"
+	"gorabc/pkg/utils/resterr"
+	"gorabc/pkg/utils/tracing"
+)
+
+// TemplateServiceInterface interface
+type TemplateServiceInterface interface {
+	FindAll(context.Context, *models.AuthUser) (*models.RoleTemplateCatalogue, *resterr.RestErr)
+	Instantiate(context.Context, string, models.InstantiateTemplateRequest, *models.AuthUser) (*models.Role, *resterr.RestErr)
+	ApplyPreset(context.Context, string, string) *resterr.RestErr
+}
+
+type templateService struct{}
+
+// TemplateService variable
+var (
+	TemplateService TemplateServiceInterface = &templateService{}
+)
+
+// FindAll role templates and presets
+func (s *templateService) FindAll(ctx context.Context, au *models.AuthUser) (*models.RoleTemplateCatalogue, *resterr.RestErr) {
+	ctx, span := tracing.Start(ctx, "TemplateService.FindAll")
+	defer span.End()
+
+	// Verify permission --> IsGranted
+	if helpers.GetScope("org:role:read", *au).IsEmpty() {
+		return nil, resterr.NewForbiddenError("Permission not granted")
+	}
+
+	catalogue := helpers.GetTemplateCatalogue()
+	return &catalogue, nil
+}
+
+// Instantiate a role template into a department
+func (s *templateService) Instantiate(ctx context.Context, key string, request models.InstantiateTemplateRequest, au *models.AuthUser) (*models.Role, *resterr.RestErr) {
+	ctx, span := tracing.Start(ctx, "TemplateService.Instantiate")
+	defer span.End()
+
+	template, ok := helpers.GetRoleTemplate(key)
+	if !ok {
+		return nil, resterr.NewNotFoundError("Role template not found")
+	}
+
+	roleRequest := models.CreateRoleRequest{
+		Name:        template.Name,
+		Department:  request.Department,
+		Permissions: template.Permissions,
+	}
+	if request.Name != "" {
+		roleRequest.Name = request.Name
+	}
+	if err := roleRequest.Validate(); err != nil {
+		return nil, err
+	}
+	role := roleRequest.Role()
+	role.Template = template.Key
+	role.TemplateVersion = template.Version
+
+	// Scope and permission checks of a regular role creation
+	return createRole(ctx, role, au)
+}
+
+// ApplyPreset creates the departments and roles of a preset in a new organization
+func (s *templateService) ApplyPreset(ctx context.Context, key string, org string) *resterr.RestErr {
+	ctx, span := tracing.Start(ctx, "TemplateService.ApplyPreset")
+	defer span.End()
+
+	preset, ok := helpers.GetPreset(key)
+	if !ok {
+		return resterr.NewBadRequestError("Invalid preset: " + key)
+	}
+
+	// Recorded as changes of gorabc itself
+	system := &models.AuthUser{ID: models.ActorSystem, Organization: org}
+
+	for i := 0; i < len(preset.Departments); i++ {
+		dept := models.Department{}
+		dept.ID = "DEPT" + encrypt.GenerateID(17)
+		dept.Organization = org
+		dept.Name = preset.Departments[i].Name
+		dept.Status = models.StatusActive
+		dept.IsActive = true
+		dept.CreatedAt = datetime.GetDateTimeString()
+		dept.UpdatedAt = datetime.GetDateTimeString()
+		dept.BuildPath(nil)
+
+		newDept, err := dao.DepartmentDao.Create(ctx, dept)
+		if err != nil {
+			return err
+		}
+		if err := helpers.MirrorDepartment(ctx, *newDept); err != nil {
+			return err
+		}
+		audit(ctx, system, "department:create", models.AuditTargetDepartment, newDept.ID, nil, newDept)
+
+		for j := 0; j < len(preset.Departments[i].Templates); j++ {
+			template, ok := helpers.GetRoleTemplate(preset.Departments[i].Templates[j])
+			if !ok {
+				return resterr.NewInternalServerError("Unknown role template: " + preset.Departments[i].Templates[j])
+			}
+
+			role := models.Role{}
+			role.ID = "ROLE" + encrypt.GenerateID(17)
+			role.Organization = org
+			role.Department = newDept.ID
+			role.Name = template.Name
+			role.Template = template.Key
+			role.TemplateVersion = template.Version
+			role.Permissions = template.Permissions
+			role.Status = models.StatusActive
+			role.IsActive = true
+			role.CreatedAt = datetime.GetDateTimeString()
+			role.UpdatedAt = datetime.GetDateTimeString()
+
+			rolePermList, err := helpers.AssignRolePermissions(ctx, role)
+			if err != nil {
+				return err
+			}
+			role.Permissions = *rolePermList
+
+			newRole, err := dao.RoleDao.Create(ctx, role)
+			if err != nil {
+				return err
+			}
+			if err := helpers.MirrorRole(ctx, *newRole); err != nil {
+				return err
+			}
+			audit(ctx, system, "role:create", models.AuditTargetRole, newRole.ID, nil, newRole)
+		}
+	}
+	return nil
+}
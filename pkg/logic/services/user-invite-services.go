@@ -0,0 +1,249 @@
+package services
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"gorabc/pkg/logic/helpers"
+	"gorabc/pkg/middlewares/jwt"
+	"gorabc/pkg/models"
+	"gorabc/pkg/repository/dao"
+	"gorabc/pkg/utils/datetime"
+	"gorabc/pkg/utils/encrypt"
+	"gorabc/pkg/utils/mailer"
+	"gorabc/pkg/utils/resterr"
+	"gorabc/pkg/utils/tracing"
+)
+
+// Invite creates a pending user with its roles and departments and emails a
+// signed, expiring invite link, the invitee chooses the password on acceptance
+func (s *userService) Invite(ctx context.Context, request models.InviteUserRequest, au *models.AuthUser) (*models.UserInvite, *resterr.RestErr) {
+	ctx, span := tracing.Start(ctx, "UserService.Invite")
+	defer span.End()
+
+	if err := request.Validate(); err != nil {
+		return nil, err
+	}
+
+	user := models.User{
+		Email:       request.Email,
+		Firstname:   request.Firstname,
+		Lastname:    request.Lastname,
+		Departments: request.Departments,
+		Roles:       request.Roles,
+		Attributes:  request.Attributes,
+		// unusable until the invite is accepted
+		Password: encrypt.GenerateToken(32),
+	}
+
+	// Permission, scope and separation of duties checks of a regular creation
+	newUser, err := s.create(ctx, user, models.StatusPending, au)
+	if err != nil {
+		return nil, err
+	}
+
+	now := datetime.GetDateTime()
+	invite := models.UserInvite{
+		ID:           "UI" + encrypt.GenerateID(18),
+		Organization: newUser.Organization,
+		UserID:       newUser.ID,
+		Email:        newUser.Email,
+		Nonce:        encrypt.GenerateToken(16),
+		Status:       models.InviteStatusPending,
+		InvitedBy:    au.ID,
+		SentCount:    1,
+		CreatedAt:    datetime.FormatDateTime(now),
+		SentAt:       datetime.FormatDateTime(now),
+		ExpiresAt:    datetime.FormatDateTime(now.Add(time.Duration(request.ExpiresInHours) * time.Hour)),
+	}
+
+	newInvite, err := dao.UserInviteDao.Create(ctx, invite)
+	if err != nil {
+		return nil, err
+	}
+	audit(ctx, au, "user:invite", models.AuditTargetUserInvite, newInvite.ID, nil, newInvite)
+
+	// An invite that was never delivered is revoked with its pending user, the
+	// email stays free to invite again
+	if err := mailer.Send(helpers.InviteMessage(*newInvite)); err != nil {
+		if revokeErr := s.RevokeInvite(ctx, newInvite.ID, au); revokeErr != nil {
+			log.Printf("Revoking undelivered invite %s failed: %s", newInvite.ID, revokeErr.Message)
+		}
+		return nil, err
+	}
+	return newInvite, nil
+}
+
+// FindInvites lists the pending invites within the scope of the user
+func (s *userService) FindInvites(ctx context.Context, au *models.AuthUser) (models.UserInvites, *resterr.RestErr) {
+	ctx, span := tracing.Start(ctx, "UserService.FindInvites")
+	defer span.End()
+
+	// Verify permission --> IsGranted
+	scope := helpers.GetScope("org:user:read", *au)
+	if scope.IsEmpty() {
+		return nil, resterr.NewForbiddenError("Permission not granted")
+	}
+
+	invites, err := dao.UserInviteDao.FindPending(ctx, au.Organization)
+	if err != nil {
+		return nil, err
+	}
+	if scope.Unrestricted() {
+		return invites, nil
+	}
+
+	// Factor out invites of users outside of the granted subtrees
+	result := models.UserInvites{}
+	for i := 0; i < len(invites); i++ {
+		if _, err := s.getInvite(ctx, invites[i].ID, "org:user:read", au); err == nil {
+			result = append(result, invites[i])
+		}
+	}
+	return result, nil
+}
+
+// ResendInvite with a new link, the previous link stops working
+func (s *userService) ResendInvite(ctx context.Context, id string, request models.ResendInviteRequest, au *models.AuthUser) (*models.UserInvite, *resterr.RestErr) {
+	ctx, span := tracing.Start(ctx, "UserService.ResendInvite")
+	defer span.End()
+
+	if err := models.ValidateInviteExpiry(&request.ExpiresInHours); err != nil {
+		return nil, err
+	}
+
+	invite, err := s.getInvite(ctx, id, "org:user:create", au)
+	if err != nil {
+		return nil, err
+	}
+	before := *invite
+
+	now := datetime.GetDateTime()
+	previousNonce := invite.Nonce
+	invite.Nonce = encrypt.GenerateToken(16)
+	invite.SentCount++
+	invite.SentAt = datetime.FormatDateTime(now)
+	invite.ExpiresAt = datetime.FormatDateTime(now.Add(time.Duration(request.ExpiresInHours) * time.Hour))
+
+	if err := dao.UserInviteDao.Update(ctx, *invite, previousNonce); err != nil {
+		return nil, err
+	}
+	audit(ctx, au, "user:invite_resend", models.AuditTargetUserInvite, invite.ID, before, invite)
+
+	if err := mailer.Send(helpers.InviteMessage(*invite)); err != nil {
+		return nil, err
+	}
+	return invite, nil
+}
+
+// RevokeInvite and remove the pending user
+func (s *userService) RevokeInvite(ctx context.Context, id string, au *models.AuthUser) *resterr.RestErr {
+	ctx, span := tracing.Start(ctx, "UserService.RevokeInvite")
+	defer span.End()
+
+	invite, err := s.getInvite(ctx, id, "org:user:create", au)
+	if err != nil {
+		return err
+	}
+	before := *invite
+
+	previousNonce := invite.Nonce
+	invite.Status = models.InviteStatusRevoked
+	invite.Nonce = ""
+	if err := dao.UserInviteDao.Update(ctx, *invite, previousNonce); err != nil {
+		return err
+	}
+
+	if err := dao.UserDao.Delete(ctx, invite.UserID, invite.Organization); err != nil {
+		return err
+	}
+	audit(ctx, au, "user:invite_revoke", models.AuditTargetUserInvite, invite.ID, before, invite)
+
+	// Remove the relation tuples of the pending user
+	return helpers.RemoveObjectTuples(ctx, invite.Organization, helpers.RelationObject(helpers.ObjectTypeUser, invite.UserID))
+}
+
+// AcceptInvite sets the password of the invitee and activates the user
+func (s *userService) AcceptInvite(ctx context.Context, request models.AcceptInviteRequest) (*models.User, *resterr.RestErr) {
+	ctx, span := tracing.Start(ctx, "UserService.AcceptInvite")
+	defer span.End()
+
+	if err := request.Validate(); err != nil {
+		return nil, err
+	}
+
+	// Verify the signature and the expiry of the link
+	claims, err := jwt.DecodeInviteToken(request.Token)
+	if err != nil {
+		return nil, err
+	}
+
+	invite, err := dao.UserInviteDao.GetByToken(ctx, claims.InviteID, claims.Nonce)
+	if err != nil {
+		return nil, resterr.NewBadRequestError("Invite is no longer valid")
+	}
+	if invite.Status != models.InviteStatusPending {
+		return nil, resterr.NewBadRequestError("Invite is no longer valid")
+	}
+	if invite.ExpiresAt <= datetime.GetDateTimeString() {
+		return nil, resterr.NewBadRequestError("Invite token is expired")
+	}
+
+	user, err := dao.UserDao.GetByID(ctx, invite.UserID, invite.Organization)
+	if err != nil {
+		return nil, err
+	}
+	if user.Status != models.StatusPending {
+		return nil, resterr.NewBadRequestError("Invite is no longer valid")
+	}
+
+	// Use the invite once
+	before := *invite
+	invite.Status = models.InviteStatusUsed
+	invite.Nonce = ""
+	invite.AcceptedAt = datetime.GetDateTimeString()
+	if err := dao.UserInviteDao.Update(ctx, *invite, claims.Nonce); err != nil {
+		return nil, err
+	}
+
+	user.Password = encrypt.GetMd5(request.Password)
+	user.Status = models.StatusActive
+	user.IsActive = true
+	user.UpdatedAt = datetime.GetDateTimeString()
+	if err := dao.UserDao.Update(ctx, *user); err != nil {
+		return nil, err
+	}
+
+	// The invitee acts on their own behalf
+	invitee := &models.AuthUser{ID: user.ID, Organization: user.Organization, IP: request.IP, RequestID: request.RequestID}
+	audit(ctx, invitee, "user:invite_accept", models.AuditTargetUserInvite, invite.ID, before, invite)
+
+	return user, nil
+}
+
+// getInvite of the organization whose pending user is within the scope of the permission
+func (s *userService) getInvite(ctx context.Context, id string, permission string, au *models.AuthUser) (*models.UserInvite, *resterr.RestErr) {
+	// Verify permission --> IsGranted
+	if helpers.GetScope(permission, *au).IsEmpty() {
+		return nil, resterr.NewForbiddenError("Permission not granted")
+	}
+
+	invite, err := dao.UserInviteDao.GetByID(ctx, id, au.Organization)
+	if err != nil {
+		return nil, err
+	}
+	if invite.Status != models.InviteStatusPending {
+		return nil, resterr.NewNotFoundError("Pending invite not found")
+	}
+
+	// Verify department scope
+	user, err := dao.UserDao.GetByID(ctx, invite.UserID, invite.Organization)
+	if err != nil {
+		return nil, err
+	}
+	if err := helpers.VerifyUserScope(ctx, permission, *user, *au); err != nil {
+		return nil, err
+	}
+	return invite, nil
+}
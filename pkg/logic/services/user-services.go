@@ -1,22 +1,33 @@
 package services
 
 import (
+	"context"
+
 	"gorabc/pkg/logic/helpers"
 	"gorabc/pkg/models"
 	"gorabc/pkg/repository/dao"
 	"gorabc/pkg/utils/datetime"
 	"gorabc/pkg/utils/encrypt"
 	"gorabc/pkg/utils/resterr"
+	"gorabc/pkg/utils/tracing"
 )
 
 // UserServiceInterface interface
 type UserServiceInterface interface {
-	Create(models.User, *models.AuthUser) (*models.User, *resterr.RestErr)
-	FindAll(*models.AuthUser) (models.Users, *resterr.RestErr)
-	GetByID(string, *models.AuthUser) (*models.User, *resterr.RestErr)
-	Update(models.User, *models.AuthUser) (*models.User, *resterr.RestErr)
-	UpdatePassword(models.User, *models.AuthUser) (*models.User, *resterr.RestErr)
-	Delete(string, *models.AuthUser) *resterr.RestErr
+	Create(context.Context, models.CreateUserRequest, *models.AuthUser) (*models.User, *resterr.RestErr)
+	FindAll(context.Context, *models.AuthUser) (models.Users, *resterr.RestErr)
+	GetByID(context.Context, string, *models.AuthUser) (*models.User, *resterr.RestErr)
+	Update(context.Context, string, models.UpdateUserRequest, *models.AuthUser) (*models.User, *resterr.RestErr)
+	UpdatePassword(context.Context, string, models.UpdatePasswordRequest, *models.AuthUser) (*models.User, *resterr.RestErr)
+	SetOrgAdmin(context.Context, string, models.OrgAdminRequest, *models.AuthUser) (*models.User, *resterr.RestErr)
+	Delete(context.Context, string, *models.AuthUser) *resterr.RestErr
+	GetEffectivePermissions(context.Context, string, *models.AuthUser) ([]models.PermissionDecision, *resterr.RestErr)
+	GetGrantEvents(context.Context, string, *models.AuthUser) (models.GrantEvents, *resterr.RestErr)
+	Invite(context.Context, models.InviteUserRequest, *models.AuthUser) (*models.UserInvite, *resterr.RestErr)
+	FindInvites(context.Context, *models.AuthUser) (models.UserInvites, *resterr.RestErr)
+	ResendInvite(context.Context, string, models.ResendInviteRequest, *models.AuthUser) (*models.UserInvite, *resterr.RestErr)
+	RevokeInvite(context.Context, string, *models.AuthUser) *resterr.RestErr
+	AcceptInvite(context.Context, models.AcceptInviteRequest) (*models.User, *resterr.RestErr)
 }
 
 type userService struct{}
@@ -27,39 +38,70 @@ var (
 )
 
 // Create user
-func (s *userService) Create(user models.User, au *models.AuthUser) (*models.User, *resterr.RestErr) {
-	if err := user.Validate(); err != nil {
+func (s *userService) Create(ctx context.Context, request models.CreateUserRequest, au *models.AuthUser) (*models.User, *resterr.RestErr) {
+	ctx, span := tracing.Start(ctx, "UserService.Create")
+	defer span.End()
+
+	if err := request.Validate(); err != nil {
+		return nil, err
+	}
+
+	return s.create(ctx, request.User(), models.StatusActive, au)
+}
+
+// create a user with the given status, pending users are invited users that
+// have not chosen their password yet
+func (s *userService) create(ctx context.Context, user models.User, status string, au *models.AuthUser) (*models.User, *resterr.RestErr) {
+	user, err := prepareCreate(ctx, user, status, au)
+	if err != nil {
+		return nil, err
+	}
+
+	// Create new user
+	newUser, err := dao.UserDao.Create(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+
+	// Mirror memberships into relation tuples
+	if err := helpers.MirrorUser(ctx, *newUser); err != nil {
 		return nil, err
 	}
 
+	audit(ctx, au, "user:create", models.AuditTargetUser, newUser.ID, nil, newUser)
+	return newUser, nil
+}
+
+// prepareCreate builds the new user and runs every check of its creation
+// without writing it, the dry run of an import runs the same checks
+func prepareCreate(ctx context.Context, user models.User, status string, au *models.AuthUser) (models.User, *resterr.RestErr) {
 	// Verify permission --> IsGranted
-	if !au.IsOrgAdmin {
-		if !helpers.IsGranted("CanCreateUser", *au) {
-			return nil, resterr.NewUnauthorizedError("Permission not granted")
-		}
+	if helpers.GetScope("org:user:create", *au).IsEmpty() {
+		return user, resterr.NewForbiddenError("Permission not granted")
 	}
 
 	// Verify unique email
-	_, emailErr := dao.UserDao.GetByEmail(user.Email)
+	_, emailErr := dao.UserDao.GetByEmail(ctx, user.Email)
 	if emailErr == nil {
-		return nil, resterr.NewBadRequestError("Email already registered")
+		return user, resterr.NewConflictError("Email already registered")
 	}
 
 	user.ID = "U" + encrypt.GenerateID(20)
 	user.Organization = au.Organization
 	user.Password = encrypt.GetMd5(user.Password)
-	user.Status = models.StatusActive
-	user.IsActive = true
+	user.Status = status
+	user.IsActive = status == models.StatusActive
 	user.IsOrgAdmin = false
+	user.IsSuperuser = false
 	user.CreatedAt = datetime.GetDateTimeString()
 	user.UpdatedAt = datetime.GetDateTimeString()
 
 	// Add user roles
 	if len(user.Roles) > 0 {
 		// validate roles
-		userRoleList, roleDeptList, rolePermList, err := helpers.AssignUserRoles(user)
+		userRoleList, roleDeptList, rolePermList, err := helpers.AssignUserRoles(ctx, user)
 		if err != nil {
-			return nil, err
+			return user, err
 		}
 		user.Roles = *userRoleList
 		user.Departments = *roleDeptList
@@ -69,81 +111,167 @@ func (s *userService) Create(user models.User, au *models.AuthUser) (*models.Use
 
 	// Add user departments
 	if len(user.Departments) > 0 {
-		userDeptList, err := helpers.AssignUserDepartments(user)
+		userDeptList, err := helpers.AssignUserDepartments(ctx, user)
 		if err != nil {
-			return nil, err
+			return user, err
 		}
 		user.Departments = *userDeptList
 	}
 
 	// Add user permissions
 	if len(user.Permissions) > 0 {
-		userPermList, err := helpers.AssignUserPermissions(user)
+		userPermList, err := helpers.AssignUserPermissions(ctx, user)
 		if err != nil {
-			return nil, err
+			return user, err
 		}
 		user.Permissions = *userPermList
 	}
 
-	// Create new user
-	newUser, err := dao.UserDao.Create(user)
-	if err != nil {
-		return nil, err
+	// Add user denies, direct ones and those of the roles
+	if len(user.Denies) > 0 || len(user.Roles) > 0 {
+		roleDenyList, err := helpers.AssignRolesDenyToUser(ctx, user)
+		if err != nil {
+			return user, err
+		}
+		userDenyList, err := helpers.AssignUserDenies(ctx, user, *roleDenyList)
+		if err != nil {
+			return user, err
+		}
+		user.Denies = *userDenyList
 	}
 
-	return newUser, nil
+	// Verify the caller holds what the new user is granted
+	if err := helpers.VerifyUserGrants(ctx, models.User{}, user, *au); err != nil {
+		return user, err
+	}
+	if err := helpers.VerifyAttributeChange(ctx, models.User{}, user, *au); err != nil {
+		return user, err
+	}
+
+	// Verify separation of duties of the roles and permissions together
+	if err := helpers.VerifyStaticSoD(ctx, user); err != nil {
+		return user, err
+	}
+
+	// Verify department scope of the new user
+	if err := helpers.VerifyAssignmentScope(ctx, "org:user:create", user, *au); err != nil {
+		return user, err
+	}
+	return user, nil
 }
 
 // FindAll active users
-func (s *userService) FindAll(au *models.AuthUser) (models.Users, *resterr.RestErr) {
+func (s *userService) FindAll(ctx context.Context, au *models.AuthUser) (models.Users, *resterr.RestErr) {
+	ctx, span := tracing.Start(ctx, "UserService.FindAll")
+	defer span.End()
+
 	// Verify permission --> IsGranted
-	if !au.IsOrgAdmin {
-		if !helpers.IsGranted("CanReadUser", *au) {
-			return nil, resterr.NewUnauthorizedError("Permission not granted")
-		}
+	scope := helpers.GetScope("org:user:read", *au)
+	if scope.IsEmpty() {
+		return nil, resterr.NewForbiddenError("Permission not granted")
 	}
 
 	org := au.Organization
-	users, err := dao.UserDao.FindAll(org)
+	users, err := dao.UserDao.FindAll(ctx, org)
 	if err != nil {
 		return nil, err
 	}
-	return users, nil
+
+	if scope.Unrestricted() {
+		return users, nil
+	}
+
+	// Factor out users outside of the granted subtrees
+	index, err := helpers.GetDepartmentIndex(ctx, org)
+	if err != nil {
+		return nil, err
+	}
+
+	result := models.Users{}
+	for i := 0; i < len(users); i++ {
+		if scope.AllowsAll(helpers.UserDepartmentIDs(users[i]), index) {
+			result = append(result, users[i])
+		}
+	}
+	return result, nil
 }
 
-func (s *userService) GetByID(id string, au *models.AuthUser) (*models.User, *resterr.RestErr) {
+func (s *userService) GetByID(ctx context.Context, id string, au *models.AuthUser) (*models.User, *resterr.RestErr) {
+	ctx, span := tracing.Start(ctx, "UserService.GetByID")
+	defer span.End()
+
 	// Verify permission --> IsGranted
-	if !au.IsOrgAdmin {
-		if !helpers.IsGranted("CanReadUser", *au) {
-			return nil, resterr.NewUnauthorizedError("Permission not granted")
-		}
+	if helpers.GetScope("org:user:read", *au).IsEmpty() {
+		return nil, resterr.NewForbiddenError("Permission not granted")
 	}
 
-	user, err := dao.UserDao.GetByID(id)
+	user, err := dao.UserDao.GetByID(ctx, id, au.Organization)
 	if err != nil {
 		return nil, err
 	}
 
-	// Verify organization
-	if !au.IsSuperuser && user.Organization != au.Organization {
-		return nil, resterr.NewUnauthorizedError("Unauthorized request")
+	// Verify department scope
+	if !au.IsSuperuser {
+		if err := helpers.VerifyUserScope(ctx, "org:user:read", *user, *au); err != nil {
+			return nil, err
+		}
 	}
 
 	return user, nil
 }
 
-func (s *userService) Update(user models.User, au *models.AuthUser) (*models.User, *resterr.RestErr) {
-	// Verify permission --> IsGranted
-	if !au.IsOrgAdmin {
-		if !helpers.IsGranted("CanUpdateUser", *au) {
-			return nil, resterr.NewUnauthorizedError("Permission not granted")
-		}
+func (s *userService) Update(ctx context.Context, id string, request models.UpdateUserRequest, au *models.AuthUser) (*models.User, *resterr.RestErr) {
+	ctx, span := tracing.Start(ctx, "UserService.Update")
+	defer span.End()
+
+	if err := request.Validate(); err != nil {
+		return nil, err
 	}
 
-	current, err := s.GetByID(user.ID, au)
+	before, current, err := prepareUpdate(ctx, id, request, au)
 	if err != nil {
 		return nil, err
 	}
+	current.UpdatedAt = datetime.GetDateTimeString()
+
+	// Update user
+	if updateErr := dao.UserDao.Update(ctx, *current); updateErr != nil {
+		return nil, updateErr
+	}
+
+	// Mirror memberships into relation tuples
+	if err := helpers.MirrorUser(ctx, *current); err != nil {
+		return nil, err
+	}
+
+	audit(ctx, au, "user:update", models.AuditTargetUser, current.ID, before, current)
+	return current, nil
+}
+
+// prepareUpdate applies the request to the current user and runs every check
+// of the update without writing it, the dry run of an import runs the same
+// checks. It returns the user before and after the update.
+func prepareUpdate(ctx context.Context, id string, request models.UpdateUserRequest, au *models.AuthUser) (models.User, *models.User, *resterr.RestErr) {
+	user := request.User(id)
+
+	// Verify permission --> IsGranted
+	if helpers.GetScope("org:user:update", *au).IsEmpty() {
+		return models.User{}, nil, resterr.NewForbiddenError("Permission not granted")
+	}
+
+	current, err := UserService.GetByID(ctx, user.ID, au)
+	if err != nil {
+		return models.User{}, nil, err
+	}
+	before := *current
+
+	// Verify department scope of the current user
+	if err := helpers.VerifyUserScope(ctx, "org:user:update", *current, *au); err != nil {
+		return before, nil, err
+	}
+	if err := helpers.VerifyAdminTarget(*current, *au); err != nil {
+		return before, nil, err
+	}
 
 	// set request user organization
 	user.Organization = current.Organization
@@ -159,18 +287,22 @@ func (s *userService) Update(user models.User, au *models.AuthUser) (*models.Use
 
 	if user.Email != "" && user.Email != current.Email {
 		// verify unique email
-		_, emailErr := dao.UserDao.GetByEmail(user.Email)
+		_, emailErr := dao.UserDao.GetByEmail(ctx, user.Email)
 		if emailErr == nil {
-			return nil, resterr.NewBadRequestError("Email already registered")
+			return before, nil, resterr.NewConflictError("Email already registered")
 		}
 		current.Email = user.Email
 	}
 
+	if user.Attributes != nil {
+		current.Attributes = user.Attributes
+	}
+
 	if len(user.Roles) > 0 {
 		// validate roles
-		userRoleList, roleDeptList, rolePermList, err := helpers.AssignUserRoles(user)
+		userRoleList, roleDeptList, rolePermList, err := helpers.AssignUserRoles(ctx, user)
 		if err != nil {
-			return nil, err
+			return before, nil, err
 		}
 		user.Roles = *userRoleList
 		user.Departments = *roleDeptList
@@ -182,68 +314,254 @@ func (s *userService) Update(user models.User, au *models.AuthUser) (*models.Use
 
 	// Add user departments
 	if len(user.Departments) > 0 {
-		userDeptList, err := helpers.AssignUserDepartments(user)
+		userDeptList, err := helpers.AssignUserDepartments(ctx, user)
 		if err != nil {
-			return nil, err
+			return before, nil, err
 		}
 		current.Departments = *userDeptList
 	}
 
 	if len(user.Permissions) > 0 {
 		// Validate permission request
-		userPermList, err := helpers.AssignUserPermissions(user)
+		userPermList, err := helpers.AssignUserPermissions(ctx, user)
 		if err != nil {
-			return nil, err
+			return before, nil, err
 		}
 
 		current.Permissions = *userPermList
 	}
 
-	current.UpdatedAt = datetime.GetDateTimeString()
+	if user.Denies != nil || len(user.Roles) > 0 {
+		// keep the current role denies unless the roles changed
+		roleDenyList := []models.Permission{}
+		directDenyList := []models.Permission{}
+		for i := 0; i < len(current.Denies); i++ {
+			if current.Denies[i].Source == models.SourceUser {
+				directDenyList = append(directDenyList, current.Denies[i])
+			} else {
+				roleDenyList = append(roleDenyList, current.Denies[i])
+			}
+		}
+		if len(user.Roles) > 0 {
+			newRoleDenyList, err := helpers.AssignRolesDenyToUser(ctx, user)
+			if err != nil {
+				return before, nil, err
+			}
+			roleDenyList = *newRoleDenyList
+		}
+		if user.Denies == nil {
+			user.Denies = directDenyList
+		}
 
-	// Update user
-	if updateErr := dao.UserDao.Update(*current); updateErr != nil {
-		return nil, updateErr
+		userDenyList, err := helpers.AssignUserDenies(ctx, user, roleDenyList)
+		if err != nil {
+			return before, nil, err
+		}
+		current.Denies = *userDenyList
 	}
 
-	return current, nil
+	// Verify the caller holds what the user is granted
+	if err := helpers.VerifyUserGrants(ctx, before, *current, *au); err != nil {
+		return before, nil, err
+	}
+	if err := helpers.VerifyAttributeChange(ctx, before, *current, *au); err != nil {
+		return before, nil, err
+	}
+
+	// Verify separation of duties of the roles and permissions together
+	if err := helpers.VerifyStaticSoD(ctx, *current); err != nil {
+		return before, nil, err
+	}
+
+	// Verify department scope of the updated user
+	if err := helpers.VerifyAssignmentScope(ctx, "org:user:update", *current, *au); err != nil {
+		return before, nil, err
+	}
+	return before, current, nil
 }
 
-func (s *userService) UpdatePassword(user models.User, au *models.AuthUser) (*models.User, *resterr.RestErr) {
+func (s *userService) UpdatePassword(ctx context.Context, id string, request models.UpdatePasswordRequest, au *models.AuthUser) (*models.User, *resterr.RestErr) {
+	ctx, span := tracing.Start(ctx, "UserService.UpdatePassword")
+	defer span.End()
+
+	// Passwords are never changed by a superuser acting as the user
+	if au.Act != nil {
+		return nil, resterr.NewForbiddenError("Passwords cannot be changed while impersonating")
+	}
+
+	if err := request.Validate(); err != nil {
+		return nil, err
+	}
+
 	// Get current user from db
-	current, err := s.GetByID(user.ID, au)
+	current, err := s.GetByID(ctx, id, au)
 	if err != nil {
 		return nil, err
 	}
 
 	// Verify permission --> IsGranted
 	if !au.IsOrgAdmin && au.ID != current.ID {
-		return nil, resterr.NewUnauthorizedError("Unauthorized request")
+		return nil, resterr.NewForbiddenError("Unauthorized request")
 	}
 
-	// Verify password field
-	if user.Password != "" {
-		user.Password = encrypt.GetMd5(user.Password)
-		current.Password = user.Password
-	}
+	before := *current
+
+	current.Password = encrypt.GetMd5(request.Password)
 
 	current.UpdatedAt = datetime.GetDateTimeString()
 
 	// Update user
-	if updateErr := dao.UserDao.Update(*current); updateErr != nil {
+	if updateErr := dao.UserDao.Update(ctx, *current); updateErr != nil {
 		return nil, updateErr
 	}
 
+	audit(ctx, au, "user:password_update", models.AuditTargetUser, current.ID, before, current)
 	return current, nil
 }
 
-func (s *userService) Delete(id string, au *models.AuthUser) *resterr.RestErr {
-	// Verify permission --> IsGranted
-	if !au.IsOrgAdmin {
-		if !helpers.IsGranted("CanDeleteUser", *au) {
-			return resterr.NewUnauthorizedError("Permission not granted")
+// SetOrgAdmin promotes or demotes an organization admin, organizations keep
+// at least one active admin
+func (s *userService) SetOrgAdmin(ctx context.Context, id string, request models.OrgAdminRequest, au *models.AuthUser) (*models.User, *resterr.RestErr) {
+	ctx, span := tracing.Start(ctx, "UserService.SetOrgAdmin")
+	defer span.End()
+
+	if err := request.Validate(); err != nil {
+		return nil, err
+	}
+
+	// Verify permission --> only admins appoint admins
+	if !au.IsOrgAdmin && !au.IsSuperuser {
+		return nil, resterr.NewForbiddenError("Permission not granted")
+	}
+
+	before, current, err := setOrgAdmin(ctx, id, au.Organization, *request.IsOrgAdmin)
+	if err != nil {
+		return nil, err
+	}
+	if before.IsOrgAdmin != current.IsOrgAdmin {
+		audit(ctx, au, orgAdminAction(current.IsOrgAdmin), models.AuditTargetUser, current.ID, before, current)
+	}
+	return current, nil
+}
+
+// setOrgAdmin promotes or demotes a user of the organization and returns the
+// user before and after the change, the last active admin is never demoted
+func setOrgAdmin(ctx context.Context, id string, org string, isOrgAdmin bool) (models.User, *models.User, *resterr.RestErr) {
+	current, err := dao.UserDao.GetByID(ctx, id, org)
+	if err != nil {
+		return models.User{}, nil, err
+	}
+	if current.Organization == "" {
+		return models.User{}, nil, resterr.NewForbiddenError("Unauthorized request")
+	}
+	before := *current
+	if current.IsOrgAdmin == isOrgAdmin {
+		return before, current, nil
+	}
+
+	// Verify the organization keeps an admin
+	if !isOrgAdmin {
+		users, err := dao.UserDao.FindAll(ctx, current.Organization)
+		if err != nil {
+			return before, nil, err
+		}
+		admins := 0
+		for i := 0; i < len(users); i++ {
+			if users[i].IsOrgAdmin && users[i].IsActive {
+				admins++
+			}
+		}
+		if current.IsActive && admins <= 1 {
+			return before, nil, resterr.NewConflictError("Organization needs at least one admin")
 		}
 	}
 
-	return dao.UserDao.Delete(id)
+	current.IsOrgAdmin = isOrgAdmin
+	current.UpdatedAt = datetime.GetDateTimeString()
+
+	if updateErr := dao.UserDao.Update(ctx, *current); updateErr != nil {
+		return before, nil, updateErr
+	}
+
+	// Mirror the admin relation into relation tuples
+	if err := helpers.MirrorUser(ctx, *current); err != nil {
+		return before, nil, err
+	}
+	return before, current, nil
+}
+
+// orgAdminAction of the audit log for a promotion or a demotion
+func orgAdminAction(isOrgAdmin bool) string {
+	if isOrgAdmin {
+		return "user:org_admin_grant"
+	}
+	return "user:org_admin_revoke"
+}
+
+func (s *userService) Delete(ctx context.Context, id string, au *models.AuthUser) *resterr.RestErr {
+	ctx, span := tracing.Start(ctx, "UserService.Delete")
+	defer span.End()
+
+	// Verify permission --> IsGranted
+	if helpers.GetScope("org:user:delete", *au).IsEmpty() {
+		return resterr.NewForbiddenError("Permission not granted")
+	}
+
+	current, err := dao.UserDao.GetByID(ctx, id, au.Organization)
+	if err != nil {
+		return err
+	}
+
+	// Verify department scope
+	if err := helpers.VerifyUserScope(ctx, "org:user:delete", *current, *au); err != nil {
+		return err
+	}
+	if err := helpers.VerifyAdminTarget(*current, *au); err != nil {
+		return err
+	}
+
+	if err := dao.UserDao.Delete(ctx, id, au.Organization); err != nil {
+		return err
+	}
+	audit(ctx, au, "user:delete", models.AuditTargetUser, id, current, nil)
+
+	// Remove the relation tuples of the user
+	return helpers.RemoveObjectTuples(ctx, current.Organization, helpers.RelationObject(helpers.ObjectTypeUser, id))
+}
+
+// GetEffectivePermissions explains every permission of the catalogue for the user
+func (s *userService) GetEffectivePermissions(ctx context.Context, id string, au *models.AuthUser) ([]models.PermissionDecision, *resterr.RestErr) {
+	ctx, span := tracing.Start(ctx, "UserService.GetEffectivePermissions")
+	defer span.End()
+
+	user, err := s.GetByID(ctx, id, au)
+	if err != nil {
+		return nil, err
+	}
+
+	permList, err := dao.PermissionDao.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	subject := helpers.GetAuthUser(*user)
+	result := []models.PermissionDecision{}
+	for i := 0; i < len(permList); i++ {
+		result = append(result, helpers.ExplainPermission(permList[i].Name, subject))
+	}
+
+	return result, nil
+}
+
+// GetGrantEvents lists the recorded grant changes of the user
+func (s *userService) GetGrantEvents(ctx context.Context, id string, au *models.AuthUser) (models.GrantEvents, *resterr.RestErr) {
+	ctx, span := tracing.Start(ctx, "UserService.GetGrantEvents")
+	defer span.End()
+
+	user, err := s.GetByID(ctx, id, au)
+	if err != nil {
+		return nil, err
+	}
+
+	return dao.GrantEventDao.FindByUser(ctx, user.ID, user.Organization)
 }
@@ -0,0 +1,87 @@
+package clientip
+
+import (
+	"net"
+	"strconv"
+	"strings"
+
+	"gorabc/pkg/utils/resterr"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Header listing the clients and proxies of a forwarded request
+const Header = "X-Forwarded-For"
+
+// trustedProxies whose forwarded header is believed, none by default
+var trustedProxies []*net.IPNet
+
+// Configure the trusted proxies, a comma separated list of ips and CIDRs
+func Configure(proxies string) *resterr.RestErr {
+	networks := []*net.IPNet{}
+	for _, proxy := range strings.Split(proxies, ",") {
+		proxy = strings.TrimSpace(proxy)
+		if proxy == "" {
+			continue
+		}
+		if !strings.Contains(proxy, "/") {
+			ip := net.ParseIP(proxy)
+			if ip == nil {
+				return resterr.NewBadRequestError("Invalid trusted proxy: " + proxy)
+			}
+			bits := 8 * net.IPv4len
+			if ip.To4() == nil {
+				bits = 8 * net.IPv6len
+			}
+			proxy = proxy + "/" + strconv.Itoa(bits)
+		}
+		_, network, err := net.ParseCIDR(proxy)
+		if err != nil {
+			return resterr.NewBadRequestError("Invalid trusted proxy: " + proxy)
+		}
+		networks = append(networks, network)
+	}
+	trustedProxies = networks
+	return nil
+}
+
+// Get the ip of the client: the peer of the connection, or the last address
+// forwarded by a chain of trusted proxies. Addresses forwarded by any other
+// peer are ignored, a client cannot spoof its ip.
+func Get(ctx *gin.Context) string {
+	ip := ctx.Request.RemoteAddr
+	if host, _, err := net.SplitHostPort(ip); err == nil {
+		ip = host
+	}
+	if !isTrusted(ip) {
+		return ip
+	}
+
+	// walk the chain from the nearest hop while the hops are trusted proxies
+	forwarded := strings.Split(ctx.GetHeader(Header), ",")
+	for i := len(forwarded) - 1; i >= 0; i-- {
+		hop := strings.TrimSpace(forwarded[i])
+		if net.ParseIP(hop) == nil {
+			break
+		}
+		ip = hop
+		if !isTrusted(hop) {
+			break
+		}
+	}
+	return ip
+}
+
+// isTrusted reports whether the ip is a trusted proxy
+func isTrusted(value string) bool {
+	ip := net.ParseIP(value)
+	if ip == nil {
+		return false
+	}
+	for i := 0; i < len(trustedProxies); i++ {
+		if trustedProxies[i].Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
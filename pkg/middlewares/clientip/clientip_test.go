@@ -0,0 +1,53 @@
+package clientip
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestGet(t *testing.T) {
+	if err := Configure("10.0.0.0/8, 192.0.2.7"); err != nil {
+		t.Fatal(err.Message)
+	}
+	defer Configure("")
+
+	tests := []struct {
+		name      string
+		peer      string
+		forwarded string
+		want      string
+	}{
+		{"direct client", "203.0.113.5:4711", "", "203.0.113.5"},
+		{"spoofed header of a client", "203.0.113.5:4711", "10.1.1.1", "203.0.113.5"},
+		{"trusted proxy", "10.0.0.2:4711", "203.0.113.5", "203.0.113.5"},
+		{"chain of trusted proxies", "192.0.2.7:4711", "203.0.113.5, 10.0.0.3", "203.0.113.5"},
+		{"spoofed hop before the proxy", "10.0.0.2:4711", "198.51.100.1, 203.0.113.5", "203.0.113.5"},
+		{"trusted proxy without header", "10.0.0.2:4711", "", "10.0.0.2"},
+		{"malformed hop", "10.0.0.2:4711", "not-an-ip", "10.0.0.2"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+			ctx.Request = httptest.NewRequest("GET", "/", nil)
+			ctx.Request.RemoteAddr = test.peer
+			if test.forwarded != "" {
+				ctx.Request.Header.Set(Header, test.forwarded)
+			}
+			if got := Get(ctx); got != test.want {
+				t.Errorf("expected %s, got %s", test.want, got)
+			}
+		})
+	}
+}
+
+func TestConfigureRejectsInvalidProxies(t *testing.T) {
+	for _, proxies := range []string{"proxy.local", "10.0.0.0/33"} {
+		if err := Configure(proxies); err == nil {
+			t.Errorf("expected %q to be rejected", proxies)
+		}
+	}
+	Configure("")
+}
@@ -1,9 +1,11 @@
 package jwt
 
 import (
+	"encoding/json"
 	"strings"
 
 	"gorabc/pkg/models"
+	"gorabc/pkg/utils/metrics"
 	"gorabc/pkg/utils/resterr"
 )
 
@@ -21,19 +23,33 @@ func GenerateToken(authUser *models.AuthUser) *models.ValueToken {
 	valueToken := models.ValueToken{}
 	valueToken.ValueToken = tokenString
 
+	if authUser.Act != nil {
+		metrics.TokenIssued(metrics.TokenImpersonation)
+	} else {
+		metrics.TokenIssued(metrics.TokenAccess)
+	}
 	return &valueToken
 }
 
 // DecodeToken func
 func DecodeToken(authHeader string) (*models.AuthUser, *resterr.RestErr) {
+	user, err := decodeToken(authHeader)
+	if err != nil {
+		metrics.TokenRejected(metrics.TokenAccess)
+	}
+	return user, err
+}
+
+// decodeToken verifies the access token of the header
+func decodeToken(authHeader string) (*models.AuthUser, *resterr.RestErr) {
 	// Check validity of authHeader
 	if authHeader == "" {
-		return nil, resterr.NewBadRequestError("Value token not provided")
+		return nil, resterr.NewUnauthorizedError("Value token not provided")
 	}
 
 	authToken := strings.Split(authHeader, " ")
 	if len(authToken) != 2 {
-		return nil, resterr.NewBadRequestError("Malformed token")
+		return nil, resterr.NewUnauthorizedError("Malformed token")
 	}
 
 	tokenType := authToken[0]
@@ -41,12 +57,12 @@ func DecodeToken(authHeader string) (*models.AuthUser, *resterr.RestErr) {
 
 	// verify tokenType
 	if tokenType != "JWT" {
-		resterr.NewBadRequestError("Incorrect token type")
+		return nil, resterr.NewUnauthorizedError("Incorrect token type")
 	}
 
 	token := strings.Split(tokenString, ".")
 	if len(token) != 3 {
-		return nil, resterr.NewBadRequestError("Malformed token")
+		return nil, resterr.NewUnauthorizedError("Malformed token")
 	}
 
 	// header := token[0]
@@ -56,7 +72,7 @@ func DecodeToken(authHeader string) (*models.AuthUser, *resterr.RestErr) {
 	secret := secretKey
 
 	if !isValidHash(payload, hashSec, secret) {
-		return nil, resterr.NewBadRequestError("Malformed token - Hash")
+		return nil, resterr.NewUnauthorizedError("Malformed token - Hash")
 	}
 
 	data, err := payloadDecoder(payload)
@@ -65,7 +81,7 @@ func DecodeToken(authHeader string) (*models.AuthUser, *resterr.RestErr) {
 	}
 
 	if !isValidToken(data.Expiry) {
-		return nil, resterr.NewBadRequestError("Token is expired")
+		return nil, resterr.NewUnauthorizedError("Token is expired")
 	}
 
 	user := models.AuthUser{}
@@ -74,6 +90,50 @@ func DecodeToken(authHeader string) (*models.AuthUser, *resterr.RestErr) {
 	user.IsSuperuser = data.IsSuperuser
 	user.IsOrgAdmin = data.IsOrgAdmin
 	user.Permissions = data.Permissions
+	user.Denies = data.Denies
+	user.Attributes = data.Attributes
+	user.Act = data.Act
+	user.ReadOnly = data.ReadOnly
+	user.Resolve()
 
 	return &user, nil
 }
+
+// GenerateInviteToken signs the claims of an invite link
+func GenerateInviteToken(claims models.InviteClaims) string {
+	data, _ := json.Marshal(claims)
+	payload := base64Encoder(data)
+	metrics.TokenIssued(metrics.TokenInvite)
+	return payload + "." + Hash("invite."+payload, secretKey)
+}
+
+// DecodeInviteToken verifies the signature and the expiry of an invite link
+func DecodeInviteToken(token string) (*models.InviteClaims, *resterr.RestErr) {
+	claims, err := decodeInviteToken(token)
+	if err != nil {
+		metrics.TokenRejected(metrics.TokenInvite)
+	}
+	return claims, err
+}
+
+// decodeInviteToken verifies an invite token
+func decodeInviteToken(token string) (*models.InviteClaims, *resterr.RestErr) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 2 || !isValidHash("invite."+parts[0], parts[1], secretKey) {
+		return nil, resterr.NewBadRequestError("Invalid invite token")
+	}
+
+	decoded, err := base64Decode(parts[0])
+	if err != nil {
+		return nil, resterr.NewBadRequestError("Invalid invite token")
+	}
+	claims := models.InviteClaims{}
+	if err := json.Unmarshal(decoded, &claims); err != nil {
+		return nil, resterr.NewBadRequestError("Invalid invite token")
+	}
+
+	if !isValidToken(claims.Expiry) {
+		return nil, resterr.NewBadRequestError("Invite token is expired")
+	}
+	return &claims, nil
+}
@@ -5,15 +5,18 @@ import (
 	"crypto/sha256"
 	"encoding/base64"
 	"encoding/json"
-	"fmt"
 	"time"
 
 	"gorabc/pkg/models"
+	"gorabc/pkg/utils/datetime"
 	"gorabc/pkg/utils/resterr"
 )
 
 const (
 	secretKey = "supersecretkey"
+
+	// impersonationTTL of the tokens acting as another user
+	impersonationTTL = time.Minute * 5
 )
 
 // Hash generates a Hmac256 hash of a string using a secret
@@ -38,7 +41,7 @@ func base64Encoder(value []byte) string {
 func base64Decode(payload string) ([]byte, *resterr.RestErr) {
 	decoded, err := base64.URLEncoding.DecodeString(payload)
 	if err != nil {
-		return nil, resterr.NewInternalServerError(fmt.Sprintf("Decoding Error %s", err))
+		return nil, resterr.NewUnauthorizedError("Malformed token")
 	}
 	return decoded, nil
 }
@@ -65,8 +68,12 @@ func payloadEncoder(authUser *models.AuthUser) string {
 	payload.IsSuperuser = authUser.IsSuperuser
 	payload.IsOrgAdmin = authUser.IsOrgAdmin
 	payload.Permissions = authUser.Permissions
+	payload.Denies = authUser.Denies
+	payload.Attributes = authUser.Attributes
+	payload.Act = authUser.Act
+	payload.ReadOnly = authUser.ReadOnly
 	payload.Authorized = true
-	payload.Expiry = time.Now().Add(time.Minute * 15).UTC().Unix()
+	payload.Expiry = tokenExpiry(authUser)
 
 	jsonPayload, _ := json.Marshal(payload)
 	payloadString := base64Encoder(jsonPayload)
@@ -83,7 +90,7 @@ func payloadDecoder(payload string) (*models.TokenPayload, *resterr.RestErr) {
 
 	data := models.TokenPayload{}
 	if err := json.Unmarshal(decoded, &data); err != nil {
-		return nil, resterr.NewInternalServerError("Error when trying to unmarshal data response")
+		return nil, resterr.NewUnauthorizedError("Malformed token")
 	}
 
 	return &data, nil
@@ -93,3 +100,26 @@ func payloadDecoder(payload string) (*models.TokenPayload, *resterr.RestErr) {
 func isValidToken(exp int64) bool {
 	return exp > time.Now().UTC().Unix()
 }
+
+// tokenExpiry is 15 minutes away, 5 for impersonation tokens, or earlier when
+// a time limited grant of the token ends before
+func tokenExpiry(authUser *models.AuthUser) int64 {
+	expiry := time.Now().Add(time.Minute * 15)
+	if authUser.Act != nil {
+		expiry = time.Now().Add(impersonationTTL)
+	}
+
+	grants := append([]models.Permission{}, authUser.Permissions...)
+	grants = append(grants, authUser.Denies...)
+	for i := 0; i < len(grants); i++ {
+		if grants[i].ValidUntil == "" {
+			continue
+		}
+		validUntil, err := datetime.ParseDateTimeString(grants[i].ValidUntil)
+		if err == nil && validUntil.Before(expiry) {
+			expiry = validUntil
+		}
+	}
+
+	return expiry.UTC().Unix()
+}
@@ -0,0 +1,89 @@
+package logging
+
+import (
+	"net/url"
+	"time"
+
+	"gorabc/pkg/middlewares/clientip"
+	"gorabc/pkg/middlewares/requestid"
+	"gorabc/pkg/utils/logger"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+const (
+	userKey         = "user_id"
+	organizationKey = "organization"
+	impersonatorKey = "impersonator"
+)
+
+// Middleware writes a JSON access log line per request, server errors are
+// logged as errors and client errors as warnings
+func Middleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		start := time.Now()
+		ctx.Next()
+
+		status := ctx.Writer.Status()
+		fields := []zap.Field{
+			zap.String("method", ctx.Request.Method),
+			zap.String("route", ctx.FullPath()),
+			zap.String("path", ctx.Request.URL.Path),
+			zap.String("query", redactQuery(ctx.Request.URL.Query())),
+			zap.Int("status", status),
+			zap.Duration("latency_ms", time.Since(start)),
+			zap.String("ip", clientip.Get(ctx)),
+			zap.String("request_id", requestid.Get(ctx)),
+			zap.String(userKey, ctx.GetString(userKey)),
+			zap.String(organizationKey, ctx.GetString(organizationKey)),
+			zap.Int("bytes", ctx.Writer.Size()),
+		}
+		if impersonator := ctx.GetString(impersonatorKey); impersonator != "" {
+			fields = append(fields, zap.String(impersonatorKey, impersonator))
+		}
+		if len(ctx.Errors) > 0 {
+			fields = append(fields, zap.String("errors", ctx.Errors.String()))
+		}
+
+		log := logger.GetLogger()
+		switch {
+		case status >= 500:
+			log.Error("request", fields...)
+		case status >= 400:
+			log.Warn("request", fields...)
+		default:
+			log.Info("request", fields...)
+		}
+	}
+}
+
+// SetUser records the authenticated user in the access log and adds it to
+// the logger of the request context
+func SetUser(ctx *gin.Context, id string, org string) {
+	ctx.Set(userKey, id)
+	ctx.Set(organizationKey, org)
+	ctx.Request = ctx.Request.WithContext(logger.NewContext(ctx.Request.Context(),
+		zap.String(userKey, id),
+		zap.String(organizationKey, org),
+	))
+}
+
+// redactQuery encodes the query with the values of secret parameters replaced
+func redactQuery(query url.Values) string {
+	for key := range query {
+		if logger.IsSecret(key) {
+			query[key] = []string{logger.Redacted}
+		}
+	}
+	return query.Encode()
+}
+
+// SetImpersonator records the superuser acting as the authenticated user in
+// the access log and adds it to the logger of the request context
+func SetImpersonator(ctx *gin.Context, id string) {
+	ctx.Set(impersonatorKey, id)
+	ctx.Request = ctx.Request.WithContext(logger.NewContext(ctx.Request.Context(),
+		zap.String(impersonatorKey, id),
+	))
+}
@@ -0,0 +1,27 @@
+package monitoring
+
+import (
+	"time"
+
+	"gorabc/pkg/utils/metrics"
+
+	"github.com/gin-gonic/gin"
+)
+
+// unmatchedRoute labels the requests matching no route, the raw paths would
+// make the label values unbounded
+const unmatchedRoute = "unmatched"
+
+// Middleware records the latency of every request by route and status
+func Middleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		start := time.Now()
+		ctx.Next()
+
+		route := ctx.FullPath()
+		if route == "" {
+			route = unmatchedRoute
+		}
+		metrics.ObserveRequest(ctx.Request.Method, route, ctx.Writer.Status(), time.Since(start))
+	}
+}
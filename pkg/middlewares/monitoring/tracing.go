@@ -0,0 +1,41 @@
+package monitoring
+
+import (
+	"gorabc/pkg/utils/logger"
+	"gorabc/pkg/utils/tracing"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/semconv"
+	"go.uber.org/zap"
+)
+
+// Tracing opens the server span of every request, continuing the trace of a
+// traceparent header, the services and the DAOs add their spans under it
+func Tracing() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		route := ctx.FullPath()
+		if route == "" {
+			route = unmatchedRoute
+		}
+
+		parent := otel.GetTextMapPropagator().Extract(ctx.Request.Context(), propagation.HeaderCarrier(ctx.Request.Header))
+		spanCtx, span := tracing.Start(parent, ctx.Request.Method+" "+route,
+			semconv.HTTPServerAttributesFromHTTPRequest("gorabc", route, ctx.Request)...,
+		)
+		defer span.End()
+
+		// Tag the request logs with the trace
+		if traceID := span.SpanContext().TraceID(); traceID.IsValid() {
+			spanCtx = logger.NewContext(spanCtx, zap.String("trace_id", traceID.String()))
+		}
+		ctx.Request = ctx.Request.WithContext(spanCtx)
+
+		ctx.Next()
+
+		status := ctx.Writer.Status()
+		span.SetAttributes(semconv.HTTPAttributesFromHTTPStatusCode(status)...)
+		span.SetStatus(semconv.SpanStatusFromHTTPStatusCode(status))
+	}
+}
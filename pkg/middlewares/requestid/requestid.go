@@ -0,0 +1,39 @@
+package requestid
+
+import (
+	"regexp"
+
+	"gorabc/pkg/utils/encrypt"
+	"gorabc/pkg/utils/logger"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// Header carrying the request id, an id sent by a proxy is kept
+const Header = "X-Request-ID"
+
+const contextKey = "request_id"
+
+// validID bounds the ids accepted from clients
+var validID = regexp.MustCompile(`^[A-Za-z0-9._-]{1,64}$`)
+
+// Middleware tags every request with an id, echoed in the response header and
+// added to the logger of the request context
+func Middleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		id := ctx.GetHeader(Header)
+		if !validID.MatchString(id) {
+			id = encrypt.GenerateToken(16)
+		}
+		ctx.Set(contextKey, id)
+		ctx.Header(Header, id)
+		ctx.Request = ctx.Request.WithContext(logger.NewContext(ctx.Request.Context(), zap.String(contextKey, id)))
+		ctx.Next()
+	}
+}
+
+// Get the id of the request
+func Get(ctx *gin.Context) string {
+	return ctx.GetString(contextKey)
+}
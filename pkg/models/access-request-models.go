@@ -0,0 +1,65 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+
+	"gorabc/pkg/utils/resterr"
+	"gorabc/pkg/utils/validation"
+)
+
+// Access request statuses
+const (
+	AccessRequestPending   = "pending"
+	AccessRequestApproved  = "approved"
+	AccessRequestDenied    = "denied"
+	AccessRequestCancelled = "cancelled"
+)
+
+// MaxAccessRequestHours bounds the duration of an elevation
+const MaxAccessRequestHours = 72
+
+// AccessRequest Structure (Model)
+// A user asks for a role during a number of hours, once approved the role is
+// assigned until ValidUntil.
+type AccessRequest struct {
+	ID             string `json:"id" bson:"id"`
+	Organization   string `json:"organization" bson:"organization"`
+	UserID         string `json:"user_id" bson:"user_id"`
+	RoleID         string `json:"role_id" bson:"role_id" validate:"required"`
+	RoleName       string `json:"role_name" bson:"role_name"`
+	Scope          string `json:"scope" bson:"scope"`
+	Hours          int    `json:"hours" bson:"hours"`
+	Justification  string `json:"justification" bson:"justification" validate:"required,max=1000"`
+	Status         string `json:"status" bson:"status"`
+	ApproverID     string `json:"approver_id,omitempty" bson:"approver_id,omitempty"`
+	DecisionReason string `json:"decision_reason,omitempty" bson:"decision_reason,omitempty"`
+	ValidFrom      string `json:"valid_from,omitempty" bson:"valid_from,omitempty"`
+	ValidUntil     string `json:"valid_until,omitempty" bson:"valid_until,omitempty"`
+	CreatedAt      string `json:"created_at" bson:"created_at"`
+	UpdatedAt      string `json:"updated_at" bson:"updated_at"`
+}
+
+// AccessRequests array
+type AccessRequests []AccessRequest
+
+// AccessRequestFilter Structure
+type AccessRequestFilter struct {
+	UserID string `form:"user_id"`
+	Status string `form:"status"`
+}
+
+// AccessRequestDecision Structure
+type AccessRequestDecision struct {
+	Reason string `json:"reason"`
+}
+
+// Validate function
+func (request *AccessRequest) Validate() *resterr.RestErr {
+	request.Justification = strings.TrimSpace(request.Justification)
+	fields := validation.Fields(request)
+	if request.Hours < 1 || request.Hours > MaxAccessRequestHours {
+		fields = append(fields, validation.Field("hours", fmt.Sprintf("must be between 1 and %d", MaxAccessRequestHours)))
+	}
+	return validation.Error(fields)
+}
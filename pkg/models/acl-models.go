@@ -0,0 +1,69 @@
+package models
+
+import (
+	"gorabc/pkg/utils/resterr"
+	"gorabc/pkg/utils/validation"
+)
+
+// Grant subject types
+const (
+	SubjectTypeUser = "user"
+	SubjectTypeRole = "role"
+)
+
+// ObjectGrant Structure (Model)
+// Grants permissions on a single resource, e.g. inventory:product:update on
+// product 123, to a user or to every user holding a role.
+type ObjectGrant struct {
+	ID           string       `json:"id" bson:"id"`
+	Organization string       `json:"organization" bson:"organization"`
+	ResourceType string       `json:"resource_type" bson:"resource_type" validate:"required"`
+	ResourceID   string       `json:"resource_id" bson:"resource_id" validate:"required"`
+	SubjectType  string       `json:"subject_type" bson:"subject_type" validate:"oneof=user role"`
+	SubjectID    string       `json:"subject_id" bson:"subject_id" validate:"required"`
+	Permissions  []Permission `json:"permissions" bson:"permissions" validate:"min=1,dive"`
+	GrantedBy    string       `json:"granted_by" bson:"granted_by"`
+	CreatedAt    string       `json:"created_at" bson:"created_at"`
+}
+
+// ObjectGrants array
+type ObjectGrants []ObjectGrant
+
+// ObjectGrantFilter Structure
+type ObjectGrantFilter struct {
+	ResourceType string `form:"resource_type"`
+	ResourceID   string `form:"resource_id"`
+	SubjectType  string `form:"subject_type"`
+	SubjectID    string `form:"subject_id"`
+}
+
+// AccessCheckRequest Structure
+// UserID defaults to the authenticated user.
+type AccessCheckRequest struct {
+	UserID       string `json:"user_id"`
+	Permission   string `json:"permission" validate:"required"`
+	ResourceType string `json:"resource_type" validate:"required"`
+	ResourceID   string `json:"resource_id" validate:"required"`
+}
+
+// AccessCheckResponse Structure
+type AccessCheckResponse struct {
+	UserID       string      `json:"user_id"`
+	Permission   string      `json:"permission"`
+	ResourceType string      `json:"resource_type"`
+	ResourceID   string      `json:"resource_id"`
+	Allowed      bool        `json:"allowed"`
+	Reason       string      `json:"reason"`
+	Grant        string      `json:"grant,omitempty"`
+	DeniedBy     *Permission `json:"denied_by,omitempty"`
+}
+
+// Validate function
+func (grant *ObjectGrant) Validate() *resterr.RestErr {
+	return validation.Struct(grant)
+}
+
+// Validate AccessCheckRequest
+func (r *AccessCheckRequest) Validate() *resterr.RestErr {
+	return validation.Struct(r)
+}
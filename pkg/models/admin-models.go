@@ -0,0 +1,129 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+
+	"gorabc/pkg/utils/resterr"
+	"gorabc/pkg/utils/validation"
+)
+
+// Admin search limits
+const (
+	DefaultAdminSearchLimit = 50
+	MaxAdminSearchLimit     = 200
+)
+
+// TenantUsage counts the objects of an organization
+type TenantUsage struct {
+	Users          int64 `json:"users" bson:"users"`
+	ActiveUsers    int64 `json:"active_users" bson:"active_users"`
+	OrgAdmins      int64 `json:"org_admins" bson:"org_admins"`
+	Departments    int64 `json:"departments" bson:"departments"`
+	Roles          int64 `json:"roles" bson:"roles"`
+	Policies       int64 `json:"policies" bson:"policies"`
+	PendingInvites int64 `json:"pending_invites" bson:"pending_invites"`
+}
+
+// Tenant is an organization with its usage, as listed by the admin console
+type Tenant struct {
+	Organization
+	Usage TenantUsage `json:"usage"`
+}
+
+// Tenants array
+type Tenants []Tenant
+
+// TenantFilter Structure
+type TenantFilter struct {
+	Query  string `form:"q"`
+	Status string `form:"status"`
+}
+
+// TenantStatusRequest is the body of a suspension or a reactivation
+type TenantStatusRequest struct {
+	Reason string `json:"reason" validate:"required,max=1000"`
+}
+
+// CatalogPermissionRequest is the body of a new catalog permission
+type CatalogPermissionRequest struct {
+	Name string `json:"name" validate:"required,max=200"`
+}
+
+// ImpersonationRequest is the body of an impersonation
+// ReadOnly restricts the token to reads and ActiveRoles selects the roles of
+// the session as in a login.
+type ImpersonationRequest struct {
+	Reason      string   `json:"reason" validate:"required,max=1000"`
+	ReadOnly    bool     `json:"read_only"`
+	ActiveRoles []string `json:"active_roles"`
+}
+
+// Impersonation is a token of a superuser acting as a user
+type Impersonation struct {
+	User     AuthUser   `json:"user"`
+	Token    ValueToken `json:"token"`
+	ReadOnly bool       `json:"read_only"`
+}
+
+// AdminSearchRequest Structure
+// Query matches the names and emails of users and the names and ids of
+// organizations of every tenant.
+type AdminSearchRequest struct {
+	Query string `form:"q" validate:"required,min=2,max=100"`
+	Limit int    `form:"limit"`
+}
+
+// AdminSearchResult Structure
+type AdminSearchResult struct {
+	Organizations Organizations `json:"organizations"`
+	Users         []interface{} `json:"users"`
+}
+
+// Validate function
+func (r *TenantStatusRequest) Validate() *resterr.RestErr {
+	r.Reason = strings.TrimSpace(r.Reason)
+	return validation.Struct(r)
+}
+
+// Validate function
+func (r *ImpersonationRequest) Validate() *resterr.RestErr {
+	r.Reason = strings.TrimSpace(r.Reason)
+	return validation.Struct(r)
+}
+
+// Validate function
+// Catalog permissions are named domain:resource:action, wildcards are granted
+// to roles and users but never cataloged.
+func (r *CatalogPermissionRequest) Validate() *resterr.RestErr {
+	r.Name = strings.TrimSpace(r.Name)
+	fields := validation.Fields(r)
+	if r.Name != "" {
+		permission := Permission{Name: r.Name}
+		segments := permission.Segments()
+		if len(segments) < 2 || permission.IsWildcard() || strings.ContainsAny(r.Name, " \t") {
+			fields = append(fields, validation.Field("name", "must be named domain:resource:action without wildcards"))
+		} else {
+			for i := 0; i < len(segments); i++ {
+				if segments[i] == "" {
+					fields = append(fields, validation.Field("name", "must not have empty segments"))
+					break
+				}
+			}
+		}
+	}
+	return validation.Error(fields)
+}
+
+// Validate function
+func (r *AdminSearchRequest) Validate() *resterr.RestErr {
+	r.Query = strings.TrimSpace(r.Query)
+	fields := validation.Fields(r)
+	if r.Limit == 0 {
+		r.Limit = DefaultAdminSearchLimit
+	}
+	if r.Limit < 1 || r.Limit > MaxAdminSearchLimit {
+		fields = append(fields, validation.Field("limit", fmt.Sprintf("must be between 1 and %d", MaxAdminSearchLimit)))
+	}
+	return validation.Error(fields)
+}
@@ -0,0 +1,117 @@
+package models
+
+import (
+	"fmt"
+
+	"gorabc/pkg/utils/datetime"
+	"gorabc/pkg/utils/resterr"
+	"gorabc/pkg/utils/validation"
+)
+
+// Audit target types
+const (
+	AuditTargetUser          = "user"
+	AuditTargetUserInvite    = "user_invite"
+	AuditTargetOrganization  = "organization"
+	AuditTargetDepartment    = "department"
+	AuditTargetRole          = "role"
+	AuditTargetPolicy        = "policy"
+	AuditTargetObjectGrant   = "object_grant"
+	AuditTargetRelation      = "relation"
+	AuditTargetNamespace     = "namespace"
+	AuditTargetAccessRequest = "access_request"
+	AuditTargetSoD           = "sod_constraint"
+	AuditTargetRegistration  = "registration_invite"
+	AuditTargetImport        = "import"
+	AuditTargetManifest      = "manifest"
+	AuditTargetPermission    = "permission"
+	AuditTargetSearch        = "search"
+)
+
+// Audit actions of logins, mutations are named resource:action (e.g. role:update)
+const (
+	AuditActionLogin       = "auth:login"
+	AuditActionLoginFailed = "auth:login_failed"
+)
+
+// Audit query limits
+const (
+	DefaultAuditLimit = 100
+	MaxAuditLimit     = 1000
+)
+
+// AuditRedacted replaces the values of secret fields in changes
+const AuditRedacted = "[REDACTED]"
+
+// AuditEvent Structure (Model)
+// Events of an organization form a hash chain, each Hash covers the event and
+// the Hash of the previous event so a modified or removed event is detected.
+// Impersonator is the superuser acting as the Actor with an impersonation token.
+type AuditEvent struct {
+	ID           string        `json:"id" bson:"id"`
+	Organization string        `json:"organization" bson:"organization"`
+	Sequence     int64         `json:"sequence" bson:"sequence"`
+	Actor        string        `json:"actor" bson:"actor"`
+	Impersonator string        `json:"impersonator,omitempty" bson:"impersonator,omitempty"`
+	Action       string        `json:"action" bson:"action"`
+	TargetType   string        `json:"target_type" bson:"target_type"`
+	Target       string        `json:"target" bson:"target"`
+	Changes      []AuditChange `json:"changes,omitempty" bson:"changes,omitempty"`
+	IP           string        `json:"ip,omitempty" bson:"ip,omitempty"`
+	RequestID    string        `json:"request_id,omitempty" bson:"request_id,omitempty"`
+	CreatedAt    string        `json:"created_at" bson:"created_at"`
+	PrevHash     string        `json:"prev_hash" bson:"prev_hash"`
+	Hash         string        `json:"hash" bson:"hash"`
+}
+
+// AuditEvents array
+type AuditEvents []AuditEvent
+
+// AuditChange of a field, the values are JSON encoded
+type AuditChange struct {
+	Field  string `json:"field" bson:"field"`
+	Before string `json:"before,omitempty" bson:"before,omitempty"`
+	After  string `json:"after,omitempty" bson:"after,omitempty"`
+}
+
+// AuditFilter of the audit queries, From and To bound created_at
+type AuditFilter struct {
+	Organization string `form:"organization"`
+	Actor        string `form:"actor"`
+	Impersonator string `form:"impersonator"`
+	Action       string `form:"action"`
+	TargetType   string `form:"target_type"`
+	Target       string `form:"target"`
+	From         string `form:"from"`
+	To           string `form:"to"`
+	Limit        int    `form:"limit"`
+}
+
+// AuditVerification reports the first broken link of a chain
+type AuditVerification struct {
+	Organization string `json:"organization"`
+	Events       int    `json:"events"`
+	Valid        bool   `json:"valid"`
+	BrokenAt     int64  `json:"broken_at,omitempty"`
+	Reason       string `json:"reason,omitempty"`
+}
+
+// Validate AuditFilter
+func (filter *AuditFilter) Validate() *resterr.RestErr {
+	fields := []resterr.FieldError{}
+	for _, date := range [][2]string{{"from", filter.From}, {"to", filter.To}} {
+		if date[1] == "" {
+			continue
+		}
+		if _, err := datetime.ParseDateTimeString(date[1]); err != nil {
+			fields = append(fields, validation.Field(date[0], "must be a date of the format 2006-01-02T15:04:05Z"))
+		}
+	}
+	if filter.Limit == 0 {
+		filter.Limit = DefaultAuditLimit
+	}
+	if filter.Limit < 0 || filter.Limit > MaxAuditLimit {
+		fields = append(fields, validation.Field("limit", fmt.Sprintf("must be between 1 and %d", MaxAuditLimit)))
+	}
+	return validation.Error(fields)
+}
@@ -1,23 +1,38 @@
 package models
 
 import (
+	"strings"
+
 	"gorabc/pkg/utils/resterr"
+	"gorabc/pkg/utils/validation"
 )
 
 // LoginRequest structure
+// ActiveRoles selects the roles of the session, all roles by default.
 type LoginRequest struct {
-	Email    string `json:"email"`
-	Password string `json:"password"`
+	Email       string   `json:"email" validate:"required"`
+	Password    string   `json:"password" validate:"required"`
+	ActiveRoles []string `json:"active_roles"`
+	IP          string   `json:"-"`
+	RequestID   string   `json:"-"`
 }
 
 // RegistrationRequest Structure
+// Preset optionally bootstraps the departments and roles of the organization.
+// InviteToken is required when registration is invite-only and Verification
+// carries the response of the registration verifier (e.g. a CAPTCHA).
 type RegistrationRequest struct {
-	OrgName   string `json:"org_name"`
-	Website   string `json:"website"`
-	Firstname string `json:"first_name"`
-	Lastname  string `json:"last_name"`
-	Email     string `json:"email"`
-	Password  string `json:"password"`
+	Preset       string `json:"preset"`
+	OrgName      string `json:"org_name" validate:"required,max=100"`
+	Website      string `json:"website" validate:"omitempty,url,max=2048"`
+	Firstname    string `json:"first_name" validate:"required,max=100"`
+	Lastname     string `json:"last_name" validate:"required,max=100"`
+	Email        string `json:"email" validate:"required,email,max=254"`
+	Password     string `json:"password" validate:"required,min=8,max=128"`
+	InviteToken  string `json:"invite_token"`
+	Verification string `json:"verification"`
+	IP           string `json:"-"`
+	RequestID    string `json:"-"`
 }
 
 // ValueToken struct
@@ -31,44 +46,38 @@ type TokenHeader struct {
 	ALG string `json:"alg"`
 }
 
+// TokenActor is the act claim of an impersonation token, the superuser
+// acting as the subject of the token
+type TokenActor struct {
+	ID string `json:"id"`
+}
+
 // TokenPayload struct
 type TokenPayload struct {
-	ID           string       `json:"id"`
-	Organization string       `json:"organization"`
-	IsSuperuser  bool         `json:"is_superuser"`
-	IsOrgAdmin   bool         `json:"is_org_admin"`
-	Permissions  []Permission `json:"permissions"`
-	Authorized   bool         `json:"authorized"`
-	Expiry       int64        `json:"exp"`
+	ID           string            `json:"id"`
+	Organization string            `json:"organization"`
+	IsSuperuser  bool              `json:"is_superuser"`
+	IsOrgAdmin   bool              `json:"is_org_admin"`
+	Permissions  []Permission      `json:"permissions"`
+	Denies       []Permission      `json:"denies"`
+	Attributes   map[string]string `json:"attributes"`
+	Act          *TokenActor       `json:"act,omitempty"`
+	ReadOnly     bool              `json:"read_only,omitempty"`
+	Authorized   bool              `json:"authorized"`
+	Expiry       int64             `json:"exp"`
 }
 
 // Validate LoginRequest
 func (r *LoginRequest) Validate() *resterr.RestErr {
-	if r.Email == "" {
-		return resterr.NewBadRequestError("Email is required")
-	}
-	if r.Password == "" {
-		return resterr.NewBadRequestError("Password is required")
-	}
-	return nil
+	return validation.Struct(r)
 }
 
 // Validate RegistrationRequest
 func (r *RegistrationRequest) Validate() *resterr.RestErr {
-	if r.OrgName == "" {
-		return resterr.NewBadRequestError("Organization name is required")
-	}
-	if r.Firstname == "" {
-		return resterr.NewBadRequestError("Firstname is required")
-	}
-	if r.Lastname == "" {
-		return resterr.NewBadRequestError("Lastname is required")
-	}
-	if r.Email == "" {
-		return resterr.NewBadRequestError("Email is required")
-	}
-	if r.Password == "" {
-		return resterr.NewBadRequestError("Password is required")
-	}
-	return nil
+	r.OrgName = strings.TrimSpace(r.OrgName)
+	r.Website = strings.TrimSpace(r.Website)
+	r.Firstname = strings.TrimSpace(r.Firstname)
+	r.Lastname = strings.TrimSpace(r.Lastname)
+	r.Email = strings.TrimSpace(strings.ToLower(r.Email))
+	return validation.Struct(r)
 }
@@ -1,14 +1,19 @@
 package models
 
 import (
+	"strings"
+
 	"gorabc/pkg/utils/resterr"
+	"gorabc/pkg/utils/validation"
 )
 
 // Department Structure (Model)
 type Department struct {
 	ID           string `json:"id" bson:"id"`
-	Organization string `json:"organization" bson:"organization"`
-	Name         string `json:"name" bson:"name"`
+	Organization string `json:"organization" bson:"organization" validate:"required"`
+	Name         string `json:"name" bson:"name" validate:"required,max=100"`
+	Parent       string `json:"parent" bson:"parent"`
+	Path         string `json:"path" bson:"path"`
 	Status       string `json:"status" bson:"status"`
 	IsActive     bool   `json:"is_active" bson:"is_active"`
 	CreatedAt    string `json:"created_at" bson:"created_at"`
@@ -18,9 +23,18 @@ type Department struct {
 // Departments array
 type Departments []Department
 
+// UpdateDepartmentRequest is the body of a department update, an empty name
+// or parent keeps the current one and ToRoot moves the department to the root
+// of the hierarchy
+type UpdateDepartmentRequest struct {
+	Name   string `json:"name" validate:"max=100"`
+	Parent string `json:"parent"`
+	ToRoot bool   `json:"to_root"`
+}
+
 // UserDepartment Structure
 type UserDepartment struct {
-	DepartmentID   string `json:"department_id" bson:"department_id"`
+	DepartmentID   string `json:"department_id" bson:"department_id" validate:"required"`
 	DepartmentName string `json:"department_name" bson:"department_name"`
 }
 
@@ -28,12 +42,41 @@ type UserDepartment struct {
 type UserDepartments []UserDepartment
 
 // Validate function
+// The organization is set by the service before the validation.
 func (department *Department) Validate() *resterr.RestErr {
-	// if department.Organization == "" {
-	// 	return resterr.NewBadRequestError("Organization ID is required")
-	// }
-	if department.Name == "" {
-		return resterr.NewBadRequestError("Department name is required")
+	department.Name = strings.TrimSpace(department.Name)
+	return validation.Struct(department)
+}
+
+// Validate function
+func (r *UpdateDepartmentRequest) Validate() *resterr.RestErr {
+	r.Name = strings.TrimSpace(r.Name)
+	if r.ToRoot && r.Parent != "" {
+		return resterr.NewBadRequestError("Parent and to_root are exclusive")
+	}
+	return validation.Struct(r)
+}
+
+// BuildPath sets the materialized path of the department below its parent
+func (department *Department) BuildPath(parent *Department) {
+	if parent == nil {
+		department.Parent = ""
+		department.Path = "/" + department.ID + "/"
+		return
+	}
+	department.Parent = parent.ID
+	department.Path = parent.Path + department.ID + "/"
+}
+
+// InSubtree reports whether the department is the given department or one of its descendants
+func (department *Department) InSubtree(deptID string) bool {
+	if deptID == "" {
+		return false
+	}
+	// departments created before the hierarchy are roots
+	path := department.Path
+	if path == "" {
+		path = "/" + department.ID + "/"
 	}
-	return nil
+	return strings.Contains(path, "/"+deptID+"/")
 }
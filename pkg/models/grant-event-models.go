@@ -0,0 +1,43 @@
+package models
+
+// Grant event types
+const (
+	GrantTypeRole       = "role"
+	GrantTypePermission = "permission"
+	GrantTypeDeny       = "deny"
+)
+
+// Grant event actions
+const (
+	GrantActionExpired   = "expired"
+	GrantActionRequested = "requested"
+	GrantActionApproved  = "approved"
+	GrantActionDenied    = "denied"
+	GrantActionCancelled = "cancelled"
+	GrantActionGranted   = "granted"
+)
+
+// ActorSystem records changes made by gorabc itself
+const ActorSystem = "system"
+
+// GrantEvent Structure (Model)
+// Records a change of the roles, permissions or denies held by a user.
+type GrantEvent struct {
+	ID           string `json:"id" bson:"id"`
+	Organization string `json:"organization" bson:"organization"`
+	UserID       string `json:"user_id" bson:"user_id"`
+	Type         string `json:"type" bson:"type"`
+	Action       string `json:"action" bson:"action"`
+	Name         string `json:"name" bson:"name"`
+	Scope        string `json:"scope,omitempty" bson:"scope,omitempty"`
+	Source       string `json:"source,omitempty" bson:"source,omitempty"`
+	ValidFrom    string `json:"valid_from,omitempty" bson:"valid_from,omitempty"`
+	ValidUntil   string `json:"valid_until,omitempty" bson:"valid_until,omitempty"`
+	Request      string `json:"request,omitempty" bson:"request,omitempty"`
+	Reason       string `json:"reason,omitempty" bson:"reason,omitempty"`
+	Actor        string `json:"actor" bson:"actor"`
+	CreatedAt    string `json:"created_at" bson:"created_at"`
+}
+
+// GrantEvents array
+type GrantEvents []GrantEvent
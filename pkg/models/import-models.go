@@ -0,0 +1,89 @@
+package models
+
+// Import kinds
+const (
+	ImportUsers       = "users"
+	ImportDepartments = "departments"
+	ImportRoles       = "roles"
+)
+
+// Import formats
+const (
+	FormatJSON = "json"
+	FormatCSV  = "csv"
+)
+
+// Import row actions
+const (
+	ImportActionCreate    = "create"
+	ImportActionUpdate    = "update"
+	ImportActionUnchanged = "unchanged"
+	ImportActionError     = "error"
+)
+
+// ImportListSeparator separates the values of a list column in CSV files
+const ImportListSeparator = ";"
+
+// UserImportRow Structure
+// Users are keyed by email, roles and departments are given by name or id.
+type UserImportRow struct {
+	Email       string   `json:"email"`
+	Firstname   string   `json:"first_name"`
+	Lastname    string   `json:"last_name"`
+	Password    string   `json:"password,omitempty"`
+	Departments []string `json:"departments"`
+	Roles       []string `json:"roles"`
+}
+
+// DepartmentImportRow Structure
+// Departments are keyed by name, the parent is given by name or id.
+type DepartmentImportRow struct {
+	Name   string `json:"name"`
+	Parent string `json:"parent"`
+}
+
+// RoleImportRow Structure
+// Roles are keyed by name, the department is given by name or id.
+type RoleImportRow struct {
+	Name        string   `json:"name"`
+	Department  string   `json:"department"`
+	Permissions []string `json:"permissions"`
+}
+
+// ImportReport Structure
+type ImportReport struct {
+	Kind      string            `json:"kind"`
+	DryRun    bool              `json:"dry_run"`
+	Total     int               `json:"total"`
+	Created   int               `json:"created"`
+	Updated   int               `json:"updated"`
+	Unchanged int               `json:"unchanged"`
+	Failed    int               `json:"failed"`
+	Rows      []ImportRowResult `json:"rows"`
+}
+
+// ImportRowResult Structure
+// Row is the 1-based position of the row in the file, headers excluded.
+type ImportRowResult struct {
+	Row    int      `json:"row"`
+	Key    string   `json:"key"`
+	Action string   `json:"action"`
+	ID     string   `json:"id,omitempty"`
+	Errors []string `json:"errors,omitempty"`
+}
+
+// Add the result of a row to the report
+func (report *ImportReport) Add(result ImportRowResult) {
+	report.Total++
+	switch result.Action {
+	case ImportActionCreate:
+		report.Created++
+	case ImportActionUpdate:
+		report.Updated++
+	case ImportActionUnchanged:
+		report.Unchanged++
+	default:
+		report.Failed++
+	}
+	report.Rows = append(report.Rows, result)
+}
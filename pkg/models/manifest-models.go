@@ -0,0 +1,69 @@
+package models
+
+// Manifest change kinds
+const (
+	ManifestDepartment = "department"
+	ManifestRole       = "role"
+)
+
+// Manifest change actions
+const (
+	ManifestCreate = "create"
+	ManifestUpdate = "update"
+	ManifestDelete = "delete"
+)
+
+// Manifest Structure
+// The desired departments and roles of an organization, departments and
+// roles are keyed by name and roles belong to a department of the manifest.
+type Manifest struct {
+	Departments []ManifestDepartmentSpec `json:"departments" yaml:"departments"`
+	Roles       []ManifestRoleSpec       `json:"roles" yaml:"roles"`
+}
+
+// ManifestDepartmentSpec Structure
+type ManifestDepartmentSpec struct {
+	Name   string `json:"name" yaml:"name"`
+	Parent string `json:"parent,omitempty" yaml:"parent,omitempty"`
+}
+
+// ManifestRoleSpec Structure
+type ManifestRoleSpec struct {
+	Name        string   `json:"name" yaml:"name"`
+	Department  string   `json:"department" yaml:"department"`
+	Permissions []string `json:"permissions" yaml:"permissions"`
+	Denies      []string `json:"denies,omitempty" yaml:"denies,omitempty"`
+}
+
+// ManifestChange Structure
+// Key is the department name or "department/role" of a role.
+type ManifestChange struct {
+	Kind    string   `json:"kind"`
+	Action  string   `json:"action"`
+	Key     string   `json:"key"`
+	ID      string   `json:"id,omitempty"`
+	Details []string `json:"details,omitempty"`
+}
+
+// ManifestPlan Structure
+// The changes converging the organization to the manifest, the records are
+// written by the apply in a single transaction.
+type ManifestPlan struct {
+	Organization string           `json:"organization"`
+	Prune        bool             `json:"prune"`
+	Changes      []ManifestChange `json:"changes"`
+	Unmanaged    []string         `json:"unmanaged,omitempty"`
+	Errors       []string         `json:"errors,omitempty"`
+
+	CreateDepartments []Department `json:"-"`
+	UpdateDepartments []Department `json:"-"`
+	DeleteDepartments []string     `json:"-"`
+	CreateRoles       []Role       `json:"-"`
+	UpdateRoles       []Role       `json:"-"`
+	DeleteRoles       []string     `json:"-"`
+}
+
+// IsEmpty reports whether the organization already matches the manifest
+func (plan *ManifestPlan) IsEmpty() bool {
+	return len(plan.Changes) == 0
+}
@@ -1,18 +1,24 @@
 package models
 
 import (
+	"strings"
+
 	"gorabc/pkg/utils/resterr"
+	"gorabc/pkg/utils/validation"
 )
 
 // Organization Structure (Model)
+// StatusReason records why a superuser last suspended or reactivated the
+// organization.
 type Organization struct {
-	ID        string `json:"id" bson:"id"`
-	Name      string `json:"name" bson:"name"`
-	Website   string `json:"website" bson:"website"`
-	Status    string `json:"status" bson:"status"`
-	IsActive  bool   `json:"is_active" bson:"is_active"`
-	CreatedAt string `json:"created_at" bson:"created_at"`
-	UpdatedAt string `json:"updated_at" bson:"updated_at"`
+	ID           string `json:"id" bson:"id"`
+	Name         string `json:"name" bson:"name" validate:"required,max=100"`
+	Website      string `json:"website" bson:"website" validate:"omitempty,url,max=2048"`
+	Status       string `json:"status" bson:"status"`
+	StatusReason string `json:"status_reason,omitempty" bson:"status_reason,omitempty"`
+	IsActive     bool   `json:"is_active" bson:"is_active"`
+	CreatedAt    string `json:"created_at" bson:"created_at"`
+	UpdatedAt    string `json:"updated_at" bson:"updated_at"`
 }
 
 // Organizations array
@@ -20,8 +26,7 @@ type Organizations []Organization
 
 // Validate function
 func (org *Organization) Validate() *resterr.RestErr {
-	if org.Name == "" {
-		return resterr.NewBadRequestError("Organization name is required")
-	}
-	return nil
+	org.Name = strings.TrimSpace(org.Name)
+	org.Website = strings.TrimSpace(org.Website)
+	return validation.Struct(org)
 }
@@ -1,9 +1,104 @@
 package models
 
+import (
+	"strings"
+
+	"gorabc/pkg/utils/datetime"
+	"gorabc/pkg/utils/resterr"
+)
+
+// Permission name syntax
+// Permissions are named domain:resource:action, e.g. inventory:product:create.
+// A "*" segment is a wildcard matching one or more segments, so inventory:*
+// grants every inventory permission and *:read grants every read permission.
+const (
+	PermissionSeparator = ":"
+	PermissionWildcard  = "*"
+)
+
 // Permission struct
+// Scope restricts the permission to a department subtree, it is empty for
+// organization wide grants. Source records where a user obtained the
+// permission, "user" for direct grants or "role:<role id>". ValidFrom and
+// ValidUntil bound time limited grants, they are empty for standing grants.
 type Permission struct {
-	Name string `json:"name" bson:"name"`
+	Name       string `json:"name" bson:"name" validate:"required"`
+	LegacyName string `json:"legacy_name,omitempty" bson:"legacy_name,omitempty"`
+	Scope      string `json:"scope,omitempty" bson:"scope,omitempty"`
+	Source     string `json:"source,omitempty" bson:"source,omitempty"`
+	ValidFrom  string `json:"valid_from,omitempty" bson:"valid_from,omitempty"`
+	ValidUntil string `json:"valid_until,omitempty" bson:"valid_until,omitempty"`
 }
 
 // Permissions list
 type Permissions []Permission
+
+// PermissionDecision explains the evaluation of a permission for a user
+type PermissionDecision struct {
+	Permission string       `json:"permission"`
+	Allowed    bool         `json:"allowed"`
+	GrantedBy  []Permission `json:"granted_by"`
+	DeniedBy   *Permission  `json:"denied_by,omitempty"`
+}
+
+// Permission sources
+const (
+	SourceUser = "user"
+	SourceRole = "role:"
+)
+
+// Segments splits the permission name into its segments
+func (permission Permission) Segments() []string {
+	return strings.Split(permission.Name, PermissionSeparator)
+}
+
+// IsWildcard reports whether the permission grants a pattern of permissions
+func (permission Permission) IsWildcard() bool {
+	segments := permission.Segments()
+	for i := 0; i < len(segments); i++ {
+		if segments[i] == PermissionWildcard {
+			return true
+		}
+	}
+	return false
+}
+
+// IsActive reports whether the grant is valid at the given datetime string
+func (permission Permission) IsActive(now string) bool {
+	return isWithinValidity(permission.ValidFrom, permission.ValidUntil, now)
+}
+
+// IsLapsed reports whether the validity of the grant has ended
+func (permission Permission) IsLapsed(now string) bool {
+	return permission.ValidUntil != "" && permission.ValidUntil <= now
+}
+
+// ValidateValidity verifies a validity window
+// Datetime strings share one layout, so they are compared as strings.
+func ValidateValidity(validFrom string, validUntil string) *resterr.RestErr {
+	if validFrom != "" {
+		if _, err := datetime.ParseDateTimeString(validFrom); err != nil {
+			return resterr.NewBadRequestError("Invalid valid_from: " + validFrom)
+		}
+	}
+	if validUntil != "" {
+		if _, err := datetime.ParseDateTimeString(validUntil); err != nil {
+			return resterr.NewBadRequestError("Invalid valid_until: " + validUntil)
+		}
+	}
+	if validFrom != "" && validUntil != "" && validUntil <= validFrom {
+		return resterr.NewBadRequestError("valid_until must be after valid_from")
+	}
+	return nil
+}
+
+// isWithinValidity of a validity window, empty bounds are open
+func isWithinValidity(validFrom string, validUntil string, now string) bool {
+	if validFrom != "" && now < validFrom {
+		return false
+	}
+	if validUntil != "" && now >= validUntil {
+		return false
+	}
+	return true
+}
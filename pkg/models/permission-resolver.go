@@ -0,0 +1,83 @@
+package models
+
+import (
+	"strings"
+
+	"gorabc/pkg/utils/datetime"
+)
+
+// permissionNode is a node of the permission trie, one node per name segment
+type permissionNode struct {
+	children map[string]*permissionNode
+	grants   []Permission
+}
+
+// PermissionResolver matches permission names against a set of grants,
+// including wildcard grants, through a trie keyed by name segment
+type PermissionResolver struct {
+	root *permissionNode
+}
+
+// NewPermissionResolver builds the trie of the granted permissions
+// Time limited grants outside of their validity are left out.
+func NewPermissionResolver(grants []Permission) *PermissionResolver {
+	resolver := &PermissionResolver{root: newPermissionNode()}
+	now := datetime.GetDateTimeString()
+	for i := 0; i < len(grants); i++ {
+		if grants[i].IsActive(now) {
+			resolver.Add(grants[i])
+		}
+	}
+	return resolver
+}
+
+func newPermissionNode() *permissionNode {
+	return &permissionNode{children: map[string]*permissionNode{}}
+}
+
+// Add inserts a grant into the trie
+func (r *PermissionResolver) Add(grant Permission) {
+	node := r.root
+	segments := grant.Segments()
+	for i := 0; i < len(segments); i++ {
+		child, ok := node.children[segments[i]]
+		if !ok {
+			child = newPermissionNode()
+			node.children[segments[i]] = child
+		}
+		node = child
+	}
+	node.grants = append(node.grants, grant)
+}
+
+// Match returns every grant matching the permission name
+func (r *PermissionResolver) Match(name string) []Permission {
+	result := []Permission{}
+	if name == "" {
+		return result
+	}
+	return r.root.match(strings.Split(name, PermissionSeparator), result)
+}
+
+// Has reports whether any grant matches the permission name
+func (r *PermissionResolver) Has(name string) bool {
+	return len(r.Match(name)) > 0
+}
+
+func (n *permissionNode) match(segments []string, result []Permission) []Permission {
+	if len(segments) == 0 {
+		return append(result, n.grants...)
+	}
+
+	if child, ok := n.children[segments[0]]; ok && segments[0] != PermissionWildcard {
+		result = child.match(segments[1:], result)
+	}
+
+	// a wildcard consumes one or more segments
+	if child, ok := n.children[PermissionWildcard]; ok {
+		for i := 1; i <= len(segments); i++ {
+			result = child.match(segments[i:], result)
+		}
+	}
+	return result
+}
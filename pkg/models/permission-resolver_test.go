@@ -0,0 +1,68 @@
+package models
+
+import "testing"
+
+func TestPermissionResolverMatch(t *testing.T) {
+	tests := []struct {
+		name       string
+		grants     []string
+		permission string
+		want       int
+	}{
+		{"exact", []string{"inventory:product:create"}, "inventory:product:create", 1},
+		{"other action", []string{"inventory:product:create"}, "inventory:product:delete", 0},
+		{"prefix is not a grant", []string{"inventory:product"}, "inventory:product:create", 0},
+		{"longer grant", []string{"inventory:product:create:bulk"}, "inventory:product:create", 0},
+		{"trailing wildcard", []string{"inventory:*"}, "inventory:product:create", 1},
+		{"trailing wildcard of another domain", []string{"inventory:*"}, "sales:order:create", 0},
+		{"wildcard needs a segment", []string{"inventory:*"}, "inventory", 0},
+		{"leading wildcard", []string{"*:read"}, "org:user:read", 1},
+		{"leading wildcard of another action", []string{"*:read"}, "org:user:update", 0},
+		{"middle wildcard", []string{"org:*:read"}, "org:user:read", 1},
+		{"middle wildcard spans segments", []string{"org:*:read"}, "org:user:grant:read", 1},
+		{"everything", []string{"*"}, "org:user:read", 1},
+		{"every matching grant", []string{"org:*", "*:read", "org:user:read", "org:user:update"}, "org:user:read", 3},
+		{"empty name", []string{"*"}, "", 0},
+		{"wildcard request is literal", []string{"org:user:read"}, "org:*", 0},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			grants := []Permission{}
+			for _, name := range test.grants {
+				grants = append(grants, Permission{Name: name})
+			}
+			if got := NewPermissionResolver(grants).Match(test.permission); len(got) != test.want {
+				t.Errorf("expected %d matching grants, got %v", test.want, got)
+			}
+		})
+	}
+}
+
+func TestPermissionResolverSkipsInactiveGrants(t *testing.T) {
+	resolver := NewPermissionResolver([]Permission{
+		{Name: "org:user:read", ValidUntil: "2000-01-01T00:00:00Z"},
+		{Name: "org:user:update", ValidFrom: "2999-01-01T00:00:00Z"},
+		{Name: "org:user:delete", ValidUntil: "2999-01-01T00:00:00Z"},
+	})
+
+	if resolver.Has("org:user:read") || resolver.Has("org:user:update") {
+		t.Error("grants outside of their validity should not match")
+	}
+	if !resolver.Has("org:user:delete") {
+		t.Error("a grant within its validity should match")
+	}
+}
+
+func TestAuthUserResolve(t *testing.T) {
+	au := AuthUser{Permissions: []Permission{{Name: "org:*"}}, Denies: []Permission{{Name: "org:user:delete"}}}
+	if !au.GrantResolver().Has("org:user:read") || !au.DenyResolver().Has("org:user:delete") {
+		t.Fatal("an unresolved user should resolve on the fly")
+	}
+
+	au.Resolve()
+	copied := au
+	if copied.GrantResolver() != au.GrantResolver() || copied.DenyResolver() != au.DenyResolver() {
+		t.Error("copies of a resolved user should share its resolvers")
+	}
+}
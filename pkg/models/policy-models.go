@@ -0,0 +1,78 @@
+package models
+
+import (
+	"gorabc/pkg/utils/resterr"
+	"gorabc/pkg/utils/validation"
+)
+
+// Policy effects
+const (
+	PolicyEffectAllow = "allow"
+	PolicyEffectDeny  = "deny"
+)
+
+// Policy condition matching
+const (
+	PolicyMatchAll = "all"
+	PolicyMatchAny = "any"
+)
+
+// Policy Structure (Model)
+// A policy applies to the actions matching one of its permission patterns
+// when its conditions hold.
+type Policy struct {
+	ID             string            `json:"id" bson:"id"`
+	Organization   string            `json:"organization" bson:"organization"`
+	Name           string            `json:"name" bson:"name" validate:"required,max=100"`
+	Description    string            `json:"description" bson:"description" validate:"max=1000"`
+	Effect         string            `json:"effect" bson:"effect" validate:"oneof=allow deny"`
+	Actions        []string          `json:"actions" bson:"actions" validate:"min=1,dive,required"`
+	Conditions     []PolicyCondition `json:"conditions" bson:"conditions" validate:"dive"`
+	ConditionMatch string            `json:"condition_match" bson:"condition_match" validate:"oneof=all any"`
+	Status         string            `json:"status" bson:"status"`
+	IsActive       bool              `json:"is_active" bson:"is_active"`
+	CreatedAt      string            `json:"created_at" bson:"created_at"`
+	UpdatedAt      string            `json:"updated_at" bson:"updated_at"`
+}
+
+// Policies array
+type Policies []Policy
+
+// PolicyCondition Structure
+// Attribute is a dotted path such as subject.warehouse, resource.warehouse or
+// environment.time. A string value of the form ${subject.warehouse} refers to
+// another attribute.
+type PolicyCondition struct {
+	Attribute string      `json:"attribute" bson:"attribute" validate:"required"`
+	Operator  string      `json:"operator" bson:"operator" validate:"required"`
+	Value     interface{} `json:"value" bson:"value"`
+}
+
+// AuthorizeRequest Structure
+type AuthorizeRequest struct {
+	Action   string                 `json:"action" validate:"required"`
+	Resource map[string]interface{} `json:"resource"`
+	IP       string                 `json:"-"`
+}
+
+// AuthorizeResponse Structure
+type AuthorizeResponse struct {
+	Action   string      `json:"action"`
+	Allowed  bool        `json:"allowed"`
+	Reason   string      `json:"reason"`
+	Policy   string      `json:"policy,omitempty"`
+	DeniedBy *Permission `json:"denied_by,omitempty"`
+}
+
+// Validate function
+func (policy *Policy) Validate() *resterr.RestErr {
+	if policy.ConditionMatch == "" {
+		policy.ConditionMatch = PolicyMatchAll
+	}
+	return validation.Struct(policy)
+}
+
+// Validate AuthorizeRequest
+func (r *AuthorizeRequest) Validate() *resterr.RestErr {
+	return validation.Struct(r)
+}
@@ -0,0 +1,71 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+
+	"gorabc/pkg/utils/resterr"
+	"gorabc/pkg/utils/validation"
+)
+
+// Organization self-registration modes
+const (
+	RegistrationOpen       = "open"
+	RegistrationInviteOnly = "invite-only"
+	RegistrationDisabled   = "disabled"
+)
+
+// Registration invite statuses
+const (
+	InviteStatusPending = "pending"
+	InviteStatusUsed    = "used"
+	InviteStatusRevoked = "revoked"
+)
+
+// MaxRegistrationInviteHours bounds the lifetime of a registration invite
+const MaxRegistrationInviteHours = 24 * 30
+
+// RegistrationInvite Structure (Model)
+// A one-time invite to register an organization when registration is
+// invite-only, only the hash of the token is stored.
+type RegistrationInvite struct {
+	ID           string `json:"id" bson:"id"`
+	TokenHash    string `json:"-" bson:"token_hash"`
+	Email        string `json:"email,omitempty" bson:"email,omitempty"`
+	Status       string `json:"status" bson:"status"`
+	Organization string `json:"organization,omitempty" bson:"organization,omitempty"`
+	CreatedBy    string `json:"created_by" bson:"created_by"`
+	CreatedAt    string `json:"created_at" bson:"created_at"`
+	ExpiresAt    string `json:"expires_at" bson:"expires_at"`
+	UsedAt       string `json:"used_at,omitempty" bson:"used_at,omitempty"`
+}
+
+// RegistrationInvites array
+type RegistrationInvites []RegistrationInvite
+
+// RegistrationInviteRequest Structure
+// Email optionally restricts the invite to the organization admin's email.
+type RegistrationInviteRequest struct {
+	Email          string `json:"email" validate:"omitempty,email,max=254"`
+	ExpiresInHours int    `json:"expires_in_hours"`
+}
+
+// RegistrationInviteResponse Structure
+// The token is only returned once, on creation.
+type RegistrationInviteResponse struct {
+	Invite RegistrationInvite `json:"invite"`
+	Token  string             `json:"token"`
+}
+
+// Validate RegistrationInviteRequest
+func (r *RegistrationInviteRequest) Validate() *resterr.RestErr {
+	r.Email = strings.TrimSpace(strings.ToLower(r.Email))
+	if r.ExpiresInHours == 0 {
+		r.ExpiresInHours = 24 * 7
+	}
+	fields := validation.Fields(r)
+	if r.ExpiresInHours < 0 || r.ExpiresInHours > MaxRegistrationInviteHours {
+		fields = append(fields, validation.Field("expires_in_hours", fmt.Sprintf("must be between 1 and %d", MaxRegistrationInviteHours)))
+	}
+	return validation.Error(fields)
+}
@@ -0,0 +1,296 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+
+	"gorabc/pkg/utils/resterr"
+	"gorabc/pkg/utils/validation"
+)
+
+// Relation rewrite types of a namespace
+const (
+	RewriteThis            = "this"
+	RewriteComputedUserset = "computed_userset"
+	RewriteTupleToUserset  = "tuple_to_userset"
+)
+
+// Consistency modes of the relation reads
+const (
+	ConsistencyMinimizeLatency = "minimize_latency"
+	ConsistencyAtLeastAsFresh  = "at_least_as_fresh"
+	ConsistencyExactSnapshot   = "at_exact_snapshot"
+)
+
+// Separators of the tuple syntax object#relation@subject
+const (
+	ObjectSeparator   = ":"
+	RelationSeparator = "#"
+)
+
+// RelationTuple Structure (Model)
+// Object is "type:id", Subject is either "type:id" or the userset
+// "type:id#relation". Deleted tuples are kept until their revision is no
+// longer readable.
+type RelationTuple struct {
+	Organization    string `json:"organization" bson:"organization"`
+	Object          string `json:"object" bson:"object"`
+	ObjectType      string `json:"object_type" bson:"object_type"`
+	Relation        string `json:"relation" bson:"relation"`
+	Subject         string `json:"subject" bson:"subject"`
+	SubjectObject   string `json:"subject_object" bson:"subject_object"`
+	Managed         bool   `json:"managed" bson:"managed"`
+	CreatedRevision int64  `json:"created_revision" bson:"created_revision"`
+	DeletedRevision int64  `json:"deleted_revision,omitempty" bson:"deleted_revision"`
+	CreatedAt       string `json:"created_at" bson:"created_at"`
+}
+
+// RelationTuples array
+type RelationTuples []RelationTuple
+
+// RelationTupleFilter Structure
+type RelationTupleFilter struct {
+	ObjectType    string `form:"object_type"`
+	Object        string `form:"object"`
+	Relation      string `form:"relation"`
+	Subject       string `form:"subject"`
+	SubjectObject string `form:"subject_object"`
+}
+
+// Namespace Structure (Model)
+// Describes the relations of a resource type. A relation without rewrites
+// only holds its direct tuples.
+type Namespace struct {
+	Organization string              `json:"organization" bson:"organization"`
+	Name         string              `json:"name" bson:"name"`
+	Relations    []NamespaceRelation `json:"relations" bson:"relations"`
+	Managed      bool                `json:"managed" bson:"managed"`
+	UpdatedAt    string              `json:"updated_at" bson:"updated_at"`
+}
+
+// Namespaces array
+type Namespaces []Namespace
+
+// NamespaceRelation Structure
+// The rewrites are combined with a union.
+type NamespaceRelation struct {
+	Name    string            `json:"name" bson:"name"`
+	Rewrite []RelationRewrite `json:"rewrite" bson:"rewrite"`
+}
+
+// RelationRewrite Structure
+// computed_userset reads Relation on the same object, tuple_to_userset
+// follows the Tupleset relation and reads Relation on its subjects.
+type RelationRewrite struct {
+	Type     string `json:"type" bson:"type"`
+	Relation string `json:"relation,omitempty" bson:"relation"`
+	Tupleset string `json:"tupleset,omitempty" bson:"tupleset"`
+}
+
+// RelationWriteRequest Structure
+type RelationWriteRequest struct {
+	Writes  RelationTuples `json:"writes"`
+	Deletes RelationTuples `json:"deletes"`
+}
+
+// RelationWriteResponse Structure
+type RelationWriteResponse struct {
+	Token string `json:"token"`
+}
+
+// RelationReadOptions Structure
+// Token is the consistency token returned by a previous write or read.
+type RelationReadOptions struct {
+	Consistency string `json:"consistency" form:"consistency"`
+	Token       string `json:"token" form:"token"`
+}
+
+// RelationCheckRequest Structure
+// Subject defaults to the authenticated user.
+type RelationCheckRequest struct {
+	RelationReadOptions
+	Object   string `json:"object"`
+	Relation string `json:"relation"`
+	Subject  string `json:"subject"`
+}
+
+// RelationCheckResponse Structure
+type RelationCheckResponse struct {
+	Object   string `json:"object"`
+	Relation string `json:"relation"`
+	Subject  string `json:"subject"`
+	Allowed  bool   `json:"allowed"`
+	Token    string `json:"token"`
+}
+
+// RelationExpandRequest Structure
+type RelationExpandRequest struct {
+	RelationReadOptions
+	Object   string `json:"object"`
+	Relation string `json:"relation"`
+}
+
+// RelationExpandResponse Structure
+type RelationExpandResponse struct {
+	Tree  UsersetTree `json:"tree"`
+	Token string      `json:"token"`
+}
+
+// UsersetTree Structure
+// A union node per relation, its leaves hold the direct subjects.
+type UsersetTree struct {
+	Operation string        `json:"operation"`
+	Object    string        `json:"object"`
+	Relation  string        `json:"relation"`
+	Subjects  []string      `json:"subjects,omitempty"`
+	Children  []UsersetTree `json:"children,omitempty"`
+}
+
+// ListObjectsRequest Structure
+// Subject defaults to the authenticated user.
+type ListObjectsRequest struct {
+	RelationReadOptions
+	ObjectType string `json:"object_type"`
+	Relation   string `json:"relation"`
+	Subject    string `json:"subject"`
+}
+
+// ListObjectsResponse Structure
+type ListObjectsResponse struct {
+	Objects []string `json:"objects"`
+	Token   string   `json:"token"`
+}
+
+// ParseObject splits "type:id"
+func ParseObject(object string) (string, string, bool) {
+	parts := strings.SplitN(object, ObjectSeparator, 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" || strings.Contains(object, RelationSeparator) {
+		return "", "", false
+	}
+	return parts[0], parts[1], true
+}
+
+// ParseSubject splits "type:id#relation", the relation is empty for a
+// direct subject
+func ParseSubject(subject string) (string, string, bool) {
+	parts := strings.SplitN(subject, RelationSeparator, 2)
+	if _, _, ok := ParseObject(parts[0]); !ok {
+		return "", "", false
+	}
+	if len(parts) == 2 {
+		if parts[1] == "" {
+			return "", "", false
+		}
+		return parts[0], parts[1], true
+	}
+	return parts[0], "", true
+}
+
+// String of the tuple
+func (tuple *RelationTuple) String() string {
+	return tuple.Object + RelationSeparator + tuple.Relation + "@" + tuple.Subject
+}
+
+// Validate function
+func (tuple *RelationTuple) Validate() *resterr.RestErr {
+	return validation.Error(tuple.invalidFields(""))
+}
+
+// invalidFields lists the invalid fields of the tuple below the prefix and
+// sets the parsed object types of a valid tuple
+func (tuple *RelationTuple) invalidFields(prefix string) []resterr.FieldError {
+	fields := []resterr.FieldError{}
+	objectType, _, ok := ParseObject(tuple.Object)
+	if !ok {
+		fields = append(fields, validation.Field(prefix+"object", "must be of the form type:id"))
+	}
+	if tuple.Relation == "" || strings.ContainsAny(tuple.Relation, ObjectSeparator+RelationSeparator) {
+		fields = append(fields, validation.Field(prefix+"relation", "must be a relation name"))
+	}
+	subjectObject, _, ok := ParseSubject(tuple.Subject)
+	if !ok {
+		fields = append(fields, validation.Field(prefix+"subject", "must be of the form type:id or type:id#relation"))
+	}
+	if len(fields) == 0 {
+		tuple.ObjectType = objectType
+		tuple.SubjectObject = subjectObject
+	}
+	return fields
+}
+
+// Validate function
+func (request *RelationWriteRequest) Validate() *resterr.RestErr {
+	if len(request.Writes) == 0 && len(request.Deletes) == 0 {
+		return resterr.NewBadRequestError("No tuples to write or delete")
+	}
+	fields := []resterr.FieldError{}
+	for i := 0; i < len(request.Writes); i++ {
+		fields = append(fields, request.Writes[i].invalidFields(fmt.Sprintf("writes[%d].", i))...)
+	}
+	for i := 0; i < len(request.Deletes); i++ {
+		fields = append(fields, request.Deletes[i].invalidFields(fmt.Sprintf("deletes[%d].", i))...)
+	}
+	return validation.Error(fields)
+}
+
+// Validate function
+func (options *RelationReadOptions) Validate() *resterr.RestErr {
+	switch options.Consistency {
+	case "", ConsistencyMinimizeLatency:
+		return nil
+	case ConsistencyAtLeastAsFresh, ConsistencyExactSnapshot:
+		if options.Token == "" {
+			return resterr.NewBadRequestError("Consistency token is required")
+		}
+		return nil
+	}
+	return resterr.NewBadRequestError("Invalid consistency: " + options.Consistency)
+}
+
+// Validate function
+func (namespace *Namespace) Validate() *resterr.RestErr {
+	if namespace.Name == "" || strings.ContainsAny(namespace.Name, ObjectSeparator+RelationSeparator) {
+		return resterr.NewBadRequestError("Invalid namespace name")
+	}
+
+	relations := map[string]bool{}
+	for i := 0; i < len(namespace.Relations); i++ {
+		name := namespace.Relations[i].Name
+		if name == "" || strings.ContainsAny(name, ObjectSeparator+RelationSeparator) {
+			return resterr.NewBadRequestError("Invalid relation name: " + name)
+		}
+		if relations[name] {
+			return resterr.NewBadRequestError("Duplicate relation: " + name)
+		}
+		relations[name] = true
+	}
+
+	for i := 0; i < len(namespace.Relations); i++ {
+		for _, rewrite := range namespace.Relations[i].Rewrite {
+			switch rewrite.Type {
+			case RewriteThis:
+			case RewriteComputedUserset:
+				if !relations[rewrite.Relation] {
+					return resterr.NewBadRequestError("Unknown computed relation: " + rewrite.Relation)
+				}
+			case RewriteTupleToUserset:
+				if !relations[rewrite.Tupleset] || rewrite.Relation == "" {
+					return resterr.NewBadRequestError("Invalid tuple to userset rewrite of " + namespace.Relations[i].Name)
+				}
+			default:
+				return resterr.NewBadRequestError("Invalid rewrite type: " + rewrite.Type)
+			}
+		}
+	}
+	return nil
+}
+
+// GetRelation of the namespace
+func (namespace *Namespace) GetRelation(name string) (*NamespaceRelation, bool) {
+	for i := 0; i < len(namespace.Relations); i++ {
+		if namespace.Relations[i].Name == name {
+			return &namespace.Relations[i], true
+		}
+	}
+	return nil, false
+}
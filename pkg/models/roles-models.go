@@ -1,20 +1,27 @@
 package models
 
 import (
+	"strings"
+
 	"gorabc/pkg/utils/resterr"
+	"gorabc/pkg/utils/validation"
 )
 
 // Role Structure (Model)
+// Template and TemplateVersion record the role template a role was created from.
 type Role struct {
-	ID           string       `json:"id" bson:"id"`
-	Organization string       `json:"organization" bson:"organization"`
-	Department   string       `json:"department" bson:"department"`
-	Name         string       `json:"name" bson:"name"`
-	Permissions  []Permission `json:"permissions" bson:"permissions"`
-	Status       string       `json:"status" bson:"status"`
-	IsActive     bool         `json:"is_active" bson:"is_active"`
-	CreatedAt    string       `json:"created_at" bson:"created_at"`
-	UpdatedAt    string       `json:"updated_at" bson:"updated_at"`
+	ID              string       `json:"id" bson:"id"`
+	Organization    string       `json:"organization" bson:"organization"`
+	Department      string       `json:"department" bson:"department"`
+	Name            string       `json:"name" bson:"name"`
+	Template        string       `json:"template,omitempty" bson:"template,omitempty"`
+	TemplateVersion int          `json:"template_version,omitempty" bson:"template_version,omitempty"`
+	Permissions     []Permission `json:"permissions" bson:"permissions"`
+	Denies          []Permission `json:"denies" bson:"denies"`
+	Status          string       `json:"status" bson:"status"`
+	IsActive        bool         `json:"is_active" bson:"is_active"`
+	CreatedAt       string       `json:"created_at" bson:"created_at"`
+	UpdatedAt       string       `json:"updated_at" bson:"updated_at"`
 }
 
 // Roles array
@@ -25,24 +32,78 @@ type RolePermissions struct {
 	RoleID       string       `json:"role_id" bson:"role_id"`
 	Organization string       `json:"organization" bson:"organization"`
 	Permissions  []Permission `json:"permissions" bson:"permissions"`
+	Denies       []Permission `json:"denies" bson:"denies"`
 }
 
 // UserRole Structure
+// Scope holds the department whose subtree the assignment applies to,
+// an empty scope grants the role organization wide. ValidFrom and ValidUntil
+// bound temporary assignments.
 type UserRole struct {
-	RoleID   string `json:"role_id" bson:"role_id"`
-	RoleName string `json:"role_name" bson:"role_name"`
+	RoleID     string `json:"role_id" bson:"role_id" validate:"required"`
+	RoleName   string `json:"role_name" bson:"role_name"`
+	Scope      string `json:"scope" bson:"scope"`
+	ValidFrom  string `json:"valid_from,omitempty" bson:"valid_from,omitempty"`
+	ValidUntil string `json:"valid_until,omitempty" bson:"valid_until,omitempty"`
 }
 
 // UserRoles array
 type UserRoles []UserRole
 
+// CreateRoleRequest is the body of a role creation
+type CreateRoleRequest struct {
+	Name        string       `json:"name" validate:"required,max=100"`
+	Department  string       `json:"department" validate:"required"`
+	Permissions []Permission `json:"permissions" validate:"dive"`
+	Denies      []Permission `json:"denies" validate:"dive"`
+}
+
+// UpdateRoleRequest is the body of a role update, empty fields are kept
+// and an empty denies list clears the denies
+type UpdateRoleRequest struct {
+	Name        string       `json:"name" validate:"max=100"`
+	Permissions []Permission `json:"permissions" validate:"dive"`
+	Denies      []Permission `json:"denies" validate:"dive"`
+}
+
 // Validate function
-func (role *Role) Validate() *resterr.RestErr {
-	if role.Name == "" {
-		return resterr.NewBadRequestError("Role Name is required")
+func (r *CreateRoleRequest) Validate() *resterr.RestErr {
+	r.Name = strings.TrimSpace(r.Name)
+	return validation.Struct(r)
+}
+
+// Role to create from the request
+func (r *CreateRoleRequest) Role() Role {
+	return Role{
+		Name:        r.Name,
+		Department:  r.Department,
+		Permissions: r.Permissions,
+		Denies:      r.Denies,
 	}
-	if role.Department == "" {
-		return resterr.NewBadRequestError("Department ID is required")
+}
+
+// Validate function
+func (r *UpdateRoleRequest) Validate() *resterr.RestErr {
+	r.Name = strings.TrimSpace(r.Name)
+	return validation.Struct(r)
+}
+
+// Role holding the changes of the request to the role of the id
+func (r *UpdateRoleRequest) Role(id string) Role {
+	return Role{
+		ID:          id,
+		Name:        r.Name,
+		Permissions: r.Permissions,
+		Denies:      r.Denies,
 	}
-	return nil
+}
+
+// IsActive reports whether the assignment is valid at the given datetime string
+func (userRole UserRole) IsActive(now string) bool {
+	return isWithinValidity(userRole.ValidFrom, userRole.ValidUntil, now)
+}
+
+// IsLapsed reports whether the validity of the assignment has ended
+func (userRole UserRole) IsLapsed(now string) bool {
+	return userRole.ValidUntil != "" && userRole.ValidUntil <= now
 }
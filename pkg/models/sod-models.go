@@ -0,0 +1,60 @@
+package models
+
+import (
+	"gorabc/pkg/utils/resterr"
+	"gorabc/pkg/utils/validation"
+)
+
+// Separation of duties constraint types
+const (
+	SoDStatic  = "static"
+	SoDDynamic = "dynamic"
+)
+
+// SoDConstraint Structure (Model)
+// A user may hold at most MaxAllowed of the roles and at most MaxAllowed of
+// the permissions of the constraint. Static constraints are enforced on
+// assignment, dynamic constraints on the roles activated in a session.
+type SoDConstraint struct {
+	ID           string   `json:"id" bson:"id"`
+	Organization string   `json:"organization" bson:"organization"`
+	Name         string   `json:"name" bson:"name" validate:"required,max=100"`
+	Description  string   `json:"description" bson:"description" validate:"max=1000"`
+	Type         string   `json:"type" bson:"type" validate:"oneof=static dynamic"`
+	Roles        []string `json:"roles" bson:"roles" validate:"dive,required"`
+	Permissions  []string `json:"permissions" bson:"permissions" validate:"dive,required"`
+	MaxAllowed   int      `json:"max_allowed" bson:"max_allowed" validate:"min=1"`
+	Status       string   `json:"status" bson:"status"`
+	IsActive     bool     `json:"is_active" bson:"is_active"`
+	CreatedAt    string   `json:"created_at" bson:"created_at"`
+	UpdatedAt    string   `json:"updated_at" bson:"updated_at"`
+}
+
+// SoDConstraints array
+type SoDConstraints []SoDConstraint
+
+// SoDViolation Structure
+type SoDViolation struct {
+	ConstraintID   string   `json:"constraint_id"`
+	ConstraintName string   `json:"constraint_name"`
+	Type           string   `json:"type"`
+	UserID         string   `json:"user_id,omitempty"`
+	RoleID         string   `json:"role_id,omitempty"`
+	Roles          []string `json:"roles,omitempty"`
+	Permissions    []string `json:"permissions,omitempty"`
+}
+
+// Validate function
+func (constraint *SoDConstraint) Validate() *resterr.RestErr {
+	if constraint.Type == "" {
+		constraint.Type = SoDStatic
+	}
+	if constraint.MaxAllowed == 0 {
+		constraint.MaxAllowed = 1
+	}
+	fields := validation.Fields(constraint)
+	if len(constraint.Roles) <= constraint.MaxAllowed && len(constraint.Permissions) <= constraint.MaxAllowed {
+		fields = append(fields, validation.Field("roles", "needs more roles or permissions than max allowed"))
+	}
+	return validation.Error(fields)
+}
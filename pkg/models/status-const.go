@@ -5,4 +5,6 @@ const (
 	StatusActive    = "Active"
 	StatusInactive  = "Inactive"
 	StatusDiscarded = "Discarded"
+	StatusPending   = "Pending"
+	StatusSuspended = "Suspended"
 )
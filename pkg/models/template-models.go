@@ -0,0 +1,47 @@
+package models
+
+// RoleTemplate Structure
+// Templates are shipped in static/json/templates, Version is bumped whenever
+// the permissions of a template change.
+type RoleTemplate struct {
+	Key         string       `json:"key"`
+	Name        string       `json:"name"`
+	Version     int          `json:"version"`
+	Description string       `json:"description"`
+	Permissions []Permission `json:"permissions"`
+}
+
+// RoleTemplates array
+type RoleTemplates []RoleTemplate
+
+// Preset Structure
+// Bootstraps the departments of a new organization with template roles.
+type Preset struct {
+	Key         string             `json:"key"`
+	Name        string             `json:"name"`
+	Description string             `json:"description"`
+	Departments []PresetDepartment `json:"departments"`
+}
+
+// Presets array
+type Presets []Preset
+
+// PresetDepartment Structure
+type PresetDepartment struct {
+	Name      string   `json:"name"`
+	Templates []string `json:"templates"`
+}
+
+// RoleTemplateCatalogue Structure
+type RoleTemplateCatalogue struct {
+	Version   string        `json:"version"`
+	Templates RoleTemplates `json:"templates"`
+	Presets   Presets       `json:"presets"`
+}
+
+// InstantiateTemplateRequest Structure
+// Name defaults to the name of the template.
+type InstantiateTemplateRequest struct {
+	Department string `json:"department"`
+	Name       string `json:"name"`
+}
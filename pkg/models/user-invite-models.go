@@ -0,0 +1,96 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+
+	"gorabc/pkg/utils/resterr"
+	"gorabc/pkg/utils/validation"
+)
+
+// MaxUserInviteHours bounds the lifetime of an invite link
+const MaxUserInviteHours = 24 * 14
+
+// UserInvite Structure (Model)
+// The invite of a pending user, the nonce is part of the signed invite link
+// and is replaced when the invite is resent.
+type UserInvite struct {
+	ID           string `json:"id" bson:"id"`
+	Organization string `json:"organization" bson:"organization"`
+	UserID       string `json:"user_id" bson:"user_id"`
+	Email        string `json:"email" bson:"email"`
+	Nonce        string `json:"-" bson:"nonce"`
+	Status       string `json:"status" bson:"status"`
+	InvitedBy    string `json:"invited_by" bson:"invited_by"`
+	SentCount    int    `json:"sent_count" bson:"sent_count"`
+	CreatedAt    string `json:"created_at" bson:"created_at"`
+	SentAt       string `json:"sent_at" bson:"sent_at"`
+	ExpiresAt    string `json:"expires_at" bson:"expires_at"`
+	AcceptedAt   string `json:"accepted_at,omitempty" bson:"accepted_at,omitempty"`
+}
+
+// UserInvites array
+type UserInvites []UserInvite
+
+// InviteUserRequest Structure
+// Roles and departments are assigned as on user creation.
+type InviteUserRequest struct {
+	Email          string            `json:"email" validate:"required,email,max=254"`
+	Firstname      string            `json:"first_name" validate:"required,max=100"`
+	Lastname       string            `json:"last_name" validate:"required,max=100"`
+	Departments    []UserDepartment  `json:"departments" validate:"dive"`
+	Roles          []UserRole        `json:"roles" validate:"dive"`
+	Attributes     map[string]string `json:"attributes" validate:"max=50,dive,keys,required,max=64,endkeys,max=256"`
+	ExpiresInHours int               `json:"expires_in_hours"`
+}
+
+// ResendInviteRequest Structure
+type ResendInviteRequest struct {
+	ExpiresInHours int `json:"expires_in_hours"`
+}
+
+// AcceptInviteRequest Structure
+type AcceptInviteRequest struct {
+	Token     string `json:"token" validate:"required"`
+	Password  string `json:"password" validate:"required,min=8,max=128"`
+	IP        string `json:"-"`
+	RequestID string `json:"-"`
+}
+
+// InviteClaims of a signed invite link
+type InviteClaims struct {
+	InviteID string `json:"invite_id"`
+	Nonce    string `json:"nonce"`
+	Expiry   int64  `json:"exp"`
+}
+
+// Validate InviteUserRequest
+func (r *InviteUserRequest) Validate() *resterr.RestErr {
+	r.Firstname = strings.TrimSpace(r.Firstname)
+	r.Lastname = strings.TrimSpace(r.Lastname)
+	r.Email = strings.TrimSpace(strings.ToLower(r.Email))
+	fields := validation.Fields(r)
+	if err := ValidateInviteExpiry(&r.ExpiresInHours); err != nil {
+		fields = append(fields, err.Fields...)
+	}
+	return validation.Error(fields)
+}
+
+// Validate AcceptInviteRequest
+func (r *AcceptInviteRequest) Validate() *resterr.RestErr {
+	r.Password = strings.TrimSpace(r.Password)
+	return validation.Struct(r)
+}
+
+// ValidateInviteExpiry defaults the lifetime of an invite link to 72 hours
+func ValidateInviteExpiry(hours *int) *resterr.RestErr {
+	if *hours == 0 {
+		*hours = 72
+	}
+	if *hours < 0 || *hours > MaxUserInviteHours {
+		return validation.Error([]resterr.FieldError{
+			validation.Field("expires_in_hours", fmt.Sprintf("must be between 1 and %d", MaxUserInviteHours)),
+		})
+	}
+	return nil
+}
@@ -5,25 +5,28 @@ import (
 	"strings"
 
 	"gorabc/pkg/utils/resterr"
+	"gorabc/pkg/utils/validation"
 )
 
 // User Structure (Model)
 type User struct {
-	ID           string           `json:"id" bson:"id"`
-	Firstname    string           `json:"first_name" bson:"first_name"`
-	Lastname     string           `json:"last_name" bson:"last_name"`
-	Email        string           `json:"email" bson:"email"`
-	Password     string           `json:"password" bson:"password"`
-	Organization string           `json:"organization" bson:"organization"`
-	Departments  []UserDepartment `json:"departments" bson:"departments"`
-	Roles        []UserRole       `json:"roles" bson:"roles"`
-	Permissions  []Permission     `json:"permissions" bson:"permissions"`
-	Status       string           `json:"status" bson:"status"`
-	IsActive     bool             `json:"is_active" bson:"is_active"`
-	IsSuperuser  bool             `json:"is_superuser" bson:"is_superuser"`
-	IsOrgAdmin   bool             `json:"is_org_admin" bson:"is_org_admin"`
-	CreatedAt    string           `json:"created_at" bson:"created_at"`
-	UpdatedAt    string           `json:"updated_at" bson:"updated_at"`
+	ID           string            `json:"id" bson:"id"`
+	Firstname    string            `json:"first_name" bson:"first_name"`
+	Lastname     string            `json:"last_name" bson:"last_name"`
+	Email        string            `json:"email" bson:"email"`
+	Password     string            `json:"password" bson:"password"`
+	Organization string            `json:"organization" bson:"organization"`
+	Departments  []UserDepartment  `json:"departments" bson:"departments"`
+	Roles        []UserRole        `json:"roles" bson:"roles"`
+	Permissions  []Permission      `json:"permissions" bson:"permissions"`
+	Denies       []Permission      `json:"denies" bson:"denies"`
+	Attributes   map[string]string `json:"attributes" bson:"attributes"`
+	Status       string            `json:"status" bson:"status"`
+	IsActive     bool              `json:"is_active" bson:"is_active"`
+	IsSuperuser  bool              `json:"is_superuser" bson:"is_superuser"`
+	IsOrgAdmin   bool              `json:"is_org_admin" bson:"is_org_admin"`
+	CreatedAt    string            `json:"created_at" bson:"created_at"`
+	UpdatedAt    string            `json:"updated_at" bson:"updated_at"`
 }
 
 // Users array
@@ -33,33 +36,70 @@ type Users []User
 type UserPermissions struct {
 	UserID      string       `json:"user_id" bson:"user_id"`
 	Permissions []Permission `json:"permissions" bson:"permissions"`
+	Denies      []Permission `json:"denies" bson:"denies"`
 }
 
 // PrivateUser Structure
 type PrivateUser struct {
-	ID           string           `json:"id"`
-	Firstname    string           `json:"first_name"`
-	Lastname     string           `json:"last_name"`
-	Email        string           `json:"email"`
-	Status       string           `json:"status"`
-	Organization string           `json:"organization"`
-	Departments  []UserDepartment `json:"departments"`
-	Roles        []UserRole       `json:"roles"`
-	Permissions  []Permission     `json:"permissions"`
-	IsActive     bool             `json:"is_active"`
-	IsSuperuser  bool             `json:"is_superuser"`
-	IsOrgAdmin   bool             `json:"is_org_admin"`
-	CreatedAt    string           `json:"created_at"`
-	UpdatedAt    string           `json:"updated_at"`
+	ID           string            `json:"id"`
+	Firstname    string            `json:"first_name"`
+	Lastname     string            `json:"last_name"`
+	Email        string            `json:"email"`
+	Status       string            `json:"status"`
+	Organization string            `json:"organization"`
+	Departments  []UserDepartment  `json:"departments"`
+	Roles        []UserRole        `json:"roles"`
+	Permissions  []Permission      `json:"permissions"`
+	Denies       []Permission      `json:"denies"`
+	Attributes   map[string]string `json:"attributes"`
+	IsActive     bool              `json:"is_active"`
+	IsSuperuser  bool              `json:"is_superuser"`
+	IsOrgAdmin   bool              `json:"is_org_admin"`
+	CreatedAt    string            `json:"created_at"`
+	UpdatedAt    string            `json:"updated_at"`
 }
 
 // AuthUser Structure
+// IP and RequestID describe the request for the audit log, they are not part of the token.
+// Act is the superuser impersonating the user, ReadOnly tokens only read.
 type AuthUser struct {
-	ID           string       `json:"id"`
-	Organization string       `json:"organization"`
-	IsSuperuser  bool         `json:"is_superuser"`
-	IsOrgAdmin   bool         `json:"is_org_admin"`
-	Permissions  []Permission `json:"permissions"`
+	ID           string            `json:"id"`
+	Organization string            `json:"organization"`
+	IsSuperuser  bool              `json:"is_superuser"`
+	IsOrgAdmin   bool              `json:"is_org_admin"`
+	Permissions  []Permission      `json:"permissions"`
+	Denies       []Permission      `json:"denies"`
+	Attributes   map[string]string `json:"attributes"`
+	Act          *TokenActor       `json:"act,omitempty"`
+	ReadOnly     bool              `json:"read_only,omitempty"`
+	IP           string            `json:"-"`
+	RequestID    string            `json:"-"`
+
+	grants *PermissionResolver
+	denies *PermissionResolver
+}
+
+// Resolve indexes the permissions and the denies of the user once, the
+// copies of the user share the resolvers
+func (au *AuthUser) Resolve() {
+	au.grants = NewPermissionResolver(au.Permissions)
+	au.denies = NewPermissionResolver(au.Denies)
+}
+
+// GrantResolver of the permissions, built on the fly for unresolved users
+func (au AuthUser) GrantResolver() *PermissionResolver {
+	if au.grants != nil {
+		return au.grants
+	}
+	return NewPermissionResolver(au.Permissions)
+}
+
+// DenyResolver of the denies, built on the fly for unresolved users
+func (au AuthUser) DenyResolver() *PermissionResolver {
+	if au.denies != nil {
+		return au.denies
+	}
+	return NewPermissionResolver(au.Denies)
 }
 
 // Marshal User interface
@@ -87,21 +127,97 @@ func (user *User) AuthMarshal() interface{} {
 	return auth
 }
 
+// CreateUserRequest is the body of a user creation, the account flags and
+// the status are set by the service
+type CreateUserRequest struct {
+	Firstname   string            `json:"first_name" validate:"required,max=100"`
+	Lastname    string            `json:"last_name" validate:"required,max=100"`
+	Email       string            `json:"email" validate:"required,email,max=254"`
+	Password    string            `json:"password" validate:"required,min=8,max=128"`
+	Departments []UserDepartment  `json:"departments" validate:"dive"`
+	Roles       []UserRole        `json:"roles" validate:"dive"`
+	Permissions []Permission      `json:"permissions" validate:"dive"`
+	Denies      []Permission      `json:"denies" validate:"dive"`
+	Attributes  map[string]string `json:"attributes" validate:"max=50,dive,keys,required,max=64,endkeys,max=256"`
+}
+
+// UpdateUserRequest is the body of a user update, empty fields are kept
+// and an empty denies list clears the direct denies
+type UpdateUserRequest struct {
+	Firstname   string            `json:"first_name" validate:"max=100"`
+	Lastname    string            `json:"last_name" validate:"max=100"`
+	Email       string            `json:"email" validate:"omitempty,email,max=254"`
+	Departments []UserDepartment  `json:"departments" validate:"dive"`
+	Roles       []UserRole        `json:"roles" validate:"dive"`
+	Permissions []Permission      `json:"permissions" validate:"dive"`
+	Denies      []Permission      `json:"denies" validate:"dive"`
+	Attributes  map[string]string `json:"attributes" validate:"max=50,dive,keys,required,max=64,endkeys,max=256"`
+}
+
+// UpdatePasswordRequest is the body of a password change
+type UpdatePasswordRequest struct {
+	Password string `json:"password" validate:"required,min=8,max=128"`
+}
+
+// OrgAdminRequest is the body of an organization admin promotion or demotion
+type OrgAdminRequest struct {
+	IsOrgAdmin *bool `json:"is_org_admin" validate:"required"`
+}
+
 // Validate function
-func (user *User) Validate() *resterr.RestErr {
-	if user.Firstname == "" {
-		return resterr.NewBadRequestError("Firstname is required")
-	}
-	if user.Lastname == "" {
-		return resterr.NewBadRequestError("Lastname is required")
-	}
-	user.Email = strings.TrimSpace(strings.ToLower(user.Email))
-	if user.Email == "" {
-		return resterr.NewBadRequestError("Invalid Email address")
+func (r *CreateUserRequest) Validate() *resterr.RestErr {
+	r.Firstname = strings.TrimSpace(r.Firstname)
+	r.Lastname = strings.TrimSpace(r.Lastname)
+	r.Email = strings.TrimSpace(strings.ToLower(r.Email))
+	r.Password = strings.TrimSpace(r.Password)
+	return validation.Struct(r)
+}
+
+// User to create from the request
+func (r *CreateUserRequest) User() User {
+	return User{
+		Firstname:   r.Firstname,
+		Lastname:    r.Lastname,
+		Email:       r.Email,
+		Password:    r.Password,
+		Departments: r.Departments,
+		Roles:       r.Roles,
+		Permissions: r.Permissions,
+		Denies:      r.Denies,
+		Attributes:  r.Attributes,
 	}
-	user.Password = strings.TrimSpace(user.Password)
-	if user.Password == "" {
-		return resterr.NewBadRequestError("Password field cannot be empty")
+}
+
+// Validate function
+func (r *UpdateUserRequest) Validate() *resterr.RestErr {
+	r.Firstname = strings.TrimSpace(r.Firstname)
+	r.Lastname = strings.TrimSpace(r.Lastname)
+	r.Email = strings.TrimSpace(strings.ToLower(r.Email))
+	return validation.Struct(r)
+}
+
+// User holding the changes of the request to the user of the id
+func (r *UpdateUserRequest) User(id string) User {
+	return User{
+		ID:          id,
+		Firstname:   r.Firstname,
+		Lastname:    r.Lastname,
+		Email:       r.Email,
+		Departments: r.Departments,
+		Roles:       r.Roles,
+		Permissions: r.Permissions,
+		Denies:      r.Denies,
+		Attributes:  r.Attributes,
 	}
-	return nil
+}
+
+// Validate function
+func (r *UpdatePasswordRequest) Validate() *resterr.RestErr {
+	r.Password = strings.TrimSpace(r.Password)
+	return validation.Struct(r)
+}
+
+// Validate function
+func (r *OrgAdminRequest) Validate() *resterr.RestErr {
+	return validation.Struct(r)
 }
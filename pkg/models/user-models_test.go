@@ -0,0 +1,66 @@
+package models
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestCreateUserRequestIgnoresPrivilegedFields(t *testing.T) {
+	body := `{
+		"first_name": "Ada",
+		"last_name": "Lovelace",
+		"email": "Ada@Example.com",
+		"password": "correct horse",
+		"is_superuser": true,
+		"is_org_admin": true,
+		"status": "Active",
+		"organization": "OTHER"
+	}`
+
+	var request CreateUserRequest
+	if err := json.Unmarshal([]byte(body), &request); err != nil {
+		t.Fatal(err)
+	}
+	if err := request.Validate(); err != nil {
+		t.Fatalf("expected a valid request, got %v", err)
+	}
+
+	user := request.User()
+	if user.IsSuperuser || user.IsOrgAdmin || user.Status != "" || user.Organization != "" {
+		t.Errorf("privileged fields must not be set from the body, got %+v", user)
+	}
+	if user.Email != "ada@example.com" {
+		t.Errorf("expected the email to be normalized, got %s", user.Email)
+	}
+}
+
+func TestUpdateUserRequestIgnoresPrivilegedFields(t *testing.T) {
+	var request UpdateUserRequest
+	if err := json.Unmarshal([]byte(`{"first_name": "Ada", "is_superuser": true, "is_org_admin": true, "password": "secret"}`), &request); err != nil {
+		t.Fatal(err)
+	}
+
+	user := request.User("U1")
+	if user.ID != "U1" || user.IsSuperuser || user.IsOrgAdmin || user.Password != "" {
+		t.Errorf("only the fields of the request should be set, got %+v", user)
+	}
+}
+
+func TestCreateUserRequestListsEveryInvalidField(t *testing.T) {
+	request := CreateUserRequest{Email: "not-an-email", Password: "short"}
+
+	err := request.Validate()
+	if err == nil {
+		t.Fatal("expected a validation error")
+	}
+
+	fields := map[string]bool{}
+	for _, field := range err.Fields {
+		fields[field.Field] = true
+	}
+	for _, name := range []string{"first_name", "last_name", "email", "password"} {
+		if !fields[name] {
+			t.Errorf("expected %s to be reported, got %v", name, err.Fields)
+		}
+	}
+}
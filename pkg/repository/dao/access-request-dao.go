@@ -0,0 +1,185 @@
+package dao
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorabc/pkg/models"
+	"gorabc/pkg/settings/db/mongodb"
+	"gorabc/pkg/utils/resterr"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// AccessRequestDaoInterface type
+type AccessRequestDaoInterface interface {
+	Create(context.Context, models.AccessRequest) (*models.AccessRequest, *resterr.RestErr)
+	FindAll(context.Context, string, models.AccessRequestFilter) (models.AccessRequests, *resterr.RestErr)
+	GetByID(context.Context, string, string) (*models.AccessRequest, *resterr.RestErr)
+	Update(context.Context, models.AccessRequest, string) *resterr.RestErr
+	Approve(context.Context, models.AccessRequest, models.User) *resterr.RestErr
+}
+
+type accessRequestDao struct{}
+
+// AccessRequestDao variable
+var (
+	AccessRequestDao AccessRequestDaoInterface = &accessRequestDao{}
+
+	errNotPending = errors.New("access request is no longer pending")
+)
+
+// Create access request
+func (d *accessRequestDao) Create(ctx context.Context, request models.AccessRequest) (*models.AccessRequest, *resterr.RestErr) {
+	ctx, cancel := operation(ctx, "access-requests", "create", 10*time.Second)
+	defer cancel()
+	userDB := mongodb.Database()
+
+	requestCollection := userDB.Collection("access-requests")
+
+	_, err := requestCollection.InsertOne(ctx, request)
+	if err != nil {
+		return nil, databaseError(ctx, err)
+	}
+	return &request, nil
+}
+
+// FindAll access requests matching the filter
+func (d *accessRequestDao) FindAll(ctx context.Context, org string, requestFilter models.AccessRequestFilter) (models.AccessRequests, *resterr.RestErr) {
+	ctx, cancel := operation(ctx, "access-requests", "find_all", 10*time.Second)
+	defer cancel()
+	userDB := mongodb.Database()
+
+	requests := []models.AccessRequest{}
+	requestCollection := userDB.Collection("access-requests")
+
+	filter, tenantErr := tenant(org, bson.M{})
+	if tenantErr != nil {
+		return nil, tenantErr
+	}
+	if requestFilter.UserID != "" {
+		filter["user_id"] = requestFilter.UserID
+	}
+	if requestFilter.Status != "" {
+		filter["status"] = requestFilter.Status
+	}
+
+	cursor, err := requestCollection.Find(ctx, filter)
+	if err != nil {
+		return nil, databaseError(ctx, err)
+	}
+
+	if err = cursor.All(ctx, &requests); err != nil {
+		return nil, databaseError(ctx, err)
+	}
+
+	return requests, nil
+}
+
+// GetByID access request
+func (d *accessRequestDao) GetByID(ctx context.Context, id string, org string) (*models.AccessRequest, *resterr.RestErr) {
+	ctx, cancel := operation(ctx, "access-requests", "get_by_id", 10*time.Second)
+	defer cancel()
+	userDB := mongodb.Database()
+
+	request := models.AccessRequest{}
+	requestCollection := userDB.Collection("access-requests")
+
+	filter, tenantErr := tenant(org, bson.M{"id": id})
+	if tenantErr != nil {
+		return nil, tenantErr
+	}
+	err := requestCollection.FindOne(ctx, filter).Decode(&request)
+	if err != nil {
+		return nil, databaseError(ctx, err)
+	}
+
+	return &request, nil
+}
+
+// Update access request still in the given status
+func (d *accessRequestDao) Update(ctx context.Context, request models.AccessRequest, status string) *resterr.RestErr {
+	ctx, cancel := operation(ctx, "access-requests", "update", 10*time.Second)
+	defer cancel()
+
+	userDB := mongodb.Database()
+	requestCollection := userDB.Collection("access-requests")
+
+	filter, tenantErr := tenant(request.Organization, bson.M{"id": request.ID, "status": status})
+	if tenantErr != nil {
+		return tenantErr
+	}
+	update := bson.D{
+		{Key: "$set", Value: bson.D{
+			{Key: "status", Value: request.Status},
+			{Key: "approver_id", Value: request.ApproverID},
+			{Key: "decision_reason", Value: request.DecisionReason},
+			{Key: "valid_from", Value: request.ValidFrom},
+			{Key: "valid_until", Value: request.ValidUntil},
+			{Key: "updated_at", Value: request.UpdatedAt},
+		}},
+	}
+
+	result, err := requestCollection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return databaseError(ctx, err)
+	}
+	if result.MatchedCount == 0 {
+		return resterr.NewBadRequestError("Access request is no longer " + status)
+	}
+	return nil
+}
+
+// Approve the pending access request and save its user with the same write
+// as UserDao.Update in a single transaction, the request is never approved
+// without the role
+func (d *accessRequestDao) Approve(ctx context.Context, request models.AccessRequest, user models.User) *resterr.RestErr {
+	ctx, cancel := operation(ctx, "access-requests", "approve", 10*time.Second)
+	defer cancel()
+	userDB := mongodb.Database()
+
+	filter, tenantErr := tenant(request.Organization, bson.M{"id": request.ID, "status": models.AccessRequestPending})
+	if tenantErr != nil {
+		return tenantErr
+	}
+
+	session, err := mongodb.Client.StartSession()
+	if err != nil {
+		return databaseError(ctx, err)
+	}
+	defer session.EndSession(ctx)
+
+	_, err = session.WithTransaction(ctx, func(sc mongo.SessionContext) (interface{}, error) {
+		requestCollection := userDB.Collection("access-requests")
+
+		update := bson.D{
+			{Key: "$set", Value: bson.D{
+				{Key: "status", Value: request.Status},
+				{Key: "approver_id", Value: request.ApproverID},
+				{Key: "decision_reason", Value: request.DecisionReason},
+				{Key: "valid_from", Value: request.ValidFrom},
+				{Key: "valid_until", Value: request.ValidUntil},
+				{Key: "updated_at", Value: request.UpdatedAt},
+			}},
+		}
+		result, err := requestCollection.UpdateOne(sc, filter, update)
+		if err != nil {
+			return nil, err
+		}
+		if result.MatchedCount == 0 {
+			return nil, errNotPending
+		}
+		return nil, writeUser(sc, user)
+	})
+	switch {
+	case err == errNotPending:
+		return resterr.NewBadRequestError("Access request is no longer " + models.AccessRequestPending)
+	case err == mongo.ErrNoDocuments:
+		return resterr.NewNotFoundError("User not found")
+	case err != nil:
+		return databaseError(ctx, err)
+	}
+	return nil
+}
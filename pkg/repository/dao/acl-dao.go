@@ -0,0 +1,165 @@
+package dao
+
+import (
+	"context"
+	"time"
+
+	"gorabc/pkg/models"
+	"gorabc/pkg/settings/db/mongodb"
+	"gorabc/pkg/utils/resterr"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// ACLDaoInterface type
+type ACLDaoInterface interface {
+	Create(context.Context, models.ObjectGrant) (*models.ObjectGrant, *resterr.RestErr)
+	FindAll(context.Context, string, models.ObjectGrantFilter) (models.ObjectGrants, *resterr.RestErr)
+	FindBySubjects(context.Context, string, string, string, []string, []string) (models.ObjectGrants, *resterr.RestErr)
+	GetByID(context.Context, string, string) (*models.ObjectGrant, *resterr.RestErr)
+	Delete(context.Context, string, string) *resterr.RestErr
+}
+
+type aclDao struct{}
+
+// ACLDao variable
+var (
+	ACLDao ACLDaoInterface = &aclDao{}
+)
+
+// Create object grant
+func (d *aclDao) Create(ctx context.Context, grant models.ObjectGrant) (*models.ObjectGrant, *resterr.RestErr) {
+	ctx, cancel := operation(ctx, "object-acl", "create", 10*time.Second)
+	defer cancel()
+	userDB := mongodb.Database()
+
+	aclCollection := userDB.Collection("object-acl")
+
+	_, err := aclCollection.InsertOne(ctx, bson.M{
+		"id":            grant.ID,
+		"organization":  grant.Organization,
+		"resource_type": grant.ResourceType,
+		"resource_id":   grant.ResourceID,
+		"subject_type":  grant.SubjectType,
+		"subject_id":    grant.SubjectID,
+		"permissions":   grant.Permissions,
+		"granted_by":    grant.GrantedBy,
+		"created_at":    grant.CreatedAt,
+	})
+	if err != nil {
+		return nil, databaseError(ctx, err)
+	}
+	return &grant, nil
+}
+
+// FindAll object grants matching the filter
+func (d *aclDao) FindAll(ctx context.Context, org string, grantFilter models.ObjectGrantFilter) (models.ObjectGrants, *resterr.RestErr) {
+	ctx, cancel := operation(ctx, "object-acl", "find_all", 10*time.Second)
+	defer cancel()
+	userDB := mongodb.Database()
+
+	grants := []models.ObjectGrant{}
+	aclCollection := userDB.Collection("object-acl")
+
+	filter, tenantErr := tenant(org, bson.M{})
+	if tenantErr != nil {
+		return nil, tenantErr
+	}
+	if grantFilter.ResourceType != "" {
+		filter["resource_type"] = grantFilter.ResourceType
+	}
+	if grantFilter.ResourceID != "" {
+		filter["resource_id"] = grantFilter.ResourceID
+	}
+	if grantFilter.SubjectType != "" {
+		filter["subject_type"] = grantFilter.SubjectType
+	}
+	if grantFilter.SubjectID != "" {
+		filter["subject_id"] = grantFilter.SubjectID
+	}
+
+	cursor, err := aclCollection.Find(ctx, filter)
+	if err != nil {
+		return nil, databaseError(ctx, err)
+	}
+
+	if err = cursor.All(ctx, &grants); err != nil {
+		return nil, databaseError(ctx, err)
+	}
+
+	return grants, nil
+}
+
+// FindBySubjects returns the grants on a resource held by a user or one of its roles
+func (d *aclDao) FindBySubjects(ctx context.Context, org string, resourceType string, resourceID string, userIDs []string, roleIDs []string) (models.ObjectGrants, *resterr.RestErr) {
+	ctx, cancel := operation(ctx, "object-acl", "find_by_subjects", 10*time.Second)
+	defer cancel()
+	userDB := mongodb.Database()
+
+	grants := []models.ObjectGrant{}
+	aclCollection := userDB.Collection("object-acl")
+
+	filter, tenantErr := tenant(org, bson.M{
+		"resource_type": resourceType,
+		"resource_id":   resourceID,
+		"$or": bson.A{
+			bson.M{"subject_type": models.SubjectTypeUser, "subject_id": bson.M{"$in": userIDs}},
+			bson.M{"subject_type": models.SubjectTypeRole, "subject_id": bson.M{"$in": roleIDs}},
+		},
+	})
+	if tenantErr != nil {
+		return nil, tenantErr
+	}
+
+	cursor, err := aclCollection.Find(ctx, filter)
+	if err != nil {
+		return nil, databaseError(ctx, err)
+	}
+
+	if err = cursor.All(ctx, &grants); err != nil {
+		return nil, databaseError(ctx, err)
+	}
+
+	return grants, nil
+}
+
+// GetByID object grant
+func (d *aclDao) GetByID(ctx context.Context, id string, org string) (*models.ObjectGrant, *resterr.RestErr) {
+	ctx, cancel := operation(ctx, "object-acl", "get_by_id", 10*time.Second)
+	defer cancel()
+	userDB := mongodb.Database()
+
+	grant := models.ObjectGrant{}
+	aclCollection := userDB.Collection("object-acl")
+
+	filter, tenantErr := tenant(org, bson.M{"id": id})
+	if tenantErr != nil {
+		return nil, tenantErr
+	}
+	err := aclCollection.FindOne(ctx, filter).Decode(&grant)
+	if err != nil {
+		return nil, databaseError(ctx, err)
+	}
+
+	return &grant, nil
+}
+
+// Delete object grant
+func (d *aclDao) Delete(ctx context.Context, id string, org string) *resterr.RestErr {
+	ctx, cancel := operation(ctx, "object-acl", "delete", 10*time.Second)
+	defer cancel()
+
+	userDB := mongodb.Database()
+	aclCollection := userDB.Collection("object-acl")
+
+	filter, tenantErr := tenant(org, bson.M{"id": id})
+	if tenantErr != nil {
+		return tenantErr
+	}
+
+	result, err := aclCollection.DeleteOne(ctx, filter)
+	if err != nil {
+		return databaseError(ctx, err)
+	}
+	return missing(ctx, result.DeletedCount)
+}
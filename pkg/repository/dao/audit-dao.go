@@ -0,0 +1,160 @@
+package dao
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"gorabc/pkg/models"
+	"gorabc/pkg/settings/db/mongodb"
+	"gorabc/pkg/utils/resterr"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ErrAuditSequenceTaken is returned by Append when another event took the sequence
+var ErrAuditSequenceTaken = resterr.NewConflictError("Audit sequence already taken")
+
+// AuditDaoInterface type
+// The audit log is append-only, events are never updated or deleted.
+type AuditDaoInterface interface {
+	Append(context.Context, models.AuditEvent) *resterr.RestErr
+	Last(context.Context, string) (*models.AuditEvent, *resterr.RestErr)
+	Find(context.Context, models.AuditFilter) (models.AuditEvents, *resterr.RestErr)
+	Walk(context.Context, models.AuditFilter, func(models.AuditEvent) *resterr.RestErr) *resterr.RestErr
+}
+
+type auditDao struct {
+	indexOnce sync.Once
+}
+
+// AuditDao variable
+var (
+	AuditDao AuditDaoInterface = &auditDao{}
+)
+
+// Append an event to the chain of its organization
+func (d *auditDao) Append(ctx context.Context, event models.AuditEvent) *resterr.RestErr {
+	ctx, cancel := operation(ctx, "audit-events", "append", 10*time.Second)
+	defer cancel()
+	userDB := mongodb.Database()
+
+	auditCollection := userDB.Collection("audit-events")
+
+	// A sequence is used once per organization, concurrent writers lose the race
+	d.indexOnce.Do(func() {
+		index := mongo.IndexModel{
+			Keys:    bson.D{{Key: "organization", Value: 1}, {Key: "sequence", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		}
+		auditCollection.Indexes().CreateOne(ctx, index)
+	})
+
+	if _, err := auditCollection.InsertOne(ctx, event); err != nil {
+		if isDuplicateKey(err) {
+			return ErrAuditSequenceTaken
+		}
+		return databaseError(ctx, err)
+	}
+	return nil
+}
+
+// Last event of the chain of an organization, nil for an empty chain
+func (d *auditDao) Last(ctx context.Context, org string) (*models.AuditEvent, *resterr.RestErr) {
+	ctx, cancel := operation(ctx, "audit-events", "last", 10*time.Second)
+	defer cancel()
+	userDB := mongodb.Database()
+
+	var event models.AuditEvent
+	opts := options.FindOne().SetSort(bson.M{"sequence": -1})
+	err := userDB.Collection("audit-events").FindOne(ctx, bson.M{"organization": org}, opts).Decode(&event)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, databaseError(ctx, err)
+	}
+	return &event, nil
+}
+
+// Find events matching the filter, newest first
+func (d *auditDao) Find(ctx context.Context, auditFilter models.AuditFilter) (models.AuditEvents, *resterr.RestErr) {
+	ctx, cancel := operation(ctx, "audit-events", "find", 10*time.Second)
+	defer cancel()
+	userDB := mongodb.Database()
+
+	events := models.AuditEvents{}
+	opts := options.Find().SetSort(bson.M{"sequence": -1}).SetLimit(int64(auditFilter.Limit))
+	cursor, err := userDB.Collection("audit-events").Find(ctx, auditQuery(auditFilter), opts)
+	if err != nil {
+		return nil, databaseError(ctx, err)
+	}
+	if err = cursor.All(ctx, &events); err != nil {
+		return nil, databaseError(ctx, err)
+	}
+	return events, nil
+}
+
+// Walk every event matching the filter in chain order, the limit is ignored
+func (d *auditDao) Walk(ctx context.Context, auditFilter models.AuditFilter, fn func(models.AuditEvent) *resterr.RestErr) *resterr.RestErr {
+	// Exports of long chains outlive the usual timeout
+	ctx, cancel := operation(ctx, "audit-events", "walk", 5*time.Minute)
+	defer cancel()
+	userDB := mongodb.Database()
+
+	opts := options.Find().SetSort(bson.M{"sequence": 1})
+	cursor, err := userDB.Collection("audit-events").Find(ctx, auditQuery(auditFilter), opts)
+	if err != nil {
+		return databaseError(ctx, err)
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var event models.AuditEvent
+		if err := cursor.Decode(&event); err != nil {
+			return databaseError(ctx, err)
+		}
+		if err := fn(event); err != nil {
+			return err
+		}
+	}
+	if err := cursor.Err(); err != nil {
+		return databaseError(ctx, err)
+	}
+	return nil
+}
+
+// auditQuery of the filter, the organization is always part of the query
+func auditQuery(auditFilter models.AuditFilter) bson.M {
+	filter := bson.M{"organization": auditFilter.Organization}
+	if auditFilter.Actor != "" {
+		filter["actor"] = auditFilter.Actor
+	}
+	if auditFilter.Impersonator != "" {
+		filter["impersonator"] = auditFilter.Impersonator
+	}
+	if auditFilter.Action != "" {
+		filter["action"] = auditFilter.Action
+	}
+	if auditFilter.TargetType != "" {
+		filter["target_type"] = auditFilter.TargetType
+	}
+	if auditFilter.Target != "" {
+		filter["target"] = auditFilter.Target
+	}
+
+	// Dates share one layout so they compare as strings
+	createdAt := bson.M{}
+	if auditFilter.From != "" {
+		createdAt["$gte"] = auditFilter.From
+	}
+	if auditFilter.To != "" {
+		createdAt["$lte"] = auditFilter.To
+	}
+	if len(createdAt) > 0 {
+		filter["created_at"] = createdAt
+	}
+	return filter
+}
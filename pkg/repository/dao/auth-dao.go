@@ -9,11 +9,12 @@ import (
 	"gorabc/pkg/utils/resterr"
 
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
 )
 
 // AuthDaoInterface type
 type AuthDaoInterface interface {
-	Login(string, string) (*models.User, *resterr.RestErr)
+	Login(context.Context, string, string) (*models.User, *resterr.RestErr)
 }
 
 type authDao struct{}
@@ -24,22 +25,27 @@ var (
 )
 
 // Login auth
-func (d *authDao) Login(email string, password string) (*models.User, *resterr.RestErr) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+func (d *authDao) Login(ctx context.Context, email string, password string) (*models.User, *resterr.RestErr) {
+	ctx, cancel := operation(ctx, "user", "login", 10*time.Second)
 	defer cancel()
-	userDB := mongodb.Client.Database("erp-user-service")
+	userDB := mongodb.Database()
 
 	user := models.User{}
 	userCollection := userDB.Collection("user")
 
 	filter := bson.M{"email": email}
+	// Unknown emails and wrong passwords are told apart in neither the
+	// status nor the message
 	err := userCollection.FindOne(ctx, filter).Decode(&user)
+	if err == mongo.ErrNoDocuments {
+		return nil, resterr.NewUnauthorizedError("Invalid email or password")
+	}
 	if err != nil {
-		return nil, resterr.NewInternalServerError(err.Error())
+		return nil, databaseError(ctx, err)
 	}
 
 	if user.Password != password {
-		return nil, resterr.NewBadRequestError("Incorrect password")
+		return nil, resterr.NewUnauthorizedError("Invalid email or password")
 	}
 
 	return &user, nil
@@ -0,0 +1,111 @@
+package dao
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"gorabc/pkg/utils/logger"
+	"gorabc/pkg/utils/metrics"
+	"gorabc/pkg/utils/resterr"
+	"gorabc/pkg/utils/tracing"
+
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.opentelemetry.io/otel/semconv"
+	"go.uber.org/zap"
+)
+
+// operationKey of the repository operation of a context
+type operationKey struct{}
+
+// operationLabels name the collection and the operation in the metrics
+type operationLabels struct {
+	collection string
+	name       string
+}
+
+// operation bounds a repository operation by the timeout within a span, its
+// latency is recorded and its span ended when the context is cancelled
+func operation(ctx context.Context, collection string, name string, timeout time.Duration) (context.Context, context.CancelFunc) {
+	start := time.Now()
+	ctx, span := tracing.Start(ctx, "mongodb "+collection+"."+name,
+		semconv.DBSystemMongodb,
+		semconv.DBMongoDBCollectionKey.String(collection),
+		semconv.DBOperationKey.String(name),
+	)
+	ctx = context.WithValue(ctx, operationKey{}, operationLabels{collection: collection, name: name})
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	return ctx, func() {
+		cancel()
+		span.End()
+		metrics.ObserveOperation(collection, name, time.Since(start))
+	}
+}
+
+// databaseError maps a driver error to the error of the operation of the
+// context without leaking the driver message. Unexpected failures are logged
+// with the request fields of the context, counted against the operation and
+// fail its span.
+func databaseError(ctx context.Context, err error) *resterr.RestErr {
+	labels, _ := ctx.Value(operationKey{}).(operationLabels)
+	resource := strings.Replace(labels.collection, "-", " ", -1)
+	if resource != "" {
+		resource = strings.ToUpper(resource[:1]) + resource[1:]
+	}
+
+	switch {
+	case errors.Is(err, mongo.ErrNoDocuments):
+		return resterr.NewNotFoundError(resource + " not found")
+	case isDuplicateKey(err):
+		return resterr.NewConflictError(resource + " already exists")
+	}
+
+	tracing.Fail(ctx, err)
+	if labels.collection != "" {
+		metrics.OperationFailed(labels.collection, labels.name)
+	}
+	logger.FromContext(ctx).Error("Database operation failed",
+		zap.Error(err),
+		zap.String("collection", labels.collection),
+		zap.String("operation", labels.name),
+	)
+
+	if isUnavailable(err) {
+		return resterr.NewUnavailableError("Database unavailable")
+	}
+	return resterr.NewInternalServerError("Database operation failed")
+}
+
+// isDuplicateKey reports a unique index violation
+func isDuplicateKey(err error) bool {
+	switch e := err.(type) {
+	case mongo.WriteException:
+		for i := 0; i < len(e.WriteErrors); i++ {
+			if e.WriteErrors[i].Code == 11000 {
+				return true
+			}
+		}
+	case mongo.BulkWriteException:
+		for i := 0; i < len(e.WriteErrors); i++ {
+			if e.WriteErrors[i].Code == 11000 {
+				return true
+			}
+		}
+	case mongo.CommandError:
+		return e.Code == 11000
+	}
+	return false
+}
+
+// isUnavailable reports a database that cannot be reached in time
+func isUnavailable(err error) bool {
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, mongo.ErrClientDisconnected) {
+		return true
+	}
+	if e, ok := err.(mongo.CommandError); ok && (e.HasErrorLabel("NetworkError") || e.IsMaxTimeMSExpiredError()) {
+		return true
+	}
+	// server selection errors are not wrapped by the driver
+	return strings.Contains(err.Error(), "server selection error")
+}
@@ -0,0 +1,37 @@
+package dao
+
+import (
+	"context"
+
+	"gorabc/pkg/utils/resterr"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// tenant scopes the filter of a tenant owned collection to the organization,
+// queries without an organization are refused instead of running across
+// every tenant
+func tenant(org string, filter bson.M) (bson.M, *resterr.RestErr) {
+	if org == "" {
+		return nil, resterr.NewForbiddenError("Organization is required")
+	}
+	filter["organization"] = org
+	return filter, nil
+}
+
+// member scopes the filter of the user collection to the organization,
+// platform users (the superusers) belong to the empty organization
+func member(org string, filter bson.M) bson.M {
+	filter["organization"] = org
+	return filter
+}
+
+// missing reports a write matching no document of the organization as not
+// found, the same as a read of another tenant's document
+func missing(ctx context.Context, count int64) *resterr.RestErr {
+	if count == 0 {
+		return databaseError(ctx, mongo.ErrNoDocuments)
+	}
+	return nil
+}
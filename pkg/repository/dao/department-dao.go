@@ -2,6 +2,9 @@ package dao
 
 import (
 	"context"
+	"regexp"
+	"strings"
+	"sync"
 	"time"
 
 	"gorabc/pkg/models"
@@ -9,18 +12,24 @@ import (
 	"gorabc/pkg/utils/resterr"
 
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 // DepartmentDaoInterface type
 type DepartmentDaoInterface interface {
-	Create(models.Department) (*models.Department, *resterr.RestErr)
-	FindAll(string) (models.Departments, *resterr.RestErr)
-	GetByID(string, string) (*models.Department, *resterr.RestErr)
-	Update(models.Department) *resterr.RestErr
-	Delete(string) *resterr.RestErr
+	Create(context.Context, models.Department) (*models.Department, *resterr.RestErr)
+	FindAll(context.Context, string) (models.Departments, *resterr.RestErr)
+	GetByID(context.Context, string, string) (*models.Department, *resterr.RestErr)
+	FindChildren(context.Context, string, string) (models.Departments, *resterr.RestErr)
+	Update(context.Context, models.Department) *resterr.RestErr
+	Move(context.Context, models.Department, string) *resterr.RestErr
+	Delete(context.Context, string, string) *resterr.RestErr
 }
 
-type departmentDao struct{}
+type departmentDao struct {
+	indexOnce sync.Once
+}
 
 // DepartmentDao variable
 var (
@@ -28,106 +37,229 @@ var (
 )
 
 // Create department
-func (d *departmentDao) Create(department models.Department) (*models.Department, *resterr.RestErr) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+func (d *departmentDao) Create(ctx context.Context, department models.Department) (*models.Department, *resterr.RestErr) {
+	ctx, cancel := operation(ctx, "department", "create", 10*time.Second)
 	defer cancel()
-	userDB := mongodb.Client.Database("erp-user-service")
+	userDB := mongodb.Database()
 
 	deptCollection := userDB.Collection("department")
 
+	// A department id is unique in its organization
+	d.indexOnce.Do(func() {
+		index := mongo.IndexModel{
+			Keys:    bson.D{{Key: "organization", Value: 1}, {Key: "id", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		}
+		deptCollection.Indexes().CreateOne(ctx, index)
+	})
+
 	_, err := deptCollection.InsertOne(ctx, bson.M{
 		"id":           department.ID,
 		"organization": department.Organization,
 		"name":         department.Name,
+		"parent":       department.Parent,
+		"path":         department.Path,
 		"status":       department.Status,
 		"is_active":    department.IsActive,
 		"created_at":   department.CreatedAt,
 		"updated_at":   department.UpdatedAt,
 	})
 	if err != nil {
-		return nil, resterr.NewInternalServerError(err.Error())
+		return nil, databaseError(ctx, err)
 	}
 	return &department, nil
 }
 
 // FindAll department
-func (d *departmentDao) FindAll(org string) (models.Departments, *resterr.RestErr) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+func (d *departmentDao) FindAll(ctx context.Context, org string) (models.Departments, *resterr.RestErr) {
+	ctx, cancel := operation(ctx, "department", "find_all", 10*time.Second)
 	defer cancel()
-	userDB := mongodb.Client.Database("erp-user-service")
+	userDB := mongodb.Database()
 
 	departments := []models.Department{}
 	deptCollection := userDB.Collection("department")
 
-	filter := bson.M{"status": models.StatusActive, "organization": org}
+	filter, tenantErr := tenant(org, bson.M{"status": models.StatusActive})
+	if tenantErr != nil {
+		return nil, tenantErr
+	}
 	cursor, err := deptCollection.Find(ctx, filter)
 	if err != nil {
-		return nil, resterr.NewInternalServerError(err.Error())
+		return nil, databaseError(ctx, err)
 	}
 
 	if err = cursor.All(ctx, &departments); err != nil {
-		return nil, resterr.NewInternalServerError(err.Error())
+		return nil, databaseError(ctx, err)
 	}
 
 	return departments, nil
 }
 
 // GetByID department
-func (d *departmentDao) GetByID(id string, org string) (*models.Department, *resterr.RestErr) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+func (d *departmentDao) GetByID(ctx context.Context, id string, org string) (*models.Department, *resterr.RestErr) {
+	ctx, cancel := operation(ctx, "department", "get_by_id", 10*time.Second)
 	defer cancel()
-	userDB := mongodb.Client.Database("erp-user-service")
+	userDB := mongodb.Database()
 
 	department := models.Department{}
 	deptCollection := userDB.Collection("department")
 
-	filter := bson.M{"id": id, "organization": org}
+	filter, tenantErr := tenant(org, bson.M{"id": id})
+	if tenantErr != nil {
+		return nil, tenantErr
+	}
 	err := deptCollection.FindOne(ctx, filter).Decode(&department)
 	if err != nil {
-		return nil, resterr.NewInternalServerError(err.Error())
+		return nil, databaseError(ctx, err)
 	}
 
 	return &department, nil
 }
 
+// FindChildren returns the direct child departments
+func (d *departmentDao) FindChildren(ctx context.Context, id string, org string) (models.Departments, *resterr.RestErr) {
+	ctx, cancel := operation(ctx, "department", "find_children", 10*time.Second)
+	defer cancel()
+	userDB := mongodb.Database()
+
+	departments := []models.Department{}
+	deptCollection := userDB.Collection("department")
+
+	filter, tenantErr := tenant(org, bson.M{"status": models.StatusActive, "parent": id})
+	if tenantErr != nil {
+		return nil, tenantErr
+	}
+	cursor, err := deptCollection.Find(ctx, filter)
+	if err != nil {
+		return nil, databaseError(ctx, err)
+	}
+
+	if err = cursor.All(ctx, &departments); err != nil {
+		return nil, databaseError(ctx, err)
+	}
+
+	return departments, nil
+}
+
 // Update  department
-func (d *departmentDao) Update(department models.Department) *resterr.RestErr {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+func (d *departmentDao) Update(ctx context.Context, department models.Department) *resterr.RestErr {
+	ctx, cancel := operation(ctx, "department", "update", 10*time.Second)
 	defer cancel()
 
-	userDB := mongodb.Client.Database("erp-user-service")
+	userDB := mongodb.Database()
 	deptCollection := userDB.Collection("department")
 
-	filter := bson.M{"id": department.ID}
+	filter, tenantErr := tenant(department.Organization, bson.M{"id": department.ID})
+	if tenantErr != nil {
+		return tenantErr
+	}
 	update := bson.D{
 		{Key: "$set", Value: bson.D{
 			{Key: "name", Value: department.Name},
+			{Key: "parent", Value: department.Parent},
+			{Key: "path", Value: department.Path},
 			{Key: "status", Value: department.Status},
 			{Key: "is_active", Value: department.IsActive},
 			{Key: "updated_at", Value: department.UpdatedAt},
 		}},
 	}
 
-	_, err := deptCollection.UpdateOne(ctx, filter, update)
+	result, err := deptCollection.UpdateOne(ctx, filter, update)
 	if err != nil {
-		return resterr.NewInternalServerError(err.Error())
+		return databaseError(ctx, err)
 	}
-	return nil
+	return missing(ctx, result.MatchedCount)
 }
 
-// Delete User
-func (d *departmentDao) Delete(id string) *resterr.RestErr {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+// Move saves the department under its new path and rewrites the materialized
+// path of every descendant from the old path in one transaction, a failure
+// leaves the department and its descendants untouched
+func (d *departmentDao) Move(ctx context.Context, department models.Department, oldPath string) *resterr.RestErr {
+	ctx, cancel := operation(ctx, "department", "move", 10*time.Second)
 	defer cancel()
 
-	userDB := mongodb.Client.Database("erp-user-service")
+	userDB := mongodb.Database()
 	deptCollection := userDB.Collection("department")
 
-	filter := bson.M{"id": id}
+	filter, tenantErr := tenant(department.Organization, bson.M{"id": department.ID})
+	if tenantErr != nil {
+		return tenantErr
+	}
+	descendantFilter := bson.M{
+		"organization": department.Organization,
+		"id":           bson.M{"$ne": department.ID},
+		"path":         bson.M{"$regex": "^" + regexp.QuoteMeta(oldPath)},
+	}
 
-	_, err := deptCollection.DeleteOne(ctx, filter)
+	session, err := mongodb.Client.StartSession()
+	if err != nil {
+		return databaseError(ctx, err)
+	}
+	defer session.EndSession(ctx)
+
+	_, err = session.WithTransaction(ctx, func(sc mongo.SessionContext) (interface{}, error) {
+		update := bson.D{
+			{Key: "$set", Value: bson.D{
+				{Key: "name", Value: department.Name},
+				{Key: "parent", Value: department.Parent},
+				{Key: "path", Value: department.Path},
+				{Key: "status", Value: department.Status},
+				{Key: "is_active", Value: department.IsActive},
+				{Key: "updated_at", Value: department.UpdatedAt},
+			}},
+		}
+		result, err := deptCollection.UpdateOne(sc, filter, update)
+		if err != nil {
+			return nil, err
+		}
+		if result.MatchedCount == 0 {
+			return nil, mongo.ErrNoDocuments
+		}
+
+		descendants := []models.Department{}
+		cursor, err := deptCollection.Find(sc, descendantFilter)
+		if err != nil {
+			return nil, err
+		}
+		if err = cursor.All(sc, &descendants); err != nil {
+			return nil, err
+		}
+
+		for i := 0; i < len(descendants); i++ {
+			path := department.Path + strings.TrimPrefix(descendants[i].Path, oldPath)
+			update := bson.D{
+				{Key: "$set", Value: bson.D{
+					{Key: "path", Value: path},
+				}},
+			}
+			if _, err := deptCollection.UpdateOne(sc, bson.M{"id": descendants[i].ID, "organization": department.Organization}, update); err != nil {
+				return nil, err
+			}
+		}
+		return nil, nil
+	})
 	if err != nil {
-		return resterr.NewInternalServerError(err.Error())
+		return databaseError(ctx, err)
 	}
 	return nil
 }
+
+// Delete department of the organization
+func (d *departmentDao) Delete(ctx context.Context, id string, org string) *resterr.RestErr {
+	ctx, cancel := operation(ctx, "department", "delete", 10*time.Second)
+	defer cancel()
+
+	userDB := mongodb.Database()
+	deptCollection := userDB.Collection("department")
+
+	filter, tenantErr := tenant(org, bson.M{"id": id})
+	if tenantErr != nil {
+		return tenantErr
+	}
+
+	result, err := deptCollection.DeleteOne(ctx, filter)
+	if err != nil {
+		return databaseError(ctx, err)
+	}
+	return missing(ctx, result.DeletedCount)
+}
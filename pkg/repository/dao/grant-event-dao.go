@@ -0,0 +1,65 @@
+package dao
+
+import (
+	"context"
+	"time"
+
+	"gorabc/pkg/models"
+	"gorabc/pkg/settings/db/mongodb"
+	"gorabc/pkg/utils/resterr"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// GrantEventDaoInterface type
+type GrantEventDaoInterface interface {
+	Create(context.Context, models.GrantEvent) (*models.GrantEvent, *resterr.RestErr)
+	FindByUser(context.Context, string, string) (models.GrantEvents, *resterr.RestErr)
+}
+
+type grantEventDao struct{}
+
+// GrantEventDao variable
+var (
+	GrantEventDao GrantEventDaoInterface = &grantEventDao{}
+)
+
+// Create grant event
+func (d *grantEventDao) Create(ctx context.Context, event models.GrantEvent) (*models.GrantEvent, *resterr.RestErr) {
+	ctx, cancel := operation(ctx, "grant-events", "create", 10*time.Second)
+	defer cancel()
+	userDB := mongodb.Database()
+
+	eventCollection := userDB.Collection("grant-events")
+
+	_, err := eventCollection.InsertOne(ctx, event)
+	if err != nil {
+		return nil, databaseError(ctx, err)
+	}
+	return &event, nil
+}
+
+// FindByUser grant events
+func (d *grantEventDao) FindByUser(ctx context.Context, userID string, org string) (models.GrantEvents, *resterr.RestErr) {
+	ctx, cancel := operation(ctx, "grant-events", "find_by_user", 10*time.Second)
+	defer cancel()
+	userDB := mongodb.Database()
+
+	events := []models.GrantEvent{}
+	eventCollection := userDB.Collection("grant-events")
+
+	filter, tenantErr := tenant(org, bson.M{"user_id": userID})
+	if tenantErr != nil {
+		return nil, tenantErr
+	}
+	cursor, err := eventCollection.Find(ctx, filter)
+	if err != nil {
+		return nil, databaseError(ctx, err)
+	}
+
+	if err = cursor.All(ctx, &events); err != nil {
+		return nil, databaseError(ctx, err)
+	}
+
+	return events, nil
+}
@@ -0,0 +1,153 @@
+package dao
+
+import (
+	"context"
+	"time"
+
+	"gorabc/pkg/models"
+	"gorabc/pkg/settings/db/mongodb"
+	"gorabc/pkg/utils/resterr"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// ManifestDaoInterface type
+type ManifestDaoInterface interface {
+	Apply(context.Context, models.ManifestPlan) *resterr.RestErr
+}
+
+type manifestDao struct{}
+
+// ManifestDao variable
+var (
+	ManifestDao ManifestDaoInterface = &manifestDao{}
+)
+
+// Apply the departments, roles and role permissions of a plan in a single
+// transaction, transactions need mongodb to run as a replica set
+func (d *manifestDao) Apply(ctx context.Context, plan models.ManifestPlan) *resterr.RestErr {
+	ctx, cancel := operation(ctx, "manifest", "apply", 30*time.Second)
+	defer cancel()
+	userDB := mongodb.Database()
+
+	if _, tenantErr := tenant(plan.Organization, bson.M{}); tenantErr != nil {
+		return tenantErr
+	}
+
+	session, err := mongodb.Client.StartSession()
+	if err != nil {
+		return databaseError(ctx, err)
+	}
+	defer session.EndSession(ctx)
+
+	_, err = session.WithTransaction(ctx, func(sc mongo.SessionContext) (interface{}, error) {
+		deptCollection := userDB.Collection("department")
+		roleCollection := userDB.Collection("role")
+		rpCollection := userDB.Collection("role-permissions")
+
+		for i := 0; i < len(plan.CreateDepartments); i++ {
+			department := plan.CreateDepartments[i]
+			_, err := deptCollection.InsertOne(sc, bson.M{
+				"id":           department.ID,
+				"organization": department.Organization,
+				"name":         department.Name,
+				"parent":       department.Parent,
+				"path":         department.Path,
+				"status":       department.Status,
+				"is_active":    department.IsActive,
+				"created_at":   department.CreatedAt,
+				"updated_at":   department.UpdatedAt,
+			})
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		for i := 0; i < len(plan.UpdateDepartments); i++ {
+			department := plan.UpdateDepartments[i]
+			update := bson.D{
+				{Key: "$set", Value: bson.D{
+					{Key: "parent", Value: department.Parent},
+					{Key: "path", Value: department.Path},
+					{Key: "updated_at", Value: department.UpdatedAt},
+				}},
+			}
+			filter := bson.M{"id": department.ID, "organization": plan.Organization}
+			if _, err := deptCollection.UpdateOne(sc, filter, update); err != nil {
+				return nil, err
+			}
+		}
+
+		for i := 0; i < len(plan.CreateRoles); i++ {
+			role := plan.CreateRoles[i]
+			_, err := roleCollection.InsertOne(sc, bson.M{
+				"id":           role.ID,
+				"organization": role.Organization,
+				"department":   role.Department,
+				"name":         role.Name,
+				"status":       role.Status,
+				"is_active":    role.IsActive,
+				"created_at":   role.CreatedAt,
+				"updated_at":   role.UpdatedAt,
+			})
+			if err != nil {
+				return nil, err
+			}
+			_, err = rpCollection.InsertOne(sc, bson.M{
+				"role_id":      role.ID,
+				"organization": role.Organization,
+				"permissions":  role.Permissions,
+				"denies":       role.Denies,
+			})
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		for i := 0; i < len(plan.UpdateRoles); i++ {
+			role := plan.UpdateRoles[i]
+			update := bson.D{
+				{Key: "$set", Value: bson.D{
+					{Key: "updated_at", Value: role.UpdatedAt},
+				}},
+			}
+			filter := bson.M{"id": role.ID, "organization": plan.Organization}
+			if _, err := roleCollection.UpdateOne(sc, filter, update); err != nil {
+				return nil, err
+			}
+			update = bson.D{
+				{Key: "$set", Value: bson.D{
+					{Key: "permissions", Value: role.Permissions},
+					{Key: "denies", Value: role.Denies},
+				}},
+			}
+			if _, err := rpCollection.UpdateOne(sc, bson.M{"role_id": role.ID, "organization": plan.Organization}, update); err != nil {
+				return nil, err
+			}
+		}
+
+		if len(plan.DeleteRoles) > 0 {
+			ids := bson.M{"$in": plan.DeleteRoles}
+			if _, err := roleCollection.DeleteMany(sc, bson.M{"id": ids, "organization": plan.Organization}); err != nil {
+				return nil, err
+			}
+			if _, err := rpCollection.DeleteMany(sc, bson.M{"role_id": ids, "organization": plan.Organization}); err != nil {
+				return nil, err
+			}
+		}
+
+		if len(plan.DeleteDepartments) > 0 {
+			filter := bson.M{"id": bson.M{"$in": plan.DeleteDepartments}, "organization": plan.Organization}
+			if _, err := deptCollection.DeleteMany(sc, filter); err != nil {
+				return nil, err
+			}
+		}
+
+		return nil, nil
+	})
+	if err != nil {
+		return databaseError(ctx, err)
+	}
+	return nil
+}
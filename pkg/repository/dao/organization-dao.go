@@ -2,6 +2,7 @@ package dao
 
 import (
 	"context"
+	"regexp"
 	"time"
 
 	"gorabc/pkg/models"
@@ -9,15 +10,18 @@ import (
 	"gorabc/pkg/utils/resterr"
 
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 // OrganizationDaoInterface type
 type OrganizationDaoInterface interface {
-	Create(models.Organization) (*models.Organization, *resterr.RestErr)
-	FindAll() (models.Organizations, *resterr.RestErr)
-	GetByID(string) (*models.Organization, *resterr.RestErr)
-	Update(models.Organization) *resterr.RestErr
-	Delete(string) *resterr.RestErr
+	Create(context.Context, models.Organization) (*models.Organization, *resterr.RestErr)
+	FindAll(context.Context) (models.Organizations, *resterr.RestErr)
+	Find(context.Context, models.TenantFilter, int) (models.Organizations, *resterr.RestErr)
+	Usage(context.Context, string) (*models.TenantUsage, *resterr.RestErr)
+	GetByID(context.Context, string) (*models.Organization, *resterr.RestErr)
+	Update(context.Context, models.Organization) *resterr.RestErr
+	Delete(context.Context, string) *resterr.RestErr
 }
 
 type organizationDao struct{}
@@ -28,10 +32,10 @@ var (
 )
 
 // Create organization
-func (d *organizationDao) Create(organization models.Organization) (*models.Organization, *resterr.RestErr) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+func (d *organizationDao) Create(ctx context.Context, organization models.Organization) (*models.Organization, *resterr.RestErr) {
+	ctx, cancel := operation(ctx, "organization", "create", 10*time.Second)
 	defer cancel()
-	userDB := mongodb.Client.Database("erp-user-service")
+	userDB := mongodb.Database()
 
 	orgCollection := userDB.Collection("organization")
 
@@ -45,16 +49,16 @@ func (d *organizationDao) Create(organization models.Organization) (*models.Orga
 		"updated_at": organization.UpdatedAt,
 	})
 	if err != nil {
-		return nil, resterr.NewInternalServerError(err.Error())
+		return nil, databaseError(ctx, err)
 	}
 	return &organization, nil
 }
 
 // FindAll organization
-func (d *organizationDao) FindAll() (models.Organizations, *resterr.RestErr) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+func (d *organizationDao) FindAll(ctx context.Context) (models.Organizations, *resterr.RestErr) {
+	ctx, cancel := operation(ctx, "organization", "find_all", 10*time.Second)
 	defer cancel()
-	userDB := mongodb.Client.Database("erp-user-service")
+	userDB := mongodb.Database()
 
 	organizations := []models.Organization{}
 	orgCollection := userDB.Collection("organization")
@@ -62,21 +66,91 @@ func (d *organizationDao) FindAll() (models.Organizations, *resterr.RestErr) {
 	filter := bson.M{"status": models.StatusActive}
 	cursor, err := orgCollection.Find(ctx, filter)
 	if err != nil {
-		return nil, resterr.NewInternalServerError(err.Error())
+		return nil, databaseError(ctx, err)
 	}
 
 	if err = cursor.All(ctx, &organizations); err != nil {
-		return nil, resterr.NewInternalServerError(err.Error())
+		return nil, databaseError(ctx, err)
 	}
 
 	return organizations, nil
 }
 
+// Find organizations of every status matching the filter, the query matches
+// the name or the id
+func (d *organizationDao) Find(ctx context.Context, tenantFilter models.TenantFilter, limit int) (models.Organizations, *resterr.RestErr) {
+	ctx, cancel := operation(ctx, "organization", "find", 10*time.Second)
+	defer cancel()
+	userDB := mongodb.Database()
+
+	organizations := []models.Organization{}
+	orgCollection := userDB.Collection("organization")
+
+	filter := bson.M{}
+	if tenantFilter.Status != "" {
+		filter["status"] = tenantFilter.Status
+	}
+	if tenantFilter.Query != "" {
+		pattern := bson.M{"$regex": regexp.QuoteMeta(tenantFilter.Query), "$options": "i"}
+		filter["$or"] = bson.A{bson.M{"name": pattern}, bson.M{"id": pattern}}
+	}
+
+	opts := options.Find().SetSort(bson.M{"created_at": 1})
+	if limit > 0 {
+		opts.SetLimit(int64(limit))
+	}
+	cursor, err := orgCollection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, databaseError(ctx, err)
+	}
+
+	if err = cursor.All(ctx, &organizations); err != nil {
+		return nil, databaseError(ctx, err)
+	}
+
+	return organizations, nil
+}
+
+// Usage counts the users, departments, roles, policies and pending invites
+// of the organization
+func (d *organizationDao) Usage(ctx context.Context, org string) (*models.TenantUsage, *resterr.RestErr) {
+	ctx, cancel := operation(ctx, "organization", "usage", 10*time.Second)
+	defer cancel()
+	userDB := mongodb.Database()
+
+	usage := models.TenantUsage{}
+	counts := []struct {
+		collection string
+		filter     bson.M
+		count      *int64
+	}{
+		{"user", bson.M{}, &usage.Users},
+		{"user", bson.M{"status": models.StatusActive}, &usage.ActiveUsers},
+		{"user", bson.M{"is_org_admin": true}, &usage.OrgAdmins},
+		{"department", bson.M{"status": models.StatusActive}, &usage.Departments},
+		{"role", bson.M{"status": models.StatusActive}, &usage.Roles},
+		{"policy", bson.M{"status": models.StatusActive}, &usage.Policies},
+		{"user-invites", bson.M{"status": models.InviteStatusPending}, &usage.PendingInvites},
+	}
+	for i := 0; i < len(counts); i++ {
+		filter, tenantErr := tenant(org, counts[i].filter)
+		if tenantErr != nil {
+			return nil, tenantErr
+		}
+		count, err := userDB.Collection(counts[i].collection).CountDocuments(ctx, filter)
+		if err != nil {
+			return nil, databaseError(ctx, err)
+		}
+		*counts[i].count = count
+	}
+	return &usage, nil
+}
+
 // GetByID organization
-func (d *organizationDao) GetByID(id string) (*models.Organization, *resterr.RestErr) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+func (d *organizationDao) GetByID(ctx context.Context, id string) (*models.Organization, *resterr.RestErr) {
+	ctx, cancel := operation(ctx, "organization", "get_by_id", 10*time.Second)
 	defer cancel()
-	userDB := mongodb.Client.Database("erp-user-service")
+	userDB := mongodb.Database()
 
 	organization := models.Organization{}
 	orgCollection := userDB.Collection("organization")
@@ -84,18 +158,18 @@ func (d *organizationDao) GetByID(id string) (*models.Organization, *resterr.Res
 	filter := bson.M{"id": id}
 	err := orgCollection.FindOne(ctx, filter).Decode(&organization)
 	if err != nil {
-		return nil, resterr.NewInternalServerError(err.Error())
+		return nil, databaseError(ctx, err)
 	}
 
 	return &organization, nil
 }
 
 // Update  organization
-func (d *organizationDao) Update(organization models.Organization) *resterr.RestErr {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+func (d *organizationDao) Update(ctx context.Context, organization models.Organization) *resterr.RestErr {
+	ctx, cancel := operation(ctx, "organization", "update", 10*time.Second)
 	defer cancel()
 
-	userDB := mongodb.Client.Database("erp-user-service")
+	userDB := mongodb.Database()
 	orgCollection := userDB.Collection("organization")
 
 	filter := bson.M{"id": organization.ID}
@@ -104,6 +178,7 @@ func (d *organizationDao) Update(organization models.Organization) *resterr.Rest
 			{Key: "name", Value: organization.Name},
 			{Key: "website", Value: organization.Website},
 			{Key: "status", Value: organization.Status},
+			{Key: "status_reason", Value: organization.StatusReason},
 			{Key: "is_active", Value: organization.IsActive},
 			{Key: "updated_at", Value: organization.UpdatedAt},
 		}},
@@ -111,24 +186,24 @@ func (d *organizationDao) Update(organization models.Organization) *resterr.Rest
 
 	_, err := orgCollection.UpdateOne(ctx, filter, update)
 	if err != nil {
-		return resterr.NewInternalServerError(err.Error())
+		return databaseError(ctx, err)
 	}
 	return nil
 }
 
 // Delete User
-func (d *organizationDao) Delete(id string) *resterr.RestErr {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+func (d *organizationDao) Delete(ctx context.Context, id string) *resterr.RestErr {
+	ctx, cancel := operation(ctx, "organization", "delete", 10*time.Second)
 	defer cancel()
 
-	userDB := mongodb.Client.Database("erp-user-service")
+	userDB := mongodb.Database()
 	orgCollection := userDB.Collection("organization")
 
 	filter := bson.M{"id": id}
 
 	_, err := orgCollection.DeleteOne(ctx, filter)
 	if err != nil {
-		return resterr.NewInternalServerError(err.Error())
+		return databaseError(ctx, err)
 	}
 	return nil
 }
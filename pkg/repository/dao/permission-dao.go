@@ -13,11 +13,11 @@ import (
 
 // PermissionDaoInterface type
 type PermissionDaoInterface interface {
-	Create(models.Permission) (*models.Permission, *resterr.RestErr)
-	FindAll() (models.Permissions, *resterr.RestErr)
-	GetByName(string) (*models.Permission, *resterr.RestErr)
-	Update(models.Permission) *resterr.RestErr
-	Delete(string) *resterr.RestErr
+	Create(context.Context, models.Permission) (*models.Permission, *resterr.RestErr)
+	FindAll(context.Context) (models.Permissions, *resterr.RestErr)
+	GetByName(context.Context, string) (*models.Permission, *resterr.RestErr)
+	Update(context.Context, models.Permission) *resterr.RestErr
+	Delete(context.Context, string) *resterr.RestErr
 }
 
 type permissionDao struct{}
@@ -28,10 +28,10 @@ var (
 )
 
 // Create permission
-func (d *permissionDao) Create(permission models.Permission) (*models.Permission, *resterr.RestErr) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+func (d *permissionDao) Create(ctx context.Context, permission models.Permission) (*models.Permission, *resterr.RestErr) {
+	ctx, cancel := operation(ctx, "permission", "create", 10*time.Second)
 	defer cancel()
-	userDB := mongodb.Client.Database("erp-user-service")
+	userDB := mongodb.Database()
 
 	permissionCollection := userDB.Collection("permission")
 
@@ -39,16 +39,16 @@ func (d *permissionDao) Create(permission models.Permission) (*models.Permission
 		"name": permission.Name,
 	})
 	if err != nil {
-		return nil, resterr.NewInternalServerError(err.Error())
+		return nil, databaseError(ctx, err)
 	}
 	return &permission, nil
 }
 
 // FindAll permission
-func (d *permissionDao) FindAll() (models.Permissions, *resterr.RestErr) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+func (d *permissionDao) FindAll(ctx context.Context) (models.Permissions, *resterr.RestErr) {
+	ctx, cancel := operation(ctx, "permission", "find_all", 10*time.Second)
 	defer cancel()
-	userDB := mongodb.Client.Database("erp-user-service")
+	userDB := mongodb.Database()
 
 	permissions := []models.Permission{}
 	permissionCollection := userDB.Collection("permission")
@@ -56,21 +56,21 @@ func (d *permissionDao) FindAll() (models.Permissions, *resterr.RestErr) {
 	filter := bson.M{}
 	cursor, err := permissionCollection.Find(ctx, filter)
 	if err != nil {
-		return nil, resterr.NewInternalServerError(err.Error())
+		return nil, databaseError(ctx, err)
 	}
 
 	if err = cursor.All(ctx, &permissions); err != nil {
-		return nil, resterr.NewInternalServerError(err.Error())
+		return nil, databaseError(ctx, err)
 	}
 
 	return permissions, nil
 }
 
 // GetByName permission
-func (d *permissionDao) GetByName(name string) (*models.Permission, *resterr.RestErr) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+func (d *permissionDao) GetByName(ctx context.Context, name string) (*models.Permission, *resterr.RestErr) {
+	ctx, cancel := operation(ctx, "permission", "get_by_name", 10*time.Second)
 	defer cancel()
-	userDB := mongodb.Client.Database("erp-user-service")
+	userDB := mongodb.Database()
 
 	permission := models.Permission{}
 	permissionCollection := userDB.Collection("permission")
@@ -78,18 +78,18 @@ func (d *permissionDao) GetByName(name string) (*models.Permission, *resterr.Res
 	filter := bson.M{"name": name}
 	err := permissionCollection.FindOne(ctx, filter).Decode(&permission)
 	if err != nil {
-		return nil, resterr.NewInternalServerError(err.Error())
+		return nil, databaseError(ctx, err)
 	}
 
 	return &permission, nil
 }
 
 // Update  permission
-func (d *permissionDao) Update(permission models.Permission) *resterr.RestErr {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+func (d *permissionDao) Update(ctx context.Context, permission models.Permission) *resterr.RestErr {
+	ctx, cancel := operation(ctx, "permission", "update", 10*time.Second)
 	defer cancel()
 
-	userDB := mongodb.Client.Database("erp-user-service")
+	userDB := mongodb.Database()
 	permissionCollection := userDB.Collection("permission")
 
 	filter := bson.M{"name": permission.Name}
@@ -101,24 +101,24 @@ func (d *permissionDao) Update(permission models.Permission) *resterr.RestErr {
 
 	_, err := permissionCollection.UpdateOne(ctx, filter, update)
 	if err != nil {
-		return resterr.NewInternalServerError(err.Error())
+		return databaseError(ctx, err)
 	}
 	return nil
 }
 
 // Delete User
-func (d *permissionDao) Delete(name string) *resterr.RestErr {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+func (d *permissionDao) Delete(ctx context.Context, name string) *resterr.RestErr {
+	ctx, cancel := operation(ctx, "permission", "delete", 10*time.Second)
 	defer cancel()
 
-	userDB := mongodb.Client.Database("erp-user-service")
+	userDB := mongodb.Database()
 	permissionCollection := userDB.Collection("permission")
 
 	filter := bson.M{"name": name}
 
 	_, err := permissionCollection.DeleteOne(ctx, filter)
 	if err != nil {
-		return resterr.NewInternalServerError(err.Error())
+		return databaseError(ctx, err)
 	}
 	return nil
 }
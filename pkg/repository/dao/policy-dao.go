@@ -0,0 +1,155 @@
+package dao
+
+import (
+	"context"
+	"time"
+
+	"gorabc/pkg/models"
+	"gorabc/pkg/settings/db/mongodb"
+	"gorabc/pkg/utils/resterr"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// PolicyDaoInterface type
+type PolicyDaoInterface interface {
+	Create(context.Context, models.Policy) (*models.Policy, *resterr.RestErr)
+	FindAll(context.Context, string) (models.Policies, *resterr.RestErr)
+	GetByID(context.Context, string, string) (*models.Policy, *resterr.RestErr)
+	Update(context.Context, models.Policy) *resterr.RestErr
+	Delete(context.Context, string, string) *resterr.RestErr
+}
+
+type policyDao struct{}
+
+// PolicyDao variable
+var (
+	PolicyDao PolicyDaoInterface = &policyDao{}
+)
+
+// Create policy
+func (d *policyDao) Create(ctx context.Context, policy models.Policy) (*models.Policy, *resterr.RestErr) {
+	ctx, cancel := operation(ctx, "policy", "create", 10*time.Second)
+	defer cancel()
+	userDB := mongodb.Database()
+
+	policyCollection := userDB.Collection("policy")
+
+	_, err := policyCollection.InsertOne(ctx, bson.M{
+		"id":              policy.ID,
+		"organization":    policy.Organization,
+		"name":            policy.Name,
+		"description":     policy.Description,
+		"effect":          policy.Effect,
+		"actions":         policy.Actions,
+		"conditions":      policy.Conditions,
+		"condition_match": policy.ConditionMatch,
+		"status":          policy.Status,
+		"is_active":       policy.IsActive,
+		"created_at":      policy.CreatedAt,
+		"updated_at":      policy.UpdatedAt,
+	})
+	if err != nil {
+		return nil, databaseError(ctx, err)
+	}
+	return &policy, nil
+}
+
+// FindAll policy
+func (d *policyDao) FindAll(ctx context.Context, org string) (models.Policies, *resterr.RestErr) {
+	ctx, cancel := operation(ctx, "policy", "find_all", 10*time.Second)
+	defer cancel()
+	userDB := mongodb.Database()
+
+	policies := []models.Policy{}
+	policyCollection := userDB.Collection("policy")
+
+	filter, tenantErr := tenant(org, bson.M{"status": models.StatusActive})
+	if tenantErr != nil {
+		return nil, tenantErr
+	}
+	cursor, err := policyCollection.Find(ctx, filter)
+	if err != nil {
+		return nil, databaseError(ctx, err)
+	}
+
+	if err = cursor.All(ctx, &policies); err != nil {
+		return nil, databaseError(ctx, err)
+	}
+
+	return policies, nil
+}
+
+// GetByID policy
+func (d *policyDao) GetByID(ctx context.Context, id string, org string) (*models.Policy, *resterr.RestErr) {
+	ctx, cancel := operation(ctx, "policy", "get_by_id", 10*time.Second)
+	defer cancel()
+	userDB := mongodb.Database()
+
+	policy := models.Policy{}
+	policyCollection := userDB.Collection("policy")
+
+	filter, tenantErr := tenant(org, bson.M{"id": id})
+	if tenantErr != nil {
+		return nil, tenantErr
+	}
+	err := policyCollection.FindOne(ctx, filter).Decode(&policy)
+	if err != nil {
+		return nil, databaseError(ctx, err)
+	}
+
+	return &policy, nil
+}
+
+// Update policy
+func (d *policyDao) Update(ctx context.Context, policy models.Policy) *resterr.RestErr {
+	ctx, cancel := operation(ctx, "policy", "update", 10*time.Second)
+	defer cancel()
+
+	userDB := mongodb.Database()
+	policyCollection := userDB.Collection("policy")
+
+	filter, tenantErr := tenant(policy.Organization, bson.M{"id": policy.ID})
+	if tenantErr != nil {
+		return tenantErr
+	}
+	update := bson.D{
+		{Key: "$set", Value: bson.D{
+			{Key: "name", Value: policy.Name},
+			{Key: "description", Value: policy.Description},
+			{Key: "effect", Value: policy.Effect},
+			{Key: "actions", Value: policy.Actions},
+			{Key: "conditions", Value: policy.Conditions},
+			{Key: "condition_match", Value: policy.ConditionMatch},
+			{Key: "status", Value: policy.Status},
+			{Key: "is_active", Value: policy.IsActive},
+			{Key: "updated_at", Value: policy.UpdatedAt},
+		}},
+	}
+
+	result, err := policyCollection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return databaseError(ctx, err)
+	}
+	return missing(ctx, result.MatchedCount)
+}
+
+// Delete policy
+func (d *policyDao) Delete(ctx context.Context, id string, org string) *resterr.RestErr {
+	ctx, cancel := operation(ctx, "policy", "delete", 10*time.Second)
+	defer cancel()
+
+	userDB := mongodb.Database()
+	policyCollection := userDB.Collection("policy")
+
+	filter, tenantErr := tenant(org, bson.M{"id": id})
+	if tenantErr != nil {
+		return tenantErr
+	}
+
+	result, err := policyCollection.DeleteOne(ctx, filter)
+	if err != nil {
+		return databaseError(ctx, err)
+	}
+	return missing(ctx, result.DeletedCount)
+}
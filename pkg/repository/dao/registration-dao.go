@@ -0,0 +1,156 @@
+package dao
+
+import (
+	"context"
+	"time"
+
+	"gorabc/pkg/models"
+	"gorabc/pkg/settings/db/mongodb"
+	"gorabc/pkg/utils/resterr"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// RegistrationDaoInterface type
+type RegistrationDaoInterface interface {
+	CreateInvite(context.Context, models.RegistrationInvite) (*models.RegistrationInvite, *resterr.RestErr)
+	FindInvites(context.Context) (models.RegistrationInvites, *resterr.RestErr)
+	RevokeInvite(context.Context, string) *resterr.RestErr
+	ConsumeInvite(context.Context, string, string, string) (*models.RegistrationInvite, *resterr.RestErr)
+	SetInviteOrganization(context.Context, string, string) *resterr.RestErr
+	ConsumeBootstrap(context.Context, string) (bool, *resterr.RestErr)
+}
+
+type registrationDao struct{}
+
+// RegistrationDao variable
+var (
+	RegistrationDao RegistrationDaoInterface = &registrationDao{}
+)
+
+// CreateInvite registration invite
+func (d *registrationDao) CreateInvite(ctx context.Context, invite models.RegistrationInvite) (*models.RegistrationInvite, *resterr.RestErr) {
+	ctx, cancel := operation(ctx, "registration-invites", "create_invite", 10*time.Second)
+	defer cancel()
+	userDB := mongodb.Database()
+
+	if _, err := userDB.Collection("registration-invites").InsertOne(ctx, invite); err != nil {
+		return nil, databaseError(ctx, err)
+	}
+	return &invite, nil
+}
+
+// FindInvites registration invites, newest first
+func (d *registrationDao) FindInvites(ctx context.Context) (models.RegistrationInvites, *resterr.RestErr) {
+	ctx, cancel := operation(ctx, "registration-invites", "find_invites", 10*time.Second)
+	defer cancel()
+	userDB := mongodb.Database()
+
+	invites := models.RegistrationInvites{}
+	opts := options.Find().SetSort(bson.M{"created_at": -1})
+	cursor, err := userDB.Collection("registration-invites").Find(ctx, bson.M{}, opts)
+	if err != nil {
+		return nil, databaseError(ctx, err)
+	}
+	if err = cursor.All(ctx, &invites); err != nil {
+		return nil, databaseError(ctx, err)
+	}
+	return invites, nil
+}
+
+// RevokeInvite pending registration invite
+func (d *registrationDao) RevokeInvite(ctx context.Context, id string) *resterr.RestErr {
+	ctx, cancel := operation(ctx, "registration-invites", "revoke_invite", 10*time.Second)
+	defer cancel()
+	userDB := mongodb.Database()
+
+	filter := bson.M{"id": id, "status": models.InviteStatusPending}
+	update := bson.D{
+		{Key: "$set", Value: bson.D{
+			{Key: "status", Value: models.InviteStatusRevoked},
+		}},
+	}
+	result, err := userDB.Collection("registration-invites").UpdateOne(ctx, filter, update)
+	if err != nil {
+		return databaseError(ctx, err)
+	}
+	if result.MatchedCount == 0 {
+		return resterr.NewNotFoundError("Pending invite not found")
+	}
+	return nil
+}
+
+// ConsumeInvite marks a pending, unexpired invite for the email as used, the
+// update is conditional so that an invite can only be used once
+func (d *registrationDao) ConsumeInvite(ctx context.Context, tokenHash string, email string, now string) (*models.RegistrationInvite, *resterr.RestErr) {
+	ctx, cancel := operation(ctx, "registration-invites", "consume_invite", 10*time.Second)
+	defer cancel()
+	userDB := mongodb.Database()
+
+	filter := bson.M{
+		"token_hash": tokenHash,
+		"status":     models.InviteStatusPending,
+		"expires_at": bson.M{"$gt": now},
+		"$or": bson.A{
+			bson.M{"email": bson.M{"$exists": false}},
+			bson.M{"email": email},
+		},
+	}
+	update := bson.D{
+		{Key: "$set", Value: bson.D{
+			{Key: "status", Value: models.InviteStatusUsed},
+			{Key: "used_at", Value: now},
+		}},
+	}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	invite := models.RegistrationInvite{}
+	err := userDB.Collection("registration-invites").FindOneAndUpdate(ctx, filter, update, opts).Decode(&invite)
+	if err == mongo.ErrNoDocuments {
+		return nil, resterr.NewForbiddenError("Invalid or expired invite")
+	}
+	if err != nil {
+		return nil, databaseError(ctx, err)
+	}
+	return &invite, nil
+}
+
+// SetInviteOrganization records the organization registered with the invite
+func (d *registrationDao) SetInviteOrganization(ctx context.Context, id string, org string) *resterr.RestErr {
+	ctx, cancel := operation(ctx, "registration-invites", "set_invite_organization", 10*time.Second)
+	defer cancel()
+	userDB := mongodb.Database()
+
+	update := bson.D{
+		{Key: "$set", Value: bson.D{
+			{Key: "organization", Value: org},
+		}},
+	}
+	if _, err := userDB.Collection("registration-invites").UpdateOne(ctx, bson.M{"id": id}, update); err != nil {
+		return databaseError(ctx, err)
+	}
+	return nil
+}
+
+// ConsumeBootstrap records a one-time bootstrap step, it reports false when
+// the step has already been consumed
+func (d *registrationDao) ConsumeBootstrap(ctx context.Context, key string) (bool, *resterr.RestErr) {
+	ctx, cancel := operation(ctx, "bootstrap", "consume_bootstrap", 10*time.Second)
+	defer cancel()
+	userDB := mongodb.Database()
+
+	_, err := userDB.Collection("bootstrap").InsertOne(ctx, bson.M{"_id": key, "consumed_at": time.Now().UTC()})
+	if err == nil {
+		return true, nil
+	}
+	if writeErr, ok := err.(mongo.WriteException); ok {
+		for i := 0; i < len(writeErr.WriteErrors); i++ {
+			if writeErr.WriteErrors[i].Code == 11000 {
+				return false, nil
+			}
+		}
+	}
+	return false, databaseError(ctx, err)
+}
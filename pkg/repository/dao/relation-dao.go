@@ -0,0 +1,312 @@
+package dao
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"gorabc/pkg/models"
+	"gorabc/pkg/settings/db/mongodb"
+	"gorabc/pkg/utils/datetime"
+	"gorabc/pkg/utils/resterr"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// RelationDaoInterface type
+type RelationDaoInterface interface {
+	Write(context.Context, string, models.RelationTuples, models.RelationTuples) (int64, *resterr.RestErr)
+	Revision(context.Context, string) (int64, *resterr.RestErr)
+	Read(context.Context, string, string, string, int64) (models.RelationTuples, *resterr.RestErr)
+	Find(context.Context, string, models.RelationTupleFilter, int64) (models.RelationTuples, *resterr.RestErr)
+	FindObjects(context.Context, string, string, int64) ([]string, *resterr.RestErr)
+	FindNamespaces(context.Context, string) (models.Namespaces, *resterr.RestErr)
+	UpsertNamespace(context.Context, models.Namespace) *resterr.RestErr
+	DeleteNamespace(context.Context, string, string) *resterr.RestErr
+}
+
+type relationDao struct {
+	indexOnce sync.Once
+}
+
+// RelationDao variable
+var (
+	RelationDao RelationDaoInterface = &relationDao{}
+)
+
+// relationRevision of an organization
+type relationRevision struct {
+	Organization string `bson:"organization"`
+	Revision     int64  `bson:"revision"`
+}
+
+// Write tuples and delete tuples under a new revision of the organization in
+// a single transaction
+func (d *relationDao) Write(ctx context.Context, org string, writes models.RelationTuples, deletes models.RelationTuples) (int64, *resterr.RestErr) {
+	ctx, cancel := operation(ctx, "relation-tuple", "write", 10*time.Second)
+	defer cancel()
+	userDB := mongodb.Database()
+
+	revisionFilter, tenantErr := tenant(org, bson.M{})
+	if tenantErr != nil {
+		return 0, tenantErr
+	}
+
+	revisionCollection := userDB.Collection("relation-revision")
+	tupleCollection := userDB.Collection("relation-tuple")
+
+	// A single revision counter per organization
+	d.indexOnce.Do(func() {
+		index := mongo.IndexModel{
+			Keys:    bson.D{{Key: "organization", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		}
+		revisionCollection.Indexes().CreateOne(ctx, index)
+	})
+
+	session, err := mongodb.Client.StartSession()
+	if err != nil {
+		return 0, databaseError(ctx, err)
+	}
+	defer session.EndSession(ctx)
+
+	// The revision is only visible with all of its tuples
+	revision := relationRevision{}
+	_, err = session.WithTransaction(ctx, func(sc mongo.SessionContext) (interface{}, error) {
+		opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+		err := revisionCollection.FindOneAndUpdate(sc,
+			revisionFilter,
+			bson.M{"$inc": bson.M{"revision": 1}},
+			opts,
+		).Decode(&revision)
+		if err != nil {
+			return nil, err
+		}
+
+		for i := 0; i < len(deletes); i++ {
+			filter := bson.M{
+				"organization":     org,
+				"object":           deletes[i].Object,
+				"relation":         deletes[i].Relation,
+				"subject":          deletes[i].Subject,
+				"deleted_revision": 0,
+			}
+			update := bson.M{"$set": bson.M{"deleted_revision": revision.Revision}}
+			if _, err := tupleCollection.UpdateMany(sc, filter, update); err != nil {
+				return nil, err
+			}
+		}
+
+		// Writing a live tuple again keeps its revision
+		for i := 0; i < len(writes); i++ {
+			filter := bson.M{
+				"organization":     org,
+				"object":           writes[i].Object,
+				"relation":         writes[i].Relation,
+				"subject":          writes[i].Subject,
+				"deleted_revision": 0,
+			}
+			update := bson.M{"$setOnInsert": bson.M{
+				"object_type":      writes[i].ObjectType,
+				"subject_object":   writes[i].SubjectObject,
+				"managed":          writes[i].Managed,
+				"created_revision": revision.Revision,
+				"created_at":       datetime.GetDateTimeString(),
+			}}
+			if _, err := tupleCollection.UpdateOne(sc, filter, update, options.Update().SetUpsert(true)); err != nil {
+				return nil, err
+			}
+		}
+		return nil, nil
+	})
+	if err != nil {
+		return 0, databaseError(ctx, err)
+	}
+
+	return revision.Revision, nil
+}
+
+// Revision returns the latest revision of the organization
+func (d *relationDao) Revision(ctx context.Context, org string) (int64, *resterr.RestErr) {
+	ctx, cancel := operation(ctx, "relation-revision", "revision", 10*time.Second)
+	defer cancel()
+	userDB := mongodb.Database()
+
+	filter, tenantErr := tenant(org, bson.M{})
+	if tenantErr != nil {
+		return 0, tenantErr
+	}
+
+	revision := relationRevision{}
+	err := userDB.Collection("relation-revision").FindOne(ctx, filter).Decode(&revision)
+	if err == mongo.ErrNoDocuments {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, databaseError(ctx, err)
+	}
+	return revision.Revision, nil
+}
+
+// Read the tuples of an object relation at a revision
+func (d *relationDao) Read(ctx context.Context, org string, object string, relation string, revision int64) (models.RelationTuples, *resterr.RestErr) {
+	return d.Find(ctx, org, models.RelationTupleFilter{Object: object, Relation: relation}, revision)
+}
+
+// Find the tuples matching the filter at a revision
+func (d *relationDao) Find(ctx context.Context, org string, tupleFilter models.RelationTupleFilter, revision int64) (models.RelationTuples, *resterr.RestErr) {
+	ctx, cancel := operation(ctx, "relation-tuple", "find", 10*time.Second)
+	defer cancel()
+	userDB := mongodb.Database()
+
+	tuples := []models.RelationTuple{}
+	tupleCollection := userDB.Collection("relation-tuple")
+
+	filter, tenantErr := snapshotFilter(org, revision)
+	if tenantErr != nil {
+		return nil, tenantErr
+	}
+	if tupleFilter.ObjectType != "" {
+		filter["object_type"] = tupleFilter.ObjectType
+	}
+	if tupleFilter.Object != "" {
+		filter["object"] = tupleFilter.Object
+	}
+	if tupleFilter.Relation != "" {
+		filter["relation"] = tupleFilter.Relation
+	}
+	if tupleFilter.Subject != "" {
+		filter["subject"] = tupleFilter.Subject
+	}
+	if tupleFilter.SubjectObject != "" {
+		filter["subject_object"] = tupleFilter.SubjectObject
+	}
+
+	cursor, err := tupleCollection.Find(ctx, filter)
+	if err != nil {
+		return nil, databaseError(ctx, err)
+	}
+
+	if err = cursor.All(ctx, &tuples); err != nil {
+		return nil, databaseError(ctx, err)
+	}
+
+	return tuples, nil
+}
+
+// FindObjects lists the objects of a type holding a tuple at a revision
+func (d *relationDao) FindObjects(ctx context.Context, org string, objectType string, revision int64) ([]string, *resterr.RestErr) {
+	ctx, cancel := operation(ctx, "relation-tuple", "find_objects", 10*time.Second)
+	defer cancel()
+	userDB := mongodb.Database()
+
+	tupleCollection := userDB.Collection("relation-tuple")
+
+	filter, tenantErr := snapshotFilter(org, revision)
+	if tenantErr != nil {
+		return nil, tenantErr
+	}
+	filter["object_type"] = objectType
+
+	values, err := tupleCollection.Distinct(ctx, "object", filter)
+	if err != nil {
+		return nil, databaseError(ctx, err)
+	}
+
+	objects := []string{}
+	for i := 0; i < len(values); i++ {
+		if object, ok := values[i].(string); ok {
+			objects = append(objects, object)
+		}
+	}
+	return objects, nil
+}
+
+// FindNamespaces of the organization
+func (d *relationDao) FindNamespaces(ctx context.Context, org string) (models.Namespaces, *resterr.RestErr) {
+	ctx, cancel := operation(ctx, "relation-namespace", "find_namespaces", 10*time.Second)
+	defer cancel()
+	userDB := mongodb.Database()
+
+	namespaces := []models.Namespace{}
+	namespaceCollection := userDB.Collection("relation-namespace")
+
+	filter, tenantErr := tenant(org, bson.M{})
+	if tenantErr != nil {
+		return nil, tenantErr
+	}
+
+	cursor, err := namespaceCollection.Find(ctx, filter)
+	if err != nil {
+		return nil, databaseError(ctx, err)
+	}
+
+	if err = cursor.All(ctx, &namespaces); err != nil {
+		return nil, databaseError(ctx, err)
+	}
+
+	return namespaces, nil
+}
+
+// UpsertNamespace of the organization
+func (d *relationDao) UpsertNamespace(ctx context.Context, namespace models.Namespace) *resterr.RestErr {
+	ctx, cancel := operation(ctx, "relation-namespace", "upsert_namespace", 10*time.Second)
+	defer cancel()
+	userDB := mongodb.Database()
+
+	namespaceCollection := userDB.Collection("relation-namespace")
+
+	filter, tenantErr := tenant(namespace.Organization, bson.M{"name": namespace.Name})
+	if tenantErr != nil {
+		return tenantErr
+	}
+	update := bson.D{
+		{Key: "$set", Value: bson.D{
+			{Key: "relations", Value: namespace.Relations},
+			{Key: "managed", Value: false},
+			{Key: "updated_at", Value: namespace.UpdatedAt},
+		}},
+	}
+
+	_, err := namespaceCollection.UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
+	if err != nil {
+		return databaseError(ctx, err)
+	}
+	return nil
+}
+
+// DeleteNamespace of the organization
+func (d *relationDao) DeleteNamespace(ctx context.Context, name string, org string) *resterr.RestErr {
+	ctx, cancel := operation(ctx, "relation-namespace", "delete_namespace", 10*time.Second)
+	defer cancel()
+	userDB := mongodb.Database()
+
+	namespaceCollection := userDB.Collection("relation-namespace")
+
+	filter, tenantErr := tenant(org, bson.M{"name": name})
+	if tenantErr != nil {
+		return tenantErr
+	}
+
+	result, err := namespaceCollection.DeleteOne(ctx, filter)
+	if err != nil {
+		return databaseError(ctx, err)
+	}
+	if result.DeletedCount == 0 {
+		return resterr.NewNotFoundError("Namespace not found")
+	}
+	return nil
+}
+
+// snapshotFilter selects the tuples of the organization live at a revision
+func snapshotFilter(org string, revision int64) (bson.M, *resterr.RestErr) {
+	return tenant(org, bson.M{
+		"created_revision": bson.M{"$lte": revision},
+		"$or": bson.A{
+			bson.M{"deleted_revision": 0},
+			bson.M{"deleted_revision": bson.M{"$gt": revision}},
+		},
+	})
+}
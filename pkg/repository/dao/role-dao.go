@@ -2,6 +2,7 @@ package dao
 
 import (
 	"context"
+	"sync"
 	"time"
 
 	"gorabc/pkg/models"
@@ -9,19 +10,23 @@ import (
 	"gorabc/pkg/utils/resterr"
 
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 // RoleDaoInterface type
 type RoleDaoInterface interface {
-	Create(models.Role) (*models.Role, *resterr.RestErr)
-	FindAll(string) (models.Roles, *resterr.RestErr)
-	GetByID(string, string) (*models.Role, *resterr.RestErr)
-	Update(models.Role) *resterr.RestErr
-	Delete(string) *resterr.RestErr
-	FindAllRolePermissions(string) ([]models.RolePermissions, *resterr.RestErr)
+	Create(context.Context, models.Role) (*models.Role, *resterr.RestErr)
+	FindAll(context.Context, string) (models.Roles, *resterr.RestErr)
+	GetByID(context.Context, string, string) (*models.Role, *resterr.RestErr)
+	Update(context.Context, models.Role) *resterr.RestErr
+	Delete(context.Context, string, string) *resterr.RestErr
+	FindAllRolePermissions(context.Context, string) ([]models.RolePermissions, *resterr.RestErr)
 }
 
-type roleDao struct{}
+type roleDao struct {
+	indexOnce sync.Once
+}
 
 // RoleDao variable
 var (
@@ -29,29 +34,40 @@ var (
 )
 
 // Create role
-func (d *roleDao) Create(role models.Role) (*models.Role, *resterr.RestErr) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+func (d *roleDao) Create(ctx context.Context, role models.Role) (*models.Role, *resterr.RestErr) {
+	ctx, cancel := operation(ctx, "role", "create", 10*time.Second)
 	defer cancel()
-	userDB := mongodb.Client.Database("erp-user-service")
+	userDB := mongodb.Database()
 
 	roleCollection := userDB.Collection("role")
 
+	// A role id is unique in its organization
+	d.indexOnce.Do(func() {
+		index := mongo.IndexModel{
+			Keys:    bson.D{{Key: "organization", Value: 1}, {Key: "id", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		}
+		roleCollection.Indexes().CreateOne(ctx, index)
+	})
+
 	_, err := roleCollection.InsertOne(ctx, bson.M{
-		"id":           role.ID,
-		"organization": role.Organization,
-		"department":   role.Department,
-		"name":         role.Name,
-		"status":       role.Status,
-		"is_active":    role.IsActive,
-		"created_at":   role.CreatedAt,
-		"updated_at":   role.UpdatedAt,
+		"id":               role.ID,
+		"organization":     role.Organization,
+		"department":       role.Department,
+		"name":             role.Name,
+		"template":         role.Template,
+		"template_version": role.TemplateVersion,
+		"status":           role.Status,
+		"is_active":        role.IsActive,
+		"created_at":       role.CreatedAt,
+		"updated_at":       role.UpdatedAt,
 	})
 	if err != nil {
-		return nil, resterr.NewInternalServerError(err.Error())
+		return nil, databaseError(ctx, err)
 	}
 
 	// Add role permissions
-	if rpErr := d.addPermissions(role); rpErr != nil {
+	if rpErr := d.addPermissions(ctx, role); rpErr != nil {
 		return nil, rpErr
 	}
 
@@ -59,62 +75,72 @@ func (d *roleDao) Create(role models.Role) (*models.Role, *resterr.RestErr) {
 }
 
 // FindAll role
-func (d *roleDao) FindAll(org string) (models.Roles, *resterr.RestErr) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+func (d *roleDao) FindAll(ctx context.Context, org string) (models.Roles, *resterr.RestErr) {
+	ctx, cancel := operation(ctx, "role", "find_all", 10*time.Second)
 	defer cancel()
-	userDB := mongodb.Client.Database("erp-user-service")
+	userDB := mongodb.Database()
 
 	roles := []models.Role{}
 	roleCollection := userDB.Collection("role")
 
-	filter := bson.M{"status": models.StatusActive, "organization": org}
+	filter, tenantErr := tenant(org, bson.M{"status": models.StatusActive})
+	if tenantErr != nil {
+		return nil, tenantErr
+	}
 	cursor, err := roleCollection.Find(ctx, filter)
 	if err != nil {
-		return nil, resterr.NewInternalServerError(err.Error())
+		return nil, databaseError(ctx, err)
 	}
 
 	if err = cursor.All(ctx, &roles); err != nil {
-		return nil, resterr.NewInternalServerError(err.Error())
+		return nil, databaseError(ctx, err)
 	}
 
 	return roles, nil
 }
 
 // GetByID role
-func (d *roleDao) GetByID(id string, org string) (*models.Role, *resterr.RestErr) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+func (d *roleDao) GetByID(ctx context.Context, id string, org string) (*models.Role, *resterr.RestErr) {
+	ctx, cancel := operation(ctx, "role", "get_by_id", 10*time.Second)
 	defer cancel()
-	userDB := mongodb.Client.Database("erp-user-service")
+	userDB := mongodb.Database()
 
 	role := models.Role{}
 	roleCollection := userDB.Collection("role")
 
-	filter := bson.M{"id": id, "organization": org}
+	filter, tenantErr := tenant(org, bson.M{"id": id})
+	if tenantErr != nil {
+		return nil, tenantErr
+	}
 	err := roleCollection.FindOne(ctx, filter).Decode(&role)
 	if err != nil {
-		return nil, resterr.NewInternalServerError(err.Error())
+		return nil, databaseError(ctx, err)
 	}
 
 	// Get role permisions
-	rp, rpErr := d.getPermissionsByRoleID(id)
+	rp, rpErr := d.getPermissionsByRoleID(ctx, id, org)
 	if rpErr != nil {
 		return nil, rpErr
 	}
 
 	role.Permissions = rp.Permissions
+	role.Denies = rp.Denies
 
 	return &role, nil
 }
 
 // Update  role
-func (d *roleDao) Update(role models.Role) *resterr.RestErr {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+func (d *roleDao) Update(ctx context.Context, role models.Role) *resterr.RestErr {
+	ctx, cancel := operation(ctx, "role", "update", 10*time.Second)
 	defer cancel()
 
-	userDB := mongodb.Client.Database("erp-user-service")
+	userDB := mongodb.Database()
 	roleCollection := userDB.Collection("role")
 
-	filter := bson.M{"id": role.ID}
+	filter, tenantErr := tenant(role.Organization, bson.M{"id": role.ID})
+	if tenantErr != nil {
+		return tenantErr
+	}
 	update := bson.D{
 		{Key: "$set", Value: bson.D{
 			{Key: "name", Value: role.Name},
@@ -124,35 +150,44 @@ func (d *roleDao) Update(role models.Role) *resterr.RestErr {
 		}},
 	}
 
-	_, err := roleCollection.UpdateOne(ctx, filter, update)
+	result, err := roleCollection.UpdateOne(ctx, filter, update)
 	if err != nil {
-		return resterr.NewInternalServerError(err.Error())
+		return databaseError(ctx, err)
+	}
+	if err := missing(ctx, result.MatchedCount); err != nil {
+		return err
 	}
 
 	// Update role permissions
-	if err := d.updatePermissions(role); err != nil {
+	if err := d.updatePermissions(ctx, role); err != nil {
 		return err
 	}
 	return nil
 }
 
-// Delete role
-func (d *roleDao) Delete(id string) *resterr.RestErr {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+// Delete role of the organization
+func (d *roleDao) Delete(ctx context.Context, id string, org string) *resterr.RestErr {
+	ctx, cancel := operation(ctx, "role", "delete", 10*time.Second)
 	defer cancel()
 
-	userDB := mongodb.Client.Database("erp-user-service")
+	userDB := mongodb.Database()
 	roleCollection := userDB.Collection("role")
 
-	filter := bson.M{"id": id}
+	filter, tenantErr := tenant(org, bson.M{"id": id})
+	if tenantErr != nil {
+		return tenantErr
+	}
 
-	_, err := roleCollection.DeleteOne(ctx, filter)
+	result, err := roleCollection.DeleteOne(ctx, filter)
 	if err != nil {
-		return resterr.NewInternalServerError(err.Error())
+		return databaseError(ctx, err)
+	}
+	if err := missing(ctx, result.DeletedCount); err != nil {
+		return err
 	}
 
 	// Delete permissions assoicated with this role
-	if rpErr := d.deletePermissions(id); rpErr != nil {
+	if rpErr := d.deletePermissions(ctx, id, org); rpErr != nil {
 		return rpErr
 	}
 
@@ -160,32 +195,35 @@ func (d *roleDao) Delete(id string) *resterr.RestErr {
 }
 
 // FindAllRolePermissions role
-func (d *roleDao) FindAllRolePermissions(org string) ([]models.RolePermissions, *resterr.RestErr) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+func (d *roleDao) FindAllRolePermissions(ctx context.Context, org string) ([]models.RolePermissions, *resterr.RestErr) {
+	ctx, cancel := operation(ctx, "role-permissions", "find_all_role_permissions", 10*time.Second)
 	defer cancel()
-	userDB := mongodb.Client.Database("erp-user-service")
+	userDB := mongodb.Database()
 
 	rp := []models.RolePermissions{}
 	roleCollection := userDB.Collection("role-permissions")
 
-	filter := bson.M{"organization": org}
+	filter, tenantErr := tenant(org, bson.M{})
+	if tenantErr != nil {
+		return nil, tenantErr
+	}
 	cursor, err := roleCollection.Find(ctx, filter)
 	if err != nil {
-		return nil, resterr.NewInternalServerError(err.Error())
+		return nil, databaseError(ctx, err)
 	}
 
 	if err = cursor.All(ctx, &rp); err != nil {
-		return nil, resterr.NewInternalServerError(err.Error())
+		return nil, databaseError(ctx, err)
 	}
 
 	return rp, nil
 }
 
 // addPermissions to role
-func (d *roleDao) addPermissions(role models.Role) *resterr.RestErr {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+func (d *roleDao) addPermissions(ctx context.Context, role models.Role) *resterr.RestErr {
+	ctx, cancel := operation(ctx, "role-permissions", "add_permissions", 10*time.Second)
 	defer cancel()
-	userDB := mongodb.Client.Database("erp-user-service")
+	userDB := mongodb.Database()
 
 	roleCollection := userDB.Collection("role-permissions")
 
@@ -193,66 +231,68 @@ func (d *roleDao) addPermissions(role models.Role) *resterr.RestErr {
 		"role_id":      role.ID,
 		"organization": role.Organization,
 		"permissions":  role.Permissions,
+		"denies":       role.Denies,
 	})
 	if err != nil {
-		return resterr.NewInternalServerError(err.Error())
+		return databaseError(ctx, err)
 	}
 	return nil
 }
 
 // getPermissionsByRoleID
-func (d *roleDao) getPermissionsByRoleID(roleID string) (*models.RolePermissions, *resterr.RestErr) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+func (d *roleDao) getPermissionsByRoleID(ctx context.Context, roleID string, org string) (*models.RolePermissions, *resterr.RestErr) {
+	ctx, cancel := operation(ctx, "role-permissions", "get_permissions_by_role_id", 10*time.Second)
 	defer cancel()
-	userDB := mongodb.Client.Database("erp-user-service")
+	userDB := mongodb.Database()
 
 	rp := models.RolePermissions{}
 	roleCollection := userDB.Collection("role-permissions")
 
-	filter := bson.M{"role_id": roleID}
+	filter := bson.M{"role_id": roleID, "organization": org}
 	err := roleCollection.FindOne(ctx, filter).Decode(&rp)
 	if err != nil {
-		return nil, resterr.NewInternalServerError(err.Error())
+		return nil, databaseError(ctx, err)
 	}
 
 	return &rp, nil
 }
 
 // updatePermissions  role
-func (d *roleDao) updatePermissions(role models.Role) *resterr.RestErr {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+func (d *roleDao) updatePermissions(ctx context.Context, role models.Role) *resterr.RestErr {
+	ctx, cancel := operation(ctx, "role-permissions", "update_permissions", 10*time.Second)
 	defer cancel()
 
-	userDB := mongodb.Client.Database("erp-user-service")
+	userDB := mongodb.Database()
 	roleCollection := userDB.Collection("role-permissions")
 
-	filter := bson.M{"role_id": role.ID}
+	filter := bson.M{"role_id": role.ID, "organization": role.Organization}
 	update := bson.D{
 		{Key: "$set", Value: bson.D{
 			{Key: "permissions", Value: role.Permissions},
+			{Key: "denies", Value: role.Denies},
 		}},
 	}
 
 	_, err := roleCollection.UpdateOne(ctx, filter, update)
 	if err != nil {
-		return resterr.NewInternalServerError(err.Error())
+		return databaseError(ctx, err)
 	}
 	return nil
 }
 
 // deletePermissions role permissions
-func (d *roleDao) deletePermissions(roleID string) *resterr.RestErr {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+func (d *roleDao) deletePermissions(ctx context.Context, roleID string, org string) *resterr.RestErr {
+	ctx, cancel := operation(ctx, "role-permissions", "delete_permissions", 10*time.Second)
 	defer cancel()
 
-	userDB := mongodb.Client.Database("erp-user-service")
+	userDB := mongodb.Database()
 	roleCollection := userDB.Collection("role-permissions")
 
-	filter := bson.M{"role_id": roleID}
+	filter := bson.M{"role_id": roleID, "organization": org}
 
 	_, err := roleCollection.DeleteOne(ctx, filter)
 	if err != nil {
-		return resterr.NewInternalServerError(err.Error())
+		return databaseError(ctx, err)
 	}
 	return nil
 }
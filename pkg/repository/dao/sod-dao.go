@@ -0,0 +1,142 @@
+package dao
+
+import (
+	"context"
+	"time"
+
+	"gorabc/pkg/models"
+	"gorabc/pkg/settings/db/mongodb"
+	"gorabc/pkg/utils/resterr"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// SoDDaoInterface type
+type SoDDaoInterface interface {
+	Create(context.Context, models.SoDConstraint) (*models.SoDConstraint, *resterr.RestErr)
+	FindAll(context.Context, string) (models.SoDConstraints, *resterr.RestErr)
+	GetByID(context.Context, string, string) (*models.SoDConstraint, *resterr.RestErr)
+	Update(context.Context, models.SoDConstraint) *resterr.RestErr
+	Delete(context.Context, string, string) *resterr.RestErr
+}
+
+type sodDao struct{}
+
+// SoDDao variable
+var (
+	SoDDao SoDDaoInterface = &sodDao{}
+)
+
+// Create separation of duties constraint
+func (d *sodDao) Create(ctx context.Context, constraint models.SoDConstraint) (*models.SoDConstraint, *resterr.RestErr) {
+	ctx, cancel := operation(ctx, "sod-constraints", "create", 10*time.Second)
+	defer cancel()
+	userDB := mongodb.Database()
+
+	sodCollection := userDB.Collection("sod-constraints")
+
+	_, err := sodCollection.InsertOne(ctx, constraint)
+	if err != nil {
+		return nil, databaseError(ctx, err)
+	}
+	return &constraint, nil
+}
+
+// FindAll active separation of duties constraints
+func (d *sodDao) FindAll(ctx context.Context, org string) (models.SoDConstraints, *resterr.RestErr) {
+	ctx, cancel := operation(ctx, "sod-constraints", "find_all", 10*time.Second)
+	defer cancel()
+	userDB := mongodb.Database()
+
+	constraints := []models.SoDConstraint{}
+	sodCollection := userDB.Collection("sod-constraints")
+
+	filter, tenantErr := tenant(org, bson.M{"status": models.StatusActive})
+	if tenantErr != nil {
+		return nil, tenantErr
+	}
+	cursor, err := sodCollection.Find(ctx, filter)
+	if err != nil {
+		return nil, databaseError(ctx, err)
+	}
+
+	if err = cursor.All(ctx, &constraints); err != nil {
+		return nil, databaseError(ctx, err)
+	}
+
+	return constraints, nil
+}
+
+// GetByID separation of duties constraint
+func (d *sodDao) GetByID(ctx context.Context, id string, org string) (*models.SoDConstraint, *resterr.RestErr) {
+	ctx, cancel := operation(ctx, "sod-constraints", "get_by_id", 10*time.Second)
+	defer cancel()
+	userDB := mongodb.Database()
+
+	constraint := models.SoDConstraint{}
+	sodCollection := userDB.Collection("sod-constraints")
+
+	filter, tenantErr := tenant(org, bson.M{"id": id})
+	if tenantErr != nil {
+		return nil, tenantErr
+	}
+	err := sodCollection.FindOne(ctx, filter).Decode(&constraint)
+	if err != nil {
+		return nil, databaseError(ctx, err)
+	}
+
+	return &constraint, nil
+}
+
+// Update separation of duties constraint
+func (d *sodDao) Update(ctx context.Context, constraint models.SoDConstraint) *resterr.RestErr {
+	ctx, cancel := operation(ctx, "sod-constraints", "update", 10*time.Second)
+	defer cancel()
+
+	userDB := mongodb.Database()
+	sodCollection := userDB.Collection("sod-constraints")
+
+	filter, tenantErr := tenant(constraint.Organization, bson.M{"id": constraint.ID})
+	if tenantErr != nil {
+		return tenantErr
+	}
+	update := bson.D{
+		{Key: "$set", Value: bson.D{
+			{Key: "name", Value: constraint.Name},
+			{Key: "description", Value: constraint.Description},
+			{Key: "type", Value: constraint.Type},
+			{Key: "roles", Value: constraint.Roles},
+			{Key: "permissions", Value: constraint.Permissions},
+			{Key: "max_allowed", Value: constraint.MaxAllowed},
+			{Key: "status", Value: constraint.Status},
+			{Key: "is_active", Value: constraint.IsActive},
+			{Key: "updated_at", Value: constraint.UpdatedAt},
+		}},
+	}
+
+	result, err := sodCollection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return databaseError(ctx, err)
+	}
+	return missing(ctx, result.MatchedCount)
+}
+
+// Delete separation of duties constraint
+func (d *sodDao) Delete(ctx context.Context, id string, org string) *resterr.RestErr {
+	ctx, cancel := operation(ctx, "sod-constraints", "delete", 10*time.Second)
+	defer cancel()
+
+	userDB := mongodb.Database()
+	sodCollection := userDB.Collection("sod-constraints")
+
+	filter, tenantErr := tenant(org, bson.M{"id": id})
+	if tenantErr != nil {
+		return tenantErr
+	}
+
+	result, err := sodCollection.DeleteOne(ctx, filter)
+	if err != nil {
+		return databaseError(ctx, err)
+	}
+	return missing(ctx, result.DeletedCount)
+}
@@ -2,6 +2,8 @@ package dao
 
 import (
 	"context"
+	"regexp"
+	"sync"
 	"time"
 
 	"gorabc/pkg/models"
@@ -9,19 +11,26 @@ import (
 	"gorabc/pkg/utils/resterr"
 
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 // UserDaoInterface type
 type UserDaoInterface interface {
-	Create(models.User) (*models.User, *resterr.RestErr)
-	FindAll(string) ([]models.User, *resterr.RestErr)
-	GetByID(string) (*models.User, *resterr.RestErr)
-	GetByEmail(string) (*models.User, *resterr.RestErr)
-	Update(models.User) *resterr.RestErr
-	Delete(string) *resterr.RestErr
+	Create(context.Context, models.User) (*models.User, *resterr.RestErr)
+	FindAll(context.Context, string) ([]models.User, *resterr.RestErr)
+	GetByID(context.Context, string, string) (*models.User, *resterr.RestErr)
+	GetByEmail(context.Context, string) (*models.User, *resterr.RestErr)
+	Update(context.Context, models.User) *resterr.RestErr
+	Delete(context.Context, string, string) *resterr.RestErr
+	FindLapsedGrants(context.Context, string) ([]models.User, *resterr.RestErr)
+	Search(context.Context, string, int) ([]models.User, *resterr.RestErr)
+	HasSuperuser(context.Context) (bool, *resterr.RestErr)
 }
 
-type userDao struct{}
+type userDao struct {
+	indexOnce sync.Once
+}
 
 // UserDao variable
 var (
@@ -29,12 +38,13 @@ var (
 )
 
 // Create User
-func (d *userDao) Create(user models.User) (*models.User, *resterr.RestErr) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+func (d *userDao) Create(ctx context.Context, user models.User) (*models.User, *resterr.RestErr) {
+	ctx, cancel := operation(ctx, "user", "create", 10*time.Second)
 	defer cancel()
-	userDB := mongodb.Client.Database("erp-user-service")
+	userDB := mongodb.Database()
 
 	userCollection := userDB.Collection("user")
+	d.ensureIndexes(ctx, userCollection)
 
 	_, err := userCollection.InsertOne(ctx, bson.M{
 		"id":           user.ID,
@@ -45,6 +55,7 @@ func (d *userDao) Create(user models.User) (*models.User, *resterr.RestErr) {
 		"organization": user.Organization,
 		"departments":  user.Departments,
 		"roles":        user.Roles,
+		"attributes":   user.Attributes,
 		"status":       user.Status,
 		"is_active":    user.IsActive,
 		"is_superuser": user.IsSuperuser,
@@ -53,11 +64,11 @@ func (d *userDao) Create(user models.User) (*models.User, *resterr.RestErr) {
 		"updated_at":   user.UpdatedAt,
 	})
 	if err != nil {
-		return nil, resterr.NewInternalServerError(err.Error())
+		return nil, databaseError(ctx, err)
 	}
 
 	// add permissions
-	if upErr := d.addPremissions(user); upErr != nil {
+	if upErr := d.addPremissions(ctx, user); upErr != nil {
 		return nil, upErr
 	}
 
@@ -65,58 +76,61 @@ func (d *userDao) Create(user models.User) (*models.User, *resterr.RestErr) {
 }
 
 // FindAll Users
-func (d *userDao) FindAll(org string) ([]models.User, *resterr.RestErr) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+func (d *userDao) FindAll(ctx context.Context, org string) ([]models.User, *resterr.RestErr) {
+	ctx, cancel := operation(ctx, "user", "find_all", 10*time.Second)
 	defer cancel()
-	userDB := mongodb.Client.Database("erp-user-service")
+	userDB := mongodb.Database()
 
 	users := []models.User{}
 	userCollection := userDB.Collection("user")
 
-	filter := bson.M{"status": models.StatusActive, "organization": org}
+	filter := member(org, bson.M{"status": models.StatusActive})
 	cursor, err := userCollection.Find(ctx, filter)
 	if err != nil {
-		return nil, resterr.NewInternalServerError(err.Error())
+		return nil, databaseError(ctx, err)
 	}
 
 	if err = cursor.All(ctx, &users); err != nil {
-		return nil, resterr.NewInternalServerError(err.Error())
+		return nil, databaseError(ctx, err)
 	}
 
 	return users, nil
 }
 
-// GetByID User
-func (d *userDao) GetByID(id string) (*models.User, *resterr.RestErr) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+// GetByID User of the organization, the empty organization holds the
+// platform superusers
+func (d *userDao) GetByID(ctx context.Context, id string, org string) (*models.User, *resterr.RestErr) {
+	ctx, cancel := operation(ctx, "user", "get_by_id", 10*time.Second)
 	defer cancel()
-	userDB := mongodb.Client.Database("erp-user-service")
+	userDB := mongodb.Database()
 
 	user := models.User{}
 	userCollection := userDB.Collection("user")
 
-	filter := bson.M{"id": id}
+	filter := member(org, bson.M{"id": id})
 	err := userCollection.FindOne(ctx, filter).Decode(&user)
 	if err != nil {
-		return nil, resterr.NewInternalServerError(err.Error())
+		return nil, databaseError(ctx, err)
 	}
 
 	// get user permissions
-	up, upERr := d.getPermissonsByUserID(id)
+	up, upERr := d.getPermissonsByUserID(ctx, id, org)
 	if upERr != nil {
 		return nil, upERr
 	}
 
 	user.Permissions = up.Permissions
+	user.Denies = up.Denies
 
 	return &user, nil
 }
 
-// GetByEmail User
-func (d *userDao) GetByEmail(email string) (*models.User, *resterr.RestErr) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+// GetByEmail User of any organization, emails are unique across the
+// platform so that logins need no organization
+func (d *userDao) GetByEmail(ctx context.Context, email string) (*models.User, *resterr.RestErr) {
+	ctx, cancel := operation(ctx, "user", "get_by_email", 10*time.Second)
 	defer cancel()
-	userDB := mongodb.Client.Database("erp-user-service")
+	userDB := mongodb.Database()
 
 	user := models.User{}
 	userCollection := userDB.Collection("user")
@@ -124,21 +138,42 @@ func (d *userDao) GetByEmail(email string) (*models.User, *resterr.RestErr) {
 	filter := bson.M{"email": email}
 	err := userCollection.FindOne(ctx, filter).Decode(&user)
 	if err != nil {
-		return nil, resterr.NewInternalServerError(err.Error())
+		return nil, databaseError(ctx, err)
 	}
 
 	return &user, nil
 }
 
 // Update User
-func (d *userDao) Update(user models.User) *resterr.RestErr {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+func (d *userDao) Update(ctx context.Context, user models.User) *resterr.RestErr {
+	ctx, cancel := operation(ctx, "user", "update", 10*time.Second)
 	defer cancel()
+	d.ensureIndexes(ctx, mongodb.Database().Collection("user"))
 
-	userDB := mongodb.Client.Database("erp-user-service")
-	userCollection := userDB.Collection("user")
+	if err := writeUser(ctx, user); err != nil {
+		return databaseError(ctx, err)
+	}
+	return nil
+}
+
+// ensureIndexes keeps an email registered to a single user
+func (d *userDao) ensureIndexes(ctx context.Context, userCollection *mongo.Collection) {
+	d.indexOnce.Do(func() {
+		index := mongo.IndexModel{
+			Keys:    bson.D{{Key: "email", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		}
+		userCollection.Indexes().CreateOne(ctx, index)
+	})
+}
+
+// writeUser saves the user and its permissions in the organization of the
+// user, a user missing from the organization is reported as no documents.
+// The writes join the session of the context, if any.
+func writeUser(ctx context.Context, user models.User) error {
+	userDB := mongodb.Database()
 
-	filter := bson.M{"id": user.ID}
+	filter := member(user.Organization, bson.M{"id": user.ID})
 	update := bson.D{
 		{Key: "$set", Value: bson.D{
 			{Key: "first_name", Value: user.Firstname},
@@ -147,6 +182,7 @@ func (d *userDao) Update(user models.User) *resterr.RestErr {
 			{Key: "password", Value: user.Password},
 			{Key: "departments", Value: user.Departments},
 			{Key: "roles", Value: user.Roles},
+			{Key: "attributes", Value: user.Attributes},
 			{Key: "status", Value: user.Status},
 			{Key: "is_active", Value: user.IsActive},
 			{Key: "is_superuser", Value: user.IsSuperuser},
@@ -155,84 +191,185 @@ func (d *userDao) Update(user models.User) *resterr.RestErr {
 		}},
 	}
 
-	_, err := userCollection.UpdateOne(ctx, filter, update)
+	result, err := userDB.Collection("user").UpdateOne(ctx, filter, update)
 	if err != nil {
-		return resterr.NewInternalServerError(err.Error())
+		return err
 	}
-	return nil
+	if result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+
+	// update user permissions, legacy documents get their organization
+	update = bson.D{
+		{Key: "$set", Value: bson.D{
+			{Key: "organization", Value: user.Organization},
+			{Key: "permissions", Value: user.Permissions},
+			{Key: "denies", Value: user.Denies},
+		}},
+	}
+	_, err = userDB.Collection("user-permissions").UpdateOne(ctx, permissionsOf(user.Organization, user.ID), update, options.Update().SetUpsert(true))
+	return err
 }
 
-// Delete User
-func (d *userDao) Delete(id string) *resterr.RestErr {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+// Delete User of the organization
+func (d *userDao) Delete(ctx context.Context, id string, org string) *resterr.RestErr {
+	ctx, cancel := operation(ctx, "user", "delete", 10*time.Second)
 	defer cancel()
 
-	userDB := mongodb.Client.Database("erp-user-service")
+	userDB := mongodb.Database()
 	userCollection := userDB.Collection("user")
 
-	filter := bson.M{"id": id}
+	filter := member(org, bson.M{"id": id})
 
-	_, err := userCollection.DeleteOne(ctx, filter)
+	result, err := userCollection.DeleteOne(ctx, filter)
 	if err != nil {
-		return resterr.NewInternalServerError(err.Error())
+		return databaseError(ctx, err)
 	}
-	return nil
+	return missing(ctx, result.DeletedCount)
+}
+
+// FindLapsedGrants returns the users holding a role, permission or deny whose
+// validity ended before now, only their id and organization are loaded
+func (d *userDao) FindLapsedGrants(ctx context.Context, now string) ([]models.User, *resterr.RestErr) {
+	ctx, cancel := operation(ctx, "user", "find_lapsed_grants", 10*time.Second)
+	defer cancel()
+	userDB := mongodb.Database()
+
+	lapsed := bson.M{"$lte": now, "$ne": ""}
+	ids := map[string]bool{}
+
+	// Role assignments
+	users, err := userDB.Collection("user").Distinct(ctx, "id", bson.M{"roles.valid_until": lapsed})
+	if err != nil {
+		return nil, databaseError(ctx, err)
+	}
+	for i := 0; i < len(users); i++ {
+		if id, ok := users[i].(string); ok {
+			ids[id] = true
+		}
+	}
+
+	// Direct and role permissions and denies
+	filter := bson.M{"$or": bson.A{
+		bson.M{"permissions.valid_until": lapsed},
+		bson.M{"denies.valid_until": lapsed},
+	}}
+	users, err = userDB.Collection("user-permissions").Distinct(ctx, "user_id", filter)
+	if err != nil {
+		return nil, databaseError(ctx, err)
+	}
+	for i := 0; i < len(users); i++ {
+		if id, ok := users[i].(string); ok {
+			ids[id] = true
+		}
+	}
+
+	result := []models.User{}
+	if len(ids) == 0 {
+		return result, nil
+	}
+	in := bson.A{}
+	for id := range ids {
+		in = append(in, id)
+	}
+	opts := options.Find().SetProjection(bson.M{"id": 1, "organization": 1})
+	cursor, err := userDB.Collection("user").Find(ctx, bson.M{"id": bson.M{"$in": in}}, opts)
+	if err != nil {
+		return nil, databaseError(ctx, err)
+	}
+	if err = cursor.All(ctx, &result); err != nil {
+		return nil, databaseError(ctx, err)
+	}
+	return result, nil
+}
+
+// Search users of every organization whose name or email matches the query,
+// only for the platform administration of superusers
+func (d *userDao) Search(ctx context.Context, query string, limit int) ([]models.User, *resterr.RestErr) {
+	ctx, cancel := operation(ctx, "user", "search", 10*time.Second)
+	defer cancel()
+	userDB := mongodb.Database()
+
+	users := []models.User{}
+	pattern := bson.M{"$regex": regexp.QuoteMeta(query), "$options": "i"}
+	filter := bson.M{"$or": bson.A{
+		bson.M{"email": pattern},
+		bson.M{"first_name": pattern},
+		bson.M{"last_name": pattern},
+		bson.M{"id": pattern},
+	}}
+
+	opts := options.Find().SetSort(bson.M{"email": 1}).SetLimit(int64(limit))
+	cursor, err := userDB.Collection("user").Find(ctx, filter, opts)
+	if err != nil {
+		return nil, databaseError(ctx, err)
+	}
+
+	if err = cursor.All(ctx, &users); err != nil {
+		return nil, databaseError(ctx, err)
+	}
+
+	return users, nil
+}
+
+// HasSuperuser reports whether a platform superuser exists
+func (d *userDao) HasSuperuser(ctx context.Context) (bool, *resterr.RestErr) {
+	ctx, cancel := operation(ctx, "user", "has_superuser", 10*time.Second)
+	defer cancel()
+	userDB := mongodb.Database()
+
+	count, err := userDB.Collection("user").CountDocuments(ctx, bson.M{"is_superuser": true})
+	if err != nil {
+		return false, databaseError(ctx, err)
+	}
+	return count > 0, nil
 }
 
 // addPremissions to user
-func (d *userDao) addPremissions(user models.User) *resterr.RestErr {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+func (d *userDao) addPremissions(ctx context.Context, user models.User) *resterr.RestErr {
+	ctx, cancel := operation(ctx, "user-permissions", "add_permissions", 10*time.Second)
 	defer cancel()
-	userDB := mongodb.Client.Database("erp-user-service")
+	userDB := mongodb.Database()
 
 	userCollection := userDB.Collection("user-permissions")
 
 	_, err := userCollection.InsertOne(ctx, bson.M{
-		"user_id":     user.ID,
-		"permissions": user.Permissions,
+		"user_id":      user.ID,
+		"organization": user.Organization,
+		"permissions":  user.Permissions,
+		"denies":       user.Denies,
 	})
 	if err != nil {
-		return resterr.NewInternalServerError(err.Error())
+		return databaseError(ctx, err)
 	}
 	return nil
 }
 
 // getPermissonsByUserID User
-func (d *userDao) getPermissonsByUserID(userID string) (*models.UserPermissions, *resterr.RestErr) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+func (d *userDao) getPermissonsByUserID(ctx context.Context, userID string, org string) (*models.UserPermissions, *resterr.RestErr) {
+	ctx, cancel := operation(ctx, "user-permissions", "get_permissions_by_user_id", 10*time.Second)
 	defer cancel()
-	userDB := mongodb.Client.Database("erp-user-service")
+	userDB := mongodb.Database()
 
 	userPermList := models.UserPermissions{}
 	userCollection := userDB.Collection("user-permissions")
 
-	filter := bson.M{"user_id": userID}
-	err := userCollection.FindOne(ctx, filter).Decode(&userPermList)
+	err := userCollection.FindOne(ctx, permissionsOf(org, userID)).Decode(&userPermList)
 	if err != nil {
-		return nil, resterr.NewInternalServerError(err.Error())
+		return nil, databaseError(ctx, err)
 	}
 
 	return &userPermList, nil
 }
 
-// updatePermissions of user
-func (d *userDao) updatePermissions(user models.User) *resterr.RestErr {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
-
-	userDB := mongodb.Client.Database("erp-user-service")
-	userCollection := userDB.Collection("user")
-
-	filter := bson.M{"user_id": user.ID}
-	update := bson.D{
-		{Key: "$set", Value: bson.D{
-			{Key: "permissions", Value: user.Permissions},
-		}},
+// permissionsOf filters the permissions of the user in the organization,
+// documents written before they carried the organization are matched by user
+func permissionsOf(org string, userID string) bson.M {
+	return bson.M{
+		"user_id": userID,
+		"$or": bson.A{
+			bson.M{"organization": org},
+			bson.M{"organization": bson.M{"$exists": false}},
+		},
 	}
-
-	_, err := userCollection.UpdateOne(ctx, filter, update)
-	if err != nil {
-		return resterr.NewInternalServerError(err.Error())
-	}
-	return nil
 }
@@ -0,0 +1,132 @@
+package dao
+
+import (
+	"context"
+	"time"
+
+	"gorabc/pkg/models"
+	"gorabc/pkg/settings/db/mongodb"
+	"gorabc/pkg/utils/resterr"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// UserInviteDaoInterface type
+type UserInviteDaoInterface interface {
+	Create(context.Context, models.UserInvite) (*models.UserInvite, *resterr.RestErr)
+	FindPending(context.Context, string) (models.UserInvites, *resterr.RestErr)
+	GetByID(context.Context, string, string) (*models.UserInvite, *resterr.RestErr)
+	GetByToken(context.Context, string, string) (*models.UserInvite, *resterr.RestErr)
+	Update(context.Context, models.UserInvite, string) *resterr.RestErr
+}
+
+type userInviteDao struct{}
+
+// UserInviteDao variable
+var (
+	UserInviteDao UserInviteDaoInterface = &userInviteDao{}
+)
+
+// Create user invite
+func (d *userInviteDao) Create(ctx context.Context, invite models.UserInvite) (*models.UserInvite, *resterr.RestErr) {
+	ctx, cancel := operation(ctx, "user-invites", "create", 10*time.Second)
+	defer cancel()
+	userDB := mongodb.Database()
+
+	if _, err := userDB.Collection("user-invites").InsertOne(ctx, invite); err != nil {
+		return nil, databaseError(ctx, err)
+	}
+	return &invite, nil
+}
+
+// FindPending user invites of the organization, newest first
+func (d *userInviteDao) FindPending(ctx context.Context, org string) (models.UserInvites, *resterr.RestErr) {
+	ctx, cancel := operation(ctx, "user-invites", "find_pending", 10*time.Second)
+	defer cancel()
+	userDB := mongodb.Database()
+
+	invites := models.UserInvites{}
+	filter, tenantErr := tenant(org, bson.M{"status": models.InviteStatusPending})
+	if tenantErr != nil {
+		return nil, tenantErr
+	}
+	opts := options.Find().SetSort(bson.M{"created_at": -1})
+	cursor, err := userDB.Collection("user-invites").Find(ctx, filter, opts)
+	if err != nil {
+		return nil, databaseError(ctx, err)
+	}
+	if err = cursor.All(ctx, &invites); err != nil {
+		return nil, databaseError(ctx, err)
+	}
+	return invites, nil
+}
+
+// GetByID user invite of the organization
+func (d *userInviteDao) GetByID(ctx context.Context, id string, org string) (*models.UserInvite, *resterr.RestErr) {
+	ctx, cancel := operation(ctx, "user-invites", "get_by_id", 10*time.Second)
+	defer cancel()
+	userDB := mongodb.Database()
+
+	filter, tenantErr := tenant(org, bson.M{"id": id})
+	if tenantErr != nil {
+		return nil, tenantErr
+	}
+
+	invite := models.UserInvite{}
+	if err := userDB.Collection("user-invites").FindOne(ctx, filter).Decode(&invite); err != nil {
+		return nil, resterr.NewNotFoundError("Invite not found")
+	}
+	return &invite, nil
+}
+
+// GetByToken user invite of any organization, the invitee has no
+// organization yet so the secret nonce of the signed link stands in for it
+func (d *userInviteDao) GetByToken(ctx context.Context, id string, nonce string) (*models.UserInvite, *resterr.RestErr) {
+	ctx, cancel := operation(ctx, "user-invites", "get_by_token", 10*time.Second)
+	defer cancel()
+	userDB := mongodb.Database()
+
+	if nonce == "" {
+		return nil, resterr.NewNotFoundError("Invite not found")
+	}
+
+	invite := models.UserInvite{}
+	filter := bson.M{"id": id, "nonce": nonce}
+	if err := userDB.Collection("user-invites").FindOne(ctx, filter).Decode(&invite); err != nil {
+		return nil, resterr.NewNotFoundError("Invite not found")
+	}
+	return &invite, nil
+}
+
+// Update user invite, only when it still has the expected nonce so that a
+// resent, revoked or accepted invite cannot be used again
+func (d *userInviteDao) Update(ctx context.Context, invite models.UserInvite, expectedNonce string) *resterr.RestErr {
+	ctx, cancel := operation(ctx, "user-invites", "update", 10*time.Second)
+	defer cancel()
+	userDB := mongodb.Database()
+
+	filter, tenantErr := tenant(invite.Organization, bson.M{"id": invite.ID, "nonce": expectedNonce, "status": models.InviteStatusPending})
+	if tenantErr != nil {
+		return tenantErr
+	}
+	update := bson.D{
+		{Key: "$set", Value: bson.D{
+			{Key: "nonce", Value: invite.Nonce},
+			{Key: "status", Value: invite.Status},
+			{Key: "sent_count", Value: invite.SentCount},
+			{Key: "sent_at", Value: invite.SentAt},
+			{Key: "expires_at", Value: invite.ExpiresAt},
+			{Key: "accepted_at", Value: invite.AcceptedAt},
+		}},
+	}
+
+	result, err := userDB.Collection("user-invites").UpdateOne(ctx, filter, update)
+	if err != nil {
+		return databaseError(ctx, err)
+	}
+	if result.MatchedCount == 0 {
+		return resterr.NewBadRequestError("Invite is no longer pending")
+	}
+	return nil
+}
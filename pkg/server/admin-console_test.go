@@ -0,0 +1,102 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"gorabc/pkg/logic/services"
+	"gorabc/pkg/middlewares/jwt"
+	"gorabc/pkg/models"
+)
+
+// TestAdminConsole checks that only superusers reach the admin console, that
+// a suspended organization cannot login and that impersonation tokens are
+// limited to their mode. The suite needs a mongodb server, MONGO_URL, and is
+// skipped without one.
+func TestAdminConsole(t *testing.T) {
+	useTestDatabase(t)
+	mapUrlsOnce.Do(mapUrls)
+
+	a := newTenant(t, "alpha")
+	b := newTenant(t, "bravo")
+	superuser := models.AuthUser{ID: "superuser", IsSuperuser: true}
+	token := "JWT " + jwt.GenerateToken(&superuser).ValueToken
+
+	tests := []struct {
+		method string
+		path   string
+		body   string
+	}{
+		{"GET", "/api/admin/tenants", ""},
+		{"GET", "/api/admin/tenants/" + b.org, ""},
+		{"POST", "/api/admin/tenants/" + b.org + "/suspend", `{"reason": "Unpaid"}`},
+		{"POST", "/api/admin/tenants/" + b.org + "/reactivate", `{"reason": "Paid"}`},
+		{"PUT", "/api/admin/tenants/" + b.org + "/users/" + b.member + "/org-admin", `{"is_org_admin": true}`},
+		{"POST", "/api/admin/tenants/" + b.org + "/users/" + b.member + "/impersonate", `{"reason": "Support ticket"}`},
+		{"GET", "/api/admin/permissions", ""},
+		{"POST", "/api/admin/permissions", `{"name": "inventory:product:archive"}`},
+		{"DELETE", "/api/admin/permissions/org:user:read", ""},
+		{"GET", "/api/admin/search?q=bravo", ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.method+" "+test.path, func(t *testing.T) {
+			if status, body := call(test.method, test.path, test.body, a.token); status != http.StatusForbidden {
+				t.Errorf("expected status 403, got %d: %s", status, body)
+			}
+		})
+	}
+
+	if status, body := call("GET", "/api/admin/search?q=bravo", "", token); status != http.StatusOK || !strings.Contains(body, b.org) || strings.Contains(body, a.org) {
+		t.Errorf("expected the search to find only bravo, got %d: %s", status, body)
+	}
+
+	login := models.LoginRequest{Email: "member@bravo.test", Password: "correct horse"}
+	if status, body := call("POST", "/api/admin/tenants/"+b.org+"/suspend", `{"reason": "Unpaid"}`, token); status != http.StatusOK {
+		t.Fatalf("suspend: got %d: %s", status, body)
+	}
+	if _, err := services.AuthService.Login(context.Background(), login); err == nil || err.Status != http.StatusForbidden {
+		t.Errorf("expected the login of a suspended organization to be forbidden, got %v", err)
+	}
+	if status, body := call("POST", "/api/admin/tenants/"+b.org+"/suspend", `{"reason": "Unpaid"}`, token); status != http.StatusConflict {
+		t.Errorf("expected status 409, got %d: %s", status, body)
+	}
+
+	if status, body := call("POST", "/api/admin/tenants/"+b.org+"/reactivate", `{"reason": "Paid"}`, token); status != http.StatusOK {
+		t.Fatalf("reactivate: got %d: %s", status, body)
+	}
+	if _, err := services.AuthService.Login(context.Background(), login); err != nil {
+		t.Errorf("expected the login of a reactivated organization, got %s", err.Message)
+	}
+
+	impersonate := "/api/admin/tenants/" + b.org + "/users/" + b.admin + "/impersonate"
+	status, body := call("POST", impersonate, `{"reason": "Support ticket", "read_only": true}`, token)
+	var response struct {
+		Object models.Impersonation `json:"object"`
+	}
+	if err := json.Unmarshal([]byte(body), &response); status != http.StatusOK || err != nil {
+		t.Fatalf("impersonate: got %d: %s", status, body)
+	}
+	if act := response.Object.User.Act; response.Object.User.ID != b.admin || act == nil || act.ID != superuser.ID {
+		t.Errorf("expected a token of %s acting as %s, got %+v", superuser.ID, b.admin, response.Object.User)
+	}
+	readOnly := "JWT " + response.Object.Token.ValueToken
+	if status, body := call("GET", "/api/users/"+b.member, "", readOnly); status != http.StatusOK {
+		t.Errorf("expected a read-only token to read, got %d: %s", status, body)
+	}
+	if status, body := call("POST", "/api/authorize", `{"action": "org:user:read"}`, readOnly); status != http.StatusOK {
+		t.Errorf("expected a read-only token to authorize, got %d: %s", status, body)
+	}
+	if status, body := call("POST", "/api/access-requests", `{"role_id": "ROLE", "justification": "Audit", "hours": 1}`, readOnly); status != http.StatusForbidden {
+		t.Errorf("expected a read-only token not to request access, got %d: %s", status, body)
+	}
+	if status, body := call("PUT", "/api/users/"+b.member, `{"first_name": "Mallory"}`, readOnly); status != http.StatusForbidden {
+		t.Errorf("expected a read-only token not to write, got %d: %s", status, body)
+	}
+	if status, body := call("POST", impersonate, `{"reason": "Again"}`, readOnly); status != http.StatusForbidden {
+		t.Errorf("expected an impersonation token not to impersonate, got %d: %s", status, body)
+	}
+}
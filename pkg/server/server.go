@@ -1,24 +1,43 @@
 package server
 
 import (
+	"context"
 	"log"
 	"os"
+	"time"
 
+	"gorabc/pkg/logic/helpers"
+	"gorabc/pkg/logic/services"
+	"gorabc/pkg/middlewares/clientip"
+	"gorabc/pkg/middlewares/logging"
+	"gorabc/pkg/middlewares/monitoring"
+	"gorabc/pkg/middlewares/requestid"
 	"gorabc/pkg/settings/db/mongodb"
 	"gorabc/pkg/settings/seed"
+	"gorabc/pkg/utils/mailer"
+	"gorabc/pkg/utils/tracing"
 
 	"github.com/gin-gonic/gin"
 )
 
 var (
-	router = gin.Default()
+	router = gin.New()
 )
 
 // StartApplication is ...
 func StartApplication() {
+	ctx := context.Background()
+
 	// Initiate mongodb
 	mongodb.InitMongoClient()
 
+	// Export the spans of the requests
+	shutdownTracing, tracingErr := tracing.Init(ctx)
+	if tracingErr != nil {
+		log.Fatal(tracingErr.Message)
+	}
+	defer shutdownTracing(ctx)
+
 	seedData := os.Getenv("SEED")
 
 	if seedData == "true" {
@@ -26,6 +45,61 @@ func StartApplication() {
 		seed.AddPermissions()
 	}
 
+	migrate := os.Getenv("MIGRATE")
+
+	if migrate == "true" {
+		// Rename legacy permission names
+		if err := seed.MigratePermissionNames(); err != nil {
+			log.Printf("Permission migration failed: %s", err.Message)
+		}
+		// Mirror memberships into relation tuples
+		if err := seed.MirrorRelationTuples(ctx); err != nil {
+			log.Printf("Relation tuple migration failed: %s", err.Message)
+		}
+	}
+
+	// Label the permission checks with the names of the catalogue only
+	if err := helpers.LoadPermissionMetrics(ctx); err != nil {
+		log.Printf("Permission metrics not loaded: %s", err.Message)
+	}
+
+	// Lock down the public registration endpoints
+	registration := helpers.RegistrationSettings{
+		Mode:           os.Getenv("REGISTRATION_MODE"),
+		BootstrapToken: os.Getenv("BOOTSTRAP_TOKEN"),
+	}
+	if err := helpers.ConfigureRegistration(registration); err != nil {
+		log.Fatal(err.Message)
+	}
+
+	// Believe the forwarded client ips of the trusted proxies only
+	if err := clientip.Configure(os.Getenv("TRUSTED_PROXIES")); err != nil {
+		log.Fatal(err.Message)
+	}
+
+	// Deliver user invites by mail when a relay is configured, logged otherwise
+	helpers.ConfigureInviteURL(os.Getenv("INVITE_URL"))
+	if smtpAddr := os.Getenv("SMTP_ADDR"); smtpAddr != "" {
+		mailer.SetMailer(mailer.SMTPMailer{
+			Addr:     smtpAddr,
+			From:     os.Getenv("SMTP_FROM"),
+			Username: os.Getenv("SMTP_USERNAME"),
+			Password: os.Getenv("SMTP_PASSWORD"),
+		})
+	}
+
+	// Remove lapsed time limited grants in the background
+	sweepInterval, err := time.ParseDuration(os.Getenv("EXPIRY_SWEEP_INTERVAL"))
+	if err != nil || sweepInterval <= 0 {
+		sweepInterval = time.Minute
+	}
+	sweepCtx, stopSweep := context.WithCancel(ctx)
+	defer stopSweep()
+	go services.ExpiryService.Start(sweepCtx, sweepInterval)
+
+	// Tag requests with an id for the audit log and the access log
+	router.Use(requestid.Middleware(), monitoring.Tracing(), logging.Middleware(), monitoring.Middleware(), gin.Recovery())
+
 	// Map all urls
 	mapUrls()
 
@@ -0,0 +1,257 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"gorabc/pkg/logic/helpers"
+	"gorabc/pkg/logic/services"
+	"gorabc/pkg/middlewares/jwt"
+	"gorabc/pkg/models"
+	"gorabc/pkg/settings/db/mongodb"
+	"gorabc/pkg/settings/seed"
+)
+
+// tenantFixture is an organization with one object of every tenant owned kind
+type tenantFixture struct {
+	org        string
+	token      string
+	admin      string
+	member     string
+	department string
+	role       string
+	policy     string
+	sod        string
+	grant      string
+	request    string
+	invite     string
+}
+
+var mapUrlsOnce sync.Once
+
+// TestTenantIsolation calls every endpoint with the token of one organization
+// and the ids of another, none of the calls may succeed. The suite needs a
+// mongodb server, MONGO_URL, and is skipped without one.
+func TestTenantIsolation(t *testing.T) {
+	useTestDatabase(t)
+	mapUrlsOnce.Do(mapUrls)
+
+	a := newTenant(t, "alpha")
+	b := newTenant(t, "bravo")
+
+	policy := `{"name": "Taken", "effect": "deny", "actions": ["org:user:delete"], "condition_match": "all"}`
+	sod := fmt.Sprintf(`{"name": "Taken", "type": "static", "roles": [%q, %q], "max_allowed": 1}`, b.role, a.role)
+
+	tests := []struct {
+		method string
+		path   string
+		body   string
+	}{
+		{"GET", "/api/users/" + b.member, ""},
+		{"PUT", "/api/users/" + b.member, `{"first_name": "Mallory"}`},
+		{"PUT", "/api/users/" + b.member + "/password", `{"password": "correct horse battery"}`},
+		{"PUT", "/api/users/" + b.member + "/org-admin", `{"is_org_admin": true}`},
+		{"GET", "/api/users/" + b.member + "/permissions", ""},
+		{"GET", "/api/users/" + b.member + "/grant-events", ""},
+		{"DELETE", "/api/users/" + b.member, ""},
+		{"DELETE", "/api/users/" + b.admin, ""},
+		{"POST", "/api/users", fmt.Sprintf(`{"first_name": "Mallory", "last_name": "M", "email": "mallory@alpha.test", "password": "correct horse", "departments": [{"department_id": %q}]}`, b.department)},
+
+		{"GET", "/api/org/" + b.org, ""},
+		{"PUT", "/api/org/" + b.org, `{"name": "Taken"}`},
+		{"DELETE", "/api/org/" + b.org, ""},
+
+		{"GET", "/api/department/" + b.department, ""},
+		{"PUT", "/api/department/" + b.department, `{"name": "Taken"}`},
+		{"DELETE", "/api/department/" + b.department, ""},
+
+		{"GET", "/api/role/" + b.role, ""},
+		{"PUT", "/api/role/" + b.role, `{"name": "Taken"}`},
+		{"DELETE", "/api/role/" + b.role, ""},
+		{"POST", "/api/role", fmt.Sprintf(`{"name": "Taken", "department": %q}`, b.department)},
+
+		{"GET", "/api/policy/" + b.policy, ""},
+		{"PUT", "/api/policy/" + b.policy, policy},
+		{"DELETE", "/api/policy/" + b.policy, ""},
+
+		{"GET", "/api/sod/" + b.sod, ""},
+		{"PUT", "/api/sod/" + b.sod, sod},
+		{"DELETE", "/api/sod/" + b.sod, ""},
+
+		{"POST", "/api/acl", fmt.Sprintf(`{"resource_type": "document", "resource_id": "D1", "subject_type": "user", "subject_id": %q, "permissions": [{"name": "org:user:read"}]}`, b.member)},
+		{"POST", "/api/acl/check", fmt.Sprintf(`{"user_id": %q, "permission": "org:user:read", "resource_type": "document", "resource_id": "D1"}`, b.member)},
+		{"DELETE", "/api/acl/" + b.grant, ""},
+
+		{"POST", "/api/access-requests", fmt.Sprintf(`{"role_id": %q, "hours": 8, "justification": "Quarter close"}`, b.role)},
+		{"GET", "/api/access-requests/" + b.request, ""},
+		{"POST", "/api/access-requests/" + b.request + "/approve", `{"reason": "ok"}`},
+		{"POST", "/api/access-requests/" + b.request + "/deny", `{"reason": "no"}`},
+		{"POST", "/api/access-requests/" + b.request + "/cancel", `{}`},
+
+		{"POST", "/api/user-invites/" + b.invite + "/resend", `{}`},
+		{"DELETE", "/api/user-invites/" + b.invite, ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.method+" "+test.path, func(t *testing.T) {
+			status, body := call(test.method, test.path, test.body, a.token)
+			if status != http.StatusForbidden && status != http.StatusNotFound {
+				t.Errorf("expected status 403 or 404, got %d: %s", status, body)
+			}
+		})
+	}
+
+	// Lists of one tenant never hold the objects of another
+	lists := []string{"/api/users", "/api/org", "/api/department", "/api/role", "/api/policy", "/api/sod", "/api/acl", "/api/access-requests", "/api/user-invites", "/api/audit"}
+	ids := []string{b.org, b.admin, b.member, b.department, b.role, b.policy, b.sod, b.grant, b.request, b.invite}
+	for _, path := range lists {
+		t.Run("GET "+path, func(t *testing.T) {
+			status, body := call("GET", path, "", a.token)
+			if status >= http.StatusMultipleChoices {
+				return
+			}
+			for _, id := range ids {
+				if strings.Contains(body, id) {
+					t.Errorf("list of another tenant holds %s: %s", id, body)
+				}
+			}
+		})
+	}
+
+	// The objects of the other tenant are untouched
+	for _, path := range []string{"/api/users/" + b.member, "/api/department/" + b.department, "/api/role/" + b.role, "/api/policy/" + b.policy, "/api/sod/" + b.sod} {
+		if status, body := call("GET", path, "", b.token); status != http.StatusOK || strings.Contains(body, "Taken") || strings.Contains(body, "Mallory") {
+			t.Errorf("expected %s to be untouched, got %d: %s", path, status, body)
+		}
+	}
+}
+
+// useTestDatabase points the application at a fresh database dropped after
+// the test, the test is skipped when no server answers
+func useTestDatabase(t *testing.T) {
+	t.Helper()
+	os.Setenv("MONGO_DATABASE", fmt.Sprintf("gorabc-test-%d", time.Now().UnixNano()))
+	mongodb.InitMongoClient()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	if err := mongodb.Client.Ping(ctx, nil); err != nil {
+		os.Unsetenv("MONGO_DATABASE")
+		t.Skipf("mongodb unavailable: %v", err)
+	}
+
+	t.Cleanup(func() {
+		mongodb.Database().Drop(context.Background())
+		os.Unsetenv("MONGO_DATABASE")
+	})
+	seed.AddPermissions()
+}
+
+// newTenant registers an organization and fills it through its admin
+func newTenant(t *testing.T, name string) tenantFixture {
+	t.Helper()
+	ctx := context.Background()
+	email := "admin@" + name + ".test"
+	password := "correct horse"
+	org, err := services.AuthService.CreateOrg(ctx, models.RegistrationRequest{
+		OrgName:   name,
+		Firstname: "Ada",
+		Lastname:  "Admin",
+		Email:     email,
+		Password:  password,
+	})
+	if err != nil {
+		t.Fatalf("create organization %s: %s", name, err.Message)
+	}
+	user, err := services.AuthService.Login(ctx, models.LoginRequest{Email: email, Password: password})
+	if err != nil {
+		t.Fatalf("login %s: %s", name, err.Message)
+	}
+	au := helpers.GetAuthUser(*user)
+	tenant := tenantFixture{org: org.ID, admin: user.ID, token: "JWT " + jwt.GenerateToken(&au).ValueToken}
+
+	department, err := services.DepartmentService.Create(ctx, models.Department{Name: "Sales"}, &au)
+	if err != nil {
+		t.Fatalf("create department: %s", err.Message)
+	}
+	tenant.department = department.ID
+
+	permissions := []models.Permission{{Name: "org:user:read"}}
+	role, err := services.RoleService.Create(ctx, models.CreateRoleRequest{Name: "Clerk", Department: department.ID, Permissions: permissions}, &au)
+	if err != nil {
+		t.Fatalf("create role: %s", err.Message)
+	}
+	tenant.role = role.ID
+	auditor, err := services.RoleService.Create(ctx, models.CreateRoleRequest{Name: "Auditor", Department: department.ID, Permissions: permissions}, &au)
+	if err != nil {
+		t.Fatalf("create role: %s", err.Message)
+	}
+
+	member, err := services.UserService.Create(ctx, models.CreateUserRequest{
+		Firstname:   "Bob",
+		Lastname:    "Member",
+		Email:       "member@" + name + ".test",
+		Password:    password,
+		Departments: []models.UserDepartment{{DepartmentID: department.ID}},
+	}, &au)
+	if err != nil {
+		t.Fatalf("create user: %s", err.Message)
+	}
+	tenant.member = member.ID
+
+	policy, err := services.PolicyService.Create(ctx, models.Policy{Name: "Freeze", Effect: "deny", Actions: []string{"org:user:delete"}, ConditionMatch: "all"}, &au)
+	if err != nil {
+		t.Fatalf("create policy: %s", err.Message)
+	}
+	tenant.policy = policy.ID
+
+	constraint, err := services.SoDService.Create(ctx, models.SoDConstraint{Name: "Clerk or auditor", Roles: []string{role.ID, auditor.ID}}, &au)
+	if err != nil {
+		t.Fatalf("create separation of duties constraint: %s", err.Message)
+	}
+	tenant.sod = constraint.ID
+
+	grant, err := services.ACLService.Grant(ctx, models.ObjectGrant{
+		ResourceType: "document",
+		ResourceID:   "D1",
+		SubjectType:  models.SubjectTypeUser,
+		SubjectID:    member.ID,
+		Permissions:  permissions,
+	}, &au)
+	if err != nil {
+		t.Fatalf("create object grant: %s", err.Message)
+	}
+	tenant.grant = grant.ID
+
+	request, err := services.AccessRequestService.Create(ctx, models.AccessRequest{RoleID: role.ID, Hours: 8, Justification: "Quarter close"}, &au)
+	if err != nil {
+		t.Fatalf("create access request: %s", err.Message)
+	}
+	tenant.request = request.ID
+
+	invite, err := services.UserService.Invite(ctx, models.InviteUserRequest{Email: "invitee@" + name + ".test", Firstname: "Eve", Lastname: "Invitee"}, &au)
+	if err != nil {
+		t.Fatalf("create invite: %s", err.Message)
+	}
+	tenant.invite = invite.ID
+
+	return tenant
+}
+
+// call the router with the token and return the status and the body
+func call(method string, path string, body string, token string) (int, string) {
+	request := httptest.NewRequest(method, path, strings.NewReader(body))
+	request.Header.Set("Authorization", token)
+	request.Header.Set("Content-Type", "application/json")
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, request)
+	return recorder.Code, recorder.Body.String()
+}
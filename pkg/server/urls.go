@@ -11,4 +11,16 @@ func mapUrls() {
 	routes.Organization(router)
 	routes.Department(router)
 	routes.Role(router)
+	routes.Policy(router)
+	routes.ACL(router)
+	routes.Relation(router)
+	routes.AccessRequest(router)
+	routes.SoD(router)
+	routes.Template(router)
+	routes.Import(router)
+	routes.Manifest(router)
+	routes.UserInvites(router)
+	routes.Audit(router)
+	routes.Admin(router)
+	routes.Metrics(router)
 }
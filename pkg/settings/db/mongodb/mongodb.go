@@ -3,6 +3,7 @@ package mongodb
 import (
 	"context"
 	"log"
+	"os"
 	"time"
 
 	"go.mongodb.org/mongo-driver/mongo"
@@ -11,7 +12,8 @@ import (
 
 // Mongo constants
 const (
-	mongoURL = "mongodb://localhost:27017"
+	mongoURL      = "mongodb://localhost:27017"
+	mongoDatabase = "erp-user-service"
 )
 
 // Mongo variables
@@ -20,13 +22,15 @@ var (
 )
 
 // InitMongoClient initiates the connection to mongodb server
+// MONGO_URL and MONGO_DATABASE override the local server and database.
 func InitMongoClient() {
 	var err error
-	Client, err = mongo.NewClient(options.Client().ApplyURI(mongoURL))
+	Client, err = mongo.NewClient(options.Client().ApplyURI(getURL()))
 	if err != nil {
 		panic(err)
 	}
-	ctx, _ := context.WithTimeout(context.Background(), 10*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
 
 	err = Client.Connect(ctx)
 	if err != nil {
@@ -34,3 +38,19 @@ func InitMongoClient() {
 	}
 	log.Println("Database successfully connected")
 }
+
+// Database of the application
+func Database() *mongo.Database {
+	name := os.Getenv("MONGO_DATABASE")
+	if name == "" {
+		name = mongoDatabase
+	}
+	return Client.Database(name)
+}
+
+func getURL() string {
+	if url := os.Getenv("MONGO_URL"); url != "" {
+		return url
+	}
+	return mongoURL
+}
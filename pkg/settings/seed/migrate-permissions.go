@@ -0,0 +1,93 @@
+package seed
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gorabc/pkg/settings/db/mongodb"
+	"gorabc/pkg/utils/resterr"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// MigratePermissionNames renames the legacy CanCreateX permission names to the
+// structured domain:resource:action names in the permission catalogue, the
+// role permissions and the user permissions
+func MigratePermissionNames() *resterr.RestErr {
+	var migrated int = 0
+
+	permissions := LoadPermissions()
+	for i := 0; i < len(permissions); i++ {
+		if permissions[i].LegacyName == "" {
+			continue
+		}
+		count, err := migratePermissionName(permissions[i].LegacyName, permissions[i].Name)
+		if err != nil {
+			return err
+		}
+		migrated += count
+	}
+
+	fmt.Println("\n//****************************************************//")
+	fmt.Printf("%v documents migrated to structured permission names.", migrated)
+	fmt.Println("\n//****************************************************//")
+	return nil
+}
+
+// migratePermissionName renames a single permission everywhere it is stored
+func migratePermissionName(legacyName string, name string) (int, *resterr.RestErr) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	userDB := mongodb.Database()
+
+	migrated := 0
+
+	// Permission catalogue
+	permissionCollection := userDB.Collection("permission")
+	count, err := permissionCollection.CountDocuments(ctx, bson.M{"name": name})
+	if err != nil {
+		return 0, resterr.NewInternalServerError(err.Error())
+	}
+	if count > 0 {
+		result, err := permissionCollection.DeleteMany(ctx, bson.M{"name": legacyName})
+		if err != nil {
+			return 0, resterr.NewInternalServerError(err.Error())
+		}
+		migrated += int(result.DeletedCount)
+	} else {
+		update := bson.D{
+			{Key: "$set", Value: bson.D{
+				{Key: "name", Value: name},
+				{Key: "legacy_name", Value: legacyName},
+			}},
+		}
+		result, err := permissionCollection.UpdateMany(ctx, bson.M{"name": legacyName}, update)
+		if err != nil {
+			return 0, resterr.NewInternalServerError(err.Error())
+		}
+		migrated += int(result.ModifiedCount)
+	}
+
+	// Role and user permissions
+	arrayFilters := options.ArrayFilters{
+		Filters: []interface{}{bson.M{"p.name": legacyName}},
+	}
+	opts := options.Update().SetArrayFilters(arrayFilters)
+	update := bson.D{
+		{Key: "$set", Value: bson.D{
+			{Key: "permissions.$[p].name", Value: name},
+		}},
+	}
+	collections := []string{"role-permissions", "user-permissions"}
+	for i := 0; i < len(collections); i++ {
+		result, err := userDB.Collection(collections[i]).UpdateMany(ctx, bson.M{"permissions.name": legacyName}, update, opts)
+		if err != nil {
+			return 0, resterr.NewInternalServerError(err.Error())
+		}
+		migrated += int(result.ModifiedCount)
+	}
+
+	return migrated, nil
+}
@@ -0,0 +1,30 @@
+package seed
+
+import (
+	"context"
+	"fmt"
+
+	"gorabc/pkg/logic/helpers"
+	"gorabc/pkg/repository/dao"
+	"gorabc/pkg/utils/resterr"
+)
+
+// MirrorRelationTuples mirrors the memberships of every organization into
+// relation tuples, memberships changed afterwards are mirrored by the services
+func MirrorRelationTuples(ctx context.Context) *resterr.RestErr {
+	organizations, err := dao.OrganizationDao.FindAll(ctx)
+	if err != nil {
+		return err
+	}
+
+	for i := 0; i < len(organizations); i++ {
+		if err := helpers.MirrorOrganization(ctx, organizations[i].ID); err != nil {
+			return err
+		}
+	}
+
+	fmt.Println("\n//****************************************************//")
+	fmt.Printf("Memberships of %v organizations mirrored into relation tuples.", len(organizations))
+	fmt.Println("\n//****************************************************//")
+	return nil
+}
@@ -17,3 +17,13 @@ func GetDateTime() time.Time {
 func GetDateTimeString() string {
 	return GetDateTime().Format(dateTimeLayout)
 }
+
+// ParseDateTimeString parses a datetime string of the service layout
+func ParseDateTimeString(value string) (time.Time, error) {
+	return time.ParseInLocation(dateTimeLayout, value, time.Local)
+}
+
+// FormatDateTime formats a time with the service layout
+func FormatDateTime(value time.Time) string {
+	return value.Format(dateTimeLayout)
+}
@@ -2,6 +2,8 @@ package encrypt
 
 import (
 	"crypto/md5"
+	crand "crypto/rand"
+	"crypto/sha256"
 	"encoding/hex"
 	"math/rand"
 	"time"
@@ -43,3 +45,18 @@ func GenerateID(n int) string {
 
 	return *(*string)(unsafe.Pointer(&b))
 }
+
+// GenerateToken returns a random secret of n bytes in hex, for one-time tokens
+func GenerateToken(n int) string {
+	b := make([]byte, n)
+	if _, err := crand.Read(b); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(b)
+}
+
+// HashToken function, tokens are only stored hashed
+func HashToken(token string) string {
+	hash := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(hash[:])
+}
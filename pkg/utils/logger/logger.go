@@ -1,6 +1,10 @@
 package logger
 
 import (
+	"context"
+	"os"
+	"strings"
+
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 )
@@ -20,64 +24,79 @@ type logger struct {
 	log *zap.Logger
 }
 
+// contextKey of the request logger
+type contextKey struct{}
+
 func init() {
 	logConfig := zap.Config{
-		OutputPaths: []string{"stdout"},
-		Level:       zap.NewAtomicLevelAt(zap.InfoLevel),
-		Encoding:    "json",
+		OutputPaths:      []string{getOutput()},
+		ErrorOutputPaths: []string{"stderr"},
+		Level:            zap.NewAtomicLevelAt(getLevel()),
+		Encoding:         "json",
 		EncoderConfig: zapcore.EncoderConfig{
-			LevelKey:     "level",
-			TimeKey:      "time",
-			MessageKey:   "msg",
-			EncodeTime:   zapcore.ISO8601TimeEncoder,
-			EncodeLevel:  zapcore.LowercaseLevelEncoder,
-			EncodeCaller: zapcore.ShortCallerEncoder,
+			LevelKey:       "level",
+			TimeKey:        "time",
+			MessageKey:     "msg",
+			EncodeTime:     zapcore.ISO8601TimeEncoder,
+			EncodeLevel:    zapcore.LowercaseLevelEncoder,
+			EncodeCaller:   zapcore.ShortCallerEncoder,
+			EncodeDuration: zapcore.MillisDurationEncoder,
 		},
 	}
 
 	var err error
-	if log.log, err = logConfig.Build(); err != nil {
+	log.log, err = logConfig.Build(zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+		return redactCore{core}
+	}))
+	if err != nil {
 		panic(err)
 	}
 }
 
-// GetLogger function
-// func GetLogger() {
-// 	return log
-// }
+// GetLogger returns the application logger
+func GetLogger() *zap.Logger {
+	return log.log
+}
 
-// func getLevel() zapcore.Level {
-// 	switch strings.ToLower(strings.TrimSpace(os.Getenv(envLogLevel))) {
-// 	case "debug":
-// 		return zap.DebugLevel
-// 	case "info":
-// 		return zap.InfoLevel
-// 	case "error":
-// 		return zap.ErrorLevel
-// 	default:
-// 		return zap.InfoLevel
-// 	}
-// }
+// NewContext returns a context whose logger adds the fields, the fields of
+// the logger already in the context are kept
+func NewContext(ctx context.Context, fields ...zap.Field) context.Context {
+	return context.WithValue(ctx, contextKey{}, FromContext(ctx).With(fields...))
+}
 
-// func getOutput() string {
-// 	output := strings.TrimSpace(os.Getenv(envLogOutput))
-// 	if output == "" {
-// 		return "stdout"
-// 	}
-// 	return output
-// }
+// FromContext returns the logger of the context, the application logger when
+// the context has none
+func FromContext(ctx context.Context) *zap.Logger {
+	if ctx != nil {
+		if l, ok := ctx.Value(contextKey{}).(*zap.Logger); ok {
+			return l
+		}
+	}
+	return log.log
+}
 
-// func (l logger) Printf(format string, v ...interface{}) {
-// 	if len(v) == 0 {
-// 		Info(format)
-// 	} else {
-// 		Info(fmt.Sprintf(format, v...))
-// 	}
-// }
+func getLevel() zapcore.Level {
+	switch strings.ToLower(strings.TrimSpace(os.Getenv(envLogLevel))) {
+	case "debug":
+		return zap.DebugLevel
+	case "info":
+		return zap.InfoLevel
+	case "warn":
+		return zap.WarnLevel
+	case "error":
+		return zap.ErrorLevel
+	default:
+		return zap.InfoLevel
+	}
+}
 
-// func (l logger) Print(v ...interface{}) {
-// 	Info(fmt.Sprintf("%v", v))
-// }
+func getOutput() string {
+	output := strings.TrimSpace(os.Getenv(envLogOutput))
+	if output == "" {
+		return "stdout"
+	}
+	return output
+}
 
 // Info logger function
 func Info(msg string, tags ...zap.Field) {
@@ -0,0 +1,64 @@
+package logger
+
+import (
+	"strings"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Redacted replaces the values of secret fields
+const Redacted = "[REDACTED]"
+
+// secretKeys are the parts of field names whose values are never logged
+var secretKeys = []string{"password", "token", "secret", "authorization"}
+
+// IsSecret reports whether the values of the key must be redacted
+func IsSecret(key string) bool {
+	key = strings.ToLower(key)
+	for i := 0; i < len(secretKeys); i++ {
+		if strings.Contains(key, secretKeys[i]) {
+			return true
+		}
+	}
+	return false
+}
+
+// redactCore replaces the values of the secret fields before encoding
+type redactCore struct {
+	zapcore.Core
+}
+
+func (c redactCore) With(fields []zapcore.Field) zapcore.Core {
+	return redactCore{c.Core.With(redact(fields))}
+}
+
+func (c redactCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(entry.Level) {
+		return checked.AddCore(entry, c)
+	}
+	return checked
+}
+
+func (c redactCore) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	return c.Core.Write(entry, redact(fields))
+}
+
+// redact returns the fields with the secret values replaced
+func redact(fields []zapcore.Field) []zapcore.Field {
+	var result []zapcore.Field
+	for i := 0; i < len(fields); i++ {
+		if !IsSecret(fields[i].Key) {
+			continue
+		}
+		if result == nil {
+			result = make([]zapcore.Field, len(fields))
+			copy(result, fields)
+		}
+		result[i] = zap.String(fields[i].Key, Redacted)
+	}
+	if result == nil {
+		return fields
+	}
+	return result
+}
@@ -0,0 +1,81 @@
+package mailer
+
+import (
+	"log"
+	"net/smtp"
+	"strings"
+
+	"gorabc/pkg/utils/logger"
+	"gorabc/pkg/utils/resterr"
+
+	"go.uber.org/zap"
+)
+
+// Message to send
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer sends messages, plug in another implementation with SetMailer
+type Mailer interface {
+	Send(Message) error
+}
+
+// mailer variable
+var (
+	mailer Mailer = LogMailer{}
+)
+
+// SetMailer replaces the mailer
+func SetMailer(m Mailer) {
+	mailer = m
+}
+
+// Send a message with the configured mailer
+func Send(message Message) *resterr.RestErr {
+	if err := mailer.Send(message); err != nil {
+		logger.Error("Sending email failed", err, zap.String("to", message.To))
+		return resterr.NewUnavailableError("Sending email failed")
+	}
+	return nil
+}
+
+// LogMailer writes messages to the log instead of sending them, for development
+type LogMailer struct{}
+
+// Send Message
+func (m LogMailer) Send(message Message) error {
+	log.Printf("Mail to %s: %s\n%s", message.To, message.Subject, message.Body)
+	return nil
+}
+
+// SMTPMailer sends messages through an SMTP server
+type SMTPMailer struct {
+	Addr     string
+	From     string
+	Username string
+	Password string
+}
+
+// Send Message
+func (m SMTPMailer) Send(message Message) error {
+	var auth smtp.Auth
+	if m.Username != "" {
+		host := m.Addr
+		if i := strings.LastIndex(host, ":"); i >= 0 {
+			host = host[:i]
+		}
+		auth = smtp.PlainAuth("", m.Username, m.Password, host)
+	}
+
+	// no header injection through the recipient or the subject
+	header := strings.NewReplacer("\r", "", "\n", "")
+	body := "From: " + m.From + "\r\n" +
+		"To: " + header.Replace(message.To) + "\r\n" +
+		"Subject: " + header.Replace(message.Subject) + "\r\n" +
+		"Content-Type: text/plain; charset=UTF-8\r\n" +
+		"\r\n" + message.Body
+	return smtp.SendMail(m.Addr, auth, m.From, []string{message.To}, []byte(body))
+}
@@ -0,0 +1,149 @@
+package metrics
+
+import (
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Metric namespace
+const namespace = "gorabc"
+
+// OtherPermission labels the checks of names outside of the catalogue
+const OtherPermission = "other"
+
+// Token types
+const (
+	TokenAccess        = "access"
+	TokenInvite        = "invite"
+	TokenImpersonation = "impersonation"
+)
+
+var (
+	httpRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "Latency of the HTTP requests by route.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	logins = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "logins_total",
+		Help:      "Login attempts by result.",
+	}, []string{"result"})
+
+	permissionChecks = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "permission_checks_total",
+		Help:      "Permission checks by permission and decision.",
+	}, []string{"permission", "decision"})
+
+	tokensIssued = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "tokens_issued_total",
+		Help:      "Signed tokens issued by type.",
+	}, []string{"type"})
+
+	tokenValidationFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "token_validation_failures_total",
+		Help:      "Rejected tokens by type.",
+	}, []string{"type"})
+
+	daoDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "dao_operation_duration_seconds",
+		Help:      "Latency of the repository operations by collection.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"collection", "operation"})
+
+	daoErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "dao_operation_errors_total",
+		Help:      "Failed repository operations by collection.",
+	}, []string{"collection", "operation"})
+)
+
+// catalogue of the permission names recorded as labels, the names checked
+// come from the requests and would grow the series without a bound
+var catalogue = struct {
+	sync.RWMutex
+	names map[string]bool
+}{names: map[string]bool{}}
+
+func init() {
+	prometheus.MustRegister(
+		httpRequestDuration,
+		logins,
+		permissionChecks,
+		tokensIssued,
+		tokenValidationFailures,
+		daoDuration,
+		daoErrors,
+	)
+}
+
+// ObserveRequest records the latency of a served request, route is the
+// pattern of the route to bound the label values
+func ObserveRequest(method string, route string, status int, duration time.Duration) {
+	httpRequestDuration.WithLabelValues(method, route, strconv.Itoa(status)).Observe(duration.Seconds())
+}
+
+// ObserveLogin counts a login attempt
+func ObserveLogin(success bool) {
+	result := "failure"
+	if success {
+		result = "success"
+	}
+	logins.WithLabelValues(result).Inc()
+}
+
+// ObservePermission counts a permission check, names outside of the
+// catalogue are counted as other
+func ObservePermission(permission string, granted bool) {
+	decision := "denied"
+	if granted {
+		decision = "granted"
+	}
+	catalogue.RLock()
+	if !catalogue.names[permission] {
+		permission = OtherPermission
+	}
+	catalogue.RUnlock()
+	permissionChecks.WithLabelValues(permission, decision).Inc()
+}
+
+// SetPermissions replaces the catalogue of the permission names recorded by
+// ObservePermission
+func SetPermissions(names []string) {
+	index := make(map[string]bool, len(names))
+	for i := 0; i < len(names); i++ {
+		index[names[i]] = true
+	}
+	catalogue.Lock()
+	catalogue.names = index
+	catalogue.Unlock()
+}
+
+// TokenIssued counts a signed token
+func TokenIssued(tokenType string) {
+	tokensIssued.WithLabelValues(tokenType).Inc()
+}
+
+// TokenRejected counts a token failing validation
+func TokenRejected(tokenType string) {
+	tokenValidationFailures.WithLabelValues(tokenType).Inc()
+}
+
+// ObserveOperation records the latency of a repository operation
+func ObserveOperation(collection string, operation string, duration time.Duration) {
+	daoDuration.WithLabelValues(collection, operation).Observe(duration.Seconds())
+}
+
+// OperationFailed counts a failed repository operation
+func OperationFailed(collection string, operation string) {
+	daoErrors.WithLabelValues(collection, operation).Inc()
+}
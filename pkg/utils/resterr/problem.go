@@ -0,0 +1,35 @@
+package resterr
+
+import (
+	"net/http"
+)
+
+// ProblemContentType of the error responses
+const ProblemContentType = "application/problem+json"
+
+// Problem details of an error response (RFC 7807)
+type Problem struct {
+	Type      string       `json:"type"`
+	Title     string       `json:"title"`
+	Status    int          `json:"status"`
+	Detail    string       `json:"detail,omitempty"`
+	Instance  string       `json:"instance,omitempty"`
+	Code      string       `json:"code"`
+	RequestID string       `json:"request_id,omitempty"`
+	Errors    []FieldError `json:"errors,omitempty"`
+}
+
+// Problem details of the error raised by the request to the instance path,
+// the code tells the errors of a status apart
+func (e *RestErr) Problem(instance string, requestID string) Problem {
+	return Problem{
+		Type:      "about:blank",
+		Title:     http.StatusText(e.Status),
+		Status:    e.Status,
+		Detail:    e.Message,
+		Instance:  instance,
+		Code:      e.Code,
+		RequestID: requestID,
+		Errors:    e.Fields,
+	}
+}
@@ -4,11 +4,34 @@ import (
 	"net/http"
 )
 
+// Error codes, stable across releases for the clients to branch on
+const (
+	CodeBadRequest   = "bad_request"
+	CodeValidation   = "validation_failed"
+	CodeUnauthorized = "unauthorized"
+	CodeForbidden    = "forbidden"
+	CodeNotFound     = "not_found"
+	CodeConflict     = "conflict"
+	CodeRateLimited  = "rate_limited"
+	CodeUnavailable  = "unavailable"
+	CodeInternal     = "internal_server_error"
+)
+
 // RestErr structure
 type RestErr struct {
 	Message string `json:"message"`
 	Status  int    `json:"status"`
-	Error   string `json:"error"`
+	Code    string `json:"code"`
+	// Fields lists the invalid fields of a validation error
+	Fields []FieldError `json:"fields,omitempty"`
+	// RetryAfter in seconds of a rate limited or unavailable error
+	RetryAfter int `json:"-"`
+}
+
+// FieldError describes an invalid field of a request
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
 }
 
 // NewBadRequestError structure
@@ -16,16 +39,39 @@ func NewBadRequestError(message string) *RestErr {
 	return &RestErr{
 		Message: message,
 		Status:  http.StatusBadRequest,
-		Error:   "bad_request",
+		Code:    CodeBadRequest,
+	}
+}
+
+// NewValidationError lists every invalid field of a request
+func NewValidationError(fields ...FieldError) *RestErr {
+	message := "Validation failed"
+	if len(fields) == 1 {
+		message = fields[0].Field + ": " + fields[0].Message
+	}
+	return &RestErr{
+		Message: message,
+		Status:  http.StatusBadRequest,
+		Code:    CodeValidation,
+		Fields:  fields,
 	}
 }
 
-// NewUnauthorizedError structure
+// NewUnauthorizedError for requests without valid credentials
 func NewUnauthorizedError(message string) *RestErr {
 	return &RestErr{
 		Message: message,
 		Status:  http.StatusUnauthorized,
-		Error:   "unauthorized",
+		Code:    CodeUnauthorized,
+	}
+}
+
+// NewForbiddenError for authenticated users lacking a permission or scope
+func NewForbiddenError(message string) *RestErr {
+	return &RestErr{
+		Message: message,
+		Status:  http.StatusForbidden,
+		Code:    CodeForbidden,
 	}
 }
 
@@ -34,7 +80,37 @@ func NewNotFoundError(message string) *RestErr {
 	return &RestErr{
 		Message: message,
 		Status:  http.StatusNotFound,
-		Error:   "not_found",
+		Code:    CodeNotFound,
+	}
+}
+
+// NewConflictError for requests clashing with the current state, e.g. a
+// duplicate key
+func NewConflictError(message string) *RestErr {
+	return &RestErr{
+		Message: message,
+		Status:  http.StatusConflict,
+		Code:    CodeConflict,
+	}
+}
+
+// NewRateLimitedError for clients to retry after the seconds
+func NewRateLimitedError(message string, retryAfter int) *RestErr {
+	return &RestErr{
+		Message:    message,
+		Status:     http.StatusTooManyRequests,
+		Code:       CodeRateLimited,
+		RetryAfter: retryAfter,
+	}
+}
+
+// NewUnavailableError for failing dependencies, e.g. the database or the
+// mail relay
+func NewUnavailableError(message string) *RestErr {
+	return &RestErr{
+		Message: message,
+		Status:  http.StatusServiceUnavailable,
+		Code:    CodeUnavailable,
 	}
 }
 
@@ -43,6 +119,6 @@ func NewInternalServerError(message string) *RestErr {
 	return &RestErr{
 		Message: message,
 		Status:  http.StatusInternalServerError,
-		Error:   "internal_server_error",
+		Code:    CodeInternal,
 	}
 }
@@ -0,0 +1,78 @@
+package tracing
+
+import (
+	"context"
+	"os"
+	"strings"
+
+	"gorabc/pkg/utils/resterr"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp"
+	"go.opentelemetry.io/otel/exporters/otlp/otlphttp"
+	"go.opentelemetry.io/otel/exporters/stdout"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/semconv"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Tracing constants
+const (
+	envExporter = "TRACING_EXPORTER"
+	serviceName = "gorabc"
+)
+
+// Span exporters
+const (
+	ExporterNone   = ""
+	ExporterOTLP   = "otlp"
+	ExporterStdout = "stdout"
+)
+
+// Init installs the tracer provider of the exporter named by TRACING_EXPORTER,
+// spans are dropped when none is configured. The returned function flushes
+// the pending spans on shutdown.
+func Init(ctx context.Context) (func(context.Context) error, *resterr.RestErr) {
+	// Continue the traces of the callers
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	var exporter sdktrace.SpanExporter
+	var err error
+	switch name := strings.ToLower(strings.TrimSpace(os.Getenv(envExporter))); name {
+	case ExporterNone:
+		return func(context.Context) error { return nil }, nil
+	case ExporterStdout:
+		exporter, err = stdout.NewExporter(stdout.WithPrettyPrint())
+	case ExporterOTLP:
+		// Endpoint, headers and TLS are read from OTEL_EXPORTER_OTLP_*
+		exporter, err = otlp.NewExporter(ctx, otlphttp.NewDriver())
+	default:
+		return nil, resterr.NewBadRequestError("Unknown tracing exporter: " + name)
+	}
+	if err != nil {
+		return nil, resterr.NewInternalServerError(err.Error())
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(resource.NewWithAttributes(semconv.ServiceNameKey.String(serviceName))),
+	)
+	otel.SetTracerProvider(provider)
+	return provider.Shutdown, nil
+}
+
+// Start a span as a child of the span of the context
+func Start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(serviceName).Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// Fail marks the span of the context as failed
+func Fail(ctx context.Context, err error) {
+	span := trace.SpanFromContext(ctx)
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+}
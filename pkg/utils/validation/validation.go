@@ -0,0 +1,120 @@
+package validation
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"gorabc/pkg/utils/resterr"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// validate checks the `validate` struct tags, fields are named after their
+// json tags in the errors
+var validate = newValidator()
+
+func newValidator() *validator.Validate {
+	v := validator.New()
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+		if name == "-" {
+			return ""
+		}
+		if name == "" {
+			return field.Name
+		}
+		return name
+	})
+	return v
+}
+
+// Struct validates the rules of a request and lists every invalid field,
+// nil when the request is valid
+func Struct(request interface{}) *resterr.RestErr {
+	return Error(Fields(request))
+}
+
+// Error of the invalid fields, nil when there are none
+func Error(fields []resterr.FieldError) *resterr.RestErr {
+	if len(fields) == 0 {
+		return nil
+	}
+	return resterr.NewValidationError(fields...)
+}
+
+// Field error of a check that cannot be declared on the tags
+func Field(field string, message string) resterr.FieldError {
+	return resterr.FieldError{Field: field, Message: message}
+}
+
+// Fields lists the invalid fields of a request, for requests adding checks
+// that cannot be declared on the tags
+func Fields(request interface{}) []resterr.FieldError {
+	err := validate.Struct(request)
+	if err == nil {
+		return nil
+	}
+	invalid, ok := err.(validator.ValidationErrors)
+	if !ok {
+		return []resterr.FieldError{{Field: "", Message: err.Error()}}
+	}
+
+	fields := make([]resterr.FieldError, len(invalid))
+	for i, fieldErr := range invalid {
+		fields[i] = resterr.FieldError{
+			Field:   fieldName(fieldErr),
+			Message: message(fieldErr),
+		}
+	}
+	return fields
+}
+
+// fieldName is the path of the field below the request, e.g. roles[0].role_id
+func fieldName(fieldErr validator.FieldError) string {
+	namespace := fieldErr.Namespace()
+	if i := strings.Index(namespace, "."); i >= 0 {
+		return namespace[i+1:]
+	}
+	return namespace
+}
+
+// message describes the failed rule of a field
+func message(fieldErr validator.FieldError) string {
+	param := fieldErr.Param()
+	switch fieldErr.Tag() {
+	case "required":
+		return "is required"
+	case "email":
+		return "must be a valid email address"
+	case "url":
+		return "must be a valid URL"
+	case "oneof":
+		return "must be one of " + strings.Join(strings.Fields(param), ", ")
+	case "min":
+		if param == "1" && unit(fieldErr) == " items" {
+			return "must not be empty"
+		}
+		return "must be at least " + param + unit(fieldErr)
+	case "max":
+		return "must be at most " + param + unit(fieldErr)
+	case "gte":
+		return "must be at least " + param
+	case "lte":
+		return "must be at most " + param
+	case "excludesall":
+		return "must not contain any of " + fmt.Sprintf("%q", param)
+	}
+	return "is invalid"
+}
+
+// unit of the length bounds by kind of field
+func unit(fieldErr validator.FieldError) string {
+	switch fieldErr.Kind() {
+	case reflect.String:
+		return " characters"
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return " items"
+	}
+	return ""
+}